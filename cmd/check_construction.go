@@ -20,10 +20,14 @@ import (
 	"github.com/coinbase/rosetta-cli/pkg/errors"
 	"time"
 
+	"github.com/coinbase/rosetta-cli/pkg/notifier"
 	"github.com/coinbase/rosetta-cli/pkg/results"
 	"github.com/coinbase/rosetta-cli/pkg/tester"
+	"github.com/coinbase/rosetta-cli/pkg/transport"
 
+	"github.com/coinbase/rosetta-sdk-go/client"
 	"github.com/coinbase/rosetta-sdk-go/fetcher"
+	"github.com/coinbase/rosetta-sdk-go/types"
 	"github.com/coinbase/rosetta-sdk-go/utils"
 	"github.com/spf13/cobra"
 	"golang.org/x/sync/errgroup"
@@ -76,6 +80,27 @@ func runCheckConstructionCmd(_ *cobra.Command, _ []string) error {
 		fetcherOpts = append(fetcherOpts, fetcher.WithForceRetry())
 	}
 
+	roundTripper, err := onlineRoundTripper()
+	if err != nil {
+		cancel()
+		return results.ExitConstruction(
+			Config,
+			nil,
+			nil,
+			err,
+		)
+	}
+	if Config.Construction.BlockCache != nil {
+		roundTripper = transport.NewBlockCache(Config.Construction.BlockCache, roundTripper)
+	}
+	fetcherOpts = append(fetcherOpts, fetcher.WithClient(client.NewAPIClient(
+		client.NewConfiguration(
+			Config.OnlineURL,
+			fetcher.DefaultUserAgent,
+			onlineHTTPClient(roundTripper),
+		),
+	)))
+
 	fetcher := fetcher.New(
 		Config.OnlineURL,
 		fetcherOpts...,
@@ -92,7 +117,7 @@ func runCheckConstructionCmd(_ *cobra.Command, _ []string) error {
 		)
 	}
 
-	_, err := utils.CheckNetworkSupported(ctx, Config.Network, fetcher)
+	_, err = utils.CheckNetworkSupported(ctx, Config.Network, fetcher)
 	if err != nil {
 		cancel()
 		return results.ExitConstruction(
@@ -145,6 +170,12 @@ func runCheckConstructionCmd(_ *cobra.Command, _ []string) error {
 		)
 	}
 
+	notifier.Notify(Config, &notifier.Message{
+		Command: "check:construction",
+		Event:   notifier.StartEvent,
+		Network: types.PrintStruct(Config.Network),
+	})
+
 	g, ctx := errgroup.WithContext(ctx)
 	g.Go(func() error {
 		return constructionTester.StartPeriodicLogger(ctx)
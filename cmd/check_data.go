@@ -20,9 +20,14 @@ import (
 	"github.com/coinbase/rosetta-cli/pkg/errors"
 	"time"
 
+	"github.com/coinbase/rosetta-cli/pkg/notifier"
 	"github.com/coinbase/rosetta-cli/pkg/results"
 	"github.com/coinbase/rosetta-cli/pkg/tester"
+	"github.com/coinbase/rosetta-cli/pkg/tracing"
+	"github.com/coinbase/rosetta-cli/pkg/transport"
+	"github.com/coinbase/rosetta-sdk-go/client"
 	"github.com/coinbase/rosetta-sdk-go/fetcher"
+	"github.com/coinbase/rosetta-sdk-go/types"
 	"github.com/coinbase/rosetta-sdk-go/utils"
 	"github.com/spf13/cobra"
 	"golang.org/x/sync/errgroup"
@@ -84,6 +89,52 @@ func runCheckDataCmd(_ *cobra.Command, _ []string) error {
 		fetcherOpts = append(fetcherOpts, fetcher.WithForceRetry())
 	}
 
+	tracer := tracing.NewTracer(Config.Data.Tracing)
+	roundTripper, err := onlineRoundTripper()
+	if err != nil {
+		cancel()
+		return results.ExitData(
+			Config,
+			nil,
+			nil,
+			nil,
+			nil,
+			nil,
+			nil,
+			err,
+			"",
+			"",
+		)
+	}
+
+	if Config.AdaptiveSyncConcurrency != nil {
+		roundTripper = transport.NewAdaptiveLimiter(
+			Config.AdaptiveSyncConcurrency,
+			Config.MaxSyncConcurrency,
+			roundTripper,
+		)
+	}
+	roundTripper = skipBlocksRoundTripper(roundTripper)
+
+	// Blocks that fail assertion are recorded (once the
+	// asserter is initialized) instead of halting syncing.
+	var assertionFailures *transport.AssertionFailures
+	if Config.Data.ContinueOnError {
+		assertionFailures = transport.NewAssertionFailures(roundTripper)
+		roundTripper = assertionFailures
+	}
+	otherTransactions := transport.NewOtherTransactions(
+		Config.Data.OtherTransactionConcurrency,
+		roundTripper,
+	)
+	fetcherOpts = append(fetcherOpts, fetcher.WithClient(client.NewAPIClient(
+		client.NewConfiguration(
+			Config.OnlineURL,
+			fetcher.DefaultUserAgent,
+			onlineHTTPClient(tracer.Transport(otherTransactions)),
+		),
+	)))
+
 	fetcher := fetcher.New(
 		Config.OnlineURL,
 		fetcherOpts...,
@@ -96,6 +147,10 @@ func runCheckDataCmd(_ *cobra.Command, _ []string) error {
 			Config,
 			nil,
 			nil,
+			nil,
+			nil,
+			nil,
+			nil,
 			fmt.Errorf("%w: unable to initialize asserter", fetchErr.Err),
 			"",
 			"",
@@ -109,6 +164,10 @@ func runCheckDataCmd(_ *cobra.Command, _ []string) error {
 			Config,
 			nil,
 			nil,
+			nil,
+			nil,
+			nil,
+			nil,
 			fmt.Errorf("%w: unable to confirm network", err),
 			"",
 			"",
@@ -124,6 +183,10 @@ func runCheckDataCmd(_ *cobra.Command, _ []string) error {
 				Config,
 				nil,
 				nil,
+				nil,
+				nil,
+				nil,
+				nil,
 				err,
 				"",
 				"",
@@ -136,6 +199,9 @@ func runCheckDataCmd(_ *cobra.Command, _ []string) error {
 		Config,
 		Config.Network,
 		fetcher,
+		tracer,
+		otherTransactions,
+		assertionFailures,
 		cancel,
 		networkStatus.GenesisBlockIdentifier,
 		nil, // only populated when doing recursive search
@@ -143,9 +209,14 @@ func runCheckDataCmd(_ *cobra.Command, _ []string) error {
 	)
 
 	if err != nil {
-		return fmt.Errorf("%s:%s", errors.ErrInitDataTester, err)
+		return fmt.Errorf("%w: %s", err, errors.ErrInitDataTester.Error())
 	}
-	defer dataTester.CloseDatabase(ctx)
+
+	notifier.Notify(Config, &notifier.Message{
+		Command: "check:data",
+		Event:   notifier.StartEvent,
+		Network: types.PrintStruct(Config.Network),
+	})
 
 	g, ctx := errgroup.WithContext(ctx)
 	g.Go(func() error {
@@ -172,10 +243,38 @@ func runCheckDataCmd(_ *cobra.Command, _ []string) error {
 		return dataTester.StartReconcilerCountUpdater(ctx)
 	})
 
+	g.Go(func() error {
+		return dataTester.StartNetworkStatusMonitor(ctx)
+	})
+
+	g.Go(func() error {
+		return dataTester.StartCounterSnapshots(ctx)
+	})
+
+	g.Go(func() error {
+		return dataTester.StartDiskUsageMonitor(ctx)
+	})
+
+	g.Go(func() error {
+		return dataTester.StartRetention(ctx)
+	})
+
+	g.Go(func() error {
+		return dataTester.StartInterestingAccountsReloader(ctx)
+	})
+
+	g.Go(func() error {
+		return dataTester.StartReconciliationPriority(ctx)
+	})
+
 	g.Go(func() error {
 		return tester.LogMemoryLoop(ctx)
 	})
 
+	g.Go(func() error {
+		return tracer.StartExporter(ctx)
+	})
+
 	g.Go(func() error {
 		return tester.StartServer(
 			ctx,
@@ -185,10 +284,27 @@ func runCheckDataCmd(_ *cobra.Command, _ []string) error {
 		)
 	})
 
+	if Config.Data.ControlPort != 0 {
+		g.Go(func() error {
+			return tester.StartLocalServer(
+				ctx,
+				"check:data control",
+				dataTester.ControlHandler(),
+				Config.Data.ControlPort,
+			)
+		})
+	}
+
 	sigListeners := []context.CancelFunc{cancel}
 	go handleSignals(&sigListeners)
 
-	// HandleErr will exit if we should not attempt
-	// to find missing operations.
-	return dataTester.HandleErr(g.Wait(), &sigListeners)
+	// HandleErr returns the result of check:data (after searching
+	// for missing operations, if applicable).
+	err = dataTester.HandleErr(g.Wait(), &sigListeners)
+	dataTester.OutputHTMLReport(err)
+	if closeErr := dataTester.CloseDatabase(ctx); closeErr != nil && err == nil {
+		return closeErr
+	}
+
+	return err
 }
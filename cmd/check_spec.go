@@ -19,6 +19,7 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/coinbase/rosetta-cli/pkg/errors"
 	"github.com/coinbase/rosetta-cli/pkg/results"
 	"github.com/coinbase/rosetta-sdk-go/fetcher"
 	"github.com/coinbase/rosetta-sdk-go/types"
@@ -97,6 +98,10 @@ func newCheckSpec(ctx context.Context) (*checkSpec, error) {
 			Config,
 			nil,
 			nil,
+			nil,
+			nil,
+			nil,
+			nil,
 			fmt.Errorf("%v: unable to initialize asserter for online node fetcher", fetchErr.Err),
 			"",
 			"",
@@ -443,5 +448,13 @@ func runCheckSpecCmd(_ *cobra.Command, _ []string) error {
 		printCheckSpecOutputBody(o)
 	}
 
+	junit := checkSpecJUnit(output)
+	junit.Output(Config.JUnitOutputFile)
+	junit.PrintAnnotations(Config.GitHubAnnotations)
+
+	if junit.Failures > 0 {
+		return fmt.Errorf("%w: %d requirements failed", errors.ErrCheckSpecFailed, junit.Failures)
+	}
+
 	return nil
 }
@@ -17,8 +17,10 @@ package cmd
 import (
 	"errors"
 	"fmt"
+	"sort"
 	"strconv"
 
+	"github.com/coinbase/rosetta-cli/pkg/results"
 	"github.com/coinbase/rosetta-sdk-go/fetcher"
 	"github.com/fatih/color"
 )
@@ -183,3 +185,28 @@ func printCheckSpecOutputBody(output checkSpecOutput) {
 		printInfo("%v\n", "+--------------------------+-------------------------------------------------------------------+-----------+-----------------+")
 	}
 }
+
+// checkSpecJUnit converts checkSpecOutput into a *results.JUnitTestSuite
+// with a test case for each API requirement.
+func checkSpecJUnit(output []checkSpecOutput) *results.JUnitTestSuite {
+	suite := results.NewJUnitTestSuite("check:spec")
+	for _, o := range output {
+		requirements := make([]string, 0, len(o.validation))
+		for k := range o.validation {
+			requirements = append(requirements, string(k))
+		}
+		sort.Strings(requirements)
+
+		for _, req := range requirements {
+			v := o.validation[checkSpecRequirement(req)]
+			passed := v.status == checkSpecSuccess
+			suite.AddCase(
+				fmt.Sprintf("%s: %s", o.api, req),
+				&passed,
+				fmt.Sprintf("coinbase spec: %t", v.coinbaseSpec),
+			)
+		}
+	}
+
+	return suite
+}
@@ -0,0 +1,99 @@
+// Copyright 2022 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path"
+
+	"github.com/coinbase/rosetta-cli/pkg/tester"
+
+	"github.com/coinbase/rosetta-sdk-go/types"
+	"github.com/spf13/cobra"
+)
+
+var (
+	dbExportCheckpointCmd = &cobra.Command{
+		Use:   "db:export-checkpoint",
+		Short: "Package check:data storage into a checkpoint archive",
+		Long: `This command packages the check:data storage in the configured
+data_directory into a single gzipped tar archive with a manifest that
+records the network, the head block, and a hash of the check:data
+configuration. The archive can be imported on another machine with
+db:import-checkpoint so a check can continue where it left off.
+
+The argument for this command is the output path (which must not
+exist). This command cannot be run while check:data is running on
+the same data_directory.`,
+		RunE: runDBExportCheckpointCmd,
+		Args: cobra.ExactArgs(1),
+	}
+
+	dbImportCheckpointCmd = &cobra.Command{
+		Use:   "db:import-checkpoint",
+		Short: "Restore check:data storage from a checkpoint archive",
+		Long: `This command verifies the manifest of an archive written by
+db:export-checkpoint and then extracts it into the configured
+data_directory. The network and check:data configuration must match
+the configuration the checkpoint was created with and the
+data_directory must not already contain check:data storage for the
+network. After extracting, the head block of the storage is checked
+against the manifest.
+
+The argument for this command is the archive path.`,
+		RunE: runDBImportCheckpointCmd,
+		Args: cobra.ExactArgs(1),
+	}
+)
+
+func runDBExportCheckpointCmd(cmd *cobra.Command, args []string) error {
+	output := path.Clean(args[0])
+	f, err := os.OpenFile(output, os.O_CREATE|os.O_EXCL|os.O_WRONLY, snapshotFileMode)
+	if err != nil {
+		return fmt.Errorf("%w: unable to create %s", err, output)
+	}
+	defer f.Close()
+
+	manifest, err := tester.ExportCheckpoint(Context, Config, f)
+	if err != nil {
+		_ = os.Remove(output)
+		return fmt.Errorf("%w: unable to export checkpoint", err)
+	}
+
+	if err := f.Sync(); err != nil {
+		return fmt.Errorf("%w: unable to write %s", err, output)
+	}
+
+	log.Printf("Checkpoint: %s\n", types.PrettyPrintStruct(manifest))
+	return nil
+}
+
+func runDBImportCheckpointCmd(cmd *cobra.Command, args []string) error {
+	f, err := os.Open(path.Clean(args[0]))
+	if err != nil {
+		return fmt.Errorf("%w: unable to open %s", err, args[0])
+	}
+	defer f.Close()
+
+	manifest, err := tester.ImportCheckpoint(Context, Config, f)
+	if err != nil {
+		return fmt.Errorf("%w: unable to import checkpoint", err)
+	}
+
+	log.Printf("Checkpoint: %s\n", types.PrettyPrintStruct(manifest))
+	return nil
+}
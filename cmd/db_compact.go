@@ -0,0 +1,70 @@
+// Copyright 2022 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/coinbase/rosetta-cli/pkg/tester"
+
+	"github.com/spf13/cobra"
+)
+
+const (
+	bytesPerMB = 1 << 20
+)
+
+var (
+	dbCompactCmd = &cobra.Command{
+		Use:   "db:compact",
+		Short: "Reclaim disk space used by check:data and check:construction",
+		Long: `Long syncs leave behind value logs and tables that Badger only
+partially reclaims while check:data or check:construction is running.
+This command compacts all tables and garbage collects the value logs
+of the databases in the configured data_directory and reports the
+space reclaimed.
+
+This command cannot be run while check:data or check:construction
+is running on the same data_directory.`,
+		RunE: runDBCompactCmd,
+	}
+)
+
+func runDBCompactCmd(cmd *cobra.Command, args []string) error {
+	compactionResults, err := tester.CompactStorage(Context, Config)
+	if err != nil {
+		return fmt.Errorf("%w: unable to compact storage", err)
+	}
+
+	if len(compactionResults) == 0 {
+		log.Printf("No storage found in %s\n", Config.DataDirectory)
+		return nil
+	}
+
+	for _, result := range compactionResults {
+		log.Printf(
+			"Compacted %s storage at %s: %fMB -> %fMB (reclaimed %fMB, %d value logs rewritten)\n",
+			result.Command,
+			result.Path,
+			float64(result.SizeBefore)/bytesPerMB,
+			float64(result.SizeAfter)/bytesPerMB,
+			float64(result.Reclaimed())/bytesPerMB,
+			result.ValueLogsGC,
+		)
+	}
+
+	return nil
+}
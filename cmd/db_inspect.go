@@ -0,0 +1,50 @@
+// Copyright 2022 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/coinbase/rosetta-cli/pkg/tester"
+
+	"github.com/coinbase/rosetta-sdk-go/types"
+	"github.com/spf13/cobra"
+)
+
+var (
+	dbInspectCmd = &cobra.Command{
+		Use:   "db:inspect",
+		Short: "Print a summary of check:data storage without syncing",
+		Long: `This command prints the head block, the number of stored blocks,
+the number of tracked accounts, the size of the database on disk, and
+all counters of the check:data storage in the configured data_directory.
+No requests are made to the Rosetta implementation.
+
+This command cannot be run while check:data is running on the same
+data_directory.`,
+		RunE: runDBInspectCmd,
+	}
+)
+
+func runDBInspectCmd(cmd *cobra.Command, args []string) error {
+	inspection, err := tester.InspectStorage(Context, Config)
+	if err != nil {
+		return fmt.Errorf("%w: unable to inspect storage", err)
+	}
+
+	log.Printf("Storage: %s\n", types.PrettyPrintStruct(inspection))
+	return nil
+}
@@ -0,0 +1,112 @@
+// Copyright 2022 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"fmt"
+	"log"
+	"strconv"
+	"time"
+
+	"github.com/coinbase/rosetta-cli/pkg/tester"
+
+	"github.com/coinbase/rosetta-sdk-go/client"
+	"github.com/coinbase/rosetta-sdk-go/fetcher"
+	"github.com/coinbase/rosetta-sdk-go/types"
+	"github.com/spf13/cobra"
+)
+
+var (
+	dbRecheckCmd = &cobra.Command{
+		Use:   "db:recheck",
+		Short: "Fetch a range of synced blocks again and compare with storage",
+		Long: `This command fetches a range of blocks that check:data already
+synced and validated, applies their balance changes to a scratch store,
+and compares the blocks and the net balance change of every account with
+the check:data storage in the configured data_directory. Any difference
+indicates nondeterminism in the implementation. The check:data storage
+is not modified.
+
+The arguments for this command are the start and end index of the range
+(inclusive). The range must not have been pruned, so pruning should be
+disabled (or the range must be within max_reorg_depth of the head block).
+This command cannot be run while check:data is running on the same
+data_directory.`,
+		RunE: runDBRecheckCmd,
+		Args: cobra.ExactArgs(2),
+	}
+)
+
+func runDBRecheckCmd(cmd *cobra.Command, args []string) error {
+	startIndex, err := strconv.ParseInt(args[0], 10, 64)
+	if err != nil {
+		return fmt.Errorf("%w: unable to parse start index %s", err, args[0])
+	}
+
+	endIndex, err := strconv.ParseInt(args[1], 10, 64)
+	if err != nil {
+		return fmt.Errorf("%w: unable to parse end index %s", err, args[1])
+	}
+
+	fetcherOpts := []fetcher.Option{
+		fetcher.WithMaxConnections(Config.MaxOnlineConnections),
+		fetcher.WithRetryElapsedTime(time.Duration(Config.RetryElapsedTime) * time.Second),
+		fetcher.WithTimeout(time.Duration(Config.HTTPTimeout) * time.Second),
+		fetcher.WithMaxRetries(Config.MaxRetries),
+	}
+	if Config.ForceRetry {
+		fetcherOpts = append(fetcherOpts, fetcher.WithForceRetry())
+	}
+
+	roundTripper, err := onlineRoundTripper()
+	if err != nil {
+		return err
+	}
+	fetcherOpts = append(fetcherOpts, fetcher.WithClient(client.NewAPIClient(
+		client.NewConfiguration(
+			Config.OnlineURL,
+			fetcher.DefaultUserAgent,
+			onlineHTTPClient(skipBlocksRoundTripper(roundTripper)),
+		),
+	)))
+
+	newFetcher := fetcher.New(
+		Config.OnlineURL,
+		fetcherOpts...,
+	)
+
+	_, _, fetchErr := newFetcher.InitializeAsserter(Context, Config.Network, Config.ValidationFile)
+	if fetchErr != nil {
+		return fmt.Errorf("%w: unable to initialize asserter", fetchErr.Err)
+	}
+
+	result, err := tester.RecheckRange(Context, Config, newFetcher, startIndex, endIndex)
+	if err != nil {
+		return fmt.Errorf("%w: unable to recheck blocks", err)
+	}
+
+	log.Printf("Recheck: %s\n", types.PrettyPrintStruct(result))
+	if !result.Consistent() {
+		return fmt.Errorf(
+			"found %d block and %d balance mismatches between %d and %d",
+			len(result.BlockMismatches),
+			len(result.BalanceMismatches),
+			startIndex,
+			endIndex,
+		)
+	}
+
+	return nil
+}
@@ -0,0 +1,63 @@
+// Copyright 2022 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/coinbase/rosetta-cli/pkg/tester"
+
+	"github.com/coinbase/rosetta-sdk-go/types"
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+)
+
+var (
+	dbRepairCmd = &cobra.Command{
+		Use:   "db:repair",
+		Short: "Recover check:data storage after an unclean shutdown",
+		Long: `When check:data is killed without closing its database (for
+example, when the host loses power), Badger may refuse to open the
+data directory. This command truncates corrupted value log entries and
+rewinds the head block to the last block that can still be read, so
+check:data can resume syncing without deleting the data directory.
+
+Balance changes applied by rewound blocks cannot be reverted. If the
+head is rewound and check:data later reports a reconciliation failure,
+the data directory must be deleted.
+
+This command cannot be run while check:data is running on the same
+data_directory.`,
+		RunE: runDBRepairCmd,
+	}
+)
+
+func runDBRepairCmd(cmd *cobra.Command, args []string) error {
+	result, err := tester.RepairStorage(Context, Config)
+	if err != nil {
+		return fmt.Errorf("%w: unable to repair storage", err)
+	}
+
+	log.Printf("Repair: %s\n", types.PrettyPrintStruct(result))
+	if result.BlocksRewound > 0 {
+		color.Yellow(
+			"Head rewound by %d blocks: balances may include changes from rewound blocks",
+			result.BlocksRewound,
+		)
+	}
+
+	return nil
+}
@@ -0,0 +1,103 @@
+// Copyright 2022 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"fmt"
+	"log"
+	"strconv"
+
+	"github.com/coinbase/rosetta-cli/pkg/tester"
+
+	"github.com/coinbase/rosetta-sdk-go/asserter"
+	"github.com/coinbase/rosetta-sdk-go/types"
+	"github.com/coinbase/rosetta-sdk-go/utils"
+	"github.com/spf13/cobra"
+)
+
+var (
+	dbReplayCmd = &cobra.Command{
+		Use:   "db:replay <asserter-configuration-file> [start index] [end index]",
+		Short: "Re-run assertions and balance computation on stored blocks",
+		Long: `This command re-runs block assertions and balance computation on
+the blocks that check:data already stored in the configured data_directory
+without making any requests to the node. This makes it possible to iterate
+on exempt_accounts, bootstrap_balances, and balance exemptions against
+captured data. Balances are computed in a scratch store, so the check:data
+storage is not modified.
+
+The first argument is the path of a file produced by
+utils:asserter-configuration, which is used to assert blocks instead of
+/network/status and /network/options. The optional start and end index
+(inclusive) default to the oldest stored block and the head block.
+Balances start from zero (or from bootstrap_balances when replaying from
+genesis), so replaying from a later block may report negative balances.
+This command cannot be run while check:data is running on the same
+data_directory.`,
+		RunE: runDBReplayCmd,
+		Args: cobra.RangeArgs(1, 3),
+	}
+)
+
+func runDBReplayCmd(cmd *cobra.Command, args []string) error {
+	startIndex := int64(-1)
+	if len(args) > 1 {
+		var err error
+		startIndex, err = strconv.ParseInt(args[1], 10, 64)
+		if err != nil {
+			return fmt.Errorf("%w: unable to parse start index %s", err, args[1])
+		}
+	}
+
+	endIndex := int64(-1)
+	if len(args) > 2 {
+		var err error
+		endIndex, err = strconv.ParseInt(args[2], 10, 64)
+		if err != nil {
+			return fmt.Errorf("%w: unable to parse end index %s", err, args[2])
+		}
+	}
+
+	replayAsserter, err := asserter.NewClientWithFile(args[0])
+	if err != nil {
+		return fmt.Errorf("%w: unable to load asserter configuration %s", err, args[0])
+	}
+
+	balanceExemptions := []*types.BalanceExemption{}
+	if len(replayBalanceExemptionsFile) > 0 {
+		if err := utils.LoadAndParse(replayBalanceExemptionsFile, &balanceExemptions); err != nil {
+			return fmt.Errorf("%w: unable to load balance exemptions", err)
+		}
+	}
+
+	result, err := tester.ReplayRange(
+		Context,
+		Config,
+		replayAsserter,
+		balanceExemptions,
+		startIndex,
+		endIndex,
+	)
+	if err != nil {
+		return fmt.Errorf("%w: unable to replay blocks", err)
+	}
+
+	log.Printf("Replay: %s\n", types.PrettyPrintStruct(result))
+	if result.Failed() {
+		return fmt.Errorf("block %d failed replay: %s", *result.FailureIndex, result.Error)
+	}
+
+	return nil
+}
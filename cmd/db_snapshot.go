@@ -0,0 +1,109 @@
+// Copyright 2022 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path"
+
+	"github.com/coinbase/rosetta-cli/pkg/tester"
+
+	"github.com/coinbase/rosetta-sdk-go/types"
+	"github.com/spf13/cobra"
+)
+
+// snapshotFileMode is the permission of an exported
+// snapshot or checkpoint.
+const snapshotFileMode = 0600
+
+var (
+	dbExportSnapshotCmd = &cobra.Command{
+		Use:   "db:export-snapshot",
+		Short: "Export the balances and coins at the head block to a snapshot",
+		Long: `This command writes the head block and the balance and coins of
+every tracked account at the head block of the check:data storage in the
+configured data_directory to a gzipped snapshot file. The snapshot can be
+used to initialize a new data_directory with db:import-snapshot, so a
+baseline validated to some height can be shared without syncing from
+genesis.
+
+To export a snapshot at an earlier height, run check:data with an
+end condition at that height before exporting.
+
+The argument for this command is the output path (which must not
+exist). This command cannot be run while check:data is running on
+the same data_directory.`,
+		RunE: runDBExportSnapshotCmd,
+		Args: cobra.ExactArgs(1),
+	}
+
+	dbImportSnapshotCmd = &cobra.Command{
+		Use:   "db:import-snapshot",
+		Short: "Initialize empty check:data storage from a snapshot",
+		Long: `This command initializes the check:data storage in the configured
+data_directory with a snapshot written by db:export-snapshot. The
+storage must be empty and the snapshot must be of the configured
+network. check:data resumes syncing at the block after the snapshot
+block.
+
+Only the snapshot block is stored, so check:data cannot handle a reorg
+that orphans the snapshot block. Balances are trusted as-is until they
+are reconciled.
+
+The argument for this command is the snapshot path.`,
+		RunE: runDBImportSnapshotCmd,
+		Args: cobra.ExactArgs(1),
+	}
+)
+
+func runDBExportSnapshotCmd(cmd *cobra.Command, args []string) error {
+	output := path.Clean(args[0])
+	f, err := os.OpenFile(output, os.O_CREATE|os.O_EXCL|os.O_WRONLY, snapshotFileMode)
+	if err != nil {
+		return fmt.Errorf("%w: unable to create %s", err, output)
+	}
+	defer f.Close()
+
+	stats, err := tester.ExportSnapshot(Context, Config, f)
+	if err != nil {
+		_ = os.Remove(output)
+		return fmt.Errorf("%w: unable to export snapshot", err)
+	}
+
+	if err := f.Sync(); err != nil {
+		return fmt.Errorf("%w: unable to write %s", err, output)
+	}
+
+	log.Printf("Snapshot: %s\n", types.PrettyPrintStruct(stats))
+	return nil
+}
+
+func runDBImportSnapshotCmd(cmd *cobra.Command, args []string) error {
+	f, err := os.Open(path.Clean(args[0]))
+	if err != nil {
+		return fmt.Errorf("%w: unable to open %s", err, args[0])
+	}
+	defer f.Close()
+
+	stats, err := tester.ImportSnapshot(Context, Config, f)
+	if err != nil {
+		return fmt.Errorf("%w: unable to import snapshot", err)
+	}
+
+	log.Printf("Snapshot: %s\n", types.PrettyPrintStruct(stats))
+	return nil
+}
@@ -18,14 +18,21 @@ import (
 	"context"
 	"fmt"
 	"log"
+	"net/http"
+	httppprof "net/http/pprof"
 	"os"
 	"os/signal"
 	"path"
 	"runtime"
 	"runtime/pprof"
 	"syscall"
+	"time"
 
 	"github.com/coinbase/rosetta-cli/configuration"
+	"github.com/coinbase/rosetta-cli/pkg/errors"
+	"github.com/coinbase/rosetta-cli/pkg/logger"
+	"github.com/coinbase/rosetta-cli/pkg/results"
+	"github.com/coinbase/rosetta-cli/pkg/sentry"
 
 	"github.com/coinbase/rosetta-sdk-go/utils"
 	"github.com/fatih/color"
@@ -35,6 +42,9 @@ import (
 const (
 	// configEnvKey is an env variable name that sets a config file location
 	configEnvKey = "ROSETTA_CONFIGURATION_FILE"
+
+	// cliVersion is the version of the rosetta-cli.
+	cliVersion = "v0.8.0"
 )
 
 var (
@@ -48,13 +58,21 @@ var (
 	cpuProfile             string
 	memProfile             string
 	blockProfile           string
+	pprofPort              uint
 	onlineURL              string
 	offlineURL             string
 	startIndex             int64
+	startBlockHash         string
 	endIndex               int64
+	endTimestamp           string
+	endDuration            string
 	dataResultFile         string
 	constructionResultFile string
 	dataDirectory          string
+	junitFile              string
+	githubAnnotations      bool
+	readOnly               bool
+	followTip              bool
 
 	// Config is the populated *configuration.Configuration from
 	// the configurationFile. If none is provided, this is set
@@ -76,6 +94,10 @@ var (
 	// cleanup a running block profile.
 	blockProfileCleanup func()
 
+	// pprofServerCleanup is called after the root command is executed to
+	// shutdown a running pprof server.
+	pprofServerCleanup func()
+
 	// OnlyChanges is a boolean indicating if only the balance changes should be
 	// logged to the console.
 	OnlyChanges bool
@@ -96,13 +118,48 @@ var (
 	// which has caused production incidents in the past. This can be used for both check:data
 	// and check:construction.
 	asserterConfigurationFile string
+
+	// replayBalanceExemptionsFile is the path of a JSON file with
+	// the []*types.BalanceExemption applied by db:replay.
+	replayBalanceExemptionsFile string
+
+	// verbose and quiet determine the logger.Verbosity
+	// of all console output.
+	verbose int
+	quiet   bool
+
+	// colorMode determines if console output
+	// is colored (always, never, or auto).
+	colorMode string
+
+	// errorTracker reports panics and fatal errors
+	// (only populated if Sentry is configured).
+	errorTracker *sentry.Client
 )
 
 // rootPreRun is executed before the root command runs and sets up cpu
 // profiling.
 //
 // Bassed on https://golang.org/pkg/runtime/pprof/#hdr-Profiling_a_Go_program
-func rootPreRun(*cobra.Command, []string) error {
+func rootPreRun(cmd *cobra.Command, _ []string) error {
+	if err := startErrorTracker(cmd); err != nil {
+		return err
+	}
+
+	if err := logger.SetColorMode(colorMode); err != nil {
+		return err
+	}
+
+	if quiet && verbose > 0 {
+		return errors.ErrVerbosityConflict
+	}
+
+	if quiet {
+		logger.SetVerbosity(logger.VerbosityQuiet)
+	} else {
+		logger.SetVerbosity(logger.Verbosity(verbose))
+	}
+
 	if cpuProfile != "" {
 		f, err := os.Create(path.Clean(cpuProfile))
 		if err != nil {
@@ -141,9 +198,42 @@ func rootPreRun(*cobra.Command, []string) error {
 		}
 	}
 
+	if pprofPort != 0 {
+		startPprofServer()
+	}
+
 	return nil
 }
 
+// startPprofServer serves net/http/pprof on localhost so
+// that a running check can be profiled without restarting.
+func startPprofServer() {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/debug/pprof/", httppprof.Index)
+	mux.HandleFunc("/debug/pprof/cmdline", httppprof.Cmdline)
+	mux.HandleFunc("/debug/pprof/profile", httppprof.Profile)
+	mux.HandleFunc("/debug/pprof/symbol", httppprof.Symbol)
+	mux.HandleFunc("/debug/pprof/trace", httppprof.Trace)
+
+	server := &http.Server{
+		Addr:    fmt.Sprintf("localhost:%d", pprofPort),
+		Handler: mux,
+	}
+
+	go func() {
+		log.Printf("pprof server running on port %d\n", pprofPort)
+		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			log.Printf("error while running pprof server: %v\n", err)
+		}
+	}()
+
+	pprofServerCleanup = func() {
+		if err := server.Close(); err != nil {
+			log.Printf("error while closing pprof server: %v\n", err)
+		}
+	}
+}
+
 // rootPostRun is executed after the root command runs and performs memory
 // profiling.
 func rootPostRun() {
@@ -155,6 +245,10 @@ func rootPostRun() {
 		blockProfileCleanup()
 	}
 
+	if pprofServerCleanup != nil {
+		pprofServerCleanup()
+	}
+
 	if memProfile != "" {
 		f, err := os.Create(path.Clean(memProfile))
 		if err != nil {
@@ -182,6 +276,59 @@ func Execute() error {
 	return rootCmd.Execute()
 }
 
+// startErrorTracker initializes errorTracker
+// if Sentry is configured.
+func startErrorTracker(cmd *cobra.Command) error {
+	tags := map[string]string{"command": cmd.Name()}
+	if Config.Network != nil {
+		tags["blockchain"] = Config.Network.Blockchain
+		tags["network"] = Config.Network.Network
+	}
+
+	var err error
+	errorTracker, err = sentry.New(
+		Config.Sentry,
+		cliVersion,
+		tags,
+		map[string]interface{}{"configuration_file": configurationFile},
+	)
+	if err != nil {
+		return fmt.Errorf("%w: unable to initialize sentry", err)
+	}
+
+	return nil
+}
+
+// CaptureError reports a fatal error returned
+// by Execute (if Sentry is configured). Operator
+// interrupts are not reported.
+func CaptureError(err error) {
+	if ExitCode(err) == results.ExitCodeInterrupted {
+		return
+	}
+
+	errorTracker.CaptureError(err)
+}
+
+// RecoverPanic reports a panic (if Sentry is configured)
+// and then re-panics. This must be deferred directly.
+func RecoverPanic() {
+	if recovered := recover(); recovered != nil {
+		errorTracker.CapturePanic(recovered)
+		panic(recovered)
+	}
+}
+
+// ExitCode returns the process exit code for an error
+// returned by Execute (see results.ExitCode).
+func ExitCode(err error) int {
+	if err != nil && SignalReceived {
+		return results.ExitCodeInterrupted
+	}
+
+	return results.ExitCode(err)
+}
+
 func init() {
 	cobra.OnInitialize(initConfig)
 
@@ -215,6 +362,60 @@ default values.`,
 		"",
 		`Save the pprof block profile in the specified file`,
 	)
+	rootFlags.UintVar(
+		&pprofPort,
+		"pprof-port",
+		0,
+		`Serve net/http/pprof on localhost at the specified port
+(e.g. go tool pprof http://localhost:<port>/debug/pprof/heap).
+If 0, the pprof server is not started`,
+	)
+	rootFlags.StringVar(
+		&junitFile,
+		"junit-file",
+		"",
+		`Save a JUnit XML report of check results in the specified file.
+This will override the junit_output_file from configuration file`,
+	)
+	rootFlags.BoolVar(
+		&githubAnnotations,
+		"github-annotations",
+		false,
+		`Print failures as GitHub Actions annotations. This will override
+the github_annotations from configuration file`,
+	)
+	rootFlags.BoolVar(
+		&readOnly,
+		"read-only",
+		false,
+		`Open existing storage read-only so inspection and export commands
+can run while a check is in progress on the same data_directory.
+This will override the read_only_storage from configuration file`,
+	)
+	rootFlags.CountVarP(
+		&verbose,
+		"verbose",
+		"v",
+		`Increase console output. -v also prints fetcher retries and
+successful reconciliations, -vv also prints processed blocks,
+transactions, and the debug output of the reconciler`,
+	)
+	rootFlags.BoolVarP(
+		&quiet,
+		"quiet",
+		"q",
+		false,
+		`Only print warnings, failures, and results to the console
+(periodic stats and progress are not printed)`,
+	)
+	rootFlags.StringVar(
+		&colorMode,
+		"color",
+		logger.ColorAuto,
+		`Print colored output (always, never, or auto). When auto,
+output is only colored if it is printed to a terminal and the
+NO_COLOR environment variable is not set`,
+	)
 	rootCmd.AddCommand(versionCmd)
 
 	// Configuration Commands
@@ -243,6 +444,13 @@ default values.`,
 		`start-block is the block height to start syncing from. This will override the start_index from configuration file`,
 	)
 
+	checkDataCmd.Flags().StringVar(
+		&startBlockHash,
+		"start-block-hash",
+		"",
+		`start-block-hash is the hash of the block to start syncing from. This will override the start_block_hash (and start_index) from configuration file`,
+	)
+
 	checkDataCmd.Flags().Int64Var(
 		&endIndex,
 		"end-block",
@@ -250,6 +458,29 @@ default values.`,
 		`End-block configures the syncer to stop once reaching a particular block height. This will override the index from configuration file`,
 	)
 
+	checkDataCmd.Flags().StringVar(
+		&endTimestamp,
+		"end",
+		"",
+		`End configures the syncer to stop at the last block before an RFC3339 timestamp or a date (YYYY-MM-DD). This will override the timestamp from configuration file`,
+	)
+
+	checkDataCmd.Flags().StringVar(
+		&endDuration,
+		"duration",
+		"",
+		`Duration configures the syncer to stop after running for a duration (like 6h or 90m). This will override the duration from configuration file`,
+	)
+
+	checkDataCmd.Flags().BoolVar(
+		&followTip,
+		"follow-tip",
+		false,
+		`Follow-tip runs check:data as a continuous monitor that syncs the tip
+indefinitely and logs (instead of halting on) reconciliation errors.
+This will override the follow_tip and end_conditions from configuration file`,
+	)
+
 	checkDataCmd.Flags().StringVar(
 		&dataResultFile,
 		"result-file",
@@ -307,6 +538,26 @@ default values.`,
 	// Utils
 	rootCmd.AddCommand(utilsAsserterConfigurationCmd)
 	rootCmd.AddCommand(utilsTrainZstdCmd)
+	rootCmd.AddCommand(utilsReconciliationHistoryCmd)
+	rootCmd.AddCommand(utilsReconciliationAuditCmd)
+	rootCmd.AddCommand(utilsExportSQLCmd)
+
+	// Storage
+	rootCmd.AddCommand(dbCompactCmd)
+	rootCmd.AddCommand(dbInspectCmd)
+	rootCmd.AddCommand(dbRepairCmd)
+	rootCmd.AddCommand(dbRecheckCmd)
+	dbReplayCmd.Flags().StringVar(
+		&replayBalanceExemptionsFile,
+		"balance-exemptions",
+		"",
+		`Path to a JSON file with the balance exemptions to apply during replay`,
+	)
+	rootCmd.AddCommand(dbReplayCmd)
+	rootCmd.AddCommand(dbExportSnapshotCmd)
+	rootCmd.AddCommand(dbImportSnapshotCmd)
+	rootCmd.AddCommand(dbExportCheckpointCmd)
+	rootCmd.AddCommand(dbImportCheckpointCmd)
 
 	// Benchmark commands
 	rootCmd.AddCommand(checkPerfCmd)
@@ -352,15 +603,61 @@ func initConfig() {
 	// Override start and end syncing index in configuration file when it's explicitly set via CLI
 	if startIndex != -1 {
 		Config.Data.StartIndex = &startIndex
+		Config.Data.StartBlockHash = ""
 		// Configures rosetta-cli to lookup the balance of newly seen accounts at the
 		// parent block before applying operations. Otherwise the balance will be 0.
 		Config.Data.InitialBalanceFetchDisabled = false
 	}
 
+	if len(startBlockHash) != 0 {
+		if startIndex != -1 {
+			log.Fatal("start-block and start-block-hash cannot both be set")
+		}
+
+		Config.Data.StartIndex = nil
+		Config.Data.StartBlockHash = startBlockHash
+		Config.Data.InitialBalanceFetchDisabled = false
+	}
+
 	if endIndex != -1 {
 		Config.Data.EndConditions.Index = &endIndex
 	}
 
+	if len(endTimestamp) != 0 {
+		if _, err := configuration.ParseEndTimestamp(endTimestamp); err != nil {
+			log.Fatalf("%s: invalid end", err.Error())
+		}
+
+		if Config.Data.EndConditions == nil {
+			Config.Data.EndConditions = &configuration.DataEndConditions{}
+		}
+
+		Config.Data.EndConditions.Timestamp = endTimestamp
+	}
+
+	if len(endDuration) != 0 {
+		duration, err := time.ParseDuration(endDuration)
+		if err != nil {
+			log.Fatalf("%s: unable to parse duration %s", err.Error(), endDuration)
+		}
+
+		if duration < time.Second {
+			log.Fatalf("duration %s must be at least 1s", endDuration)
+		}
+
+		if Config.Data.EndConditions == nil {
+			Config.Data.EndConditions = &configuration.DataEndConditions{}
+		}
+
+		seconds := uint64(duration.Seconds())
+		Config.Data.EndConditions.Duration = &seconds
+	}
+
+	if followTip {
+		Config.Data.FollowTip = true
+		Config.Data.EndConditions = nil
+	}
+
 	if len(dataResultFile) != 0 {
 		Config.Data.ResultsOutputFile = dataResultFile
 	}
@@ -372,6 +669,18 @@ func initConfig() {
 	if len(dataDirectory) != 0 {
 		Config.DataDirectory = dataDirectory
 	}
+
+	if len(junitFile) != 0 {
+		Config.JUnitOutputFile = junitFile
+	}
+
+	if githubAnnotations {
+		Config.GitHubAnnotations = true
+	}
+
+	if readOnly {
+		Config.ReadOnlyStorage = true
+	}
 }
 
 func ensureDataDirectoryExists() {
@@ -407,6 +716,6 @@ var versionCmd = &cobra.Command{
 	Use:   "version",
 	Short: "Print rosetta-cli version",
 	Run: func(cmd *cobra.Command, args []string) {
-		fmt.Println("v0.8.0")
+		fmt.Println(cliVersion)
 	},
 }
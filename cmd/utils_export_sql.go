@@ -0,0 +1,76 @@
+// Copyright 2022 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path"
+
+	"github.com/coinbase/rosetta-cli/pkg/tester"
+
+	"github.com/spf13/cobra"
+)
+
+// sqlFileMode is the permission of an exported SQL script.
+const sqlFileMode = 0600
+
+var (
+	utilsExportSQLCmd = &cobra.Command{
+		Use:   "utils:export-sql",
+		Short: "Export synced blocks, operations, and balances as a SQL script",
+		Long: `This command dumps all blocks, transactions, and operations
+synced by check:data (and the balance of each account at the head
+block) into a SQL script that creates the tables blocks, transactions,
+operations, and balances. Indexes are created on hashes, addresses,
+and operation types so ad-hoc debugging queries are fast.
+
+The argument for this command is the path of the SQL script. The script
+is written in the SQLite dialect and does not create a database itself.
+Loading it requires the sqlite3 binary:
+
+sqlite3 <db> < <script>
+
+This command must be run with the same data_directory used by check:data
+and cannot be run while check:data is running.`,
+		RunE: runUtilsExportSQLCmd,
+		Args: cobra.ExactArgs(1),
+	}
+)
+
+func runUtilsExportSQLCmd(cmd *cobra.Command, args []string) error {
+	output := path.Clean(args[0])
+	f, err := os.OpenFile(output, os.O_CREATE|os.O_EXCL|os.O_WRONLY, sqlFileMode)
+	if err != nil {
+		return fmt.Errorf("%w: unable to create %s", err, output)
+	}
+	defer f.Close()
+
+	stats, err := tester.ExportSQL(Context, Config, f)
+	if err != nil {
+		return fmt.Errorf("%w: unable to export sql", err)
+	}
+
+	log.Printf(
+		"Exported %d blocks, %d transactions, %d operations, and %d balances to %s\n",
+		stats.Blocks,
+		stats.Transactions,
+		stats.Operations,
+		stats.Balances,
+		output,
+	)
+	return nil
+}
@@ -0,0 +1,87 @@
+// Copyright 2022 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"fmt"
+	"log"
+	"strconv"
+
+	"github.com/coinbase/rosetta-cli/pkg/processor"
+	"github.com/coinbase/rosetta-cli/pkg/tester"
+
+	"github.com/coinbase/rosetta-sdk-go/types"
+	"github.com/coinbase/rosetta-sdk-go/utils"
+	"github.com/spf13/cobra"
+)
+
+var (
+	utilsReconciliationAuditCmd = &cobra.Command{
+		Use:   "utils:reconciliation-audit",
+		Short: "Export all reconciliations in a range of blocks",
+		Long: `When reconciliation_history_enabled is set in the data configuration,
+check:data persists every reconciliation attempt (account, currency, block,
+computed vs live balance, and outcome). This command exports all attempts
+at blocks in a range of indexes (inclusive), across all accounts, so that it
+can be demonstrated which accounts were verified at which heights.
+
+For example, utils:reconciliation-audit 100 200 audit.json writes all
+attempts at blocks 100 through 200 to audit.json. If no output file is
+provided, a count of attempts by outcome is printed to the console.
+
+This command must be run with the same data_directory used by check:data
+and cannot be run while check:data is running.`,
+		RunE: runUtilsReconciliationAuditCmd,
+		Args: cobra.RangeArgs(2, 3),
+	}
+)
+
+func runUtilsReconciliationAuditCmd(cmd *cobra.Command, args []string) error {
+	startIndex, err := strconv.ParseInt(args[0], 10, 64)
+	if err != nil {
+		return fmt.Errorf("%w: unable to parse start index %s", err, args[0])
+	}
+
+	endIndex, err := strconv.ParseInt(args[1], 10, 64)
+	if err != nil {
+		return fmt.Errorf("%w: unable to parse end index %s", err, args[1])
+	}
+
+	attempts, err := tester.LoadReconciliationAudit(Context, Config, startIndex, endIndex)
+	if err != nil {
+		return fmt.Errorf("%w: unable to load reconciliation history", err)
+	}
+
+	if len(args) == 2 {
+		outcomes := map[string]int{
+			processor.ReconciliationSuccess: 0,
+			processor.ReconciliationFailure: 0,
+			processor.ReconciliationExempt:  0,
+		}
+		for _, attempt := range attempts {
+			outcomes[attempt.Outcome]++
+		}
+
+		log.Printf("Reconciliation Outcomes: %s\n", types.PrettyPrintStruct(outcomes))
+		return nil
+	}
+
+	if err := utils.SerializeAndWrite(args[2], attempts); err != nil {
+		return fmt.Errorf("%w: unable to save reconciliation audit", err)
+	}
+
+	log.Printf("Exported %d reconciliation attempts to %s\n", len(attempts), args[2])
+	return nil
+}
@@ -0,0 +1,78 @@
+// Copyright 2022 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+
+	"github.com/coinbase/rosetta-cli/pkg/tester"
+
+	"github.com/coinbase/rosetta-sdk-go/asserter"
+	"github.com/coinbase/rosetta-sdk-go/types"
+	"github.com/coinbase/rosetta-sdk-go/utils"
+	"github.com/spf13/cobra"
+)
+
+var (
+	utilsReconciliationHistoryCmd = &cobra.Command{
+		Use:   "utils:reconciliation-history",
+		Short: "Export the reconciliation history of an account",
+		Long: `When reconciliation_history_enabled is set in the data configuration,
+check:data persists every reconciliation attempt (account, currency, block,
+computed vs live balance, and outcome). This command exports all attempts
+for an account so that reconciliation coverage of specific addresses
+can be demonstrated.
+
+The account is provided as a JSON representation of a types.AccountIdentifier.
+For example, utils:reconciliation-history '{"address":"interesting address"}' history.json
+writes all attempts for "interesting address" to history.json. If no output
+file is provided, the history is printed to the console.
+
+This command must be run with the same data_directory used by check:data
+and cannot be run while check:data is running.`,
+		RunE: runUtilsReconciliationHistoryCmd,
+		Args: cobra.RangeArgs(1, 2),
+	}
+)
+
+func runUtilsReconciliationHistoryCmd(cmd *cobra.Command, args []string) error {
+	account := &types.AccountIdentifier{}
+	if err := json.Unmarshal([]byte(args[0]), account); err != nil {
+		return fmt.Errorf("%w: unable to unmarshal account %s", err, args[0])
+	}
+
+	if err := asserter.AccountIdentifier(account); err != nil {
+		return fmt.Errorf("%w: invalid account identifier %s", err, types.PrintStruct(account))
+	}
+
+	attempts, err := tester.LoadReconciliationHistory(Context, Config, account)
+	if err != nil {
+		return fmt.Errorf("%w: unable to load reconciliation history", err)
+	}
+
+	if len(args) == 1 {
+		log.Printf("Reconciliation History: %s\n", types.PrettyPrintStruct(attempts))
+		return nil
+	}
+
+	if err := utils.SerializeAndWrite(args[1], attempts); err != nil {
+		return fmt.Errorf("%w: unable to save reconciliation history", err)
+	}
+
+	log.Printf("Exported %d reconciliation attempts to %s\n", len(attempts), args[1])
+	return nil
+}
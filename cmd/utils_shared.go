@@ -14,6 +14,14 @@
 
 package cmd
 
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/coinbase/rosetta-cli/pkg/transport"
+)
+
 func isEmpty(s string) bool {
 	return s == ""
 }
@@ -29,3 +37,103 @@ func isEqual(s1 string, s2 string) bool {
 func isUTXO() bool {
 	return Config.CoinSupported
 }
+
+// onlineRoundTripper returns the http.RoundTripper used to
+// reach the online Rosetta servers (through proxy, with
+// compression, failing over between them when online_urls
+// is populated, and recording or replaying responses when
+// fixtures is populated).
+func onlineRoundTripper() (http.RoundTripper, error) {
+	httpTransport := transport.NewHTTPTransport(
+		Config.MaxOnlineConnections,
+		Config.HTTPTransport,
+	)
+	if Config.Proxy != nil {
+		proxy, err := transport.NewProxy(Config.Proxy)
+		if err != nil {
+			return nil, fmt.Errorf("%w: unable to initialize proxy", err)
+		}
+
+		httpTransport.Proxy = proxy
+	}
+
+	var roundTripper http.RoundTripper = httpTransport
+	if Config.Compression != nil {
+		httpTransport.DisableCompression = Config.Compression.ResponseCompressionDisabled
+		if Config.Compression.RequestCompressionEnabled {
+			roundTripper = transport.NewRequestCompression(
+				Config.Compression.RequestCompressionThreshold,
+				roundTripper,
+			)
+		}
+	}
+
+	if len(Config.OnlineURLs) > 0 {
+		failover, err := transport.NewFailover(
+			Config.OnlineURL,
+			Config.OnlineURLs,
+			Config.OnlineURLStrategy,
+			roundTripper,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("%w: unable to initialize failover", err)
+		}
+
+		roundTripper = failover
+	}
+
+	if Config.Fixtures != nil {
+		fixtures, err := transport.NewFixtures(Config.Fixtures, roundTripper)
+		if err != nil {
+			return nil, fmt.Errorf("%w: unable to initialize fixtures", err)
+		}
+
+		roundTripper = fixtures
+	}
+
+	return roundTripper, nil
+}
+
+// skipBlocksRoundTripper returns roundTripper wrapped to
+// remove the transactions of any skip_blocks from their
+// /block responses (before they are asserted).
+func skipBlocksRoundTripper(roundTripper http.RoundTripper) http.RoundTripper {
+	if Config.Data == nil || len(Config.Data.SkipBlocks) == 0 {
+		return roundTripper
+	}
+
+	return transport.NewSkipBlocks(Config.Data.SkipBlocks, roundTripper)
+}
+
+// onlineHTTPClient returns the *http.Client used to send requests
+// to the online Rosetta servers with roundTripper, applying
+// http_timeout (or endpoint_timeouts) to each request, pausing
+// requests when circuit_breaker is populated, and retrying
+// requests when retry_policy is populated.
+func onlineHTTPClient(roundTripper http.RoundTripper) *http.Client {
+	timeout := time.Duration(Config.HTTPTimeout) * time.Second
+	if len(Config.EndpointTimeouts) == 0 &&
+		Config.CircuitBreaker == nil &&
+		Config.RetryPolicy == nil {
+		return transport.NewHTTPClient(timeout, roundTripper)
+	}
+
+	// The timeout is applied to each attempt below the circuit
+	// breaker and retry policy so that paused requests (and
+	// requests waiting to be retried) do not time out.
+	endpointTimeouts := map[string]time.Duration{}
+	for endpoint, endpointTimeout := range Config.EndpointTimeouts {
+		endpointTimeouts[endpoint] = time.Duration(endpointTimeout) * time.Second
+	}
+
+	roundTripper = transport.NewEndpointTimeouts(timeout, endpointTimeouts, roundTripper)
+	if Config.CircuitBreaker != nil {
+		roundTripper = transport.NewCircuitBreaker(Config.CircuitBreaker, roundTripper)
+	}
+
+	if Config.RetryPolicy != nil {
+		roundTripper = transport.NewRetryPolicy(Config.RetryPolicy, roundTripper)
+	}
+
+	return transport.NewHTTPClient(0, roundTripper)
+}
@@ -20,9 +20,12 @@ import (
 	"errors"
 	"fmt"
 	"log"
+	"net/url"
 	"path"
 	"runtime"
 	"strings"
+	"text/template"
+	"time"
 
 	customerrors "github.com/coinbase/rosetta-cli/pkg/errors"
 	"github.com/coinbase/rosetta-sdk-go/asserter"
@@ -41,6 +44,7 @@ func DefaultDataConfiguration() *DataConfiguration {
 		InactiveReconciliationConcurrency: DefaultInactiveReconciliationConcurrency,
 		InactiveReconciliationFrequency:   DefaultInactiveReconciliationFrequency,
 		StatusPort:                        DefaultStatusPort,
+		OtherTransactionConcurrency:       DefaultOtherTransactionConcurrency,
 	}
 }
 
@@ -123,9 +127,39 @@ func populateConstructionMissingFields(
 		constructionConfig.StatusPort = DefaultStatusPort
 	}
 
+	if constructionConfig.BlockCache != nil && constructionConfig.BlockCache.Size == 0 {
+		constructionConfig.BlockCache.Size = DefaultBlockCacheSize
+	}
+
 	return constructionConfig
 }
 
+func populateNetworkStatusAssertionsMissingFields(
+	assertions *NetworkStatusAssertions,
+) *NetworkStatusAssertions {
+	if assertions.CheckInterval == 0 {
+		assertions.CheckInterval = DefaultNetworkStatusCheckInterval
+	}
+
+	if assertions.StallTimeout == 0 {
+		assertions.StallTimeout = DefaultNetworkStatusStallTimeout
+	}
+
+	if len(assertions.CurrentBlock) == 0 {
+		assertions.CurrentBlock = DefaultAssertionStrictness
+	}
+
+	if len(assertions.Peers) == 0 {
+		assertions.Peers = DefaultAssertionStrictness
+	}
+
+	if len(assertions.SyncStatus) == 0 {
+		assertions.SyncStatus = DefaultAssertionStrictness
+	}
+
+	return assertions
+}
+
 func populateDataMissingFields(dataConfig *DataConfiguration) *DataConfiguration {
 	if dataConfig == nil {
 		return DefaultDataConfiguration()
@@ -139,6 +173,10 @@ func populateDataMissingFields(dataConfig *DataConfiguration) *DataConfiguration
 		dataConfig.InactiveReconciliationConcurrency = DefaultInactiveReconciliationConcurrency
 	}
 
+	if dataConfig.OtherTransactionConcurrency == 0 {
+		dataConfig.OtherTransactionConcurrency = DefaultOtherTransactionConcurrency
+	}
+
 	if dataConfig.InactiveReconciliationFrequency == 0 {
 		dataConfig.InactiveReconciliationFrequency = DefaultInactiveReconciliationFrequency
 	}
@@ -147,6 +185,67 @@ func populateDataMissingFields(dataConfig *DataConfiguration) *DataConfiguration
 		dataConfig.StatusPort = DefaultStatusPort
 	}
 
+	if dataConfig.NetworkStatusAssertions != nil {
+		dataConfig.NetworkStatusAssertions = populateNetworkStatusAssertionsMissingFields(
+			dataConfig.NetworkStatusAssertions,
+		)
+	}
+
+	if dataConfig.Tracing != nil {
+		if len(dataConfig.Tracing.ServiceName) == 0 {
+			dataConfig.Tracing.ServiceName = DefaultTracingServiceName
+		}
+
+		if dataConfig.Tracing.ExportInterval == 0 {
+			dataConfig.Tracing.ExportInterval = DefaultTracingExportInterval
+		}
+	}
+
+	if dataConfig.ParquetExport != nil && dataConfig.ParquetExport.BlocksPerFile == 0 {
+		dataConfig.ParquetExport.BlocksPerFile = DefaultParquetBlocksPerFile
+	}
+
+	if dataConfig.CounterSnapshots != nil && dataConfig.CounterSnapshots.Frequency == 0 {
+		dataConfig.CounterSnapshots.Frequency = DefaultCounterSnapshotFrequency
+	}
+
+	if dataConfig.DiskUsage != nil && dataConfig.DiskUsage.CheckFrequency == 0 {
+		dataConfig.DiskUsage.CheckFrequency = DefaultDiskUsageCheckFrequency
+	}
+
+	if dataConfig.Retention != nil && dataConfig.Retention.Frequency == 0 {
+		dataConfig.Retention.Frequency = DefaultRetentionFrequency
+	}
+
+	if dataConfig.ReconciliationReverification != nil &&
+		dataConfig.ReconciliationReverification.Attempts == 0 {
+		dataConfig.ReconciliationReverification.Attempts = DefaultReverificationAttempts
+	}
+
+	if dataConfig.ReconciliationPriority != nil {
+		if dataConfig.ReconciliationPriority.Accounts == 0 {
+			dataConfig.ReconciliationPriority.Accounts = DefaultReconciliationPriorityAccounts
+		}
+
+		if dataConfig.ReconciliationPriority.Frequency == 0 {
+			dataConfig.ReconciliationPriority.Frequency = DefaultReconciliationPriorityFrequency
+		}
+	}
+
+	if dataConfig.AccountFilter != nil {
+		if dataConfig.AccountFilter.ExpectedAccounts == 0 {
+			dataConfig.AccountFilter.ExpectedAccounts = DefaultAccountFilterExpectedAccounts
+		}
+
+		if dataConfig.AccountFilter.FalsePositiveRate == 0 {
+			dataConfig.AccountFilter.FalsePositiveRate = DefaultAccountFilterFalsePositiveRate
+		}
+
+		if dataConfig.AccountFilter.PersistFrequency == 0 {
+			dataConfig.AccountFilter.PersistFrequency = DefaultAccountFilterPersistFrequency
+		}
+	}
+
 	return dataConfig
 }
 
@@ -179,6 +278,28 @@ func populateMissingFields(config *Configuration) *Configuration {
 		config.MaxSyncConcurrency = DefaultMaxSyncConcurrency
 	}
 
+	if config.AdaptiveSyncConcurrency != nil && config.AdaptiveSyncConcurrency.MinConcurrency == 0 {
+		config.AdaptiveSyncConcurrency.MinConcurrency = DefaultAdaptiveMinConcurrency
+	}
+
+	if config.RetryPolicy != nil && config.RetryPolicy.MaxBackoff == 0 {
+		config.RetryPolicy.MaxBackoff = DefaultRetryPolicyMaxBackoff
+	}
+
+	if config.CircuitBreaker != nil {
+		if config.CircuitBreaker.FailureThreshold == 0 {
+			config.CircuitBreaker.FailureThreshold = DefaultCircuitBreakerFailureThreshold
+		}
+
+		if config.CircuitBreaker.Cooldown == 0 {
+			config.CircuitBreaker.Cooldown = DefaultCircuitBreakerCooldown
+		}
+	}
+
+	if config.Compression != nil && config.Compression.RequestCompressionThreshold == 0 {
+		config.Compression.RequestCompressionThreshold = DefaultRequestCompressionThreshold
+	}
+
 	if config.TipDelay == 0 {
 		config.TipDelay = DefaultTipDelay
 	}
@@ -200,6 +321,10 @@ func populateMissingFields(config *Configuration) *Configuration {
 		config.ValidationFile = ""
 	}
 
+	if config.Email != nil && config.Email.SMTPPort == 0 {
+		config.Email.SMTPPort = DefaultSMTPPort
+	}
+
 	config.Construction = populateConstructionMissingFields(config.Construction)
 	config.Data = populateDataMissingFields(config.Data)
 	config.Perf = populatePerfMissingFields(config.Perf)
@@ -207,6 +332,128 @@ func populateMissingFields(config *Configuration) *Configuration {
 	return config
 }
 
+func assertNotifiers(notifiers []*NotifierConfiguration) error {
+	for _, notifier := range notifiers {
+		switch notifier.Type {
+		case SlackNotifier, DiscordNotifier:
+		default:
+			return fmt.Errorf("notifier type %s is not supported", notifier.Type)
+		}
+
+		if len(notifier.WebhookURL) == 0 {
+			return fmt.Errorf("%s notifier is missing webhook url", notifier.Type)
+		}
+
+		if _, err := template.New("notification").Parse(notifier.Template); err != nil {
+			return fmt.Errorf("%w: invalid %s notifier template", err, notifier.Type)
+		}
+	}
+
+	return nil
+}
+
+func assertEmail(email *EmailConfiguration) error {
+	if email == nil {
+		return nil
+	}
+
+	if len(email.SMTPHost) == 0 {
+		return errors.New("smtp host must be populated")
+	}
+
+	if len(email.From) == 0 {
+		return errors.New("from address must be populated")
+	}
+
+	if len(email.To) == 0 {
+		return errors.New("at least one to address must be populated")
+	}
+
+	return nil
+}
+
+func assertSentry(sentry *SentryConfiguration) error {
+	if sentry == nil {
+		return nil
+	}
+
+	dsn, err := url.Parse(sentry.DSN)
+	if err != nil {
+		return fmt.Errorf("%w: unable to parse dsn", err)
+	}
+
+	if dsn.User == nil || len(dsn.User.Username()) == 0 {
+		return errors.New("dsn must include a public key")
+	}
+
+	if len(strings.Trim(dsn.Path, "/")) == 0 {
+		return errors.New("dsn must include a project id")
+	}
+
+	return nil
+}
+
+func assertBadger(badger *BadgerConfiguration) error {
+	if badger == nil {
+		return nil
+	}
+
+	if badger.MaxTableSize != nil && *badger.MaxTableSize <= 0 {
+		return errors.New("max table size must be positive")
+	}
+
+	if badger.ValueLogFileSize != nil && *badger.ValueLogFileSize <= 0 {
+		return errors.New("value log file size must be positive")
+	}
+
+	if badger.IndexCacheSize != nil && *badger.IndexCacheSize < 0 {
+		return errors.New("index cache size must not be negative")
+	}
+
+	if badger.BlockCacheSize != nil && *badger.BlockCacheSize < 0 {
+		return errors.New("block cache size must not be negative")
+	}
+
+	switch badger.Compression {
+	case "", BadgerCompressionNone, BadgerCompressionSnappy, BadgerCompressionZSTD:
+	default:
+		return fmt.Errorf("badger compression %s is not supported", badger.Compression)
+	}
+
+	switch badger.TableLoadingMode {
+	case "", BadgerLoadingFileIO, BadgerLoadingMemoryMap, BadgerLoadingRAM:
+	default:
+		return fmt.Errorf("table loading mode %s is not supported", badger.TableLoadingMode)
+	}
+
+	switch badger.ValueLogLoadingMode {
+	case "", BadgerLoadingFileIO, BadgerLoadingMemoryMap:
+	default:
+		return fmt.Errorf("value log loading mode %s is not supported", badger.ValueLogLoadingMode)
+	}
+
+	if badger.Encryption != nil &&
+		(len(badger.Encryption.KeyEnv) == 0) == (len(badger.Encryption.KeyFile) == 0) {
+		return errors.New("exactly one of encryption key_env and key_file must be populated")
+	}
+
+	return nil
+}
+
+func assertLogBackend(backend *LogBackendConfiguration) error {
+	if backend == nil {
+		return nil
+	}
+
+	switch backend.Type {
+	case FileLogBackend, SyslogLogBackend, JournaldLogBackend:
+	default:
+		return fmt.Errorf("log backend type %s is not supported", backend.Type)
+	}
+
+	return nil
+}
+
 func assertConstructionConfiguration(ctx context.Context, config *ConstructionConfiguration) error {
 	if config == nil {
 		return nil
@@ -220,6 +467,17 @@ func assertConstructionConfiguration(ctx context.Context, config *ConstructionCo
 		return fmt.Errorf("%w: both workflows and DSL file path are empty", customerrors.ErrParseFileFailed)
 	}
 
+	if config.SuggestedFeeTolerance != nil && *config.SuggestedFeeTolerance < 1 {
+		return fmt.Errorf(
+			"suggested fee tolerance %f must be >= 1",
+			*config.SuggestedFeeTolerance,
+		)
+	}
+
+	if err := assertBlockCache(config.BlockCache); err != nil {
+		return fmt.Errorf("%w: invalid block cache", err)
+	}
+
 	// Compile ConstructorDSLFile and save to Workflows
 	if len(config.ConstructorDSLFile) > 0 {
 		compiledWorkflows, err := dsl.Parse(ctx, config.ConstructorDSLFile)
@@ -280,121 +538,1018 @@ func assertConstructionConfiguration(ctx context.Context, config *ConstructionCo
 	return nil
 }
 
-func assertDataConfiguration(config *DataConfiguration) error { // nolint:gocognit
-	if config.StartIndex != nil && *config.StartIndex < 0 {
-		return fmt.Errorf("start index %d cannot be negative", *config.StartIndex)
+func assertGenesisAssertions(config *DataConfiguration) error {
+	genesis := config.GenesisAssertions
+	if genesis == nil {
+		return nil
 	}
 
-	if !config.ReconciliationDisabled && config.BalanceTrackingDisabled {
-		return errors.New("balance tracking must be enabled to perform reconciliation")
+	if genesis.Timestamp != nil && *genesis.Timestamp < 0 {
+		return fmt.Errorf("genesis timestamp %d cannot be negative", *genesis.Timestamp)
 	}
 
-	if config.EndConditions == nil {
+	if len(genesis.BootstrapSupply) == 0 {
 		return nil
 	}
 
-	if config.EndConditions.Index != nil {
-		if *config.EndConditions.Index < 0 {
-			return fmt.Errorf("end index %d cannot be negative", *config.EndConditions.Index)
-		}
+	if len(config.BootstrapBalances) == 0 {
+		return errors.New("bootstrap supply cannot be checked without bootstrap balances")
 	}
 
-	if config.EndConditions.ReconciliationCoverage != nil {
-		coverage := config.EndConditions.ReconciliationCoverage.Coverage
-		if coverage < 0 || coverage > 1 {
-			return fmt.Errorf("reconciliation coverage %f must be [0.0,1.0]", coverage)
+	for _, amount := range genesis.BootstrapSupply {
+		if err := asserter.Amount(amount); err != nil {
+			return fmt.Errorf("%w: invalid bootstrap supply", err)
 		}
+	}
 
-		index := config.EndConditions.ReconciliationCoverage.Index
-		if index != nil && *index < 0 {
-			return fmt.Errorf("reconciliation coverage height %d must be >= 0", *index)
-		}
+	return nil
+}
 
-		accountCount := config.EndConditions.ReconciliationCoverage.AccountCount
-		if accountCount != nil && *accountCount < 0 {
-			return fmt.Errorf(
-				"reconciliation coverage account count %d must be >= 0",
-				*accountCount,
-			)
-		}
+func assertStrictness(strictness AssertionStrictness) error {
+	switch strictness {
+	case StrictnessIgnore, StrictnessWarn, StrictnessFail:
+		return nil
+	default:
+		return fmt.Errorf("strictness %s is not supported", strictness)
+	}
+}
 
-		if config.BalanceTrackingDisabled {
-			return errors.New(
-				"balance tracking must be enabled for reconciliation coverage end condition",
-			)
-		}
+func assertNetworkStatusAssertions(assertions *NetworkStatusAssertions) error {
+	if assertions == nil {
+		return nil
+	}
 
-		if config.IgnoreReconciliationError {
-			return errors.New(
-				"reconciliation errors cannot be ignored for reconciliation coverage end condition",
-			)
+	if err := assertStrictness(assertions.CurrentBlock); err != nil {
+		return fmt.Errorf("%w: invalid current block strictness", err)
+	}
+
+	if err := assertStrictness(assertions.Peers); err != nil {
+		return fmt.Errorf("%w: invalid peers strictness", err)
+	}
+
+	if err := assertStrictness(assertions.SyncStatus); err != nil {
+		return fmt.Errorf("%w: invalid sync status strictness", err)
+	}
+
+	return nil
+}
+
+func assertTracing(tracing *TracingConfiguration) error {
+	if tracing == nil {
+		return nil
+	}
+
+	endpoint, err := url.Parse(tracing.Endpoint)
+	if err != nil {
+		return fmt.Errorf("%w: unable to parse endpoint %s", err, tracing.Endpoint)
+	}
+
+	if endpoint.Scheme != "http" && endpoint.Scheme != "https" {
+		return fmt.Errorf("endpoint %s must use http or https", tracing.Endpoint)
+	}
+
+	return nil
+}
+
+func assertLogFilter(filter *LogFilterConfiguration) error {
+	if filter == nil {
+		return nil
+	}
+
+	if len(filter.Accounts) == 0 && len(filter.Currencies) == 0 {
+		return errors.New("accounts or currencies must be populated")
+	}
+
+	for _, account := range filter.Accounts {
+		if err := asserter.AccountIdentifier(account); err != nil {
+			return fmt.Errorf("%w: invalid account", err)
 		}
+	}
 
-		if config.ReconciliationDisabled {
-			return errors.New(
-				"reconciliation cannot be disabled for reconciliation coverage end condition",
-			)
+	for _, currency := range filter.Currencies {
+		if err := asserter.Currency(currency); err != nil {
+			return fmt.Errorf("%w: invalid currency", err)
 		}
 	}
 
 	return nil
 }
 
-func assertConfiguration(ctx context.Context, config *Configuration) error {
-	if err := asserter.NetworkIdentifier(config.Network); err != nil {
-		return fmt.Errorf("%w: invalid network identifier", err)
+func assertParquetExport(export *ParquetExportConfiguration) error {
+	if export == nil {
+		return nil
 	}
 
-	if config.SeenBlockWorkers <= 0 {
-		return errors.New("seen_block_workers must be > 0")
+	if len(export.Directory) == 0 {
+		return errors.New("directory must be populated")
 	}
 
-	if config.SerialBlockWorkers <= 0 {
-		return errors.New("serial_block_workers must be > 0")
+	if export.BlocksPerFile < 0 {
+		return fmt.Errorf("blocks per file %d must be > 0", export.BlocksPerFile)
 	}
 
-	if err := assertDataConfiguration(config.Data); err != nil {
-		return fmt.Errorf("%w: invalid data configuration", err)
+	return nil
+}
+
+func assertCounterSnapshots(snapshots *CounterSnapshotConfiguration) error {
+	if snapshots == nil {
+		return nil
 	}
 
-	if err := assertConstructionConfiguration(ctx, config.Construction); err != nil {
-		return fmt.Errorf("%w: invalid construction configuration", err)
+	if len(snapshots.File) == 0 {
+		return errors.New("file must be populated")
 	}
 
 	return nil
 }
 
-// modifyFilePaths modifies a collection of filepaths in a *Configuration
-// file to make them relative to the configuration file (this makes it a lot easier
-// to store all config-related files in the same directory and to run the rosetta-cli
-// from a different directory).
-func modifyFilePaths(config *Configuration, fileDir string) {
-	if config.Data != nil {
-		if len(config.Data.BootstrapBalances) > 0 {
-			config.Data.BootstrapBalances = path.Join(fileDir, config.Data.BootstrapBalances)
-		}
+func assertDiskUsage(diskUsage *DiskUsageConfiguration) error {
+	if diskUsage == nil {
+		return nil
+	}
 
-		if len(config.Data.InterestingAccounts) > 0 {
-			config.Data.InterestingAccounts = path.Join(fileDir, config.Data.InterestingAccounts)
-		}
+	if diskUsage.MaxSizeMB <= 0 {
+		return fmt.Errorf("max size %d must be positive", diskUsage.MaxSizeMB)
+	}
 
-		if len(config.Data.ExemptAccounts) > 0 {
-			config.Data.ExemptAccounts = path.Join(fileDir, config.Data.ExemptAccounts)
-		}
+	switch diskUsage.Action {
+	case DiskUsagePrune, DiskUsagePause:
+	default:
+		return fmt.Errorf("action %s is not supported", diskUsage.Action)
 	}
 
-	if config.Construction != nil {
-		if len(config.Construction.ConstructorDSLFile) > 0 {
-			config.Construction.ConstructorDSLFile = path.Join(
-				fileDir,
-				config.Construction.ConstructorDSLFile,
-			)
-		}
+	return nil
+}
+
+func assertRetryPolicy(config *Configuration) error {
+	if config.RetryPolicy == nil {
+		return nil
 	}
 
-	if len(config.ValidationFile) > 0 {
-		config.ValidationFile = path.Join(fileDir, config.ValidationFile)
+	if config.ForceRetry {
+		return errors.New("retry_policy cannot be used with force_retry")
+	}
+
+	if config.RetryPolicy.RateLimitRetries < 0 {
+		return errors.New("rate_limit_retries must be >= 0")
+	}
+
+	if config.RetryPolicy.ServerErrorRetries < 0 {
+		return errors.New("server_error_retries must be >= 0")
+	}
+
+	if config.RetryPolicy.NonRetriableErrorRetries < 0 {
+		return errors.New("non_retriable_error_retries must be >= 0")
 	}
+
+	return nil
+}
+
+func assertCircuitBreaker(circuitBreaker *CircuitBreakerConfiguration) error {
+	if circuitBreaker == nil {
+		return nil
+	}
+
+	if circuitBreaker.FailureThreshold < 0 {
+		return errors.New("failure_threshold must be >= 0")
+	}
+
+	if circuitBreaker.RetryBudget < 0 {
+		return errors.New("retry_budget must be >= 0")
+	}
+
+	return nil
+}
+
+func assertHTTPTransport(httpTransport *HTTPTransportConfiguration) error {
+	if httpTransport == nil {
+		return nil
+	}
+
+	if httpTransport.MaxIdleConnections < 0 {
+		return errors.New("max_idle_connections must be >= 0")
+	}
+
+	if httpTransport.MaxIdleConnectionsPerHost < 0 {
+		return errors.New("max_idle_connections_per_host must be >= 0")
+	}
+
+	if httpTransport.MaxConnectionsPerHost < 0 {
+		return errors.New("max_connections_per_host must be >= 0")
+	}
+
+	return nil
+}
+
+func assertProxyURL(proxyURL string) error {
+	u, err := url.Parse(proxyURL)
+	if err != nil {
+		return fmt.Errorf("%w: unable to parse %s", err, proxyURL)
+	}
+
+	switch u.Scheme {
+	case "http", "https", "socks5":
+	default:
+		return fmt.Errorf("scheme of %s must be http, https, or socks5", proxyURL)
+	}
+
+	if len(u.Host) == 0 {
+		return fmt.Errorf("%s must include a host", proxyURL)
+	}
+
+	return nil
+}
+
+func assertProxy(proxy *ProxyConfiguration) error {
+	if proxy == nil {
+		return nil
+	}
+
+	if len(proxy.URL) > 0 {
+		if err := assertProxyURL(proxy.URL); err != nil {
+			return err
+		}
+	}
+
+	for host, proxyURL := range proxy.Overrides {
+		if len(proxyURL) == 0 {
+			continue
+		}
+
+		if err := assertProxyURL(proxyURL); err != nil {
+			return fmt.Errorf("%w: invalid override for %s", err, host)
+		}
+	}
+
+	return nil
+}
+
+func assertEndpointTimeouts(endpointTimeouts map[string]uint64) error {
+	for endpoint, timeout := range endpointTimeouts {
+		if !strings.HasPrefix(endpoint, "/") {
+			return fmt.Errorf("endpoint %s must start with /", endpoint)
+		}
+
+		if timeout == 0 {
+			return fmt.Errorf("timeout of endpoint %s must be > 0", endpoint)
+		}
+	}
+
+	return nil
+}
+
+func assertFixtures(fixtures *FixturesConfiguration) error {
+	if fixtures == nil {
+		return nil
+	}
+
+	if len(fixtures.Directory) == 0 {
+		return errors.New("directory must be populated")
+	}
+
+	switch fixtures.Mode {
+	case FixturesRecord, FixturesReplay:
+	default:
+		return fmt.Errorf("mode %s is not supported", fixtures.Mode)
+	}
+
+	return nil
+}
+
+func assertOnlineURLs(config *Configuration) error {
+	switch config.OnlineURLStrategy {
+	case "", OnlineURLFailover, OnlineURLRoundRobin:
+	default:
+		return fmt.Errorf("strategy %s is not supported", config.OnlineURLStrategy)
+	}
+
+	for _, onlineURL := range config.OnlineURLs {
+		u, err := url.Parse(onlineURL)
+		if err != nil {
+			return fmt.Errorf("%w: unable to parse %s", err, onlineURL)
+		}
+
+		if len(u.Scheme) == 0 || len(u.Host) == 0 {
+			return fmt.Errorf("%s must include a scheme and host", onlineURL)
+		}
+	}
+
+	return nil
+}
+
+func assertBlockCache(blockCache *BlockCacheConfiguration) error {
+	if blockCache == nil {
+		return nil
+	}
+
+	if blockCache.Size < 0 {
+		return fmt.Errorf("size %d cannot be negative", blockCache.Size)
+	}
+
+	u, err := url.Parse(blockCache.URL)
+	if err != nil {
+		return fmt.Errorf("%w: unable to parse %s", err, blockCache.URL)
+	}
+
+	if len(u.Scheme) == 0 || len(u.Host) == 0 {
+		return fmt.Errorf("%s must include a scheme and host", blockCache.URL)
+	}
+
+	return nil
+}
+
+func assertAdaptiveConcurrency(
+	adaptive *AdaptiveConcurrencyConfiguration,
+	maxConcurrency int64,
+) error {
+	if adaptive == nil {
+		return nil
+	}
+
+	if adaptive.TargetLatency == 0 {
+		return errors.New("target latency must be populated")
+	}
+
+	if adaptive.MinConcurrency < 0 || adaptive.MinConcurrency > maxConcurrency {
+		return fmt.Errorf(
+			"min concurrency %d must be between 0 and max sync concurrency %d",
+			adaptive.MinConcurrency,
+			maxConcurrency,
+		)
+	}
+
+	return nil
+}
+
+func assertRetention(retention *RetentionConfiguration) error {
+	if retention == nil {
+		return nil
+	}
+
+	if retention.MaxAge == 0 && retention.MaxBlocks == 0 {
+		return errors.New("max age or max blocks must be populated")
+	}
+
+	return nil
+}
+
+func assertBalanceThresholds(thresholds []*BalanceThreshold) error {
+	seen := map[string]struct{}{}
+	for _, threshold := range thresholds {
+		if threshold.Currency == nil {
+			return errors.New("currency must be populated")
+		}
+
+		key := types.Hash(threshold.Currency)
+		if _, ok := seen[key]; ok {
+			return fmt.Errorf(
+				"duplicate threshold for %s",
+				types.PrintStruct(threshold.Currency),
+			)
+		}
+		seen[key] = struct{}{}
+
+		minimum, err := types.BigInt(threshold.Minimum)
+		if err != nil {
+			return fmt.Errorf("%w: invalid minimum %s", err, threshold.Minimum)
+		}
+
+		if minimum.Sign() < 0 {
+			return fmt.Errorf("minimum %s cannot be negative", threshold.Minimum)
+		}
+	}
+
+	return nil
+}
+
+func assertReferenceBalance(reference *ReferenceBalanceConfiguration) error {
+	if reference == nil {
+		return nil
+	}
+
+	switch reference.Type {
+	case RosettaReference:
+		u, err := url.Parse(reference.URL)
+		if err != nil {
+			return fmt.Errorf("%w: unable to parse %s", err, reference.URL)
+		}
+
+		if len(u.Scheme) == 0 || len(u.Host) == 0 {
+			return fmt.Errorf("%s must include a scheme and host", reference.URL)
+		}
+	case RESTReference:
+		if _, err := template.New("reference").Parse(reference.URL); err != nil {
+			return fmt.Errorf("%w: unable to parse url template", err)
+		}
+
+		if len(reference.BalanceField) == 0 {
+			return errors.New("balance field must be populated")
+		}
+	default:
+		return fmt.Errorf("type %s is not supported", reference.Type)
+	}
+
+	return nil
+}
+
+func assertAudit(config *DataConfiguration) error {
+	audit := config.Audit
+	if audit == nil {
+		return nil
+	}
+
+	if len(audit.Heights) == 0 && audit.Interval == 0 {
+		return errors.New("heights or interval must be populated")
+	}
+
+	if audit.Interval < 0 {
+		return fmt.Errorf("interval %d cannot be negative", audit.Interval)
+	}
+
+	for _, height := range audit.Heights {
+		if height < 0 {
+			return fmt.Errorf("height %d cannot be negative", height)
+		}
+	}
+
+	if len(audit.Directory) == 0 {
+		return errors.New("directory must be populated")
+	}
+
+	if config.BalanceTrackingDisabled {
+		return errors.New("balance tracking must be enabled")
+	}
+
+	return nil
+}
+
+func assertBalanceExemptions(exemptions []*BalanceExemptionConfiguration) error {
+	for _, exemption := range exemptions {
+		switch exemption.ExemptionType {
+		case types.BalanceDynamic, types.BalanceGreaterOrEqual, types.BalanceLessOrEqual:
+		default:
+			return fmt.Errorf("exemption type %s is not supported", exemption.ExemptionType)
+		}
+
+		if exemption.Account != nil && exemption.SubAccountAddress != nil {
+			return errors.New("account and sub-account address cannot both be populated")
+		}
+
+		if exemption.Account == nil &&
+			exemption.SubAccountAddress == nil &&
+			exemption.Currency == nil {
+			return errors.New("account, sub-account address, or currency must be populated")
+		}
+	}
+
+	return nil
+}
+
+func assertCurrencyReconciliation(configs []*CurrencyReconciliationConfiguration) error {
+	seen := map[string]struct{}{}
+	for _, config := range configs {
+		if config.Currency == nil {
+			return errors.New("currency must be populated")
+		}
+
+		key := types.Hash(config.Currency)
+		if _, ok := seen[key]; ok {
+			return fmt.Errorf(
+				"duplicate configuration for %s",
+				types.PrintStruct(config.Currency),
+			)
+		}
+		seen[key] = struct{}{}
+
+		if config.Concurrency < 0 {
+			return fmt.Errorf("concurrency %d cannot be negative", config.Concurrency)
+		}
+
+		if len(config.Tolerance) == 0 {
+			continue
+		}
+
+		tolerance, err := types.BigInt(config.Tolerance)
+		if err != nil {
+			return fmt.Errorf("%w: invalid tolerance %s", err, config.Tolerance)
+		}
+
+		if tolerance.Sign() < 0 {
+			return fmt.Errorf("tolerance %s cannot be negative", config.Tolerance)
+		}
+	}
+
+	return nil
+}
+
+func assertReconciliationPriority(config *DataConfiguration) error {
+	priority := config.ReconciliationPriority
+	if priority == nil {
+		return nil
+	}
+
+	switch priority.Strategy {
+	case RecentActivityPriority, LargestBalancePriority:
+	default:
+		return fmt.Errorf("strategy %s is not supported", priority.Strategy)
+	}
+
+	if priority.Accounts < 0 {
+		return fmt.Errorf("accounts %d cannot be negative", priority.Accounts)
+	}
+
+	if config.BalanceTrackingDisabled || config.ReconciliationDisabled {
+		return errors.New("balance tracking and reconciliation must be enabled")
+	}
+
+	return nil
+}
+
+func assertBalanceAdjustmentURL(config *DataConfiguration) error {
+	if len(config.BalanceAdjustmentURL) == 0 {
+		return nil
+	}
+
+	u, err := url.Parse(config.BalanceAdjustmentURL)
+	if err != nil {
+		return fmt.Errorf("%w: unable to parse %s", err, config.BalanceAdjustmentURL)
+	}
+
+	if len(u.Scheme) == 0 || len(u.Host) == 0 {
+		return fmt.Errorf("%s must include a scheme and host", config.BalanceAdjustmentURL)
+	}
+
+	if config.BalanceTrackingDisabled {
+		return errors.New("balance tracking must be enabled")
+	}
+
+	return nil
+}
+
+func assertLogRotation(rotation *LogRotationConfiguration) error {
+	if rotation == nil {
+		return nil
+	}
+
+	if rotation.MaxSizeMB < 0 {
+		return fmt.Errorf("max size %d cannot be negative", rotation.MaxSizeMB)
+	}
+
+	if rotation.MaxBackups < 0 {
+		return fmt.Errorf("max backups %d cannot be negative", rotation.MaxBackups)
+	}
+
+	if rotation.MaxSizeMB == 0 && rotation.MaxAge == 0 {
+		return errors.New("max size or max age must be populated")
+	}
+
+	return nil
+}
+
+func assertDataConfiguration(config *DataConfiguration) error { // nolint:gocognit
+	if config.StartIndex != nil && *config.StartIndex < 0 {
+		return fmt.Errorf("start index %d cannot be negative", *config.StartIndex)
+	}
+
+	if config.StartIndex != nil && len(config.StartBlockHash) > 0 {
+		return errors.New("start index and start block hash cannot both be populated")
+	}
+
+	if config.FollowTip && config.EndConditions != nil {
+		return errors.New("end conditions cannot be populated when following the tip")
+	}
+
+	if !config.ReconciliationDisabled && config.BalanceTrackingDisabled {
+		return errors.New("balance tracking must be enabled to perform reconciliation")
+	}
+
+	if err := assertGenesisAssertions(config); err != nil {
+		return fmt.Errorf("%w: invalid genesis assertions", err)
+	}
+
+	if err := assertNetworkStatusAssertions(config.NetworkStatusAssertions); err != nil {
+		return fmt.Errorf("%w: invalid network status assertions", err)
+	}
+
+	if err := assertLogRotation(config.LogRotation); err != nil {
+		return fmt.Errorf("%w: invalid log rotation", err)
+	}
+
+	if err := assertTracing(config.Tracing); err != nil {
+		return fmt.Errorf("%w: invalid tracing", err)
+	}
+
+	if err := assertLogFilter(config.LogFilter); err != nil {
+		return fmt.Errorf("%w: invalid log filter", err)
+	}
+
+	if err := assertParquetExport(config.ParquetExport); err != nil {
+		return fmt.Errorf("%w: invalid parquet export", err)
+	}
+
+	if err := assertCounterSnapshots(config.CounterSnapshots); err != nil {
+		return fmt.Errorf("%w: invalid counter snapshots", err)
+	}
+
+	if err := assertDiskUsage(config.DiskUsage); err != nil {
+		return fmt.Errorf("%w: invalid disk usage", err)
+	}
+
+	if err := assertRetention(config.Retention); err != nil {
+		return fmt.Errorf("%w: invalid retention", err)
+	}
+
+	if err := assertBalanceThresholds(config.ReconciliationBalanceThresholds); err != nil {
+		return fmt.Errorf("%w: invalid reconciliation balance thresholds", err)
+	}
+
+	if err := assertCurrencyReconciliation(config.CurrencyReconciliation); err != nil {
+		return fmt.Errorf("%w: invalid currency reconciliation", err)
+	}
+
+	if err := assertBalanceExemptions(config.BalanceExemptions); err != nil {
+		return fmt.Errorf("%w: invalid balance exemptions", err)
+	}
+
+	if err := assertAudit(config); err != nil {
+		return fmt.Errorf("%w: invalid audit", err)
+	}
+
+	if err := assertReferenceBalance(config.ReferenceBalance); err != nil {
+		return fmt.Errorf("%w: invalid reference balance", err)
+	}
+
+	if err := assertReconciliationPriority(config); err != nil {
+		return fmt.Errorf("%w: invalid reconciliation priority", err)
+	}
+
+	switch config.SubAccounts {
+	case "", SeparateSubAccounts, AggregateSubAccounts, IgnoreSubAccounts:
+	default:
+		return fmt.Errorf("sub-account mode %s is not supported", config.SubAccounts)
+	}
+
+	if err := assertBalanceAdjustmentURL(config); err != nil {
+		return fmt.Errorf("%w: invalid balance adjustment url", err)
+	}
+
+	if len(config.NegativeBalanceAccounts) > 0 && config.BalanceTrackingDisabled {
+		return errors.New("negative balance accounts require balance tracking")
+	}
+
+	if reverification := config.ReconciliationReverification; reverification != nil &&
+		(reverification.Attempts < 0 || reverification.WaitBlocks < 0) {
+		return fmt.Errorf(
+			"reconciliation reverification attempts %d and wait blocks %d cannot be negative",
+			reverification.Attempts,
+			reverification.WaitBlocks,
+		)
+	}
+
+	if config.ReconciliationFailureLimit < 0 {
+		return fmt.Errorf(
+			"reconciliation failure limit %d cannot be negative",
+			config.ReconciliationFailureLimit,
+		)
+	}
+
+	if config.ContinueOnError && config.ReconciliationFailureLimit > 0 {
+		return errors.New(
+			"reconciliation failure limit cannot be used with continue on error",
+		)
+	}
+
+	if config.ActiveReconciliationSampleRate < 0 || config.ActiveReconciliationSampleRate > 1 {
+		return fmt.Errorf(
+			"active reconciliation sample rate %f must be between 0 and 1",
+			config.ActiveReconciliationSampleRate,
+		)
+	}
+
+	if config.MinimumReconciliationCoverage < 0 || config.MinimumReconciliationCoverage > 1 {
+		return fmt.Errorf(
+			"minimum reconciliation coverage %f must be between 0 and 1",
+			config.MinimumReconciliationCoverage,
+		)
+	}
+
+	if config.InterestingAccountsReloadFrequency > 0 &&
+		(len(config.InterestingAccounts) == 0 || config.BalanceTrackingDisabled) {
+		return errors.New(
+			"interesting accounts reload requires an interesting accounts file and balance tracking",
+		)
+	}
+
+	if config.MinimumReconciliationCoverage > 0 &&
+		(config.BalanceTrackingDisabled || config.ReconciliationDisabled) {
+		return errors.New(
+			"balance tracking and reconciliation must be enabled for minimum reconciliation coverage",
+		)
+	}
+
+	if config.BlockCommitBatchSize < 0 {
+		return fmt.Errorf("block commit batch size %d cannot be negative", config.BlockCommitBatchSize)
+	}
+
+	if config.BlockCommitFlushIntervalMs < 0 {
+		return fmt.Errorf(
+			"block commit flush interval %d cannot be negative",
+			config.BlockCommitFlushIntervalMs,
+		)
+	}
+
+	if config.MetadataDedupeThreshold < 0 {
+		return fmt.Errorf(
+			"metadata dedupe threshold %d cannot be negative",
+			config.MetadataDedupeThreshold,
+		)
+	}
+
+	if config.BalanceCacheSize < 0 {
+		return fmt.Errorf("balance cache size %d cannot be negative", config.BalanceCacheSize)
+	}
+
+	if config.OtherTransactionConcurrency < 0 {
+		return fmt.Errorf(
+			"other transaction concurrency %d cannot be negative",
+			config.OtherTransactionConcurrency,
+		)
+	}
+
+	if config.MissingOpsSearchConcurrency < 0 {
+		return fmt.Errorf(
+			"missing ops search concurrency %d cannot be negative",
+			config.MissingOpsSearchConcurrency,
+		)
+	}
+
+	switch config.MissingOpsSearchStrategy {
+	case "", FixedWindowSearch, ExponentialWindowSearch, FirstSeenSearch:
+	default:
+		return fmt.Errorf(
+			"missing ops search strategy %s is not supported",
+			config.MissingOpsSearchStrategy,
+		)
+	}
+
+	if config.BackwardSync != nil && config.BackwardSync.Depth < 0 {
+		return fmt.Errorf("backward sync depth %d cannot be negative", config.BackwardSync.Depth)
+	}
+
+	for _, skipBlock := range config.SkipBlocks {
+		if err := asserter.PartialBlockIdentifier(skipBlock); err != nil {
+			return fmt.Errorf("%w: invalid skip block", err)
+		}
+	}
+
+	if config.AccountFilter != nil {
+		rate := config.AccountFilter.FalsePositiveRate
+		if rate <= 0 || rate >= 1 {
+			return fmt.Errorf("account filter false positive rate %f must be between 0 and 1", rate)
+		}
+	}
+
+	if config.ControlPort != 0 && config.ControlPort == config.StatusPort {
+		return fmt.Errorf("control port %d cannot be the same as the status port", config.ControlPort)
+	}
+
+	if config.EndConditions == nil {
+		return nil
+	}
+
+	if config.EndConditions.Index != nil {
+		if *config.EndConditions.Index < 0 {
+			return fmt.Errorf("end index %d cannot be negative", *config.EndConditions.Index)
+		}
+	}
+
+	if config.EndConditions.TipDistance != nil && *config.EndConditions.TipDistance < 0 {
+		return fmt.Errorf("tip distance %d cannot be negative", *config.EndConditions.TipDistance)
+	}
+
+	if len(config.EndConditions.Timestamp) > 0 {
+		if _, err := ParseEndTimestamp(config.EndConditions.Timestamp); err != nil {
+			return err
+		}
+	}
+
+	if config.EndConditions.ReconciliationCoverage != nil {
+		coverage := config.EndConditions.ReconciliationCoverage.Coverage
+		if coverage < 0 || coverage > 1 {
+			return fmt.Errorf("reconciliation coverage %f must be [0.0,1.0]", coverage)
+		}
+
+		index := config.EndConditions.ReconciliationCoverage.Index
+		if index != nil && *index < 0 {
+			return fmt.Errorf("reconciliation coverage height %d must be >= 0", *index)
+		}
+
+		accountCount := config.EndConditions.ReconciliationCoverage.AccountCount
+		if accountCount != nil && *accountCount < 0 {
+			return fmt.Errorf(
+				"reconciliation coverage account count %d must be >= 0",
+				*accountCount,
+			)
+		}
+
+		if config.BalanceTrackingDisabled {
+			return errors.New(
+				"balance tracking must be enabled for reconciliation coverage end condition",
+			)
+		}
+
+		if config.IgnoreReconciliationError {
+			return errors.New(
+				"reconciliation errors cannot be ignored for reconciliation coverage end condition",
+			)
+		}
+
+		if config.ReconciliationDisabled {
+			return errors.New(
+				"reconciliation cannot be disabled for reconciliation coverage end condition",
+			)
+		}
+	}
+
+	if err := assertReconciliationCount(config); err != nil {
+		return fmt.Errorf("%w: invalid reconciliation count end condition", err)
+	}
+
+	return nil
+}
+
+func assertReconciliationCount(config *DataConfiguration) error {
+	count := config.EndConditions.ReconciliationCount
+	if count == nil {
+		return nil
+	}
+
+	if count.Active < 0 || count.Inactive < 0 {
+		return errors.New("active and inactive reconciliations must be >= 0")
+	}
+
+	if count.Active == 0 && count.Inactive == 0 {
+		return errors.New("active or inactive reconciliations must be populated")
+	}
+
+	if config.BalanceTrackingDisabled || config.ReconciliationDisabled {
+		return errors.New("balance tracking and reconciliation must be enabled")
+	}
+
+	return nil
+}
+
+func assertConfiguration(ctx context.Context, config *Configuration) error {
+	if err := asserter.NetworkIdentifier(config.Network); err != nil {
+		return fmt.Errorf("%w: invalid network identifier", err)
+	}
+
+	if config.SeenBlockWorkers <= 0 {
+		return errors.New("seen_block_workers must be > 0")
+	}
+
+	if config.SerialBlockWorkers <= 0 {
+		return errors.New("serial_block_workers must be > 0")
+	}
+
+	if config.SyncMemoryBudgetMB < 0 {
+		return errors.New("sync_memory_budget_mb must be >= 0")
+	}
+
+	if config.ReadOnlyStorage && config.InMemoryStorage {
+		return errors.New("read_only_storage cannot be used with in_memory_storage")
+	}
+
+	if err := assertOnlineURLs(config); err != nil {
+		return fmt.Errorf("%w: invalid online urls", err)
+	}
+
+	if err := assertHTTPTransport(config.HTTPTransport); err != nil {
+		return fmt.Errorf("%w: invalid http transport", err)
+	}
+
+	if err := assertRetryPolicy(config); err != nil {
+		return fmt.Errorf("%w: invalid retry policy", err)
+	}
+
+	if err := assertCircuitBreaker(config.CircuitBreaker); err != nil {
+		return fmt.Errorf("%w: invalid circuit breaker", err)
+	}
+
+	if config.Compression != nil && config.Compression.RequestCompressionThreshold < 0 {
+		return errors.New("request_compression_threshold must be >= 0")
+	}
+
+	if err := assertProxy(config.Proxy); err != nil {
+		return fmt.Errorf("%w: invalid proxy", err)
+	}
+
+	if err := assertEndpointTimeouts(config.EndpointTimeouts); err != nil {
+		return fmt.Errorf("%w: invalid endpoint timeouts", err)
+	}
+
+	if err := assertFixtures(config.Fixtures); err != nil {
+		return fmt.Errorf("%w: invalid fixtures", err)
+	}
+
+	if err := assertAdaptiveConcurrency(
+		config.AdaptiveSyncConcurrency,
+		config.MaxSyncConcurrency,
+	); err != nil {
+		return fmt.Errorf("%w: invalid adaptive sync concurrency", err)
+	}
+
+	if err := assertDataConfiguration(config.Data); err != nil {
+		return fmt.Errorf("%w: invalid data configuration", err)
+	}
+
+	if err := assertConstructionConfiguration(ctx, config.Construction); err != nil {
+		return fmt.Errorf("%w: invalid construction configuration", err)
+	}
+
+	if err := assertNotifiers(config.Notifiers); err != nil {
+		return fmt.Errorf("%w: invalid notifiers", err)
+	}
+
+	if err := assertEmail(config.Email); err != nil {
+		return fmt.Errorf("%w: invalid email", err)
+	}
+
+	if err := assertLogBackend(config.LogBackend); err != nil {
+		return fmt.Errorf("%w: invalid log backend", err)
+	}
+
+	if err := assertSentry(config.Sentry); err != nil {
+		return fmt.Errorf("%w: invalid sentry", err)
+	}
+
+	if err := assertBadger(config.Badger); err != nil {
+		return fmt.Errorf("%w: invalid badger configuration", err)
+	}
+
+	return nil
+}
+
+// modifyFilePaths modifies a collection of filepaths in a *Configuration
+// file to make them relative to the configuration file (this makes it a lot easier
+// to store all config-related files in the same directory and to run the rosetta-cli
+// from a different directory).
+func modifyFilePaths(config *Configuration, fileDir string) {
+	if config.Data != nil {
+		if len(config.Data.BootstrapBalances) > 0 {
+			config.Data.BootstrapBalances = path.Join(fileDir, config.Data.BootstrapBalances)
+		}
+
+		if len(config.Data.InterestingAccounts) > 0 {
+			config.Data.InterestingAccounts = path.Join(fileDir, config.Data.InterestingAccounts)
+		}
+
+		if len(config.Data.ExemptAccounts) > 0 {
+			config.Data.ExemptAccounts = path.Join(fileDir, config.Data.ExemptAccounts)
+		}
+
+		if len(config.Data.NegativeBalanceAccounts) > 0 {
+			config.Data.NegativeBalanceAccounts = path.Join(
+				fileDir,
+				config.Data.NegativeBalanceAccounts,
+			)
+		}
+	}
+
+	if config.Construction != nil {
+		if len(config.Construction.ConstructorDSLFile) > 0 {
+			config.Construction.ConstructorDSLFile = path.Join(
+				fileDir,
+				config.Construction.ConstructorDSLFile,
+			)
+		}
+	}
+
+	if len(config.ValidationFile) > 0 {
+		config.ValidationFile = path.Join(fileDir, config.ValidationFile)
+	}
+}
+
+// endDateLayout is the layout of
+// end timestamps that are dates.
+const endDateLayout = "2006-01-02"
+
+// ParseEndTimestamp parses an end timestamp given as an
+// RFC3339 timestamp or a date (which is interpreted in UTC).
+func ParseEndTimestamp(value string) (time.Time, error) {
+	if endTime, err := time.Parse(time.RFC3339, value); err == nil {
+		return endTime, nil
+	}
+
+	endTime, err := time.Parse(endDateLayout, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf(
+			"end timestamp %s must be an RFC3339 timestamp or a date (YYYY-MM-DD)",
+			value,
+		)
+	}
+
+	return endTime, nil
 }
 
 // LoadConfiguration returns a parsed and asserted Configuration for running
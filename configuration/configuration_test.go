@@ -95,6 +95,7 @@ var (
 			HistoricalBalanceDisabled:         &historicalDisabled,
 			StartIndex:                        &startIndex,
 			StatusPort:                        123,
+			OtherTransactionConcurrency:       2,
 			EndConditions: &DataEndConditions{
 				ReconciliationCoverage: &ReconciliationCoverage{
 					Coverage: goodCoverage,
@@ -367,6 +368,532 @@ func TestLoadConfiguration(t *testing.T) {
 			},
 			err: true,
 		},
+		"invalid genesis assertions (negative timestamp)": {
+			provided: &Configuration{
+				Data: &DataConfiguration{
+					GenesisAssertions: &GenesisAssertions{
+						Timestamp: &badStartIndex,
+					},
+				},
+			},
+			err: true,
+		},
+		"invalid genesis assertions (bootstrap supply without bootstrap balances)": {
+			provided: &Configuration{
+				Data: &DataConfiguration{
+					GenesisAssertions: &GenesisAssertions{
+						BootstrapSupply: []*types.Amount{
+							{
+								Value: "100",
+								Currency: &types.Currency{
+									Symbol:   "ETH",
+									Decimals: 18,
+								},
+							},
+						},
+					},
+				},
+			},
+			err: true,
+		},
+		"invalid network status assertions (unsupported strictness)": {
+			provided: &Configuration{
+				Data: &DataConfiguration{
+					NetworkStatusAssertions: &NetworkStatusAssertions{
+						Peers: "sometimes",
+					},
+				},
+			},
+			err: true,
+		},
+		"invalid log rotation (no size or age)": {
+			provided: &Configuration{
+				Data: &DataConfiguration{
+					LogRotation: &LogRotationConfiguration{
+						MaxBackups: 5,
+					},
+				},
+			},
+			err: true,
+		},
+		"invalid tracing (unsupported endpoint scheme)": {
+			provided: &Configuration{
+				Data: &DataConfiguration{
+					Tracing: &TracingConfiguration{
+						Endpoint: "grpc://localhost:4317",
+					},
+				},
+			},
+			err: true,
+		},
+		"invalid log filter (empty)": {
+			provided: &Configuration{
+				Data: &DataConfiguration{
+					LogFilter: &LogFilterConfiguration{},
+				},
+			},
+			err: true,
+		},
+		"invalid counter snapshots (missing file)": {
+			provided: &Configuration{
+				Data: &DataConfiguration{
+					CounterSnapshots: &CounterSnapshotConfiguration{
+						Frequency: 10,
+					},
+				},
+			},
+			err: true,
+		},
+		"invalid disk usage (unsupported action)": {
+			provided: &Configuration{
+				Data: &DataConfiguration{
+					DiskUsage: &DiskUsageConfiguration{
+						MaxSizeMB: 1024,
+						Action:    "delete",
+					},
+				},
+			},
+			err: true,
+		},
+		"invalid start (index and hash)": {
+			provided: &Configuration{
+				Data: &DataConfiguration{
+					StartIndex:     &startIndex,
+					StartBlockHash: "block 10",
+				},
+			},
+			err: true,
+		},
+		"invalid follow tip (end conditions)": {
+			provided: &Configuration{
+				Data: &DataConfiguration{
+					FollowTip: true,
+					EndConditions: &DataEndConditions{
+						Tip: &endTip,
+					},
+				},
+			},
+			err: true,
+		},
+		"invalid retention (nothing expires)": {
+			provided: &Configuration{
+				Data: &DataConfiguration{
+					Retention: &RetentionConfiguration{
+						Frequency: 60,
+					},
+				},
+			},
+			err: true,
+		},
+		"invalid reconciliation failure limit": {
+			provided: &Configuration{
+				Data: &DataConfiguration{
+					ReconciliationFailureLimit: -1,
+				},
+			},
+			err: true,
+		},
+		"reconciliation failure limit with continue on error": {
+			provided: &Configuration{
+				Data: &DataConfiguration{
+					ReconciliationFailureLimit: 2,
+					ContinueOnError:            true,
+				},
+			},
+			err: true,
+		},
+		"interesting accounts reload without file": {
+			provided: &Configuration{
+				Data: &DataConfiguration{
+					InterestingAccountsReloadFrequency: 10,
+				},
+			},
+			err: true,
+		},
+		"invalid reconciliation balance threshold": {
+			provided: &Configuration{
+				Data: &DataConfiguration{
+					ReconciliationBalanceThresholds: []*BalanceThreshold{
+						{
+							Currency: &types.Currency{Symbol: "BTC", Decimals: 8},
+							Minimum:  "-1",
+						},
+					},
+				},
+			},
+			err: true,
+		},
+		"invalid currency reconciliation tolerance": {
+			provided: &Configuration{
+				Data: &DataConfiguration{
+					CurrencyReconciliation: []*CurrencyReconciliationConfiguration{
+						{
+							Currency:  &types.Currency{Symbol: "BTC", Decimals: 8},
+							Tolerance: "-1",
+						},
+					},
+				},
+			},
+			err: true,
+		},
+		"invalid active reconciliation sample rate": {
+			provided: &Configuration{
+				Data: &DataConfiguration{
+					ActiveReconciliationSampleRate: 2,
+				},
+			},
+			err: true,
+		},
+		"invalid reconciliation priority strategy": {
+			provided: &Configuration{
+				Data: &DataConfiguration{
+					ReconciliationPriority: &ReconciliationPriorityConfiguration{
+						Strategy: "random",
+					},
+				},
+			},
+			err: true,
+		},
+		"invalid reconciliation reverification": {
+			provided: &Configuration{
+				Data: &DataConfiguration{
+					ReconciliationReverification: &ReverificationConfiguration{
+						WaitBlocks: -1,
+					},
+				},
+			},
+			err: true,
+		},
+		"invalid sub-account mode": {
+			provided: &Configuration{
+				Data: &DataConfiguration{
+					SubAccounts: "merge",
+				},
+			},
+			err: true,
+		},
+		"invalid balance adjustment url": {
+			provided: &Configuration{
+				Data: &DataConfiguration{
+					BalanceAdjustmentURL: "localhost",
+				},
+			},
+			err: true,
+		},
+		"negative balance accounts without balance tracking": {
+			provided: &Configuration{
+				Data: &DataConfiguration{
+					NegativeBalanceAccounts: "negative_balance_accounts.json",
+					BalanceTrackingDisabled: true,
+				},
+			},
+			err: true,
+		},
+		"invalid balance exemption type": {
+			provided: &Configuration{
+				Data: &DataConfiguration{
+					BalanceExemptions: []*BalanceExemptionConfiguration{
+						{
+							Currency: &types.Currency{
+								Symbol:   "BTC",
+								Decimals: 8,
+							},
+							ExemptionType: "greater",
+						},
+					},
+				},
+			},
+			err: true,
+		},
+		"invalid audit interval": {
+			provided: &Configuration{
+				Data: &DataConfiguration{
+					Audit: &AuditConfiguration{
+						Interval:  -1,
+						Directory: "audits",
+					},
+				},
+			},
+			err: true,
+		},
+		"reference balance without balance field": {
+			provided: &Configuration{
+				Data: &DataConfiguration{
+					ReferenceBalance: &ReferenceBalanceConfiguration{
+						Type: RESTReference,
+						URL:  "https://explorer.example.com/address/{{.Address}}",
+					},
+				},
+			},
+			err: true,
+		},
+		"invalid missing ops search concurrency": {
+			provided: &Configuration{
+				Data: &DataConfiguration{
+					MissingOpsSearchConcurrency: -1,
+				},
+			},
+			err: true,
+		},
+		"invalid missing ops search strategy": {
+			provided: &Configuration{
+				Data: &DataConfiguration{
+					MissingOpsSearchStrategy: "binary",
+				},
+			},
+			err: true,
+		},
+		"invalid minimum reconciliation coverage": {
+			provided: &Configuration{
+				Data: &DataConfiguration{
+					MinimumReconciliationCoverage: 1.5,
+				},
+			},
+			err: true,
+		},
+		"invalid block commit batch size": {
+			provided: &Configuration{
+				Data: &DataConfiguration{
+					BlockCommitBatchSize: -1,
+				},
+			},
+			err: true,
+		},
+		"invalid reconciliation count (empty)": {
+			provided: &Configuration{
+				Data: &DataConfiguration{
+					EndConditions: &DataEndConditions{
+						ReconciliationCount: &ReconciliationCount{},
+					},
+				},
+			},
+			err: true,
+		},
+		"invalid tip distance": {
+			provided: &Configuration{
+				Data: &DataConfiguration{
+					EndConditions: &DataEndConditions{
+						TipDistance: &badStartIndex,
+					},
+				},
+			},
+			err: true,
+		},
+		"invalid end timestamp": {
+			provided: &Configuration{
+				Data: &DataConfiguration{
+					EndConditions: &DataEndConditions{
+						Timestamp: "January 1",
+					},
+				},
+			},
+			err: true,
+		},
+		"invalid block commit flush interval": {
+			provided: &Configuration{
+				Data: &DataConfiguration{
+					BlockCommitFlushIntervalMs: -1,
+				},
+			},
+			err: true,
+		},
+		"invalid metadata dedupe threshold": {
+			provided: &Configuration{
+				Data: &DataConfiguration{
+					MetadataDedupeThreshold: -1,
+				},
+			},
+			err: true,
+		},
+		"invalid account filter (false positive rate)": {
+			provided: &Configuration{
+				Data: &DataConfiguration{
+					AccountFilter: &AccountFilterConfiguration{
+						FalsePositiveRate: 1.5,
+					},
+				},
+			},
+			err: true,
+		},
+		"invalid balance cache size": {
+			provided: &Configuration{
+				Data: &DataConfiguration{
+					BalanceCacheSize: -1,
+				},
+			},
+			err: true,
+		},
+		"invalid skip blocks (empty identifier)": {
+			provided: &Configuration{
+				Data: &DataConfiguration{
+					SkipBlocks: []*types.PartialBlockIdentifier{{}},
+				},
+			},
+			err: true,
+		},
+		"invalid other transaction concurrency": {
+			provided: &Configuration{
+				Data: &DataConfiguration{
+					OtherTransactionConcurrency: -1,
+				},
+			},
+			err: true,
+		},
+		"invalid backward sync (negative depth)": {
+			provided: &Configuration{
+				Data: &DataConfiguration{
+					BackwardSync: &BackwardSyncConfiguration{
+						Depth: -1,
+					},
+				},
+			},
+			err: true,
+		},
+		"invalid sync memory budget": {
+			provided: &Configuration{
+				SyncMemoryBudgetMB: -1,
+			},
+			err: true,
+		},
+		"invalid sentry (missing project id)": {
+			provided: &Configuration{
+				Sentry: &SentryConfiguration{
+					DSN: "https://public@sentry.example.com",
+				},
+			},
+			err: true,
+		},
+		"invalid badger (unsupported compression)": {
+			provided: &Configuration{
+				Badger: &BadgerConfiguration{
+					Compression: "lz4",
+				},
+			},
+			err: true,
+		},
+		"invalid storage (read only in memory)": {
+			provided: &Configuration{
+				InMemoryStorage: true,
+				ReadOnlyStorage: true,
+			},
+			err: true,
+		},
+		"invalid online urls (missing scheme)": {
+			provided: &Configuration{
+				OnlineURLs: []string{"localhost:8080"},
+			},
+			err: true,
+		},
+		"invalid http transport (negative max idle connections)": {
+			provided: &Configuration{
+				HTTPTransport: &HTTPTransportConfiguration{
+					MaxIdleConnections: -1,
+				},
+			},
+			err: true,
+		},
+		"invalid retry policy (force retry)": {
+			provided: &Configuration{
+				ForceRetry:  true,
+				RetryPolicy: &RetryPolicyConfiguration{},
+			},
+			err: true,
+		},
+		"invalid circuit breaker (negative retry budget)": {
+			provided: &Configuration{
+				CircuitBreaker: &CircuitBreakerConfiguration{
+					RetryBudget: -1,
+				},
+			},
+			err: true,
+		},
+		"invalid compression (negative threshold)": {
+			provided: &Configuration{
+				Compression: &CompressionConfiguration{
+					RequestCompressionThreshold: -1,
+				},
+			},
+			err: true,
+		},
+		"invalid proxy (unsupported scheme)": {
+			provided: &Configuration{
+				Proxy: &ProxyConfiguration{
+					URL: "ftp://proxy.internal:21",
+				},
+			},
+			err: true,
+		},
+		"invalid endpoint timeouts (zero timeout)": {
+			provided: &Configuration{
+				EndpointTimeouts: map[string]uint64{"/account/balance": 0},
+			},
+			err: true,
+		},
+		"invalid fixtures (unsupported mode)": {
+			provided: &Configuration{
+				Fixtures: &FixturesConfiguration{
+					Directory: "fixtures",
+					Mode:      "capture",
+				},
+			},
+			err: true,
+		},
+		"invalid adaptive sync concurrency (missing target latency)": {
+			provided: &Configuration{
+				AdaptiveSyncConcurrency: &AdaptiveConcurrencyConfiguration{
+					MinConcurrency: 2,
+				},
+			},
+			err: true,
+		},
+		"invalid badger (encryption key source)": {
+			provided: &Configuration{
+				Badger: &BadgerConfiguration{
+					Encryption: &BadgerEncryptionConfiguration{},
+				},
+			},
+			err: true,
+		},
+		"invalid email (missing recipients)": {
+			provided: &Configuration{
+				Email: &EmailConfiguration{
+					SMTPHost: "smtp.example.com",
+					From:     "rosetta-cli@example.com",
+				},
+			},
+			err: true,
+		},
+		"invalid log backend (unsupported type)": {
+			provided: &Configuration{
+				LogBackend: &LogBackendConfiguration{
+					Type: "kafka",
+				},
+			},
+			err: true,
+		},
+		"invalid notifier (unsupported type)": {
+			provided: &Configuration{
+				Notifiers: []*NotifierConfiguration{
+					{
+						Type:       "pager",
+						WebhookURL: "https://hooks.example.com",
+					},
+				},
+			},
+			err: true,
+		},
+		"invalid notifier (bad template)": {
+			provided: &Configuration{
+				Notifiers: []*NotifierConfiguration{
+					{
+						Type:       SlackNotifier,
+						WebhookURL: "https://hooks.example.com",
+						Template:   "{{.Command",
+					},
+				},
+			},
+			err: true,
+		},
 		"empty workflows": {
 			provided: &Configuration{
 				Construction: &ConstructionConfiguration{
@@ -375,6 +902,17 @@ func TestLoadConfiguration(t *testing.T) {
 			},
 			err: true,
 		},
+		"invalid block cache (missing scheme)": {
+			provided: &Configuration{
+				Construction: &ConstructionConfiguration{
+					Workflows: fakeWorkflows,
+					BlockCache: &BlockCacheConfiguration{
+						URL: "localhost:9090",
+					},
+				},
+			},
+			err: true,
+		},
 		"non-existent dsl file": {
 			provided: &Configuration{
 				Construction: &ConstructionConfiguration{
@@ -40,6 +40,93 @@ const (
 	// ReconciliationCoverageEndCondition is used to indicate that the reconciliation
 	// coverage end condition has been met.
 	ReconciliationCoverageEndCondition CheckDataEndCondition = "Reconciliation Coverage End Condition"
+
+	// BackwardSyncEndCondition is used to indicate that the backward
+	// sync completed and forward sync is disabled.
+	BackwardSyncEndCondition CheckDataEndCondition = "Backward Sync End Condition"
+
+	// ReconciliationCountEndCondition is used to indicate that the
+	// reconciliation count end condition has been met.
+	ReconciliationCountEndCondition CheckDataEndCondition = "Reconciliation Count End Condition"
+
+	// TipDistanceEndCondition is used to indicate that the tip
+	// distance end condition has been met.
+	TipDistanceEndCondition CheckDataEndCondition = "Tip Distance End Condition"
+)
+
+// AssertionStrictness determines how the "check:data" method
+// responds to a failed network status assertion.
+type AssertionStrictness string
+
+const (
+	// StrictnessIgnore is used to indicate that an assertion
+	// should not be evaluated.
+	StrictnessIgnore AssertionStrictness = "ignore"
+
+	// StrictnessWarn is used to indicate that a failed assertion
+	// should be logged but should not halt "check:data".
+	StrictnessWarn AssertionStrictness = "warn"
+
+	// StrictnessFail is used to indicate that a failed assertion
+	// should halt "check:data".
+	StrictnessFail AssertionStrictness = "fail"
+)
+
+// NotifierType is the service a notification
+// is posted to.
+type NotifierType string
+
+const (
+	// SlackNotifier posts notifications to a Slack incoming webhook.
+	SlackNotifier NotifierType = "slack"
+
+	// DiscordNotifier posts notifications to a Discord webhook.
+	DiscordNotifier NotifierType = "discord"
+)
+
+// LogBackendType is the destination of logged events.
+type LogBackendType string
+
+const (
+	// FileLogBackend writes events to files in the data
+	// directory and to stdout.
+	FileLogBackend LogBackendType = "file"
+
+	// SyslogLogBackend sends events to the local syslog daemon.
+	SyslogLogBackend LogBackendType = "syslog"
+
+	// JournaldLogBackend sends events to the systemd journal.
+	JournaldLogBackend LogBackendType = "journald"
+)
+
+// BadgerCompression is the block compression
+// applied by Badger to each table.
+type BadgerCompression string
+
+const (
+	// BadgerCompressionNone disables table compression.
+	BadgerCompressionNone BadgerCompression = "none"
+
+	// BadgerCompressionSnappy compresses tables with Snappy.
+	BadgerCompressionSnappy BadgerCompression = "snappy"
+
+	// BadgerCompressionZSTD compresses tables with Zstandard.
+	BadgerCompressionZSTD BadgerCompression = "zstd"
+)
+
+// BadgerLoadingMode determines how Badger
+// loads tables and value logs.
+type BadgerLoadingMode string
+
+const (
+	// BadgerLoadingFileIO reads from disk on each access.
+	BadgerLoadingFileIO BadgerLoadingMode = "file_io"
+
+	// BadgerLoadingMemoryMap memory maps files.
+	BadgerLoadingMemoryMap BadgerLoadingMode = "memory_map"
+
+	// BadgerLoadingRAM loads files into RAM.
+	BadgerLoadingRAM BadgerLoadingMode = "load_to_ram"
 )
 
 // Default Configuration Values
@@ -60,6 +147,29 @@ const (
 	DefaultBlockBroadcastLimit               = 5
 	DefaultStatusPort                        = 9090
 	DefaultMaxReorgDepth                     = 100
+	DefaultNetworkStatusCheckInterval        = 30
+	DefaultNetworkStatusStallTimeout         = 300
+	DefaultAssertionStrictness               = StrictnessWarn
+	DefaultTracingServiceName                = "rosetta-cli"
+	DefaultTracingExportInterval             = 5
+	DefaultCounterSnapshotFrequency          = 60
+	DefaultDiskUsageCheckFrequency           = 30
+	DefaultRetentionFrequency                = 3600
+	DefaultAdaptiveMinConcurrency            = 1
+	DefaultBlockCacheSize                    = 1000
+	DefaultRequestCompressionThreshold       = 1024
+	DefaultCircuitBreakerFailureThreshold    = 10
+	DefaultCircuitBreakerCooldown            = 30
+	DefaultRetryPolicyMaxBackoff             = 60
+	DefaultOtherTransactionConcurrency       = 8
+	DefaultAccountFilterExpectedAccounts     = 10000000
+	DefaultAccountFilterFalsePositiveRate    = 0.01
+	DefaultAccountFilterPersistFrequency     = 60
+	DefaultParquetBlocksPerFile              = 10000
+	DefaultReconciliationPriorityAccounts    = 100
+	DefaultReconciliationPriorityFrequency   = 10
+	DefaultReverificationAttempts            = 1
+	DefaultSMTPPort                          = 587
 
 	// Check Perf Default Configs
 	DefaultStartBlock                             = 100
@@ -82,6 +192,22 @@ var (
 	}
 )
 
+// BlockCacheConfiguration configures consulting the blocks
+// synced by a running check:data before fetching them from
+// the node.
+type BlockCacheConfiguration struct {
+	// URL is the URL of the status server of a running check:data
+	// (data.status_port). check:data answers /block requests for
+	// blocks it has synced that are deeper than max_reorg_depth (or
+	// that are requested by hash), so the same blocks are not fetched
+	// twice when check:data and check:construction run together.
+	URL string `json:"url"`
+
+	// Size is the maximum number of blocks returned by check:data
+	// that are held in memory. If not populated, 1000 is used.
+	Size int `json:"size,omitempty"`
+}
+
 // ConstructionConfiguration contains all configurations
 // to run check:construction.
 type ConstructionConfiguration struct {
@@ -92,6 +218,14 @@ type ConstructionConfiguration struct {
 	// fetcher will open.
 	MaxOfflineConnections int `json:"max_offline_connections"`
 
+	// OfflineValidationEnabled determines if we should verify that
+	// the implementation at OfflineURL is actually running in
+	// "offline mode" (i.e. that it is distinct from OnlineURL and
+	// refuses to serve network-dependent endpoints like /network/status
+	// with a rosetta error) before starting check:construction. If
+	// the offline implementation cannot be reached, the check fails.
+	OfflineValidationEnabled bool `json:"offline_validation_enabled,omitempty"`
+
 	// ForceRetry overrides the default retry handling to retry
 	// on all non-200 responses.
 	ForceRetry bool `json:"force_retry,omitempty"`
@@ -169,6 +303,18 @@ type ConstructionConfiguration struct {
 	// This is a separate config from the data config because it
 	// is usually false whereas the data config by the same name is usually true.
 	InitialBalanceFetchDisabled bool `json:"initial_balance_fetch_disabled"`
+
+	// SuggestedFeeTolerance enables the comparison of the suggested fee
+	// returned by /construction/metadata with the fee observed when
+	// the broadcast transaction is confirmed on-chain. A mismatch is
+	// reported if the observed fee is not within [suggested / tolerance,
+	// suggested * tolerance] or if the fee currencies differ.
+	SuggestedFeeTolerance *float64 `json:"suggested_fee_tolerance,omitempty"`
+
+	// BlockCache enables fetching blocks already synced by a
+	// running check:data (sharing its block storage) before
+	// fetching them from the node.
+	BlockCache *BlockCacheConfiguration `json:"block_cache,omitempty"`
 }
 
 // ReconciliationCoverage is used to add conditions
@@ -203,6 +349,18 @@ type ReconciliationCoverage struct {
 	AccountCount *int64 `json:"account_count,omitempty"`
 }
 
+// ReconciliationCount is the minimum number of
+// active and inactive reconciliations to perform.
+type ReconciliationCount struct {
+	// Active is the minimum number of active reconciliations
+	// (of accounts with balance changes in a block).
+	Active int64 `json:"active,omitempty"`
+
+	// Inactive is the minimum number of inactive reconciliations
+	// (of accounts that were reconciled again at a later block).
+	Inactive int64 `json:"inactive,omitempty"`
+}
+
 // DataEndConditions contains all the conditions for the syncer to stop
 // when running check:data. If any one of these conditions is considered
 // true, `check:data` will stop with success.
@@ -210,11 +368,25 @@ type DataEndConditions struct {
 	// Index configures the syncer to stop once reaching a particular block height.
 	Index *int64 `json:"index,omitempty"`
 
+	// Timestamp configures the syncer to stop at the last block
+	// before a time, given as an RFC3339 timestamp (like
+	// "2022-01-01T00:00:00Z") or a date in UTC (like "2022-01-01").
+	// The block is found by searching block timestamps before
+	// syncing starts, so the time must be before the current block.
+	Timestamp string `json:"timestamp,omitempty"`
+
 	// Tip configures the syncer to stop once it reached the tip.
 	// Make sure to configure `tip_delay` if you use this end
 	// condition.
 	Tip *bool `json:"tip,omitempty"`
 
+	// TipDistance configures the syncer to stop once the last synced
+	// block is within TipDistance blocks of the current block (as
+	// reported by /network/status, which is fetched again each time
+	// the condition is evaluated). Unlike Tip, this does not depend
+	// on block timestamps or `tip_delay`.
+	TipDistance *int64 `json:"tip_distance,omitempty"`
+
 	// Duration configures the syncer to stop after running
 	// for Duration seconds.
 	Duration *uint64 `json:"duration,omitempty"`
@@ -222,6 +394,586 @@ type DataEndConditions struct {
 	// ReconciliationCoverage configures the syncer to stop once it reaches
 	// some level of reconciliation coverage.
 	ReconciliationCoverage *ReconciliationCoverage `json:"reconciliation_coverage,omitempty"`
+
+	// ReconciliationCount configures the syncer to stop once it
+	// performs some number of active and inactive reconciliations.
+	ReconciliationCount *ReconciliationCount `json:"reconciliation_count,omitempty"`
+}
+
+// GenesisAssertions contains the expected properties of the
+// genesis block. When populated, `check:data` verifies the fetched
+// genesis block against these values before syncing and aborts
+// on any mismatch (this is usually a sign the rosetta-cli is pointed
+// at the wrong network).
+type GenesisAssertions struct {
+	// Hash is the expected hash of the genesis block.
+	Hash string `json:"hash,omitempty"`
+
+	// Timestamp is the expected timestamp (in milliseconds) of the genesis block.
+	Timestamp *int64 `json:"timestamp,omitempty"`
+
+	// BootstrapSupply is the expected sum (per currency) of the balances
+	// the node returns at the genesis block for the accounts in the
+	// BootstrapBalances file.
+	BootstrapSupply []*types.Amount `json:"bootstrap_supply,omitempty"`
+}
+
+// NetworkStatusAssertions configures the periodic validation of
+// the /network/status response during `check:data`. Each assertion
+// can be configured with its own AssertionStrictness.
+type NetworkStatusAssertions struct {
+	// CheckInterval is the frequency (in seconds) that /network/status
+	// is fetched and evaluated.
+	CheckInterval uint64 `json:"check_interval,omitempty"`
+
+	// StallTimeout is the number of seconds the current block may
+	// remain unchanged before it is considered stalled.
+	StallTimeout uint64 `json:"stall_timeout,omitempty"`
+
+	// CurrentBlock asserts that the current block is populated
+	// (non-zero index, hash, and timestamp) and advances over time.
+	CurrentBlock AssertionStrictness `json:"current_block,omitempty"`
+
+	// Peers asserts that at least one peer is returned.
+	Peers AssertionStrictness `json:"peers,omitempty"`
+
+	// SyncStatus asserts that the sync status is populated and
+	// is consistent with the observed movement of the current block.
+	SyncStatus AssertionStrictness `json:"sync_status,omitempty"`
+}
+
+// TracingConfiguration configures the export of OpenTelemetry
+// spans covering block fetching, storage, and reconciliation.
+type TracingConfiguration struct {
+	// Endpoint is the base URL of an OTLP/HTTP collector
+	// (spans are posted to <endpoint>/v1/traces).
+	Endpoint string `json:"endpoint"`
+
+	// ServiceName identifies the rosetta-cli in exported spans.
+	ServiceName string `json:"service_name,omitempty"`
+
+	// Headers are added to every export request (this
+	// is commonly used for collector authentication).
+	Headers map[string]string `json:"headers,omitempty"`
+
+	// ExportInterval is the number of seconds
+	// between span exports.
+	ExportInterval uint64 `json:"export_interval,omitempty"`
+}
+
+// LogFilterConfiguration restricts balance change and
+// reconciliation logging to accounts and/or currencies of
+// interest. If both Accounts and Currencies are populated,
+// an event must match both to be logged.
+type LogFilterConfiguration struct {
+	// Accounts are the accounts to log. If an account does not
+	// include a SubAccountIdentifier, all sub-accounts of the
+	// address are logged.
+	Accounts []*types.AccountIdentifier `json:"accounts,omitempty"`
+
+	// Currencies are the currencies to log.
+	Currencies []*types.Currency `json:"currencies,omitempty"`
+}
+
+// ParquetExportConfiguration configures the export of synced
+// blocks and operations to Parquet files for analytics.
+type ParquetExportConfiguration struct {
+	// Directory is where Parquet files are written. Blocks are written
+	// to <directory>/blocks and operations are written to
+	// <directory>/operations.
+	Directory string `json:"directory"`
+
+	// BlocksPerFile is the size of the block range
+	// stored in each file.
+	BlocksPerFile int64 `json:"blocks_per_file,omitempty"`
+}
+
+// CounterSnapshotConfiguration configures periodic snapshots
+// of the counters of a check:data run. Each snapshot is appended
+// to File as a line of JSON so that throughput over time can be
+// graphed after the run.
+type CounterSnapshotConfiguration struct {
+	// File is the path snapshots are appended to.
+	File string `json:"file"`
+
+	// Frequency is the number of seconds between snapshots.
+	Frequency uint64 `json:"frequency,omitempty"`
+}
+
+// DiskUsageAction is the action taken when the
+// data directory approaches its maximum size.
+type DiskUsageAction string
+
+const (
+	// DiskUsagePrune prunes blocks deeper than max_reorg_depth
+	// (and pauses syncing if that does not free enough space).
+	DiskUsagePrune DiskUsageAction = "prune"
+
+	// DiskUsagePause pauses syncing until space is freed.
+	DiskUsagePause DiskUsageAction = "pause"
+)
+
+// DiskUsageConfiguration bounds the size of the data
+// directory. Once the directory exceeds 90% of MaxSizeMB,
+// Action is taken instead of syncing until the disk is
+// full (which can corrupt the database).
+type DiskUsageConfiguration struct {
+	// MaxSizeMB is the maximum size (in megabytes)
+	// of the data directory.
+	MaxSizeMB int64 `json:"max_size_mb"`
+
+	// Action is the action taken when the data directory
+	// approaches MaxSizeMB ("prune" or "pause").
+	Action DiskUsageAction `json:"action"`
+
+	// CheckFrequency is the number of seconds between
+	// checks of the size of the data directory.
+	CheckFrequency uint64 `json:"check_frequency,omitempty"`
+}
+
+// OnlineURLStrategy determines how requests are spread
+// across multiple online URLs.
+type OnlineURLStrategy string
+
+const (
+	// OnlineURLFailover sends all requests to the same URL
+	// until it is unavailable (starting with online_url).
+	OnlineURLFailover OnlineURLStrategy = "failover"
+
+	// OnlineURLRoundRobin sends each request to the next URL
+	// (skipping URLs that are unavailable).
+	OnlineURLRoundRobin OnlineURLStrategy = "round_robin"
+)
+
+// HTTPTransportConfiguration tunes the connections opened
+// to the online server. Any field that is not populated
+// keeps the behavior of the default fetcher client.
+type HTTPTransportConfiguration struct {
+	// KeepAlive is the number of seconds between TCP keep-alive
+	// probes on open connections. If not populated, probes are
+	// sent every 30 seconds.
+	KeepAlive uint64 `json:"keep_alive,omitempty"`
+
+	// IdleConnectionTimeout is the number of seconds an idle
+	// connection is kept open for reuse. If not populated,
+	// idle connections are closed after 30 seconds.
+	IdleConnectionTimeout uint64 `json:"idle_connection_timeout,omitempty"`
+
+	// MaxIdleConnections is the maximum number of idle connections
+	// kept open for reuse. If not populated, max_online_connections
+	// is used.
+	MaxIdleConnections int `json:"max_idle_connections,omitempty"`
+
+	// MaxIdleConnectionsPerHost is the maximum number of idle
+	// connections kept open for reuse with each host. If not
+	// populated, 120 is used.
+	MaxIdleConnectionsPerHost int `json:"max_idle_connections_per_host,omitempty"`
+
+	// MaxConnectionsPerHost is the maximum number of connections
+	// (idle or in use) opened to each host. If not populated,
+	// the number of connections is not limited.
+	MaxConnectionsPerHost int `json:"max_connections_per_host,omitempty"`
+
+	// ConnectionReuseDisabled opens a new connection for
+	// each request instead of reusing idle connections.
+	ConnectionReuseDisabled bool `json:"connection_reuse_disabled,omitempty"`
+
+	// HTTP2Disabled only sends requests with HTTP/1.1, even
+	// when the online server supports HTTP/2.
+	HTTP2Disabled bool `json:"http2_disabled,omitempty"`
+}
+
+// RetryPolicyConfiguration determines how requests to the
+// online server are retried for each class of error. Rosetta
+// errors with retriable=true are still retried up to
+// max_retries.
+type RetryPolicyConfiguration struct {
+	// RateLimitRetries is the number of times a request that
+	// received a 429 is retried (honoring Retry-After).
+	RateLimitRetries int `json:"rate_limit_retries"`
+
+	// ServerErrorRetries is the number of times a request that
+	// received a 408 or a 5xx (other than a Rosetta error) is
+	// retried before failing.
+	ServerErrorRetries int `json:"server_error_retries"`
+
+	// NonRetriableErrorRetries is the number of times a request
+	// that received a Rosetta error with retriable=false is retried.
+	// If not populated, such requests fail immediately.
+	NonRetriableErrorRetries int `json:"non_retriable_error_retries,omitempty"`
+
+	// MaxBackoff is the maximum number of seconds to wait before
+	// retrying a request (the wait starts at 1 second and doubles
+	// with each retry). If not populated, 60 is used.
+	MaxBackoff uint64 `json:"max_backoff,omitempty"`
+}
+
+// CircuitBreakerConfiguration determines when requests to the
+// online server are paused because it appears to be down.
+type CircuitBreakerConfiguration struct {
+	// FailureThreshold is the number of consecutive failed requests
+	// (that could not reach the server or received a 502, 503, or 504)
+	// after which all requests are paused. If not populated, 10 is used.
+	FailureThreshold int `json:"failure_threshold,omitempty"`
+
+	// Cooldown is the number of seconds requests are paused before a
+	// single request probes if the server is back. If not populated,
+	// 30 is used.
+	Cooldown uint64 `json:"cooldown,omitempty"`
+
+	// RetryBudget is the number of failed probes after which the
+	// check fails instead of pausing again. If not populated, the
+	// check pauses until the server is back.
+	RetryBudget int `json:"retry_budget,omitempty"`
+}
+
+// CompressionConfiguration determines how requests to and
+// responses from the online server are compressed with gzip.
+type CompressionConfiguration struct {
+	// RequestCompressionEnabled compresses request bodies with
+	// gzip (setting "Content-Encoding: gzip"). This should only be
+	// enabled if the online server (or a proxy in front of it)
+	// decompresses requests.
+	RequestCompressionEnabled bool `json:"request_compression_enabled,omitempty"`
+
+	// RequestCompressionThreshold is the minimum size (in bytes) of
+	// a request body to compress it. If not populated, 1024 is used.
+	RequestCompressionThreshold int `json:"request_compression_threshold,omitempty"`
+
+	// ResponseCompressionDisabled stops requesting gzip-compressed
+	// responses ("Accept-Encoding: gzip"), which is otherwise
+	// negotiated with every request.
+	ResponseCompressionDisabled bool `json:"response_compression_disabled,omitempty"`
+}
+
+// ProxyConfiguration determines the proxy used to reach
+// the online server. Proxy URLs must use the http, https,
+// or socks5 scheme.
+type ProxyConfiguration struct {
+	// URL is the proxy used for all requests (unless overridden).
+	// If not populated, the proxy in the environment is used.
+	URL string `json:"url,omitempty"`
+
+	// Environment sends requests through the proxy in HTTP_PROXY
+	// or HTTPS_PROXY (honoring NO_PROXY) instead of through URL
+	// when one applies to the request.
+	Environment bool `json:"environment,omitempty"`
+
+	// Overrides are the proxies used for requests to specific
+	// hosts (like "node-2.internal:8080"), keyed by host. An
+	// empty proxy sends requests to the host directly.
+	Overrides map[string]string `json:"overrides,omitempty"`
+}
+
+// FixturesMode determines if responses are
+// recorded to or replayed from fixtures.
+type FixturesMode string
+
+const (
+	// FixturesRecord sends requests to the online
+	// server and records every response.
+	FixturesRecord FixturesMode = "record"
+
+	// FixturesReplay answers every request with the recorded
+	// responses without contacting the online server.
+	FixturesReplay FixturesMode = "replay"
+)
+
+// FixturesConfiguration determines where responses from
+// the online server are recorded (or replayed from).
+type FixturesConfiguration struct {
+	// Directory is the folder where fixtures are stored.
+	Directory string `json:"directory"`
+
+	// Mode is "record" or "replay". When recording, the responses
+	// to repeated requests (like /network/status) are stored in
+	// order so that a replay sees them in the same order.
+	Mode FixturesMode `json:"mode"`
+}
+
+// BackwardSyncConfiguration configures validating blocks
+// backward from the current tip (toward genesis) before
+// syncing forward.
+type BackwardSyncConfiguration struct {
+	// Depth is the number of blocks to validate (starting at the
+	// current tip). If 0, blocks are validated back to genesis.
+	Depth int64 `json:"depth,omitempty"`
+
+	// ForwardSyncDisabled ends check:data once the backward
+	// sync completes (instead of syncing forward).
+	ForwardSyncDisabled bool `json:"forward_sync_disabled,omitempty"`
+}
+
+// AdaptiveConcurrencyConfiguration configures adjusting the
+// number of concurrent /block requests to the latency and
+// errors of the node. Concurrency is halved when /block
+// requests fail or are slower than TargetLatency on average
+// and is increased by one (up to max_sync_concurrency) when
+// they take less than half of TargetLatency.
+type AdaptiveConcurrencyConfiguration struct {
+	// TargetLatency is the average latency (in milliseconds)
+	// of /block requests the concurrency is adjusted to.
+	TargetLatency uint64 `json:"target_latency"`
+
+	// MinConcurrency is the number of concurrent /block
+	// requests that is never reduced further.
+	MinConcurrency int64 `json:"min_concurrency,omitempty"`
+}
+
+// RetentionConfiguration expires records that otherwise grow
+// for as long as check:data runs: reconciliation history, rotated
+// log files, and counter snapshots.
+type RetentionConfiguration struct {
+	// MaxAge is the maximum age (in seconds) of reconciliation
+	// history, rotated log files, and counter snapshots. If 0,
+	// records are not expired by age.
+	MaxAge uint64 `json:"max_age,omitempty"`
+
+	// MaxBlocks is the number of blocks behind the head block
+	// for which reconciliation history is retained. If 0,
+	// reconciliation history is not expired by block.
+	MaxBlocks uint64 `json:"max_blocks,omitempty"`
+
+	// Frequency is the number of seconds between
+	// removals of expired records.
+	Frequency uint64 `json:"frequency,omitempty"`
+}
+
+// AccountFilterConfiguration configures the bloom filter of
+// seen accounts. The filter is persisted in the check:data
+// storage path and is rebuilt from storage (which requires
+// reading every account) if it is missing or was sized with
+// different parameters.
+type AccountFilterConfiguration struct {
+	// ExpectedAccounts is the number of accounts the filter is
+	// sized for. The false positive rate increases if more
+	// accounts are seen.
+	ExpectedAccounts uint64 `json:"expected_accounts,omitempty"`
+
+	// FalsePositiveRate is the fraction of lookups of unseen
+	// accounts that still read storage once ExpectedAccounts
+	// have been seen.
+	FalsePositiveRate float64 `json:"false_positive_rate,omitempty"`
+
+	// PersistFrequency is the number of seconds
+	// between writes of the filter to disk.
+	PersistFrequency uint64 `json:"persist_frequency,omitempty"`
+}
+
+// LogRotationConfiguration configures the rotation of the
+// block, transaction, balance, and reconciliation log files.
+// A file is rotated once it exceeds MaxSizeMB or is older
+// than MaxAge (whichever comes first).
+type LogRotationConfiguration struct {
+	// MaxSizeMB is the maximum size (in megabytes) of a log file
+	// before it is rotated. If 0, files are not rotated by size.
+	MaxSizeMB int64 `json:"max_size_mb,omitempty"`
+
+	// MaxAge is the maximum age (in seconds) of a log file
+	// before it is rotated. If 0, files are not rotated by age.
+	MaxAge uint64 `json:"max_age,omitempty"`
+
+	// MaxBackups is the number of rotated files to retain for
+	// each log. If 0, all rotated files are retained.
+	MaxBackups int `json:"max_backups,omitempty"`
+
+	// CompressionDisabled determines if rotated files
+	// should not be compressed with gzip.
+	CompressionDisabled bool `json:"compression_disabled,omitempty"`
+}
+
+// SubAccountMode determines how the balances
+// of sub-accounts are tracked and reconciled.
+type SubAccountMode string
+
+const (
+	// SeparateSubAccounts tracks and reconciles each
+	// sub-account as its own account.
+	SeparateSubAccounts SubAccountMode = "separate"
+
+	// AggregateSubAccounts applies the balance changes of each
+	// sub-account to its parent account and reconciles the parent
+	// with the sum of the live balances of the parent and all of
+	// its sub-accounts.
+	AggregateSubAccounts SubAccountMode = "aggregate"
+
+	// IgnoreSubAccounts ignores all operations on
+	// sub-accounts when computing balances.
+	IgnoreSubAccounts SubAccountMode = "ignore"
+)
+
+// MissingOpsSearchStrategy determines which windows of blocks
+// are searched for the block missing operations.
+type MissingOpsSearchStrategy string
+
+const (
+	// FixedWindowSearch searches the window preceding the
+	// reconciliation failure (or starting at the last block
+	// where the account was reconciled) and then consecutive
+	// windows of the same size back toward genesis.
+	FixedWindowSearch MissingOpsSearchStrategy = "fixed_window"
+
+	// ExponentialWindowSearch searches the same first window as
+	// FixedWindowSearch and then doubles the size of each
+	// preceding window (reaching genesis in fewer searches).
+	ExponentialWindowSearch MissingOpsSearchStrategy = "exponential"
+
+	// FirstSeenSearch searches a single window from the block
+	// where the account was first seen (the earliest balance in
+	// storage) to the reconciliation failure.
+	FirstSeenSearch MissingOpsSearchStrategy = "first_seen"
+)
+
+// ReconciliationPriorityStrategy determines which
+// accounts are prioritized for reconciliation.
+type ReconciliationPriorityStrategy string
+
+const (
+	// RecentActivityPriority prioritizes the accounts
+	// whose balances changed most recently.
+	RecentActivityPriority ReconciliationPriorityStrategy = "recent_activity"
+
+	// LargestBalancePriority prioritizes the accounts
+	// with the largest computed balances (in whole units
+	// of their currency).
+	LargestBalancePriority ReconciliationPriorityStrategy = "largest_balance"
+)
+
+// ReconciliationPriorityConfiguration configures reconciling
+// some accounts more often than the inactive reconciler (which
+// checks all accounts round-robin) would. Only accounts whose
+// balance changed since check:data started are prioritized.
+type ReconciliationPriorityConfiguration struct {
+	// Strategy is "recent_activity" or "largest_balance".
+	Strategy ReconciliationPriorityStrategy `json:"strategy"`
+
+	// Accounts is the number of prioritized
+	// accounts queued for reconciliation at once.
+	Accounts int `json:"accounts,omitempty"`
+
+	// Frequency is the number of seconds between queueing
+	// prioritized accounts. An account is not prioritized
+	// again until inactive_reconciliation_frequency blocks
+	// have been added.
+	Frequency uint64 `json:"frequency,omitempty"`
+}
+
+// ReverificationConfiguration configures checking a failed
+// reconciliation again before it is reported as a failure.
+type ReverificationConfiguration struct {
+	// Attempts is the number of times the live balance is
+	// fetched again and compared with the computed balance.
+	Attempts int `json:"attempts,omitempty"`
+
+	// WaitBlocks is the number of blocks that must be added
+	// after the block of the failed reconciliation before
+	// each attempt. If 0, attempts are made immediately.
+	WaitBlocks int64 `json:"wait_blocks,omitempty"`
+}
+
+// BalanceThreshold is the minimum computed balance of an
+// account in a currency for it to be reconciled.
+type BalanceThreshold struct {
+	Currency *types.Currency `json:"currency"`
+
+	// Minimum is the minimum balance (in atomic units).
+	Minimum string `json:"minimum"`
+}
+
+// BalanceExemptionConfiguration is a balance exemption (like those
+// returned in /network/options) that can also match an account. An
+// exemption matches all accounts and currencies that are not
+// restricted by its populated fields.
+type BalanceExemptionConfiguration struct {
+	// Account restricts the exemption to a single account.
+	Account *types.AccountIdentifier `json:"account_identifier,omitempty"`
+
+	// SubAccountAddress restricts the exemption to
+	// accounts with a sub-account of this address.
+	SubAccountAddress *string `json:"sub_account_address,omitempty"`
+
+	// Currency restricts the exemption to a single currency.
+	Currency *types.Currency `json:"currency,omitempty"`
+
+	// ExemptionType is "dynamic" (any difference is allowed),
+	// "greater_or_equal" (the live balance may only be greater
+	// than or equal to the computed balance), or "less_or_equal"
+	// (the live balance may only be less than or equal to the
+	// computed balance).
+	ExemptionType types.ExemptionType `json:"exemption_type"`
+}
+
+// AuditConfiguration configures reconciling every known
+// account at fixed block heights (like epoch boundaries).
+type AuditConfiguration struct {
+	// Heights are the block indexes to audit.
+	Heights []int64 `json:"heights,omitempty"`
+
+	// Interval audits every block whose index
+	// is a multiple of it (if not 0).
+	Interval int64 `json:"interval,omitempty"`
+
+	// Directory is where the report of each
+	// audit is written (as <index>.json).
+	Directory string `json:"directory"`
+}
+
+// ReferenceBalanceType is the kind of API
+// that reference balances are fetched from.
+type ReferenceBalanceType string
+
+const (
+	// RosettaReference fetches reference balances from
+	// /account/balance of another Rosetta implementation.
+	RosettaReference ReferenceBalanceType = "rosetta"
+
+	// RESTReference fetches reference balances with a GET
+	// request to a URL rendered from a template.
+	RESTReference ReferenceBalanceType = "rest"
+)
+
+// ReferenceBalanceConfiguration configures an API that balances
+// are fetched from when a reconciliation fails, so the computed
+// balance, the live balance, and the reference balance can be
+// compared.
+type ReferenceBalanceConfiguration struct {
+	// Type is "rosetta" or "rest".
+	Type ReferenceBalanceType `json:"type"`
+
+	// URL is the URL of the Rosetta implementation or, for
+	// "rest", a Go template of the URL that is rendered with
+	// .Address, .SubAccount, .Symbol, .Decimals, .Index, and .Hash.
+	URL string `json:"url"`
+
+	// Network is the network of the Rosetta implementation
+	// (if it differs from the network being checked).
+	Network *types.NetworkIdentifier `json:"network,omitempty"`
+
+	// BalanceField is the dot-separated path of the balance
+	// (in atomic units) in "rest" responses (like "data.balance").
+	BalanceField string `json:"balance_field,omitempty"`
+}
+
+// CurrencyReconciliationConfiguration overrides how accounts
+// in a single currency are reconciled.
+type CurrencyReconciliationConfiguration struct {
+	Currency *types.Currency `json:"currency"`
+
+	// Disabled determines if accounts in
+	// the currency should not be reconciled.
+	Disabled bool `json:"disabled,omitempty"`
+
+	// Concurrency is the maximum number of live balances in the
+	// currency that are fetched at once (in addition to the
+	// overall reconciliation concurrency). If 0, there is no
+	// limit specific to the currency.
+	Concurrency int64 `json:"concurrency,omitempty"`
+
+	// Tolerance is the largest difference (in atomic units) between
+	// a computed and live balance that is counted as an exempt
+	// reconciliation instead of a failure. If not populated, any
+	// difference fails reconciliation.
+	Tolerance string `json:"tolerance,omitempty"`
 }
 
 // DataConfiguration contains all configurations to run check:data.
@@ -239,6 +991,11 @@ type DataConfiguration struct {
 	InactiveReconciliationFrequency uint64 `json:"inactive_reconciliation_frequency"`
 
 	// LogBlocks is a boolean indicating whether to log processed blocks.
+	//
+	// LogBlocks, LogTransactions, LogBalanceChanges, and LogReconciliations
+	// determine which events are written to log files. Whether these events
+	// are also printed to the console is determined by the --verbose and
+	// --quiet flags.
 	LogBlocks bool `json:"log_blocks"`
 
 	// LogTransactions is a boolean indicating whether to log processed transactions.
@@ -255,12 +1012,55 @@ type DataConfiguration struct {
 	// reconciliation errors during development.
 	IgnoreReconciliationError bool `json:"ignore_reconciliation_error"`
 
+	// ReconciliationFailureLimit is the number of distinct accounts
+	// (and currencies) that must fail reconciliation before block
+	// processing halts. This allows a single run to surface several
+	// distinct problems. The check still fails if any reconciliation
+	// fails (each failure is included in the consolidated failure
+	// report, and the first is searched for missing operations if
+	// processing did not halt). If not populated, processing halts on
+	// the first failure.
+	ReconciliationFailureLimit int `json:"reconciliation_failure_limit,omitempty"`
+
+	// ContinueOnError records reconciliation failures (deduplicated by
+	// account, currency, and reconciliation type) and blocks that fail
+	// assertion (deduplicated by assertion error) instead of halting on
+	// them and prints one consolidated failure report when check:data
+	// ends. The transactions of blocks that fail assertion are not
+	// applied to balances (so accounts they change may also fail
+	// reconciliation). Errors that cannot be skipped (like a malformed
+	// block identifier or other transaction) still halt processing but
+	// are included in the report. The check fails if any failure was
+	// recorded.
+	ContinueOnError bool `json:"continue_on_error,omitempty"`
+
+	// MinimumReconciliationCoverage is the proportion of accounts
+	// seen [0.0, 1.0] that must be reconciled for check:data to
+	// succeed. The coverage is included in the final verdict as
+	// its own test. If not populated, coverage is only reported.
+	MinimumReconciliationCoverage float64 `json:"minimum_reconciliation_coverage,omitempty"`
+
+	// FollowTip runs check:data as a continuous monitor. It syncs the
+	// chain tip indefinitely (rolling back storage on reorgs up to
+	// max_reorg_depth), keeps reconciling, and logs reconciliation
+	// errors instead of halting on them. End conditions cannot be
+	// used with FollowTip.
+	FollowTip bool `json:"follow_tip,omitempty"`
+
 	// ExemptAccounts is a path relative to the configuration file
 	// to a file listing all accounts to exempt from balance
 	// tracking and reconciliation. Look at the examples directory for an example of
 	// how to structure this file.
 	ExemptAccounts string `json:"exempt_accounts"`
 
+	// NegativeBalanceAccounts is a path relative to the configuration file
+	// to a file listing accounts (in the same format as exempt_accounts)
+	// whose computed balances are allowed to go negative (like protocol
+	// accounts that mint or burn funds). Unlike exempt accounts, these
+	// accounts are still tracked and actively reconciled (but they are
+	// not checked by inactive reconciliation).
+	NegativeBalanceAccounts string `json:"negative_balance_accounts,omitempty"`
+
 	// BootstrapBalances is a path relative to the configuration file to a file used
 	// to bootstrap balances before starting syncing. If this value is populated after
 	// beginning syncing, it will be ignored.
@@ -276,6 +1076,90 @@ type DataConfiguration struct {
 	// at the examples directory for an example of how to structure this file.
 	InterestingAccounts string `json:"interesting_accounts"`
 
+	// InterestingAccountsReloadFrequency is the frequency (in seconds)
+	// at which the interesting accounts file is checked for changes.
+	// Accounts newly listed in the file are checked on each block
+	// from then on (accounts removed from the file are still checked
+	// until restart). Accounts can also be added with the control API.
+	// If 0, the file is only loaded on startup.
+	InterestingAccountsReloadFrequency uint64 `json:"interesting_accounts_reload_frequency,omitempty"`
+
+	// ReconciliationBalanceThresholds restricts active and inactive
+	// reconciliation to accounts with a computed balance of at least
+	// the minimum of their currency (checked each time the balance
+	// changes). This focuses reconciliation on economically significant
+	// accounts on networks with many dust accounts. Accounts in a
+	// currency without a threshold are always reconciled.
+	ReconciliationBalanceThresholds []*BalanceThreshold `json:"reconciliation_balance_thresholds,omitempty"`
+
+	// CurrencyReconciliation overrides whether accounts in a
+	// currency are reconciled, how many are reconciled at once,
+	// and which balance differences are tolerated. This allows
+	// strict checks on a native asset while being lenient with
+	// less reliable token currencies.
+	CurrencyReconciliation []*CurrencyReconciliationConfiguration `json:"currency_reconciliation,omitempty"`
+
+	// BalanceExemptions are added to the balance exemptions returned
+	// by the node. This allows interest-bearing or rebasing assets to
+	// be checked in the expected direction instead of being exempted
+	// entirely. Exemptions without an account reset computed balances
+	// to live balances (like exemptions returned by the node), which
+	// requires the initial balance fetch. Exemptions of an account only
+	// allow its reconciliations to differ in the exempted direction.
+	BalanceExemptions []*BalanceExemptionConfiguration `json:"balance_exemptions,omitempty"`
+
+	// ActiveReconciliationSampleRate is the proportion of balance
+	// changes (0.0, 1.0] that are actively reconciled. Changes are
+	// sampled by hashing them with ActiveReconciliationSampleSeed, so
+	// runs with the same seed reconcile the same changes. An account
+	// is only inactively reconciled once one of its changes is
+	// sampled. If not populated, all changes are reconciled.
+	ActiveReconciliationSampleRate float64 `json:"active_reconciliation_sample_rate,omitempty"`
+
+	// ReconciliationReverification fetches the live balance again (and
+	// compares it with the computed balance) before a reconciliation
+	// is reported as a failure. This filters out transient node
+	// inconsistencies near the tip. A reconciliation that passes on
+	// re-verification is counted as successful. If not populated,
+	// failed reconciliations are not verified again.
+	ReconciliationReverification *ReverificationConfiguration `json:"reconciliation_reverification,omitempty"`
+
+	// ReconciliationPriority queues the highest priority accounts
+	// for reconciliation at the current block (like interesting
+	// accounts) so that limited reconciliation throughput is spent
+	// where problems are most likely to matter. The inactive
+	// reconciler continues to check all accounts round-robin. If
+	// not populated, no accounts are prioritized.
+	ReconciliationPriority *ReconciliationPriorityConfiguration `json:"reconciliation_priority,omitempty"`
+
+	// ActiveReconciliationSampleSeed is the seed used
+	// to sample balance changes for active reconciliation.
+	ActiveReconciliationSampleSeed int64 `json:"active_reconciliation_sample_seed,omitempty"`
+
+	// ExemptOperationTypes are operation types that are ignored when
+	// computing balances (like informational operations that carry
+	// an amount but don't move funds). Successful operations of these
+	// types are still counted in stats.
+	ExemptOperationTypes []string `json:"exempt_operation_types,omitempty"`
+
+	// SubAccounts is "separate" (the default), "aggregate", or "ignore".
+	// Aggregating is useful when the node only reports balances of
+	// some sub-accounts (like staked balances) as part of a total.
+	// When aggregating, the sub-accounts of a parent are only known
+	// once they are seen in a block, so check:data should start
+	// before any sub-account activity (like at genesis).
+	SubAccounts SubAccountMode `json:"sub_accounts,omitempty"`
+
+	// BalanceAdjustmentURL is called with the network and block identifier
+	// of each block and responds with the balance adjustments of the block
+	// that are not represented by operations (like rewards credited or
+	// stake slashed by the protocol). Adjustments are applied to computed
+	// balances like any other balance change, so these accounts can be
+	// reconciled instead of exempted. The response must be the same each
+	// time a block is requested (adjustments are reverted on reorgs):
+	//	{"adjustments": [{"account_identifier": {...}, "amount": {...}}]}
+	BalanceAdjustmentURL string `json:"balance_adjustment_url,omitempty"`
+
 	// ReconciliationDisabled is a boolean that indicates reconciliation should not
 	// be attempted. When first testing an implementation, it can be useful to disable
 	// some of the more advanced checks to confirm syncing is working as expected.
@@ -292,12 +1176,117 @@ type DataConfiguration struct {
 	// is disabled.
 	InactiveDiscrepancySearchDisabled bool `json:"inactive_discrepancy_search_disabled"`
 
+	// ActiveDiscrepancySearchDisabled is a boolean indicating if a search
+	// should be performed to find the block missing operations when an
+	// active reconciliation fails. The search starts at the last block
+	// where the account was reconciled (if known). Note, a search will
+	// never be performed if historical balance lookup is disabled.
+	ActiveDiscrepancySearchDisabled bool `json:"active_discrepancy_search_disabled,omitempty"`
+
+	// MissingOpsSearchConcurrency is the number of lookback windows
+	// synced concurrently (each into its own temporary store) when
+	// searching for the block missing operations. If several windows
+	// find a failing block, the one closest to the reconciliation
+	// failure is reported. When 0 or 1, windows are searched one at
+	// a time.
+	MissingOpsSearchConcurrency int `json:"missing_ops_search_concurrency,omitempty"`
+
+	// MissingOpsSearchStrategy is the strategy used to choose the
+	// windows searched for the block missing operations (defaults
+	// to "fixed_window").
+	MissingOpsSearchStrategy MissingOpsSearchStrategy `json:"missing_ops_search_strategy,omitempty"`
+
 	// BalanceTrackingDisabled is a boolean that indicates balances calculation
 	// should not be attempted. When first testing an implemenation, it can be
 	// useful to just try to fetch all blocks before checking for balance
 	// consistency.
 	BalanceTrackingDisabled bool `json:"balance_tracking_disabled"`
 
+	// SeparateBalanceStorage is a boolean that indicates balances
+	// should be stored in a separate database from blocks (in the
+	// "balances" subdirectory of the check:data storage path). This
+	// allows block data to be pruned, compacted, or discarded without
+	// rewriting balance state. This cannot be changed after syncing
+	// has started with an existing data directory. If check:data exits
+	// between the commits of the two databases, the data directory
+	// cannot be opened again (restore a snapshot or checkpoint).
+	SeparateBalanceStorage bool `json:"separate_balance_storage,omitempty"`
+
+	// BlockCommitBatchSize is the number of consecutive block commits
+	// (each containing the block, its transactions, balance changes,
+	// and counters) applied to storage in a single write. Batches are
+	// written at least once every BlockCommitFlushIntervalMs and
+	// whenever the reconciler reads from storage. Large batch sizes
+	// can exceed the transaction size limit of Badger. If not
+	// populated, each block is written on its own.
+	//
+	// The head block is persisted with each batch, so batching is
+	// crash-safe: if check:data exits before a batch is written, none
+	// of the blocks in the batch are persisted and syncing resumes
+	// from the last persisted head block (re-fetching at most one
+	// batch of blocks).
+	BlockCommitBatchSize int `json:"block_commit_batch_size,omitempty"`
+
+	// BlockCommitFlushIntervalMs is the maximum number of milliseconds
+	// a committed block waits in an open batch before the batch is
+	// written (only used when BlockCommitBatchSize > 1). Longer
+	// intervals write less often on fast syncs but lose more progress
+	// on a crash. If not populated, 1000 is used.
+	BlockCommitFlushIntervalMs int64 `json:"block_commit_flush_interval_ms,omitempty"`
+
+	// MetadataDedupeThreshold is the minimum size (in bytes, when
+	// encoded as JSON) of transaction and operation metadata that is
+	// stored once per distinct value instead of in every transaction.
+	// This substantially reduces disk usage on chains that repeat
+	// large metadata objects. If not populated, metadata is not
+	// deduplicated.
+	MetadataDedupeThreshold int `json:"metadata_dedupe_threshold,omitempty"`
+
+	// AccountFilter configures a bloom filter of all seen accounts
+	// that is used to skip storage lookups when deciding if an
+	// account is new. If not populated, every lookup reads storage.
+	AccountFilter *AccountFilterConfiguration `json:"account_filter,omitempty"`
+
+	// BalanceCacheSize is the maximum number of computed balances
+	// (the balance of an account at some block) cached in memory
+	// for reconciliation. Cached balances are invalidated when
+	// blocks are orphaned. If 0, balances are always read from
+	// storage.
+	BalanceCacheSize int `json:"balance_cache_size,omitempty"`
+
+	// SkipBlocks are blocks (identified by index or hash) with
+	// known malformed data that will never be fixed. The transactions
+	// of these blocks are removed from /block responses, so they are
+	// not asserted or applied to balances or coins (the blocks are
+	// still synced and stored without their transactions), and the
+	// blocks skipped are listed in the check:data results.
+	SkipBlocks []*types.PartialBlockIdentifier `json:"skip_blocks,omitempty"`
+
+	// OmittedBlocksDisabled indicates the network never omits a block
+	// index (/block never returns an empty response). If true, any
+	// synced block whose parent is not the previous index fails
+	// check:data. Otherwise, omitted indexes are allowed but a block
+	// whose parent is an index that was reported as omitted fails
+	// check:data (rather than being orphaned over and over).
+	OmittedBlocksDisabled bool `json:"omitted_blocks_disabled,omitempty"`
+
+	// OtherTransactionConcurrency is the maximum number of concurrent
+	// /block/transaction requests used to fetch the other_transactions
+	// returned by /block. Each fetched transaction is validated and
+	// the transactions fetched to sync each block are counted
+	// separately in check:data stats.
+	OtherTransactionConcurrency int `json:"other_transaction_concurrency,omitempty"`
+
+	// BackwardSync validates blocks backward from the current tip
+	// before syncing forward, to get fast feedback on recent chain
+	// behavior before committing to a full-history sync. Each
+	// block is linked to its parent by hash and (if historical
+	// balance lookup is enabled and reconciliation is not disabled)
+	// the balance changes in each block are compared with the
+	// difference between the live balances at the block and its
+	// parent. Nothing is stored during the backward sync.
+	BackwardSync *BackwardSyncConfiguration `json:"backward_sync,omitempty"`
+
 	// CoinTrackingDisabled is a boolean that indicates coin (or UTXO) tracking
 	// should not be attempted. When first testing an implemenation, it can be
 	// useful to just try to fetch all blocks before checking for coin
@@ -309,6 +1298,12 @@ type DataConfiguration struct {
 	// If no blocks have ever been synced, syncing will start from genesis.
 	StartIndex *int64 `json:"start_index,omitempty"`
 
+	// StartBlockHash is the hash of the block to start syncing from. The
+	// index of the block is looked up with /block before syncing, so
+	// a check can be anchored to a known block even if implementations
+	// disagree about its index. This cannot be populated with StartIndex.
+	StartBlockHash string `json:"start_block_hash,omitempty"`
+
 	// EndCondition contains the conditions for the syncer to stop.
 	EndConditions *DataEndConditions `json:"end_conditions,omitempty"`
 
@@ -317,6 +1312,12 @@ type DataConfiguration struct {
 	// of parsing logs to populate some sort of status dashboard.
 	StatusPort uint `json:"status_port,omitempty"`
 
+	// ControlPort enables an HTTP API (on localhost) that can pause
+	// and resume syncing, adjust reconciliation concurrency, or start
+	// an inactive reconciliation sweep while check:data is running.
+	// If 0, the control API is not started.
+	ControlPort uint `json:"control_port,omitempty"`
+
 	// ResultsOutputFile is the absolute filepath of where to save
 	// the results of a check:data run.
 	ResultsOutputFile string `json:"results_output_file"`
@@ -345,6 +1346,247 @@ type DataConfiguration struct {
 	// to keep in the active reconciliation backlog before skipping
 	// reconciliation on new changes.
 	ReconcilerActiveBacklog *int `json:"reconciler_active_backlog,omitempty"`
+
+	// GenesisAssertions are checked against the genesis block
+	// before syncing begins.
+	GenesisAssertions *GenesisAssertions `json:"genesis_assertions,omitempty"`
+
+	// NetworkStatusAssertions are periodically checked against the
+	// /network/status response while syncing.
+	NetworkStatusAssertions *NetworkStatusAssertions `json:"network_status_assertions,omitempty"`
+
+	// HTMLReportFile is the absolute filepath of where to save
+	// a self-contained HTML report of a check:data run (including
+	// charts of sync and reconciliation progress over time).
+	HTMLReportFile string `json:"html_report_file,omitempty"`
+
+	// ReconciliationHistoryEnabled determines if every reconciliation
+	// attempt (account, currency, block, computed and live balance,
+	// and outcome) should be persisted so that it can be exported
+	// with utils:reconciliation-history (by account) or
+	// utils:reconciliation-audit (by block range).
+	ReconciliationHistoryEnabled bool `json:"reconciliation_history_enabled,omitempty"`
+
+	// LogRotation configures the rotation and compression of log
+	// files when LogBlocks, LogTransactions, LogBalanceChanges, or
+	// LogReconciliations are enabled.
+	LogRotation *LogRotationConfiguration `json:"log_rotation,omitempty"`
+
+	// Tracing configures the export of OpenTelemetry spans
+	// so that slow stages of a check:data run can be identified.
+	// If not populated, no spans are recorded.
+	Tracing *TracingConfiguration `json:"tracing,omitempty"`
+
+	// FailureArtifactsDirectory is the directory where a bundle of
+	// artifacts is written when check:data halts on a reconciliation
+	// failure. Each bundle is written to its own subdirectory and
+	// contains the block where reconciliation failed, the account's
+	// balance history from storage, the live balance response, and
+	// the counters of the run. If not populated, no bundle is written.
+	FailureArtifactsDirectory string `json:"failure_artifacts_directory,omitempty"`
+
+	// MissingOpsArtifactFile is the absolute filepath of where to save
+	// a description of the block found by the missing-ops search
+	// (the block, the operations in it affecting the account, and the
+	// account's expected and computed balance changes in the block).
+	// If not populated, the block is only printed.
+	MissingOpsArtifactFile string `json:"missing_ops_artifact_file,omitempty"`
+
+	// Audit pauses syncing at configured block heights and reconciles
+	// every known account at that height (comparing the computed and
+	// live balances), writing a report of each audit. Auditing a height
+	// other than the tip requires historical balance lookup. If not
+	// populated, no heights are audited.
+	Audit *AuditConfiguration `json:"audit,omitempty"`
+
+	// ReferenceBalance is queried for the balance of an account
+	// each time its reconciliation fails, and the reference balance
+	// is included in the failure (showing whether the computed or
+	// the live balance disagrees with the reference). If not
+	// populated, no reference is queried.
+	ReferenceBalance *ReferenceBalanceConfiguration `json:"reference_balance,omitempty"`
+
+	// LogFilter restricts LogBalanceChanges and LogReconciliations
+	// to accounts and/or currencies of interest. Reconciliation
+	// failures are always printed to the console. If not populated,
+	// all accounts and currencies are logged.
+	LogFilter *LogFilterConfiguration `json:"log_filter,omitempty"`
+
+	// BalanceChangesCSVFile is the path of a CSV file where every
+	// balance-changing operation applied to balance storage is
+	// written (with its block, account, currency, delta, and
+	// transaction hash). Rows are appended to an existing file.
+	// If not populated, balance changes are not exported.
+	BalanceChangesCSVFile string `json:"balance_changes_csv_file,omitempty"`
+
+	// ParquetExport configures the export of synced blocks and
+	// operations to Parquet files. Blocks are only exported once
+	// they are deeper than max_reorg_depth. If not populated,
+	// nothing is exported.
+	ParquetExport *ParquetExportConfiguration `json:"parquet_export,omitempty"`
+
+	// CounterSnapshots configures periodic snapshots of the
+	// counters of the run. If not populated, no snapshots are taken.
+	CounterSnapshots *CounterSnapshotConfiguration `json:"counter_snapshots,omitempty"`
+
+	// DiskUsage bounds the size of the data directory. If not
+	// populated, the data directory can grow without limit.
+	DiskUsage *DiskUsageConfiguration `json:"disk_usage,omitempty"`
+
+	// Retention expires old reconciliation history, rotated log
+	// files, and counter snapshots. If not populated, they are
+	// retained for as long as the data directory exists.
+	Retention *RetentionConfiguration `json:"retention,omitempty"`
+}
+
+// NotifierConfiguration configures a webhook that is notified
+// when a check starts, succeeds, or fails.
+type NotifierConfiguration struct {
+	// Type is the service the webhook belongs to.
+	Type NotifierType `json:"type"`
+
+	// WebhookURL is the URL notifications are posted to.
+	WebhookURL string `json:"webhook_url"`
+
+	// Channel overrides the default channel of the webhook
+	// (only supported by Slack).
+	Channel string `json:"channel,omitempty"`
+
+	// Template is a text/template used to render each message. The
+	// template is executed with the command, event, network, error,
+	// and stats of the run. If not populated, a default template is used.
+	Template string `json:"template,omitempty"`
+}
+
+// EmailConfiguration configures an email summary that is
+// sent when a check:data or check:construction run completes.
+type EmailConfiguration struct {
+	// SMTPHost is the hostname of the SMTP server. If the server
+	// supports STARTTLS, it is used.
+	SMTPHost string `json:"smtp_host"`
+
+	// SMTPPort is the port of the SMTP server.
+	SMTPPort uint `json:"smtp_port,omitempty"`
+
+	// Username and Password are used to authenticate with the
+	// SMTP server. If Username is not populated, no authentication
+	// is attempted.
+	Username string `json:"username,omitempty"`
+	Password string `json:"password,omitempty"`
+
+	// From is the address the summary is sent from.
+	From string `json:"from"`
+
+	// To are the addresses the summary is sent to.
+	To []string `json:"to"`
+
+	// ResultsURL is a link to the results of the run included
+	// in the summary (like the URL of a CI artifact).
+	ResultsURL string `json:"results_url,omitempty"`
+
+	// AttachResults determines if the results output file
+	// (if any) should be attached to the summary.
+	AttachResults bool `json:"attach_results,omitempty"`
+}
+
+// SentryConfiguration configures the reporting of panics and
+// fatal errors (with stack traces and run metadata) to Sentry so
+// that they are not lost when a long-running check is restarted.
+type SentryConfiguration struct {
+	// DSN is the Sentry DSN of the project events are
+	// reported to (https://<key>@<host>/<project id>).
+	DSN string `json:"dsn"`
+
+	// Environment is attached to every event
+	// (ex: "staging" or "production").
+	Environment string `json:"environment,omitempty"`
+}
+
+// LogBackendConfiguration configures where logged events
+// are sent. This is useful when running the rosetta-cli as a
+// service, where events should end up in the system log.
+type LogBackendConfiguration struct {
+	// Type is the backend events are sent to.
+	Type LogBackendType `json:"type"`
+
+	// Tag identifies the rosetta-cli in the system log. If not
+	// populated, "rosetta-cli" is used.
+	Tag string `json:"tag,omitempty"`
+}
+
+// BadgerConfiguration overrides the settings of the Badger
+// database used by check:data and check:construction. Any
+// setting that is not populated keeps its default value (the
+// performance settings are the default if memory_limit_disabled
+// is true).
+type BadgerConfiguration struct {
+	// MaxTableSize is the max size of each table in bytes. Each MB
+	// increases RAM usage by roughly 10 MB.
+	MaxTableSize *int64 `json:"max_table_size,omitempty"`
+
+	// ValueLogFileSize is the max size of each value
+	// log file in bytes.
+	ValueLogFileSize *int64 `json:"value_log_file_size,omitempty"`
+
+	// Compression is the compression applied by Badger to
+	// each table (none, snappy, or zstd). This is applied
+	// in addition to the compression disabled with
+	// compression_disabled.
+	Compression BadgerCompression `json:"compression,omitempty"`
+
+	// TableLoadingMode determines how tables are
+	// loaded (file_io, memory_map, or load_to_ram).
+	TableLoadingMode BadgerLoadingMode `json:"table_loading_mode,omitempty"`
+
+	// ValueLogLoadingMode determines how value logs
+	// are loaded (file_io or memory_map).
+	ValueLogLoadingMode BadgerLoadingMode `json:"value_log_loading_mode,omitempty"`
+
+	// IndexCacheSize is the memory (in bytes) used to cache
+	// table indexes and bloom filters. If 0, all indexes
+	// are kept in memory.
+	IndexCacheSize *int64 `json:"index_cache_size,omitempty"`
+
+	// BlockCacheSize is the memory (in bytes)
+	// used to cache table blocks.
+	BlockCacheSize *int64 `json:"block_cache_size,omitempty"`
+
+	// KeepL0InMemory determines if level 0
+	// tables are kept in memory.
+	KeepL0InMemory *bool `json:"keep_l0_in_memory,omitempty"`
+
+	// LoadBloomsOnOpen determines if all bloom filters
+	// are loaded when the database is opened.
+	LoadBloomsOnOpen *bool `json:"load_blooms_on_open,omitempty"`
+
+	// ValueLogGCDisabled disables the value log garbage collection
+	// that otherwise runs every minute (rewriting value logs with
+	// at least 10% stale data). This avoids the write amplification
+	// of garbage collection on fast disks at the cost of disk usage.
+	// Stale data can still be reclaimed with db:compact.
+	ValueLogGCDisabled bool `json:"value_log_gc_disabled,omitempty"`
+
+	// Encryption encrypts all data written by Badger. The
+	// data directory cannot be opened without the same key.
+	Encryption *BadgerEncryptionConfiguration `json:"encryption,omitempty"`
+}
+
+// BadgerEncryptionConfiguration determines where the Badger
+// encryption key is loaded from. The key must be hex-encoded
+// and 16, 24, or 32 bytes long (AES-128, AES-192, or AES-256).
+// Exactly one of KeyEnv and KeyFile must be populated.
+type BadgerEncryptionConfiguration struct {
+	// KeyEnv is the name of the environment
+	// variable that contains the key.
+	KeyEnv string `json:"key_env,omitempty"`
+
+	// KeyFile is the path of the file that contains the key.
+	KeyFile string `json:"key_file,omitempty"`
+
+	// KeyRotationDuration is the number of seconds after which
+	// Badger rotates the data keys encrypted with the key. If
+	// not populated, Badger's default (10 days) is used.
+	KeyRotationDuration uint64 `json:"key_rotation_duration,omitempty"`
 }
 
 // Configuration contains all configuration settings for running
@@ -358,6 +1600,46 @@ type Configuration struct {
 	// OnlineURL is the URL of a Rosetta API implementation in "online mode".
 	OnlineURL string `json:"online_url"`
 
+	// OnlineURLs are the URLs of additional Rosetta API implementations
+	// in "online mode" serving the same network as OnlineURL. When
+	// populated, requests that cannot reach a server (or receive a
+	// 502, 503, or 504) are retried on the next server so long checks
+	// survive node restarts and rolling deploys.
+	OnlineURLs []string `json:"online_urls,omitempty"`
+
+	// OnlineURLStrategy determines how requests are spread across
+	// OnlineURL and OnlineURLs ("failover" or "round_robin"). If
+	// not populated, "failover" is used.
+	OnlineURLStrategy OnlineURLStrategy `json:"online_url_strategy,omitempty"`
+
+	// RetryPolicy retries requests to the online server based on
+	// the class of error (rate limits, server errors, and Rosetta
+	// errors that are not retriable) instead of retrying all
+	// errors alike. This cannot be used with ForceRetry.
+	RetryPolicy *RetryPolicyConfiguration `json:"retry_policy,omitempty"`
+
+	// CircuitBreaker pauses all requests to the online server (with a
+	// clear status message) when it appears to be down instead of
+	// retrying each request independently. If not populated, each
+	// request is retried until max_retries or retry_elapsed_time.
+	CircuitBreaker *CircuitBreakerConfiguration `json:"circuit_breaker,omitempty"`
+
+	// Compression determines if requests to and responses from the
+	// online server are compressed with gzip, which reduces bandwidth
+	// significantly for chains with large blocks. If not populated,
+	// only responses are compressed (if the server supports it).
+	Compression *CompressionConfiguration `json:"compression,omitempty"`
+
+	// Proxy sends requests to the online server through an HTTP
+	// or SOCKS5 proxy (like a corporate proxy or SSH tunnel). If
+	// not populated, the proxy in the environment is used.
+	Proxy *ProxyConfiguration `json:"proxy,omitempty"`
+
+	// Fixtures records all responses from the online server into a
+	// directory (or serves all requests from a directory recorded
+	// earlier) so checks can be re-run deterministically.
+	Fixtures *FixturesConfiguration `json:"fixtures,omitempty"`
+
 	// DataDirectory is a folder used to store logs and any data used to perform validation.
 	// The path can be absolute, or it can be relative to where rosetta-cli
 	// binary is being executed.
@@ -366,6 +1648,12 @@ type Configuration struct {
 	// HTTPTimeout is the timeout for a HTTP request in seconds.
 	HTTPTimeout uint64 `json:"http_timeout"`
 
+	// EndpointTimeouts overrides HTTPTimeout (in seconds) for requests
+	// to specific endpoints of the online server, keyed by path (like
+	// "/account/balance"). This is useful when some endpoints (like
+	// historical balance lookups) are much slower than others.
+	EndpointTimeouts map[string]uint64 `json:"endpoint_timeouts,omitempty"`
+
 	// MaxRetries is the number of times we will retry an HTTP request. If retry_elapsed_time
 	// is also populated, we may stop attempting retries early.
 	MaxRetries uint64 `json:"max_retries"`
@@ -377,6 +1665,11 @@ type Configuration struct {
 	// fetcher will open.
 	MaxOnlineConnections int `json:"max_online_connections"`
 
+	// HTTPTransport tunes keep-alive, connection reuse, and HTTP/2
+	// for connections to the online server (which can reduce
+	// connection churn behind some load balancers).
+	HTTPTransport *HTTPTransportConfiguration `json:"http_transport,omitempty"`
+
 	// ForceRetry overrides the default retry handling to retry
 	// on all non-200 responses.
 	ForceRetry bool `json:"force_retry,omitempty"`
@@ -385,6 +1678,19 @@ type Configuration struct {
 	// Sync concurrency is managed automatically by the `syncer` package.
 	MaxSyncConcurrency int64 `json:"max_sync_concurrency"`
 
+	// SyncMemoryBudgetMB is the maximum size (in megabytes) of fetched
+	// blocks held in memory while syncing. When blocks are large, sync
+	// concurrency is reduced (down to a single fetcher) to stay within
+	// this budget instead of exhausting memory. If not populated, the
+	// budget is 2000 MB.
+	SyncMemoryBudgetMB int64 `json:"sync_memory_budget_mb,omitempty"`
+
+	// AdaptiveSyncConcurrency adjusts the number of concurrent /block
+	// requests (up to MaxSyncConcurrency) to the latency and errors
+	// of the node. If not populated, the number of concurrent requests
+	// is only bounded by MaxSyncConcurrency.
+	AdaptiveSyncConcurrency *AdaptiveConcurrencyConfiguration `json:"adaptive_sync_concurrency,omitempty"`
+
 	// TipDelay dictates how many seconds behind the current time is considered
 	// tip. If we are > TipDelay seconds from the last processed block,
 	// we are considered to be behind tip.
@@ -414,6 +1720,23 @@ type Configuration struct {
 	// but can use 10s of GBs of RAM, even with pruning enabled.
 	MemoryLimitDisabled bool `json:"memory_limit_disabled"`
 
+	// InMemoryStorage configures storage to keep all data in memory
+	// instead of on disk. This dramatically speeds up short checks
+	// (like syncing a few thousand blocks in CI) but all data is lost
+	// when the run exits, so it should not be used for long syncs.
+	InMemoryStorage bool `json:"in_memory_storage,omitempty"`
+
+	// ReadOnlyStorage opens existing storage read-only (without
+	// acquiring the directory lock) so commands that only read
+	// storage (like db:inspect) can run while a check is in progress.
+	// Commands that write to storage refuse to run in this mode.
+	ReadOnlyStorage bool `json:"read_only_storage,omitempty"`
+
+	// Badger overrides the settings of the database used to
+	// store data on disk. This is useful to reduce disk and
+	// memory usage on large chains.
+	Badger *BadgerConfiguration `json:"badger,omitempty"`
+
 	// SeenBlockWorkers is the number of goroutines spawned to store
 	// seen blocks in storage before we attempt to sequence. If not populated,
 	// this value defaults to runtime.NumCPU().
@@ -439,6 +1762,32 @@ type Configuration struct {
 	// then this value must be true.
 	CoinSupported bool `json:"coin_supported"`
 
+	// JUnitOutputFile is the absolute filepath of where to save
+	// a JUnit XML report of the results of a check:data,
+	// check:construction, or check:spec run.
+	JUnitOutputFile string `json:"junit_output_file,omitempty"`
+
+	// GitHubAnnotations determines if failures should be printed
+	// to the console as GitHub Actions workflow commands so that
+	// they are surfaced as annotations on the run.
+	GitHubAnnotations bool `json:"github_annotations,omitempty"`
+
+	// Notifiers are posted to when a check:data or
+	// check:construction run starts, succeeds, or fails.
+	Notifiers []*NotifierConfiguration `json:"notifiers,omitempty"`
+
+	// Email configures an email summary that is sent when
+	// a check:data or check:construction run completes.
+	Email *EmailConfiguration `json:"email,omitempty"`
+
+	// LogBackend configures where logged events are sent. If not
+	// populated, events are written to files and stdout.
+	LogBackend *LogBackendConfiguration `json:"log_backend,omitempty"`
+
+	// Sentry configures the reporting of panics and fatal errors
+	// to Sentry. If not populated, nothing is reported.
+	Sentry *SentryConfiguration `json:"sentry,omitempty"`
+
 	Construction *ConstructionConfiguration `json:"construction"`
 	Data         *DataConfiguration         `json:"data"`
 	Perf         *CheckPerfConfiguration    `json:"perf"`
@@ -4,10 +4,12 @@ go 1.16
 
 require (
 	github.com/coinbase/rosetta-sdk-go v0.7.11-0.20220629212620-136b591fb3f4
+	github.com/dgraph-io/badger/v2 v2.2007.4
 	github.com/fatih/color v1.13.0
 	github.com/golang/protobuf v1.5.2 // indirect
 	github.com/google/go-cmp v0.5.6 // indirect
 	github.com/mattn/go-colorable v0.1.12 // indirect
+	github.com/neilotoole/errgroup v0.1.6
 	github.com/olekukonko/tablewriter v0.0.5
 	github.com/pkg/errors v0.9.1
 	github.com/spf13/cobra v1.4.0
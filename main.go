@@ -23,9 +23,12 @@ import (
 )
 
 func main() {
+	defer cmd.RecoverPanic()
+
 	err := cmd.Execute()
 	if err != nil {
+		cmd.CaptureError(err)
 		color.Red("Command Failed: %s", err.Error())
-		os.Exit(1)
+		os.Exit(cmd.ExitCode(err))
 	}
 }
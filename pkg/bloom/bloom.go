@@ -0,0 +1,176 @@
+// Copyright 2022 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bloom
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"math"
+	"sync"
+)
+
+const (
+	wordSize = 64
+
+	// headerSize is the size of the encoded number
+	// of bits and hash functions of a *Filter.
+	headerSize = 16
+)
+
+// Filter is a bloom filter: a set that may report false
+// positives (but never false negatives). It is safe
+// for concurrent use.
+type Filter struct {
+	lock   sync.RWMutex
+	bits   []uint64
+	m      uint64
+	hashes uint64
+}
+
+// New returns a new *Filter sized to store capacity
+// items with the provided false positive rate.
+func New(capacity uint64, falsePositiveRate float64) *Filter {
+	m, hashes := Parameters(capacity, falsePositiveRate)
+	return &Filter{
+		bits:   make([]uint64, (m+wordSize-1)/wordSize),
+		m:      m,
+		hashes: hashes,
+	}
+}
+
+// Parameters returns the number of bits and hash functions
+// of a *Filter sized to store capacity items with the
+// provided false positive rate.
+func Parameters(capacity uint64, falsePositiveRate float64) (uint64, uint64) {
+	n := math.Max(float64(capacity), 1)
+	m := math.Ceil(-n * math.Log(falsePositiveRate) / (math.Ln2 * math.Ln2))
+	hashes := math.Max(math.Round(m/n*math.Ln2), 1)
+
+	return uint64(m), uint64(hashes)
+}
+
+// locations returns the hash functions of key using
+// double hashing of two 64-bit FNV hashes.
+func (f *Filter) locations(key []byte) []uint64 {
+	a := fnv.New64a()
+	_, _ = a.Write(key)
+	h1 := a.Sum64()
+
+	b := fnv.New64()
+	_, _ = b.Write(key)
+	h2 := b.Sum64() | 1
+
+	locations := make([]uint64, f.hashes)
+	for i := range locations {
+		locations[i] = (h1 + uint64(i)*h2) % f.m
+	}
+
+	return locations
+}
+
+// Add adds key to the *Filter.
+func (f *Filter) Add(key []byte) {
+	locations := f.locations(key)
+
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	for _, l := range locations {
+		f.bits[l/wordSize] |= 1 << (l % wordSize)
+	}
+}
+
+// Test returns false if key was never added to the *Filter
+// (and true if it may have been added).
+func (f *Filter) Test(key []byte) bool {
+	locations := f.locations(key)
+
+	f.lock.RLock()
+	defer f.lock.RUnlock()
+
+	for _, l := range locations {
+		if f.bits[l/wordSize]&(1<<(l%wordSize)) == 0 {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Copy returns a *Filter with the same
+// parameters and keys as the *Filter.
+func (f *Filter) Copy() *Filter {
+	f.lock.RLock()
+	defer f.lock.RUnlock()
+
+	return &Filter{
+		bits:   append([]uint64{}, f.bits...),
+		m:      f.m,
+		hashes: f.hashes,
+	}
+}
+
+// Matches returns a boolean indicating if the *Filter has
+// the same number of bits and hash functions as a *Filter
+// sized with Parameters(capacity, falsePositiveRate).
+func (f *Filter) Matches(capacity uint64, falsePositiveRate float64) bool {
+	m, hashes := Parameters(capacity, falsePositiveRate)
+	return f.m == m && f.hashes == hashes
+}
+
+// MarshalBinary encodes the *Filter.
+func (f *Filter) MarshalBinary() ([]byte, error) {
+	f.lock.RLock()
+	defer f.lock.RUnlock()
+
+	b := make([]byte, headerSize+len(f.bits)*8)
+	binary.BigEndian.PutUint64(b[0:8], f.m)
+	binary.BigEndian.PutUint64(b[8:16], f.hashes)
+	for i, word := range f.bits {
+		binary.BigEndian.PutUint64(b[headerSize+i*8:], word)
+	}
+
+	return b, nil
+}
+
+// UnmarshalBinary decodes a *Filter
+// encoded with MarshalBinary.
+func (f *Filter) UnmarshalBinary(b []byte) error {
+	if len(b) < headerSize {
+		return errors.New("filter is truncated")
+	}
+
+	m := binary.BigEndian.Uint64(b[0:8])
+	hashes := binary.BigEndian.Uint64(b[8:16])
+	words := (m + wordSize - 1) / wordSize
+	if m == 0 || hashes == 0 || uint64(len(b)-headerSize) != words*8 {
+		return fmt.Errorf("filter with %d bits has invalid size %d", m, len(b))
+	}
+
+	bits := make([]uint64, words)
+	for i := range bits {
+		bits[i] = binary.BigEndian.Uint64(b[headerSize+i*8:])
+	}
+
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	f.bits = bits
+	f.m = m
+	f.hashes = hashes
+	return nil
+}
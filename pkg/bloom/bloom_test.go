@@ -0,0 +1,60 @@
+// Copyright 2022 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bloom
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFilter(t *testing.T) {
+	f := New(1000, 0.01)
+	for i := 0; i < 1000; i++ {
+		f.Add([]byte(fmt.Sprintf("added/%d", i)))
+	}
+
+	for i := 0; i < 1000; i++ {
+		assert.True(t, f.Test([]byte(fmt.Sprintf("added/%d", i))))
+	}
+
+	falsePositives := 0
+	for i := 0; i < 10000; i++ {
+		if f.Test([]byte(fmt.Sprintf("missing/%d", i))) {
+			falsePositives++
+		}
+	}
+	assert.Less(t, falsePositives, 300)
+
+	b, err := f.MarshalBinary()
+	assert.NoError(t, err)
+
+	decoded := &Filter{}
+	assert.NoError(t, decoded.UnmarshalBinary(b))
+	assert.True(t, decoded.Matches(1000, 0.01))
+	assert.False(t, decoded.Matches(2000, 0.01))
+	for i := 0; i < 1000; i++ {
+		assert.True(t, decoded.Test([]byte(fmt.Sprintf("added/%d", i))))
+	}
+
+	assert.Error(t, decoded.UnmarshalBinary(b[:len(b)-1]))
+
+	copied := f.Copy()
+	copied.Add([]byte("copied"))
+	assert.True(t, copied.Test([]byte("added/0")))
+	assert.True(t, copied.Test([]byte("copied")))
+	assert.False(t, f.Test([]byte("copied")))
+}
@@ -23,9 +23,14 @@ import (
 var (
 	// Data Errors
 
-	ErrCheckStorageTipFailed = errors.New("unable to check storage tip")
-	ErrDataCheckHalt         = errors.New("data check halted")
-	ErrInitDataTester        = errors.New("unexpected error occurred while trying to initialize data tester")
+	ErrCheckStorageTipFailed  = errors.New("unable to check storage tip")
+	ErrDataCheckHalt          = errors.New("data check halted")
+	ErrInitDataTester         = errors.New("unexpected error occurred while trying to initialize data tester")
+	ErrDuplicateBlockHash     = errors.New("multiple block indexes report the same block hash")
+	ErrGenesisBlockMismatch   = errors.New("genesis block does not match genesis assertions")
+	ErrNetworkStatusAssertion = errors.New("network status assertion failed")
+	ErrUnexpectedBlockGap     = errors.New("block index omitted but omitted blocks are disabled")
+	ErrOmittedParentBlock     = errors.New("block parent was reported as omitted")
 
 	// Construction Configuration Errors
 
@@ -38,12 +43,39 @@ var (
 
 	// Construction check errors
 
-	ErrConstructionCheckHalt = errors.New("construction check halted")
+	ErrConstructionCheckHalt      = errors.New("construction check halted")
+	ErrOfflineEndpointOnline      = errors.New("offline endpoint serves network-dependent responses")
+	ErrOfflineEndpointUnreachable = errors.New("offline endpoint did not return a rosetta error")
+
+	ErrSignatureTypeUnsupported    = errors.New("signature type is not supported by signing key")
+	ErrSignatureVerificationFailed = errors.New("signature verification failed")
 
 	ErrAsserterConfigError = errors.New("asserter configuration validation failed")
 
+	// Check Spec Errors
+
+	ErrCheckSpecFailed = errors.New("check:spec requirements not met")
+
+	// Logger Errors
+
+	ErrLogBackendUnsupported = errors.New("log backend is not supported on this platform")
+
+	// Fixture Errors
+
+	ErrFixtureNotFound = errors.New("no recorded response for request")
+
+	// Circuit Breaker Errors
+
+	ErrRetryBudgetExhausted = errors.New("online server is unavailable and retry budget is exhausted")
+
+	// Retry Policy Errors
+
+	ErrServerErrorRetriesExhausted = errors.New("server error retries exhausted")
+
 	// Bad Command Errors
 
 	ErrBlockNotFound      = errors.New("block not found")
 	ErrNoAvailableNetwork = errors.New("no networks available")
+	ErrVerbosityConflict  = errors.New("--quiet cannot be combined with --verbose")
+	ErrInvalidColorMode   = errors.New("--color must be always, never, or auto")
 )
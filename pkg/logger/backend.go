@@ -0,0 +1,174 @@
+// Copyright 2022 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logger
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path"
+	"strings"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+
+	"github.com/coinbase/rosetta-cli/configuration"
+)
+
+// defaultLogTag identifies the rosetta-cli in the
+// system log if no tag is configured.
+const defaultLogTag = "rosetta-cli"
+
+// severity is the importance of an event
+// sent to a logging backend.
+type severity int
+
+const (
+	severityInfo severity = iota
+	severityWarning
+	severityError
+)
+
+// eventSink receives structured events when a logging
+// backend other than files is configured.
+type eventSink interface {
+	io.Writer
+
+	// Emit sends a single event with structured fields.
+	Emit(severity severity, message string, fields map[string]string) error
+
+	Close() error
+}
+
+// SetBackend sends all events logged by the *Logger to the
+// configured backend instead of files and stdout.
+func (l *Logger) SetBackend(backend *configuration.LogBackendConfiguration) error {
+	tag := backend.Tag
+	if len(tag) == 0 {
+		tag = defaultLogTag
+	}
+
+	var sink eventSink
+	var err error
+	switch backend.Type {
+	case configuration.FileLogBackend:
+		return nil
+	case configuration.SyslogLogBackend:
+		sink, err = newSyslogSink(tag)
+	case configuration.JournaldLogBackend:
+		sink, err = newJournaldSink(tag)
+	default:
+		return fmt.Errorf("log backend type %s is not supported", backend.Type)
+	}
+	if err != nil {
+		return fmt.Errorf("%w: unable to connect to %s", err, backend.Type)
+	}
+
+	l.sink = sink
+	l.zapLogger = zap.New(
+		zapcore.NewCore(
+			zapcore.NewJSONEncoder(zap.NewProductionEncoderConfig()),
+			zapcore.AddSync(sink),
+			zapcore.DebugLevel,
+		),
+		zap.Fields(l.zapFields...),
+	)
+
+	return nil
+}
+
+// Close releases the logging backend (if any).
+func (l *Logger) Close() error {
+	if l.sink == nil {
+		return nil
+	}
+
+	return l.sink.Close()
+}
+
+// console prints a message with the provided printer or, if
+// a logging backend is configured, emits it to the backend. The
+// message is dropped if level is not enabled.
+func (l *Logger) console(
+	level Verbosity,
+	severity severity,
+	message string,
+	printer func(format string, a ...interface{}),
+) {
+	if !Enabled(level) {
+		return
+	}
+
+	if l.sink == nil {
+		printer("%s", message)
+		return
+	}
+
+	if err := l.sink.Emit(severity, strings.TrimSuffix(message, "\n"), nil); err != nil {
+		log.Printf("%s: unable to emit log event\n", err.Error())
+	}
+}
+
+// stream is an open destination for events of
+// a single kind (like blocks or transactions).
+type stream interface {
+	write(line string, fields map[string]string) error
+	close()
+}
+
+type fileStream struct {
+	f *os.File
+}
+
+func (s *fileStream) write(line string, _ map[string]string) error {
+	_, err := s.f.WriteString(line)
+	return err
+}
+
+func (s *fileStream) close() {
+	closeFile(s.f)
+}
+
+type sinkStream struct {
+	sink eventSink
+	name string
+}
+
+func (s *sinkStream) write(line string, fields map[string]string) error {
+	fields["stream"] = s.name
+	return s.sink.Emit(severityInfo, strings.TrimSuffix(line, "\n"), fields)
+}
+
+func (s *sinkStream) close() {}
+
+// newStream opens the stream stored in the provided file
+// or, if a logging backend is configured, a stream that
+// emits to the backend.
+func (l *Logger) newStream(name string) (stream, error) {
+	if l.sink != nil {
+		return &sinkStream{
+			sink: l.sink,
+			name: strings.TrimSuffix(name, path.Ext(name)),
+		}, nil
+	}
+
+	f, err := l.openStream(name)
+	if err != nil {
+		return nil, err
+	}
+
+	return &fileStream{f: f}, nil
+}
@@ -0,0 +1,138 @@
+// Copyright 2022 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build !windows && !plan9
+// +build !windows,!plan9
+
+package logger
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"log/syslog"
+	"net"
+	"sort"
+	"strings"
+)
+
+// journaldSocket is where the systemd journal
+// accepts native protocol datagrams.
+const journaldSocket = "/run/systemd/journal/socket"
+
+type syslogSink struct {
+	w *syslog.Writer
+}
+
+func newSyslogSink(tag string) (eventSink, error) {
+	w, err := syslog.New(syslog.LOG_INFO|syslog.LOG_DAEMON, tag)
+	if err != nil {
+		return nil, err
+	}
+
+	return &syslogSink{w: w}, nil
+}
+
+func (s *syslogSink) Write(p []byte) (int, error) {
+	return s.w.Write(p)
+}
+
+func (s *syslogSink) Emit(severity severity, message string, fields map[string]string) error {
+	keys := make([]string, 0, len(fields))
+	for k := range fields {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var b strings.Builder
+	b.WriteString(message)
+	for _, k := range keys {
+		b.WriteString(fmt.Sprintf(" %s=%q", k, fields[k]))
+	}
+
+	switch severity {
+	case severityError:
+		return s.w.Err(b.String())
+	case severityWarning:
+		return s.w.Warning(b.String())
+	default:
+		return s.w.Info(b.String())
+	}
+}
+
+func (s *syslogSink) Close() error {
+	return s.w.Close()
+}
+
+type journaldSink struct {
+	conn net.Conn
+	tag  string
+}
+
+func newJournaldSink(tag string) (eventSink, error) {
+	conn, err := net.Dial("unixgram", journaldSocket)
+	if err != nil {
+		return nil, err
+	}
+
+	return &journaldSink{conn: conn, tag: tag}, nil
+}
+
+// appendJournaldField encodes a field using the journal native
+// protocol. Values containing a newline are length-prefixed.
+func appendJournaldField(buf *bytes.Buffer, key string, value string) {
+	if !strings.Contains(value, "\n") {
+		buf.WriteString(fmt.Sprintf("%s=%s\n", key, value))
+		return
+	}
+
+	buf.WriteString(key)
+	buf.WriteByte('\n')
+	_ = binary.Write(buf, binary.LittleEndian, uint64(len(value)))
+	buf.WriteString(value)
+	buf.WriteByte('\n')
+}
+
+func (s *journaldSink) Write(p []byte) (int, error) {
+	if err := s.Emit(severityInfo, strings.TrimSuffix(string(p), "\n"), nil); err != nil {
+		return 0, err
+	}
+
+	return len(p), nil
+}
+
+func (s *journaldSink) Emit(severity severity, message string, fields map[string]string) error {
+	priority := syslog.LOG_INFO
+	switch severity {
+	case severityError:
+		priority = syslog.LOG_ERR
+	case severityWarning:
+		priority = syslog.LOG_WARNING
+	}
+
+	var buf bytes.Buffer
+	appendJournaldField(&buf, "MESSAGE", message)
+	appendJournaldField(&buf, "PRIORITY", fmt.Sprintf("%d", priority))
+	appendJournaldField(&buf, "SYSLOG_IDENTIFIER", s.tag)
+	for k, v := range fields {
+		appendJournaldField(&buf, strings.ToUpper(k), v)
+	}
+
+	_, err := s.conn.Write(buf.Bytes())
+	return err
+}
+
+func (s *journaldSink) Close() error {
+	return s.conn.Close()
+}
@@ -0,0 +1,81 @@
+// Copyright 2022 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logger
+
+import (
+	"os"
+
+	cliErrs "github.com/coinbase/rosetta-cli/pkg/errors"
+
+	"github.com/fatih/color"
+)
+
+const (
+	// ColorAlways prints colored output even
+	// when the console is not a terminal.
+	ColorAlways = "always"
+
+	// ColorNever never prints colored output.
+	ColorNever = "never"
+
+	// ColorAuto prints colored output only when
+	// stdout and stderr are terminals, NO_COLOR is
+	// not set, and TERM is not "dumb".
+	ColorAuto = "auto"
+)
+
+// SetColorMode determines if status and error messages are
+// printed with color. All console output is printed with
+// github.com/fatih/color, so this applies to every command.
+func SetColorMode(mode string) error {
+	switch mode {
+	case ColorAlways:
+		color.NoColor = false
+	case ColorNever:
+		color.NoColor = true
+	case ColorAuto:
+		color.NoColor = !colorSupported()
+	default:
+		return cliErrs.ErrInvalidColorMode
+	}
+
+	return nil
+}
+
+// colorSupported returns a boolean indicating if
+// the console supports colored output (see https://no-color.org).
+func colorSupported() bool {
+	if _, ok := os.LookupEnv("NO_COLOR"); ok {
+		return false
+	}
+
+	if os.Getenv("TERM") == "dumb" {
+		return false
+	}
+
+	return isTerminal(os.Stdout) && isTerminal(os.Stderr)
+}
+
+// isTerminal returns a boolean indicating if
+// f is a character device (like a TTY) instead
+// of a file or pipe.
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	if err != nil {
+		return false
+	}
+
+	return info.Mode()&os.ModeCharDevice != 0
+}
@@ -0,0 +1,86 @@
+// Copyright 2022 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logger
+
+import (
+	"github.com/coinbase/rosetta-cli/configuration"
+
+	"github.com/coinbase/rosetta-sdk-go/types"
+)
+
+// logFilter determines which accounts and currencies
+// balance changes and reconciliations are logged for.
+type logFilter struct {
+	// addresses match any sub-account of an address.
+	addresses  map[string]struct{}
+	accounts   map[string]struct{}
+	currencies map[string]struct{}
+}
+
+// SetFilter restricts balance change and reconciliation
+// logging to the accounts and/or currencies in filter.
+func (l *Logger) SetFilter(filter *configuration.LogFilterConfiguration) {
+	if filter == nil {
+		l.filter = nil
+		return
+	}
+
+	f := &logFilter{}
+	if len(filter.Accounts) > 0 {
+		f.addresses = map[string]struct{}{}
+		f.accounts = map[string]struct{}{}
+		for _, account := range filter.Accounts {
+			if account.SubAccount == nil {
+				f.addresses[account.Address] = struct{}{}
+				continue
+			}
+
+			f.accounts[types.Hash(account)] = struct{}{}
+		}
+	}
+
+	if len(filter.Currencies) > 0 {
+		f.currencies = map[string]struct{}{}
+		for _, currency := range filter.Currencies {
+			f.currencies[types.Hash(currency)] = struct{}{}
+		}
+	}
+
+	l.filter = f
+}
+
+// shouldLog returns a boolean indicating if an event for
+// an account and currency passes the configured filter.
+func (l *Logger) shouldLog(account *types.AccountIdentifier, currency *types.Currency) bool {
+	if l.filter == nil {
+		return true
+	}
+
+	if l.filter.accounts != nil {
+		_, addressOk := l.filter.addresses[account.Address]
+		_, accountOk := l.filter.accounts[types.Hash(account)]
+		if !addressOk && !accountOk {
+			return false
+		}
+	}
+
+	if l.filter.currencies != nil {
+		if _, ok := l.filter.currencies[types.Hash(currency)]; !ok {
+			return false
+		}
+	}
+
+	return true
+}
@@ -19,11 +19,16 @@ import (
 	"fmt"
 	"log"
 	"os"
-	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
 
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
 
+	"github.com/coinbase/rosetta-cli/configuration"
 	"github.com/coinbase/rosetta-cli/pkg/results"
 
 	"github.com/coinbase/rosetta-sdk-go/parser"
@@ -83,6 +88,14 @@ type Logger struct {
 	lastProgressMessage string
 
 	zapLogger *zap.Logger
+	zapFields []zap.Field
+	sink      eventSink
+
+	filter *logFilter
+
+	rotationLock sync.Mutex
+	rotation     *configuration.LogRotationConfiguration
+	streamStart  map[string]time.Time
 }
 
 // NewLogger constructs a new Logger.
@@ -96,7 +109,8 @@ func NewLogger(
 	network *types.NetworkIdentifier,
 	fields ...zap.Field,
 ) (*Logger, error) {
-	zapLogger, err := buildZapLogger(checkType, network, fields...)
+	zapFields := buildZapFields(checkType, network, fields...)
+	zapLogger, err := buildZapLogger(zapFields)
 	if err != nil {
 		return nil, err
 	}
@@ -107,26 +121,30 @@ func NewLogger(
 		logBalanceChanges: logBalanceChanges,
 		logReconciliation: logReconciliation,
 		zapLogger:         zapLogger,
+		zapFields:         zapFields,
+		streamStart:       map[string]time.Time{},
 	}, nil
 }
 
-func buildZapLogger(
+func buildZapFields(
 	checkType CheckType,
 	network *types.NetworkIdentifier,
 	fields ...zap.Field,
-) (*zap.Logger, error) {
-	config := zap.NewDevelopmentConfig()
-	config.EncoderConfig.EncodeLevel = zapcore.CapitalColorLevelEncoder
-
+) []zap.Field {
 	baseSlice := []zap.Field {
 		zap.String("blockchain", network.Blockchain),
 		zap.String("network", network.Network),
 		zap.String("check_type", string(checkType)),
 	}
-	mergedSlice := append(baseSlice, fields...)
+	return append(baseSlice, fields...)
+}
+
+func buildZapLogger(fields []zap.Field) (*zap.Logger, error) {
+	config := zap.NewDevelopmentConfig()
+	config.EncoderConfig.EncodeLevel = zapcore.CapitalColorLevelEncoder
 
 	zapLogger, err := config.Build(
-		zap.Fields(mergedSlice...),
+		zap.Fields(fields...),
 	)
 	return zapLogger, err
 }
@@ -157,7 +175,9 @@ func (l *Logger) LogDataStatus(ctx context.Context, status *results.CheckDataSta
 	}
 
 	l.lastStatsMessage = statsMessage
-	color.Cyan(statsMessage)
+	l.console(VerbosityNormal, severityInfo, statsMessage, color.Cyan)
+	l.logOperationStats(status.Stats)
+	l.logStorageStats(status.Storage)
 
 	// If Progress is nil, it means we're already done.
 	if status.Progress == nil {
@@ -181,7 +201,57 @@ func (l *Logger) LogDataStatus(ctx context.Context, status *results.CheckDataSta
 	}
 
 	l.lastProgressMessage = progressMessage
-	color.Cyan(progressMessage)
+	l.console(VerbosityNormal, severityInfo, progressMessage, color.Cyan)
+}
+
+// logOperationStats logs the average number of operations
+// per block and transaction and the number of operations
+// of each type.
+func (l *Logger) logOperationStats(stats *results.CheckDataStats) {
+	opTypes := make([]string, 0, len(stats.OperationTypes))
+	for opType := range stats.OperationTypes {
+		opTypes = append(opTypes, opType)
+	}
+	sort.Strings(opTypes)
+
+	typeCounts := make([]string, len(opTypes))
+	for i, opType := range opTypes {
+		typeCounts[i] = fmt.Sprintf("%s: %d", opType, stats.OperationTypes[opType])
+	}
+
+	operationsMessage := fmt.Sprintf(
+		"[OPERATIONS] Per Block: %f Per Transaction: %f Types: (%s)",
+		stats.OperationsPerBlock,
+		stats.OperationsPerTransaction,
+		strings.Join(typeCounts, ", "),
+	)
+	l.console(VerbosityNormal, severityInfo, operationsMessage, color.Cyan)
+}
+
+// logStorageStats logs the size of storage on disk
+// (split between tables and value logs) and the number
+// of value logs reclaimed by garbage collection.
+func (l *Logger) logStorageStats(storage *results.CheckDataStorage) {
+	if storage == nil {
+		return
+	}
+
+	storageMessage := fmt.Sprintf(
+		"[STORAGE] Size: %d MB (Tables: %d MB, Value Logs: %d MB) Tables: %d Value Logs: %d (Reclaimed: %d)", // nolint:lll
+		storage.Size/bytesInMB,
+		storage.TablesSize/bytesInMB,
+		storage.ValueLogSize/bytesInMB,
+		storage.Tables,
+		storage.ValueLogs,
+		storage.ValueLogsReclaimed,
+	)
+	l.console(VerbosityNormal, severityInfo, storageMessage, color.Cyan)
+}
+
+// LogProgressBar logs a progress bar rendered
+// by results.ProgressTracker.
+func (l *Logger) LogProgressBar(ctx context.Context, bar string) {
+	l.console(VerbosityNormal, severityInfo, fmt.Sprintf("[PROGRESS] %s", bar), color.Cyan)
 }
 
 // LogConstructionStatus logs results.CheckConstructionStatus.
@@ -203,7 +273,7 @@ func (l *Logger) LogConstructionStatus(
 	}
 
 	l.lastStatsMessage = statsMessage
-	color.Cyan(statsMessage)
+	l.console(VerbosityNormal, severityInfo, statsMessage, color.Cyan)
 }
 
 // LogMemoryStats logs memory usage information.
@@ -217,7 +287,9 @@ func LogMemoryStats(ctx context.Context) {
 		memUsage.GarbageCollections,
 	)
 
-	color.Cyan(statsMessage)
+	if Enabled(VerbosityNormal) {
+		color.Cyan(statsMessage)
+	}
 }
 
 // AddBlockStream writes the next processed block to the end of the
@@ -230,16 +302,12 @@ func (l *Logger) AddBlockStream(
 		return nil
 	}
 
-	f, err := os.OpenFile(
-		path.Join(l.logDir, blockStreamFile),
-		os.O_APPEND|os.O_CREATE|os.O_WRONLY,
-		os.FileMode(utils.DefaultFilePermissions),
-	)
+	s, err := l.newStream(blockStreamFile)
 	if err != nil {
 		return err
 	}
 
-	defer closeFile(f)
+	defer s.close()
 
 	blockString := fmt.Sprintf(
 		"%s Block %d:%s with Parent Block %d:%s\n",
@@ -249,8 +317,15 @@ func (l *Logger) AddBlockStream(
 		block.ParentBlockIdentifier.Index,
 		block.ParentBlockIdentifier.Hash,
 	)
-	fmt.Print(blockString)
-	if _, err := f.WriteString(blockString); err != nil {
+	l.console(VerbosityDebug, severityInfo, blockString, fmt.Printf)
+	err = s.write(blockString, map[string]string{
+		"event":        addEvent,
+		"block_index":  strconv.FormatInt(block.BlockIdentifier.Index, 10),
+		"block_hash":   block.BlockIdentifier.Hash,
+		"parent_index": strconv.FormatInt(block.ParentBlockIdentifier.Index, 10),
+		"parent_hash":  block.ParentBlockIdentifier.Hash,
+	})
+	if err != nil {
 		return err
 	}
 
@@ -267,16 +342,12 @@ func (l *Logger) RemoveBlockStream(
 		return nil
 	}
 
-	f, err := os.OpenFile(
-		path.Join(l.logDir, blockStreamFile),
-		os.O_APPEND|os.O_CREATE|os.O_WRONLY,
-		os.FileMode(utils.DefaultFilePermissions),
-	)
+	s, err := l.newStream(blockStreamFile)
 	if err != nil {
 		return err
 	}
 
-	defer closeFile(f)
+	defer s.close()
 
 	blockString := fmt.Sprintf(
 		"%s Block %d:%s\n",
@@ -284,9 +355,12 @@ func (l *Logger) RemoveBlockStream(
 		block.Index,
 		block.Hash,
 	)
-	fmt.Print(blockString)
-	_, err = f.WriteString(blockString)
-	return err
+	l.console(VerbosityDebug, severityInfo, blockString, fmt.Printf)
+	return s.write(blockString, map[string]string{
+		"event":       removeEvent,
+		"block_index": strconv.FormatInt(block.Index, 10),
+		"block_hash":  block.Hash,
+	})
 }
 
 // TransactionStream writes the next processed block's transactions
@@ -299,16 +373,12 @@ func (l *Logger) TransactionStream(
 		return nil
 	}
 
-	f, err := os.OpenFile(
-		path.Join(l.logDir, transactionStreamFile),
-		os.O_APPEND|os.O_CREATE|os.O_WRONLY,
-		os.FileMode(utils.DefaultFilePermissions),
-	)
+	s, err := l.newStream(transactionStreamFile)
 	if err != nil {
 		return err
 	}
 
-	defer closeFile(f)
+	defer s.close()
 
 	for _, tx := range block.Transactions {
 		transactionString := fmt.Sprintf(
@@ -318,8 +388,12 @@ func (l *Logger) TransactionStream(
 			block.BlockIdentifier.Hash,
 		)
 
-		fmt.Print(transactionString)
-		_, err = f.WriteString(transactionString)
+		l.console(VerbosityDebug, severityInfo, transactionString, fmt.Printf)
+		err = s.write(transactionString, map[string]string{
+			"transaction_hash": tx.TransactionIdentifier.Hash,
+			"block_index":      strconv.FormatInt(block.BlockIdentifier.Index, 10),
+			"block_hash":       block.BlockIdentifier.Hash,
+		})
 
 		if err != nil {
 			return err
@@ -342,7 +416,7 @@ func (l *Logger) TransactionStream(
 				networkIndex = *op.OperationIdentifier.NetworkIndex
 			}
 
-			_, err = f.WriteString(fmt.Sprintf(
+			err = s.write(fmt.Sprintf(
 				"TxOp %d(%d) %s %s %s %s %s\n",
 				op.OperationIdentifier.Index,
 				networkIndex,
@@ -351,7 +425,16 @@ func (l *Logger) TransactionStream(
 				amount,
 				symbol,
 				*op.Status,
-			))
+			), map[string]string{
+				"transaction_hash": tx.TransactionIdentifier.Hash,
+				"operation_index":  strconv.FormatInt(op.OperationIdentifier.Index, 10),
+				"network_index":    strconv.FormatInt(networkIndex, 10),
+				"operation_type":   op.Type,
+				"account":          participant,
+				"amount":           amount,
+				"currency":         symbol,
+				"status":           *op.Status,
+			})
 			if err != nil {
 				return err
 			}
@@ -371,18 +454,18 @@ func (l *Logger) BalanceStream(
 		return nil
 	}
 
-	f, err := os.OpenFile(
-		path.Join(l.logDir, balanceStreamFile),
-		os.O_APPEND|os.O_CREATE|os.O_WRONLY,
-		os.FileMode(utils.DefaultFilePermissions),
-	)
+	s, err := l.newStream(balanceStreamFile)
 	if err != nil {
 		return err
 	}
 
-	defer closeFile(f)
+	defer s.close()
 
 	for _, balanceChange := range balanceChanges {
+		if !l.shouldLog(balanceChange.Account, balanceChange.Currency) {
+			continue
+		}
+
 		balanceLog := fmt.Sprintf(
 			"Account: %s Change: %s:%s Block: %d:%s",
 			balanceChange.Account.Address,
@@ -392,7 +475,14 @@ func (l *Logger) BalanceStream(
 			balanceChange.Block.Hash,
 		)
 
-		if _, err := f.WriteString(fmt.Sprintf("%s\n", balanceLog)); err != nil {
+		err := s.write(fmt.Sprintf("%s\n", balanceLog), map[string]string{
+			"account":     balanceChange.Account.Address,
+			"difference":  balanceChange.Difference,
+			"currency":    types.CurrencyString(balanceChange.Currency),
+			"block_index": strconv.FormatInt(balanceChange.Block.Index, 10),
+			"block_hash":  balanceChange.Block.Hash,
+		})
+		if err != nil {
 			return err
 		}
 	}
@@ -409,29 +499,25 @@ func (l *Logger) ReconcileSuccessStream(
 	balance string,
 	block *types.BlockIdentifier,
 ) error {
-	if !l.logReconciliation {
+	if !l.logReconciliation || !l.shouldLog(account, currency) {
 		return nil
 	}
 
-	f, err := os.OpenFile(
-		path.Join(l.logDir, reconcileSuccessStreamFile),
-		os.O_APPEND|os.O_CREATE|os.O_WRONLY,
-		os.FileMode(utils.DefaultFilePermissions),
-	)
+	s, err := l.newStream(reconcileSuccessStreamFile)
 	if err != nil {
 		return err
 	}
 
-	defer closeFile(f)
+	defer s.close()
 
-	log.Printf(
+	l.console(VerbosityVerbose, severityInfo, fmt.Sprintf(
 		"%s Reconciled %s at %d\n",
 		reconciliationType,
 		types.AccountString(account),
 		block.Index,
-	)
+	), log.Printf)
 
-	_, err = f.WriteString(fmt.Sprintf(
+	err = s.write(fmt.Sprintf(
 		"Type:%s Account: %s Currency: %s Balance: %s Block: %d:%s\n",
 		reconciliationType,
 		types.AccountString(account),
@@ -439,7 +525,14 @@ func (l *Logger) ReconcileSuccessStream(
 		balance,
 		block.Index,
 		block.Hash,
-	))
+	), map[string]string{
+		"reconciliation_type": reconciliationType,
+		"account":             types.AccountString(account),
+		"currency":            types.CurrencyString(currency),
+		"balance":             balance,
+		"block_index":         strconv.FormatInt(block.Index, 10),
+		"block_hash":          block.Hash,
+	})
 	if err != nil {
 		return err
 	}
@@ -460,16 +553,16 @@ func (l *Logger) ReconcileFailureStream(
 ) error {
 	// Always print out reconciliation failures
 	if reconciliationType == reconciler.InactiveReconciliation {
-		color.Yellow(
+		l.console(VerbosityQuiet, severityWarning, fmt.Sprintf(
 			"Missing balance-changing operation detected for %s computed: %s%s live: %s%s",
 			types.AccountString(account),
 			computedBalance,
 			currency.Symbol,
 			liveBalance,
 			currency.Symbol,
-		)
+		), color.Yellow)
 	} else {
-		color.Yellow(
+		l.console(VerbosityQuiet, severityWarning, fmt.Sprintf(
 			"Reconciliation failed for %s at %d computed: %s%s live: %s%s",
 			types.AccountString(account),
 			block.Index,
@@ -477,25 +570,21 @@ func (l *Logger) ReconcileFailureStream(
 			currency.Symbol,
 			liveBalance,
 			currency.Symbol,
-		)
+		), color.Yellow)
 	}
 
-	if !l.logReconciliation {
+	if !l.logReconciliation || !l.shouldLog(account, currency) {
 		return nil
 	}
 
-	f, err := os.OpenFile(
-		path.Join(l.logDir, reconcileFailureStreamFile),
-		os.O_APPEND|os.O_CREATE|os.O_WRONLY,
-		os.FileMode(utils.DefaultFilePermissions),
-	)
+	s, err := l.newStream(reconcileFailureStreamFile)
 	if err != nil {
 		return err
 	}
 
-	defer closeFile(f)
+	defer s.close()
 
-	_, err = f.WriteString(fmt.Sprintf(
+	err = s.write(fmt.Sprintf(
 		"Type:%s Account: %s Currency: %s Block: %s:%d computed: %s live: %s\n",
 		reconciliationType,
 		types.AccountString(account),
@@ -504,7 +593,15 @@ func (l *Logger) ReconcileFailureStream(
 		block.Index,
 		computedBalance,
 		liveBalance,
-	))
+	), map[string]string{
+		"reconciliation_type": reconciliationType,
+		"account":             types.AccountString(account),
+		"currency":            types.CurrencyString(currency),
+		"block_index":         strconv.FormatInt(block.Index, 10),
+		"block_hash":          block.Hash,
+		"computed_balance":    computedBalance,
+		"live_balance":        liveBalance,
+	})
 	if err != nil {
 		return err
 	}
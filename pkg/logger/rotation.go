@@ -0,0 +1,208 @@
+// Copyright 2022 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logger
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/coinbase/rosetta-cli/configuration"
+
+	"github.com/coinbase/rosetta-sdk-go/utils"
+)
+
+const (
+	// bytesInMB is the number of bytes in a megabyte.
+	bytesInMB = 1024 * 1024
+
+	// rotatedTimeFormat is appended to the name of
+	// rotated stream files.
+	rotatedTimeFormat = "20060102T150405.000000000"
+)
+
+// streamFiles are all stream files written by the *Logger.
+var streamFiles = []string{
+	blockStreamFile,
+	transactionStreamFile,
+	balanceStreamFile,
+	reconcileSuccessStreamFile,
+	reconcileFailureStreamFile,
+}
+
+// SetRotation configures size and/or time based rotation
+// of all stream files written by the *Logger.
+func (l *Logger) SetRotation(rotation *configuration.LogRotationConfiguration) {
+	l.rotationLock.Lock()
+	defer l.rotationLock.Unlock()
+
+	l.rotation = rotation
+}
+
+// openStream opens a stream file for appending, rotating
+// it first if it has exceeded the configured size or age.
+func (l *Logger) openStream(name string) (*os.File, error) {
+	filePath := path.Join(l.logDir, name)
+	if err := l.rotate(filePath); err != nil {
+		return nil, fmt.Errorf("%w: unable to rotate %s", err, name)
+	}
+
+	return os.OpenFile(
+		filePath,
+		os.O_APPEND|os.O_CREATE|os.O_WRONLY,
+		os.FileMode(utils.DefaultFilePermissions),
+	)
+}
+
+func (l *Logger) rotate(filePath string) error {
+	l.rotationLock.Lock()
+	defer l.rotationLock.Unlock()
+
+	if l.rotation == nil {
+		return nil
+	}
+
+	info, err := os.Stat(filePath)
+	if os.IsNotExist(err) {
+		l.streamStart[filePath] = time.Now()
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	start, ok := l.streamStart[filePath]
+	if !ok {
+		start = info.ModTime()
+		l.streamStart[filePath] = start
+	}
+
+	sizeExceeded := l.rotation.MaxSizeMB > 0 && info.Size() >= l.rotation.MaxSizeMB*bytesInMB
+	ageExceeded := l.rotation.MaxAge > 0 &&
+		time.Since(start) >= time.Duration(l.rotation.MaxAge)*time.Second
+	if !sizeExceeded && !ageExceeded {
+		return nil
+	}
+
+	rotatedPath := fmt.Sprintf("%s.%s", filePath, time.Now().UTC().Format(rotatedTimeFormat))
+	if err := os.Rename(filePath, rotatedPath); err != nil {
+		return err
+	}
+	l.streamStart[filePath] = time.Now()
+
+	if !l.rotation.CompressionDisabled {
+		if err := compressFile(rotatedPath); err != nil {
+			return err
+		}
+	}
+
+	return pruneRotated(filePath, l.rotation.MaxBackups)
+}
+
+// compressFile gzips a file and removes the original.
+func compressFile(filePath string) error {
+	src, err := os.Open(path.Clean(filePath))
+	if err != nil {
+		return err
+	}
+	defer closeFile(src)
+
+	dst, err := os.OpenFile(
+		fmt.Sprintf("%s.gz", filePath),
+		os.O_CREATE|os.O_TRUNC|os.O_WRONLY,
+		os.FileMode(utils.DefaultFilePermissions),
+	)
+	if err != nil {
+		return err
+	}
+	defer closeFile(dst)
+
+	gz := gzip.NewWriter(dst)
+	if _, err := io.Copy(gz, src); err != nil {
+		return err
+	}
+
+	if err := gz.Close(); err != nil {
+		return err
+	}
+
+	return os.Remove(filePath)
+}
+
+// pruneRotated removes the oldest rotated files for a stream
+// so that at most maxBackups remain. If maxBackups is 0,
+// all rotated files are retained.
+func pruneRotated(filePath string, maxBackups int) error {
+	if maxBackups <= 0 {
+		return nil
+	}
+
+	rotated, err := filepath.Glob(fmt.Sprintf("%s.*", filePath))
+	if err != nil {
+		return err
+	}
+
+	if len(rotated) <= maxBackups {
+		return nil
+	}
+
+	// The rotated timestamp sorts lexicographically.
+	sort.Strings(rotated)
+	for _, old := range rotated[:len(rotated)-maxBackups] {
+		if err := os.Remove(old); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// PruneRotated removes all rotated stream files last written
+// before cutoff and returns the number of files removed.
+func (l *Logger) PruneRotated(cutoff time.Time) (int, error) {
+	l.rotationLock.Lock()
+	defer l.rotationLock.Unlock()
+
+	removed := 0
+	for _, name := range streamFiles {
+		rotated, err := filepath.Glob(fmt.Sprintf("%s.*", path.Join(l.logDir, name)))
+		if err != nil {
+			return removed, err
+		}
+
+		for _, old := range rotated {
+			info, err := os.Stat(old)
+			if err != nil {
+				return removed, err
+			}
+
+			if !info.ModTime().Before(cutoff) {
+				continue
+			}
+
+			if err := os.Remove(old); err != nil {
+				return removed, err
+			}
+			removed++
+		}
+	}
+
+	return removed, nil
+}
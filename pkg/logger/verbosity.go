@@ -0,0 +1,78 @@
+// Copyright 2022 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logger
+
+import (
+	"bytes"
+	"io"
+	"log"
+	"os"
+)
+
+// Verbosity determines which events are printed to the
+// console (or sent to the configured log backend). Events
+// are always written to log files when they are enabled
+// in the configuration.
+type Verbosity int
+
+const (
+	// VerbosityQuiet only prints warnings and failures.
+	VerbosityQuiet Verbosity = -1
+
+	// VerbosityNormal also prints periodic stats and progress.
+	VerbosityNormal Verbosity = 0
+
+	// VerbosityVerbose also prints fetcher retries and
+	// successful reconciliations.
+	VerbosityVerbose Verbosity = 1
+
+	// VerbosityDebug also prints processed blocks, transactions,
+	// and the debug output of the reconciler.
+	VerbosityDebug Verbosity = 2
+)
+
+// retryMessage is included in every message the
+// fetcher logs before retrying a request.
+var retryMessage = []byte("retrying fetch for")
+
+var verbosity = VerbosityNormal
+
+// SetVerbosity sets the verbosity of all loggers. Fetcher retry
+// messages (logged with the standard library logger) are dropped
+// unless verbosity is at least VerbosityVerbose.
+func SetVerbosity(v Verbosity) {
+	verbosity = v
+	log.SetOutput(&retryFilter{w: os.Stderr})
+}
+
+// Enabled returns a boolean indicating if events
+// of the provided verbosity should be printed.
+func Enabled(v Verbosity) bool {
+	return verbosity >= v
+}
+
+// retryFilter drops fetcher retry messages
+// below VerbosityVerbose.
+type retryFilter struct {
+	w io.Writer
+}
+
+func (f *retryFilter) Write(p []byte) (int, error) {
+	if !Enabled(VerbosityVerbose) && bytes.Contains(p, retryMessage) {
+		return len(p), nil
+	}
+
+	return f.w.Write(p)
+}
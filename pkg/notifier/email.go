@@ -0,0 +1,165 @@
+// Copyright 2022 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package notifier
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"mime/multipart"
+	"net/smtp"
+	"net/textproto"
+	"path"
+	"strings"
+
+	"github.com/coinbase/rosetta-cli/configuration"
+)
+
+const (
+	// emailLineLength is the maximum length of a
+	// line of a base64 encoded attachment.
+	emailLineLength = 76
+
+	// emailTemplate is used to render the body of
+	// an email summary.
+	emailTemplate = "rosetta-cli {{.Command}} {{.Event}} on {{.Network}}\n" +
+		"{{if .Error}}\nError:\n{{.Error}}\n{{end}}" +
+		"{{if .Stats}}\nStats:\n{{.Stats}}\n{{end}}"
+)
+
+// Email sends a summary of a completed check to the configured
+// email recipients. If resultsFile is populated and attachments
+// are enabled, it is attached to the summary. Like Notify, this
+// is best effort so any failure is logged instead of being returned.
+func Email(config *configuration.Configuration, message *Message, resultsFile string) {
+	if config.Email == nil {
+		return
+	}
+
+	if err := email(config.Email, message, resultsFile); err != nil {
+		log.Printf("%s: unable to send email summary\n", err.Error())
+	}
+}
+
+func email(
+	config *configuration.EmailConfiguration,
+	message *Message,
+	resultsFile string,
+) error {
+	body, err := render(emailTemplate, message)
+	if err != nil {
+		return fmt.Errorf("%w: unable to render email", err)
+	}
+
+	if len(config.ResultsURL) > 0 {
+		body = fmt.Sprintf("%s\nResults: %s\n", body, config.ResultsURL)
+	}
+
+	var attachment []byte
+	if config.AttachResults && len(resultsFile) > 0 {
+		attachment, err = ioutil.ReadFile(path.Clean(resultsFile))
+		if err != nil {
+			return fmt.Errorf("%w: unable to read results file %s", err, resultsFile)
+		}
+	}
+
+	msg, err := buildEmail(
+		config,
+		fmt.Sprintf("rosetta-cli %s %s on %s", message.Command, message.Event, message.Network),
+		body,
+		path.Base(resultsFile),
+		attachment,
+	)
+	if err != nil {
+		return fmt.Errorf("%w: unable to build email", err)
+	}
+
+	var auth smtp.Auth
+	if len(config.Username) > 0 {
+		auth = smtp.PlainAuth("", config.Username, config.Password, config.SMTPHost)
+	}
+
+	return smtp.SendMail(
+		fmt.Sprintf("%s:%d", config.SMTPHost, config.SMTPPort),
+		auth,
+		config.From,
+		config.To,
+		msg,
+	)
+}
+
+// buildEmail creates a MIME message with a plain text body
+// and (if provided) a JSON attachment.
+func buildEmail(
+	config *configuration.EmailConfiguration,
+	subject string,
+	body string,
+	attachmentName string,
+	attachment []byte,
+) ([]byte, error) {
+	var buf bytes.Buffer
+	writer := multipart.NewWriter(&buf)
+
+	fmt.Fprintf(&buf, "From: %s\r\n", config.From)
+	fmt.Fprintf(&buf, "To: %s\r\n", strings.Join(config.To, ", "))
+	fmt.Fprintf(&buf, "Subject: %s\r\n", subject)
+	fmt.Fprintf(&buf, "MIME-Version: 1.0\r\n")
+	fmt.Fprintf(&buf, "Content-Type: multipart/mixed; boundary=%s\r\n\r\n", writer.Boundary())
+
+	part, err := writer.CreatePart(textproto.MIMEHeader{
+		"Content-Type": {"text/plain; charset=UTF-8"},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := part.Write([]byte(strings.ReplaceAll(body, "\n", "\r\n"))); err != nil {
+		return nil, err
+	}
+
+	if len(attachment) > 0 {
+		part, err := writer.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {"application/json"},
+			"Content-Transfer-Encoding": {"base64"},
+			"Content-Disposition": {
+				fmt.Sprintf("attachment; filename=%q", attachmentName),
+			},
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		encoded := base64.StdEncoding.EncodeToString(attachment)
+		for len(encoded) > 0 {
+			n := emailLineLength
+			if len(encoded) < n {
+				n = len(encoded)
+			}
+
+			if _, err := fmt.Fprintf(part, "%s\r\n", encoded[:n]); err != nil {
+				return nil, err
+			}
+			encoded = encoded[n:]
+		}
+	}
+
+	if err := writer.Close(); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
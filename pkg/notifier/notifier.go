@@ -0,0 +1,133 @@
+// Copyright 2022 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package notifier
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"text/template"
+	"time"
+
+	"github.com/coinbase/rosetta-cli/configuration"
+)
+
+// Event is the stage of a check that
+// a notification is sent for.
+type Event string
+
+const (
+	// StartEvent is sent when a check begins.
+	StartEvent Event = "started"
+
+	// SuccessEvent is sent when a check completes
+	// without error.
+	SuccessEvent Event = "succeeded"
+
+	// FailureEvent is sent when a check exits
+	// with an error.
+	FailureEvent Event = "failed"
+
+	// DefaultTemplate is used to render a Message when
+	// a NotifierConfiguration does not provide a template.
+	DefaultTemplate = "rosetta-cli {{.Command}} {{.Event}} on {{.Network}}" +
+		"{{if .Error}}\nError: {{.Error}}{{end}}" +
+		"{{if .Stats}}\n```{{.Stats}}```{{end}}"
+
+	// notifyTimeout is the maximum amount of time to wait
+	// for a webhook to respond.
+	notifyTimeout = 10 * time.Second
+)
+
+// Message contains the information available
+// to a notification template.
+type Message struct {
+	Command string
+	Event   Event
+	Network string
+	Error   string
+	Stats   string
+}
+
+// Notify renders a *Message and posts it to each configured
+// webhook. Notifications are best effort, so any failure is
+// logged instead of being returned.
+func Notify(config *configuration.Configuration, message *Message) {
+	for _, notifier := range config.Notifiers {
+		if err := notify(notifier, message); err != nil {
+			log.Printf("%s: unable to send %s notification\n", err.Error(), notifier.Type)
+		}
+	}
+}
+
+func notify(notifier *configuration.NotifierConfiguration, message *Message) error {
+	text, err := render(notifier.Template, message)
+	if err != nil {
+		return fmt.Errorf("%w: unable to render template", err)
+	}
+
+	var payload map[string]string
+	switch notifier.Type {
+	case configuration.SlackNotifier:
+		payload = map[string]string{"text": text}
+		if len(notifier.Channel) > 0 {
+			payload["channel"] = notifier.Channel
+		}
+	case configuration.DiscordNotifier:
+		payload = map[string]string{"content": text}
+	default:
+		return fmt.Errorf("notifier type %s is not supported", notifier.Type)
+	}
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("%w: unable to encode payload", err)
+	}
+
+	client := &http.Client{Timeout: notifyTimeout}
+	resp, err := client.Post(notifier.WebhookURL, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("%w: unable to post to webhook", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		respBody, _ := ioutil.ReadAll(resp.Body)
+		return fmt.Errorf("received %d status with body %s", resp.StatusCode, respBody)
+	}
+
+	return nil
+}
+
+func render(text string, message *Message) (string, error) {
+	if len(text) == 0 {
+		text = DefaultTemplate
+	}
+
+	tmpl, err := template.New("notification").Parse(text)
+	if err != nil {
+		return "", err
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, message); err != nil {
+		return "", err
+	}
+
+	return buf.String(), nil
+}
@@ -0,0 +1,299 @@
+// Copyright 2022 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package parquet
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"path"
+	"sync"
+
+	"github.com/coinbase/rosetta-cli/configuration"
+
+	"github.com/coinbase/rosetta-sdk-go/storage/database"
+	"github.com/coinbase/rosetta-sdk-go/storage/modules"
+	"github.com/coinbase/rosetta-sdk-go/types"
+	"github.com/neilotoole/errgroup"
+)
+
+const (
+	blocksTable     = "blocks"
+	operationsTable = "operations"
+
+	// exportDirMode is the permission of
+	// each export table directory.
+	exportDirMode = 0750
+
+	// exportFileMode is the permission
+	// of each exported file.
+	exportFileMode = 0600
+)
+
+var _ modules.BlockWorker = (*Exporter)(nil)
+
+// Exporter implements the modules.BlockWorker interface. It writes
+// synced blocks and their operations to Parquet files partitioned
+// by block range (<directory>/<table>/<first index>-<last index>.parquet).
+//
+// Blocks are buffered until a range is complete and its last block
+// is deeper than the max reorg depth, so exported files never
+// contain orphaned blocks.
+type Exporter struct {
+	directory     string
+	blocksPerFile int64
+	reorgDepth    int64
+
+	lock    sync.Mutex
+	pending []*types.Block
+}
+
+// NewExporter returns a new *Exporter.
+func NewExporter(
+	config *configuration.ParquetExportConfiguration,
+	reorgDepth int64,
+) (*Exporter, error) {
+	for _, table := range []string{blocksTable, operationsTable} {
+		if err := os.MkdirAll(path.Join(config.Directory, table), exportDirMode); err != nil {
+			return nil, fmt.Errorf("%w: unable to create %s directory", err, table)
+		}
+	}
+
+	return &Exporter{
+		directory:     config.Directory,
+		blocksPerFile: config.BlocksPerFile,
+		reorgDepth:    reorgDepth,
+	}, nil
+}
+
+// AddingBlock is called by BlockStorage when adding a block.
+func (e *Exporter) AddingBlock(
+	ctx context.Context,
+	g *errgroup.Group,
+	block *types.Block,
+	dbTx database.Transaction,
+) (database.CommitWorker, error) {
+	return func(ctx context.Context) error {
+		e.lock.Lock()
+		defer e.lock.Unlock()
+
+		e.pending = append(e.pending, block)
+		return e.flush(block.BlockIdentifier.Index, false)
+	}, nil
+}
+
+// RemovingBlock is called by BlockStorage when removing a block.
+func (e *Exporter) RemovingBlock(
+	ctx context.Context,
+	g *errgroup.Group,
+	block *types.Block,
+	dbTx database.Transaction,
+) (database.CommitWorker, error) {
+	return func(ctx context.Context) error {
+		e.lock.Lock()
+		defer e.lock.Unlock()
+
+		last := len(e.pending) - 1
+		if last < 0 || types.Hash(e.pending[last].BlockIdentifier) != types.Hash(block.BlockIdentifier) {
+			log.Printf(
+				"block %d:%s was removed after it was exported\n",
+				block.BlockIdentifier.Index,
+				block.BlockIdentifier.Hash,
+			)
+			return nil
+		}
+
+		e.pending = e.pending[:last]
+		return nil
+	}, nil
+}
+
+// Close exports all buffered blocks (even if
+// their range is not complete).
+func (e *Exporter) Close() error {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+
+	if len(e.pending) == 0 {
+		return nil
+	}
+
+	return e.flush(e.pending[len(e.pending)-1].BlockIdentifier.Index, true)
+}
+
+// rangeEnd returns the last index in the range of index.
+func (e *Exporter) rangeEnd(index int64) int64 {
+	return index - index%e.blocksPerFile + e.blocksPerFile - 1
+}
+
+// flush exports all buffered ranges that can no longer be
+// orphaned at head (or all buffered blocks if force is true).
+// flush must be called while holding the lock.
+func (e *Exporter) flush(head int64, force bool) error {
+	for len(e.pending) > 0 {
+		end := e.rangeEnd(e.pending[0].BlockIdentifier.Index)
+		if !force && head-end < e.reorgDepth {
+			return nil
+		}
+
+		count := 0
+		for count < len(e.pending) && e.pending[count].BlockIdentifier.Index <= end {
+			count++
+		}
+
+		if err := e.export(e.pending[:count]); err != nil {
+			return err
+		}
+
+		e.pending = e.pending[count:]
+	}
+
+	return nil
+}
+
+func (e *Exporter) export(blocks []*types.Block) error {
+	name := fmt.Sprintf(
+		"%d-%d.parquet",
+		blocks[0].BlockIdentifier.Index,
+		blocks[len(blocks)-1].BlockIdentifier.Index,
+	)
+
+	blocksFile := path.Join(e.directory, blocksTable, name)
+	if err := writeTable(blocksFile, blockTable(blocks)); err != nil {
+		return fmt.Errorf("%w: unable to export blocks to %s", err, blocksFile)
+	}
+
+	operationsFile := path.Join(e.directory, operationsTable, name)
+	if err := writeTable(operationsFile, operationTable(blocks)); err != nil {
+		return fmt.Errorf("%w: unable to export operations to %s", err, operationsFile)
+	}
+
+	return nil
+}
+
+// writeTable writes a *Table to a temporary file and then
+// renames it so that partially written files are never read.
+func writeTable(filePath string, table *Table) error {
+	tmpPath := filePath + ".tmp"
+	f, err := os.OpenFile(
+		path.Clean(tmpPath),
+		os.O_CREATE|os.O_TRUNC|os.O_WRONLY,
+		exportFileMode,
+	)
+	if err != nil {
+		return err
+	}
+
+	if err := table.Write(f); err != nil {
+		_ = f.Close()
+		return err
+	}
+
+	if err := f.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmpPath, filePath)
+}
+
+func blockTable(blocks []*types.Block) *Table {
+	var (
+		index       = Int64Column("block_index")
+		hash        = StringColumn("block_hash")
+		parentIndex = Int64Column("parent_block_index")
+		parentHash  = StringColumn("parent_block_hash")
+		timestamp   = TimestampColumn("timestamp")
+		txs         = Int64Column("transactions")
+	)
+
+	for _, block := range blocks {
+		index.AppendInt64(block.BlockIdentifier.Index)
+		hash.AppendString(block.BlockIdentifier.Hash)
+		parentIndex.AppendInt64(block.ParentBlockIdentifier.Index)
+		parentHash.AppendString(block.ParentBlockIdentifier.Hash)
+		timestamp.AppendInt64(block.Timestamp)
+		txs.AppendInt64(int64(len(block.Transactions)))
+	}
+
+	return NewTable(index, hash, parentIndex, parentHash, timestamp, txs)
+}
+
+func operationTable(blocks []*types.Block) *Table {
+	var (
+		blockIndex = Int64Column("block_index")
+		blockHash  = StringColumn("block_hash")
+		timestamp  = TimestampColumn("timestamp")
+		txHash     = StringColumn("transaction_hash")
+		opIndex    = Int64Column("operation_index")
+		opType     = StringColumn("type")
+		status     = StringColumn("status")
+		address    = StringColumn("address")
+		subAccount = StringColumn("sub_account")
+		currency   = StringColumn("currency")
+		amount     = StringColumn("amount")
+	)
+
+	for _, block := range blocks {
+		for _, tx := range block.Transactions {
+			for _, op := range tx.Operations {
+				blockIndex.AppendInt64(block.BlockIdentifier.Index)
+				blockHash.AppendString(block.BlockIdentifier.Hash)
+				timestamp.AppendInt64(block.Timestamp)
+				txHash.AppendString(tx.TransactionIdentifier.Hash)
+				opIndex.AppendInt64(op.OperationIdentifier.Index)
+				opType.AppendString(op.Type)
+
+				opStatus := ""
+				if op.Status != nil {
+					opStatus = *op.Status
+				}
+				status.AppendString(opStatus)
+
+				opAddress, opSubAccount := "", ""
+				if op.Account != nil {
+					opAddress = op.Account.Address
+					if op.Account.SubAccount != nil {
+						opSubAccount = op.Account.SubAccount.Address
+					}
+				}
+				address.AppendString(opAddress)
+				subAccount.AppendString(opSubAccount)
+
+				opCurrency, opAmount := "", ""
+				if op.Amount != nil {
+					opCurrency = types.CurrencyString(op.Amount.Currency)
+					opAmount = op.Amount.Value
+				}
+				currency.AppendString(opCurrency)
+				amount.AppendString(opAmount)
+			}
+		}
+	}
+
+	return NewTable(
+		blockIndex,
+		blockHash,
+		timestamp,
+		txHash,
+		opIndex,
+		opType,
+		status,
+		address,
+		subAccount,
+		currency,
+		amount,
+	)
+}
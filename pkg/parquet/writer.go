@@ -0,0 +1,369 @@
+// Copyright 2022 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package parquet
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+)
+
+// This file implements the subset of the Parquet format needed
+// to export flat tables: each file has a single row group, each
+// column is REQUIRED, PLAIN encoded, and uncompressed, and is
+// stored in a single data page. Metadata is encoded with the
+// Thrift compact protocol.
+
+const (
+	magic     = "PAR1"
+	createdBy = "rosetta-cli"
+
+	// Parquet physical types
+	typeInt64     = 2
+	typeByteArray = 6
+
+	// Parquet converted types
+	convertedUTF8            = 0
+	convertedTimestampMillis = 9
+
+	repetitionRequired = 0
+	pageTypeData       = 0
+	encodingPlain      = 0
+	encodingRLE        = 3
+	codecUncompressed  = 0
+	fileVersion        = 1
+
+	// Thrift compact protocol types
+	compactI32    = 5
+	compactI64    = 6
+	compactBinary = 8
+	compactList   = 9
+	compactStruct = 12
+
+	// maxShortFieldDelta is the largest field id delta
+	// that can be encoded in a field header.
+	maxShortFieldDelta = 15
+
+	// maxShortListSize is the largest list size that
+	// can be encoded in a list header.
+	maxShortListSize = 14
+
+	int64Size = 8
+)
+
+// Column is a single column of a *Table.
+type Column struct {
+	name          string
+	physicalType  int32
+	convertedType *int32
+
+	int64s  []int64
+	strings []string
+}
+
+func convertedType(t int32) *int32 {
+	return &t
+}
+
+// Int64Column returns a new column of int64 values.
+func Int64Column(name string) *Column {
+	return &Column{name: name, physicalType: typeInt64}
+}
+
+// TimestampColumn returns a new column of timestamps
+// (in milliseconds since the Unix epoch).
+func TimestampColumn(name string) *Column {
+	return &Column{
+		name:          name,
+		physicalType:  typeInt64,
+		convertedType: convertedType(convertedTimestampMillis),
+	}
+}
+
+// StringColumn returns a new column of UTF-8 strings.
+func StringColumn(name string) *Column {
+	return &Column{
+		name:          name,
+		physicalType:  typeByteArray,
+		convertedType: convertedType(convertedUTF8),
+	}
+}
+
+// AppendInt64 appends a value to an int64 or timestamp column.
+func (c *Column) AppendInt64(v int64) {
+	c.int64s = append(c.int64s, v)
+}
+
+// AppendString appends a value to a string column.
+func (c *Column) AppendString(v string) {
+	c.strings = append(c.strings, v)
+}
+
+func (c *Column) len() int {
+	if c.physicalType == typeInt64 {
+		return len(c.int64s)
+	}
+
+	return len(c.strings)
+}
+
+// plain returns the PLAIN encoding of all values in the column.
+func (c *Column) plain() []byte {
+	var buf bytes.Buffer
+	if c.physicalType == typeInt64 {
+		b := make([]byte, int64Size)
+		for _, v := range c.int64s {
+			binary.LittleEndian.PutUint64(b, uint64(v))
+			buf.Write(b)
+		}
+
+		return buf.Bytes()
+	}
+
+	for _, v := range c.strings {
+		_ = binary.Write(&buf, binary.LittleEndian, uint32(len(v)))
+		buf.WriteString(v)
+	}
+
+	return buf.Bytes()
+}
+
+// Table is a collection of columns with the same
+// number of values that is written as a Parquet file.
+type Table struct {
+	columns []*Column
+}
+
+// NewTable returns a new *Table.
+func NewTable(columns ...*Column) *Table {
+	return &Table{columns: columns}
+}
+
+// Rows returns the number of rows in the *Table.
+func (t *Table) Rows() int {
+	if len(t.columns) == 0 {
+		return 0
+	}
+
+	return t.columns[0].len()
+}
+
+type columnChunk struct {
+	column *Column
+	offset int64
+	size   int64
+}
+
+// Write writes the *Table as a Parquet file.
+func (t *Table) Write(w io.Writer) error {
+	rows := t.Rows()
+	for _, column := range t.columns {
+		if column.len() != rows {
+			return fmt.Errorf(
+				"column %s has %d values but expected %d",
+				column.name,
+				column.len(),
+				rows,
+			)
+		}
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString(magic)
+
+	chunks := make([]*columnChunk, len(t.columns))
+	for i, column := range t.columns {
+		data := column.plain()
+		header := pageHeader(len(data), rows)
+
+		chunks[i] = &columnChunk{
+			column: column,
+			offset: int64(buf.Len()),
+			size:   int64(len(header) + len(data)),
+		}
+		buf.Write(header)
+		buf.Write(data)
+	}
+
+	metadata := fileMetaData(chunks, int64(rows))
+	buf.Write(metadata)
+	_ = binary.Write(&buf, binary.LittleEndian, uint32(len(metadata)))
+	buf.WriteString(magic)
+
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+func pageHeader(size int, values int) []byte {
+	w := newCompactWriter()
+	w.beginStruct()
+	w.i32Field(1, pageTypeData)
+	w.i32Field(2, int32(size)) // uncompressed_page_size
+	w.i32Field(3, int32(size)) // compressed_page_size
+
+	w.structField(5) // data_page_header
+	w.i32Field(1, int32(values))
+	w.i32Field(2, encodingPlain)
+	w.i32Field(3, encodingRLE) // definition_level_encoding
+	w.i32Field(4, encodingRLE) // repetition_level_encoding
+	w.endStruct()
+
+	w.endStruct()
+	return w.buf.Bytes()
+}
+
+func fileMetaData(chunks []*columnChunk, rows int64) []byte {
+	w := newCompactWriter()
+	w.beginStruct()
+	w.i32Field(1, fileVersion)
+
+	// The schema is a root element followed by each column.
+	w.listField(2, compactStruct, len(chunks)+1)
+	w.beginStruct()
+	w.binaryField(4, "schema")
+	w.i32Field(5, int32(len(chunks))) // num_children
+	w.endStruct()
+	for _, chunk := range chunks {
+		w.beginStruct()
+		w.i32Field(1, chunk.column.physicalType)
+		w.i32Field(3, repetitionRequired)
+		w.binaryField(4, chunk.column.name)
+		if chunk.column.convertedType != nil {
+			w.i32Field(6, *chunk.column.convertedType)
+		}
+		w.endStruct()
+	}
+
+	w.i64Field(3, rows)
+
+	totalSize := int64(0)
+	for _, chunk := range chunks {
+		totalSize += chunk.size
+	}
+
+	w.listField(4, compactStruct, 1) // row_groups
+	w.beginStruct()
+	w.listField(1, compactStruct, len(chunks))
+	for _, chunk := range chunks {
+		w.beginStruct()
+		w.i64Field(2, chunk.offset) // file_offset
+
+		w.structField(3) // meta_data
+		w.i32Field(1, chunk.column.physicalType)
+		w.listField(2, compactI32, 1)
+		w.i32(encodingPlain)
+		w.listField(3, compactBinary, 1)
+		w.binary(chunk.column.name)
+		w.i32Field(4, codecUncompressed)
+		w.i64Field(5, rows)
+		w.i64Field(6, chunk.size)   // total_uncompressed_size
+		w.i64Field(7, chunk.size)   // total_compressed_size
+		w.i64Field(9, chunk.offset) // data_page_offset
+		w.endStruct()
+
+		w.endStruct()
+	}
+	w.i64Field(2, totalSize)
+	w.i64Field(3, rows)
+	w.endStruct()
+
+	w.binaryField(6, createdBy)
+	w.endStruct()
+	return w.buf.Bytes()
+}
+
+// compactWriter encodes Thrift structs with the compact protocol.
+type compactWriter struct {
+	buf bytes.Buffer
+
+	// lastField is a stack of the last field id
+	// written in each open struct.
+	lastField []int16
+}
+
+func newCompactWriter() *compactWriter {
+	return &compactWriter{}
+}
+
+func zigzag(v int64) uint64 {
+	return uint64((v << 1) ^ (v >> 63))
+}
+
+func (w *compactWriter) varint(v uint64) {
+	b := make([]byte, binary.MaxVarintLen64)
+	w.buf.Write(b[:binary.PutUvarint(b, v)])
+}
+
+func (w *compactWriter) beginStruct() {
+	w.lastField = append(w.lastField, 0)
+}
+
+func (w *compactWriter) endStruct() {
+	w.buf.WriteByte(0) // stop field
+	w.lastField = w.lastField[:len(w.lastField)-1]
+}
+
+func (w *compactWriter) fieldHeader(id int16, fieldType byte) {
+	last := &w.lastField[len(w.lastField)-1]
+	if delta := id - *last; delta > 0 && delta <= maxShortFieldDelta {
+		w.buf.WriteByte(byte(delta)<<4 | fieldType)
+	} else {
+		w.buf.WriteByte(fieldType)
+		w.varint(zigzag(int64(id)))
+	}
+
+	*last = id
+}
+
+func (w *compactWriter) i32(v int32) {
+	w.varint(zigzag(int64(v)))
+}
+
+func (w *compactWriter) binary(v string) {
+	w.varint(uint64(len(v)))
+	w.buf.WriteString(v)
+}
+
+func (w *compactWriter) i32Field(id int16, v int32) {
+	w.fieldHeader(id, compactI32)
+	w.i32(v)
+}
+
+func (w *compactWriter) i64Field(id int16, v int64) {
+	w.fieldHeader(id, compactI64)
+	w.varint(zigzag(v))
+}
+
+func (w *compactWriter) binaryField(id int16, v string) {
+	w.fieldHeader(id, compactBinary)
+	w.binary(v)
+}
+
+func (w *compactWriter) structField(id int16) {
+	w.fieldHeader(id, compactStruct)
+	w.beginStruct()
+}
+
+func (w *compactWriter) listField(id int16, elementType byte, size int) {
+	w.fieldHeader(id, compactList)
+	if size <= maxShortListSize {
+		w.buf.WriteByte(byte(size)<<4 | elementType)
+		return
+	}
+
+	w.buf.WriteByte(0xF0 | elementType)
+	w.varint(uint64(size))
+}
@@ -0,0 +1,65 @@
+// Copyright 2022 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package parquet
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCompactWriter(t *testing.T) {
+	w := newCompactWriter()
+	w.beginStruct()
+	w.i32Field(1, 1)
+	w.binaryField(4, "ab")
+	w.i64Field(20, -1)
+	w.endStruct()
+
+	assert.Equal(t, []byte{
+		0x15, 0x02, // field 1 (i32): 1
+		0x38, 0x02, 'a', 'b', // field 4 (binary, delta 3): "ab"
+		0x06, 0x28, 0x01, // field 20 (i64, long form): -1
+		0x00, // stop
+	}, w.buf.Bytes())
+}
+
+func TestTableWrite(t *testing.T) {
+	index := Int64Column("index")
+	hash := StringColumn("hash")
+	for i, h := range []string{"a", "bc"} {
+		index.AppendInt64(int64(i))
+		hash.AppendString(h)
+	}
+
+	var buf bytes.Buffer
+	assert.NoError(t, NewTable(index, hash).Write(&buf))
+
+	b := buf.Bytes()
+	assert.Equal(t, magic, string(b[:4]))
+	assert.Equal(t, magic, string(b[len(b)-4:]))
+
+	metadataSize := binary.LittleEndian.Uint32(b[len(b)-8 : len(b)-4])
+	assert.Less(t, int(metadataSize), len(b)-8)
+
+	// PLAIN encoded values are written after each page header.
+	assert.True(t, bytes.Contains(b, []byte{1, 0, 0, 0, 0, 0, 0, 0}))
+	assert.True(t, bytes.Contains(b, []byte{2, 0, 0, 0, 'b', 'c'}))
+
+	hash.AppendString("extra")
+	assert.Error(t, NewTable(index, hash).Write(&buf))
+}
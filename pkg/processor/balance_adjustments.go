@@ -0,0 +1,242 @@
+// Copyright 2022 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package processor
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"time"
+
+	"github.com/coinbase/rosetta-sdk-go/asserter"
+	"github.com/coinbase/rosetta-sdk-go/storage/database"
+	"github.com/coinbase/rosetta-sdk-go/storage/modules"
+	"github.com/coinbase/rosetta-sdk-go/types"
+	"github.com/neilotoole/errgroup"
+)
+
+const (
+	// balanceAdjustmentOperationType is the type of the operations
+	// added to blocks for balance adjustments. These operations are
+	// only seen by BalanceStorage (blocks are stored unmodified).
+	balanceAdjustmentOperationType = "BALANCE_ADJUSTMENT"
+
+	// balanceAdjustmentTransactionHash is the hash of the
+	// transaction that contains balance adjustment operations.
+	balanceAdjustmentTransactionHash = "balance_adjustments"
+)
+
+// BalanceAdjustment is a change in the balance of an account
+// in a block that is not represented by any operation (like
+// staking rewards credited by the protocol at the end of an epoch).
+type BalanceAdjustment struct {
+	Account *types.AccountIdentifier `json:"account_identifier"`
+	Amount  *types.Amount            `json:"amount"`
+}
+
+// BalanceAdjuster computes the balance adjustments of a block.
+// The same adjustments must be returned each time a block is
+// provided (they are reverted when a block is orphaned).
+type BalanceAdjuster interface {
+	BalanceAdjustments(
+		ctx context.Context,
+		block *types.Block,
+	) ([]*BalanceAdjustment, error)
+}
+
+var _ modules.BlockWorker = (*BalanceAdjustmentWorker)(nil)
+
+// BalanceAdjustmentWorker implements the modules.BlockWorker
+// interface. It adds the balance adjustments of each block as
+// operations to the block provided to BalanceStorage, so they
+// are applied (and reconciled) like any other balance change.
+type BalanceAdjustmentWorker struct {
+	balanceStorage modules.BlockWorker
+	adjuster       BalanceAdjuster
+	status         string
+}
+
+// NewBalanceAdjustmentWorker returns a new *BalanceAdjustmentWorker
+// that must be added to the block workers instead of balanceStorage.
+// Adjustment operations have status (which must be successful).
+func NewBalanceAdjustmentWorker(
+	balanceStorage modules.BlockWorker,
+	adjuster BalanceAdjuster,
+	status string,
+) *BalanceAdjustmentWorker {
+	return &BalanceAdjustmentWorker{
+		balanceStorage: balanceStorage,
+		adjuster:       adjuster,
+		status:         status,
+	}
+}
+
+// adjust returns a copy of block with a transaction
+// containing its balance adjustments (if any).
+func (w *BalanceAdjustmentWorker) adjust(
+	ctx context.Context,
+	block *types.Block,
+) (*types.Block, error) {
+	adjustments, err := w.adjuster.BalanceAdjustments(ctx, block)
+	if err != nil {
+		return nil, fmt.Errorf(
+			"%w: unable to get balance adjustments of block %s",
+			err,
+			types.PrintStruct(block.BlockIdentifier),
+		)
+	}
+
+	if len(adjustments) == 0 {
+		return block, nil
+	}
+
+	operations := make([]*types.Operation, len(adjustments))
+	for i, adjustment := range adjustments {
+		operations[i] = &types.Operation{
+			OperationIdentifier: &types.OperationIdentifier{Index: int64(i)},
+			Type:                balanceAdjustmentOperationType,
+			Status:              types.String(w.status),
+			Account:             adjustment.Account,
+			Amount:              adjustment.Amount,
+		}
+	}
+
+	adjusted := *block
+	adjusted.Transactions = append(
+		append([]*types.Transaction{}, block.Transactions...),
+		&types.Transaction{
+			TransactionIdentifier: &types.TransactionIdentifier{
+				Hash: balanceAdjustmentTransactionHash,
+			},
+			Operations: operations,
+		},
+	)
+
+	return &adjusted, nil
+}
+
+// AddingBlock is called by BlockStorage when adding a block.
+func (w *BalanceAdjustmentWorker) AddingBlock(
+	ctx context.Context,
+	g *errgroup.Group,
+	block *types.Block,
+	dbTx database.Transaction,
+) (database.CommitWorker, error) {
+	adjusted, err := w.adjust(ctx, block)
+	if err != nil {
+		return nil, err
+	}
+
+	return w.balanceStorage.AddingBlock(ctx, g, adjusted, dbTx)
+}
+
+// RemovingBlock is called by BlockStorage when removing a block.
+func (w *BalanceAdjustmentWorker) RemovingBlock(
+	ctx context.Context,
+	g *errgroup.Group,
+	block *types.Block,
+	dbTx database.Transaction,
+) (database.CommitWorker, error) {
+	adjusted, err := w.adjust(ctx, block)
+	if err != nil {
+		return nil, err
+	}
+
+	return w.balanceStorage.RemovingBlock(ctx, g, adjusted, dbTx)
+}
+
+var _ BalanceAdjuster = (*HTTPBalanceAdjuster)(nil)
+
+// HTTPBalanceAdjuster is a BalanceAdjuster that posts the
+// network and block identifier of each block to a URL, which
+// responds with the balance adjustments of the block:
+//
+//	{"adjustments": [{"account_identifier": {...}, "amount": {...}}]}
+type HTTPBalanceAdjuster struct {
+	url     string
+	network *types.NetworkIdentifier
+	client  *http.Client
+}
+
+// NewHTTPBalanceAdjuster returns a new *HTTPBalanceAdjuster.
+func NewHTTPBalanceAdjuster(
+	url string,
+	network *types.NetworkIdentifier,
+	timeout time.Duration,
+) *HTTPBalanceAdjuster {
+	return &HTTPBalanceAdjuster{
+		url:     url,
+		network: network,
+		client:  &http.Client{Timeout: timeout},
+	}
+}
+
+// BalanceAdjustments returns the balance adjustments of block.
+func (a *HTTPBalanceAdjuster) BalanceAdjustments(
+	ctx context.Context,
+	block *types.Block,
+) ([]*BalanceAdjustment, error) {
+	body, err := json.Marshal(map[string]interface{}{
+		"network_identifier": a.network,
+		"block_identifier":   block.BlockIdentifier,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%w: unable to encode balance adjustment request", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, a.url, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("%w: unable to create balance adjustment request", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := a.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("%w: unable to post to balance adjustment url", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		respBody, _ := ioutil.ReadAll(resp.Body)
+		return nil, fmt.Errorf("received %d status with body %s", resp.StatusCode, respBody)
+	}
+
+	var response struct {
+		Adjustments []*BalanceAdjustment `json:"adjustments"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+		return nil, fmt.Errorf("%w: unable to parse balance adjustments", err)
+	}
+
+	for _, adjustment := range response.Adjustments {
+		if adjustment == nil {
+			return nil, errors.New("balance adjustment cannot be nil")
+		}
+
+		if err := asserter.AccountIdentifier(adjustment.Account); err != nil {
+			return nil, fmt.Errorf("%w: invalid balance adjustment account", err)
+		}
+
+		if err := asserter.Amount(adjustment.Amount); err != nil {
+			return nil, fmt.Errorf("%w: invalid balance adjustment amount", err)
+		}
+	}
+
+	return response.Adjustments, nil
+}
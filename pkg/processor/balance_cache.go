@@ -0,0 +1,235 @@
+// Copyright 2022 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package processor
+
+import (
+	"container/list"
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/coinbase/rosetta-sdk-go/storage/database"
+	"github.com/coinbase/rosetta-sdk-go/storage/modules"
+	"github.com/coinbase/rosetta-sdk-go/types"
+	"github.com/neilotoole/errgroup"
+)
+
+var _ modules.BlockWorker = (*BalanceCache)(nil)
+
+// BalanceCache is a size-bounded LRU cache of computed balances
+// (the balance of an account at a block index). A computed balance
+// only changes if a block at or below its index is orphaned, so
+// entries are invalidated when blocks are removed (and not when
+// blocks are added).
+//
+// Each read transaction records the generation of the cache when it
+// is created. Entries are only inserted by transactions created in
+// the current generation and only returned to transactions created
+// in the generation they were inserted in (or later), so a transaction
+// never observes balances from a different side of a reorg than its
+// snapshot of storage. The cache is bypassed while a block
+// removal is being committed.
+type BalanceCache struct {
+	lock       sync.Mutex
+	size       int
+	entries    map[string]*list.Element
+	order      *list.List
+	generation uint64
+	removing   int
+}
+
+type balanceCacheEntry struct {
+	key        string
+	index      int64
+	amount     *types.Amount
+	generation uint64
+}
+
+// NewBalanceCache returns a new *BalanceCache that
+// holds at most size balances.
+func NewBalanceCache(size int) *BalanceCache {
+	return &BalanceCache{
+		size:    size,
+		entries: map[string]*list.Element{},
+		order:   list.New(),
+	}
+}
+
+func balanceCacheKey(
+	account *types.AccountIdentifier,
+	currency *types.Currency,
+	index int64,
+) string {
+	return fmt.Sprintf("%s/%s/%d", types.Hash(account), types.Hash(currency), index)
+}
+
+// copyAmount returns a shallow copy of amount so callers
+// cannot modify cached balances.
+func copyAmount(amount *types.Amount) *types.Amount {
+	return &types.Amount{
+		Value:    amount.Value,
+		Currency: amount.Currency,
+		Metadata: amount.Metadata,
+	}
+}
+
+// balanceCacheTransaction is a read transaction
+// tagged with the generation it was created in.
+type balanceCacheTransaction struct {
+	database.Transaction
+
+	generation uint64
+}
+
+// Transaction returns a read transaction of db
+// tagged with the current generation.
+func (c *BalanceCache) Transaction(
+	ctx context.Context,
+	db database.Database,
+) database.Transaction {
+	c.lock.Lock()
+	generation := c.generation
+	c.lock.Unlock()
+
+	return &balanceCacheTransaction{
+		Transaction: db.ReadTransaction(ctx),
+		generation:  generation,
+	}
+}
+
+// Get returns the cached balance of an account at index
+// (if it can be observed by dbTx).
+func (c *BalanceCache) Get(
+	dbTx database.Transaction,
+	account *types.AccountIdentifier,
+	currency *types.Currency,
+	index int64,
+) (*types.Amount, bool) {
+	tagged, ok := dbTx.(*balanceCacheTransaction)
+	if !ok {
+		return nil, false
+	}
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if c.removing > 0 {
+		return nil, false
+	}
+
+	element, ok := c.entries[balanceCacheKey(account, currency, index)]
+	if !ok {
+		return nil, false
+	}
+
+	entry := element.Value.(*balanceCacheEntry)
+	if entry.generation > tagged.generation {
+		return nil, false
+	}
+
+	c.order.MoveToFront(element)
+	return copyAmount(entry.amount), true
+}
+
+// Set caches the balance of an account at index (if dbTx
+// was created in the current generation).
+func (c *BalanceCache) Set(
+	dbTx database.Transaction,
+	account *types.AccountIdentifier,
+	currency *types.Currency,
+	index int64,
+	amount *types.Amount,
+) {
+	tagged, ok := dbTx.(*balanceCacheTransaction)
+	if !ok {
+		return
+	}
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if c.removing > 0 || tagged.generation != c.generation {
+		return
+	}
+
+	key := balanceCacheKey(account, currency, index)
+	if element, ok := c.entries[key]; ok {
+		c.order.MoveToFront(element)
+		return
+	}
+
+	c.entries[key] = c.order.PushFront(&balanceCacheEntry{
+		key:        key,
+		index:      index,
+		amount:     copyAmount(amount),
+		generation: c.generation,
+	})
+
+	if c.order.Len() > c.size {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*balanceCacheEntry).key)
+	}
+}
+
+// invalidate removes all balances at or above index
+// and starts a new generation. invalidate must be
+// called while holding the lock.
+func (c *BalanceCache) invalidate(index int64) {
+	c.generation++
+	for element := c.order.Front(); element != nil; {
+		next := element.Next()
+		entry := element.Value.(*balanceCacheEntry)
+		if entry.index >= index {
+			c.order.Remove(element)
+			delete(c.entries, entry.key)
+		}
+
+		element = next
+	}
+}
+
+// AddingBlock is called by BlockStorage when adding a block.
+func (c *BalanceCache) AddingBlock(
+	ctx context.Context,
+	g *errgroup.Group,
+	block *types.Block,
+	transaction database.Transaction,
+) (database.CommitWorker, error) {
+	return nil, nil
+}
+
+// RemovingBlock is called by BlockStorage when removing a block.
+// The cache is disabled until the removal is committed.
+func (c *BalanceCache) RemovingBlock(
+	ctx context.Context,
+	g *errgroup.Group,
+	block *types.Block,
+	transaction database.Transaction,
+) (database.CommitWorker, error) {
+	c.lock.Lock()
+	c.removing++
+	c.invalidate(block.BlockIdentifier.Index)
+	c.lock.Unlock()
+
+	return func(ctx context.Context) error {
+		c.lock.Lock()
+		defer c.lock.Unlock()
+
+		c.invalidate(block.BlockIdentifier.Index)
+		c.removing--
+		return nil
+	}, nil
+}
@@ -0,0 +1,194 @@
+// Copyright 2022 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package processor
+
+import (
+	"encoding/csv"
+	"fmt"
+	"math/big"
+	"os"
+	"path"
+	"strconv"
+	"sync"
+
+	"github.com/coinbase/rosetta-sdk-go/parser"
+	"github.com/coinbase/rosetta-sdk-go/types"
+)
+
+const (
+	// balanceChangesCSVFileMode is the permission
+	// of the balance changes CSV file.
+	balanceChangesCSVFileMode = 0600
+
+	// BalanceChangeAdded is the event of a balance
+	// change applied when a block is added.
+	BalanceChangeAdded = "added"
+
+	// BalanceChangeRemoved is the event of a balance
+	// change reverted when a block is orphaned.
+	BalanceChangeRemoved = "removed"
+)
+
+// balanceChangesCSVHeader is written at the
+// start of a new balance changes CSV file.
+var balanceChangesCSVHeader = []string{
+	"event",
+	"block_index",
+	"block_hash",
+	"transaction_hash",
+	"operation_index",
+	"address",
+	"sub_account",
+	"currency",
+	"delta",
+}
+
+// BalanceChangesCSV writes every balance-changing operation
+// applied to BalanceStorage as a CSV row. Operations are written
+// individually (instead of summed per block) so that each change
+// can be attributed to a transaction.
+type BalanceChangesCSV struct {
+	parser *parser.Parser
+
+	lock   sync.Mutex
+	f      *os.File
+	writer *csv.Writer
+}
+
+// NewBalanceChangesCSV opens (or creates) the CSV file at
+// filePath. Rows are appended to an existing file.
+func NewBalanceChangesCSV(
+	filePath string,
+	parser *parser.Parser,
+) (*BalanceChangesCSV, error) {
+	f, err := os.OpenFile(
+		path.Clean(filePath),
+		os.O_APPEND|os.O_CREATE|os.O_WRONLY,
+		balanceChangesCSVFileMode,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("%w: unable to open balance changes csv %s", err, filePath)
+	}
+
+	info, err := f.Stat()
+	if err != nil {
+		_ = f.Close()
+		return nil, fmt.Errorf("%w: unable to stat balance changes csv %s", err, filePath)
+	}
+
+	writer := csv.NewWriter(f)
+	if info.Size() == 0 {
+		if err := writer.Write(balanceChangesCSVHeader); err != nil {
+			_ = f.Close()
+			return nil, fmt.Errorf("%w: unable to write balance changes csv header", err)
+		}
+	}
+
+	return &BalanceChangesCSV{
+		parser: parser,
+		f:      f,
+		writer: writer,
+	}, nil
+}
+
+// Write writes a row for each balance-changing operation in
+// block. When a block is removed, deltas are negated.
+func (c *BalanceChangesCSV) Write(block *types.Block, removed bool) error {
+	event := BalanceChangeAdded
+	if removed {
+		event = BalanceChangeRemoved
+	}
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	for _, tx := range block.Transactions {
+		for _, op := range tx.Operations {
+			skip, err := c.skipOperation(op)
+			if err != nil {
+				return fmt.Errorf("%w: unable to check operation", err)
+			}
+
+			if skip {
+				continue
+			}
+
+			delta := op.Amount.Value
+			if removed {
+				value, ok := new(big.Int).SetString(delta, 10)
+				if !ok {
+					return fmt.Errorf("%s is not an integer", delta)
+				}
+
+				delta = new(big.Int).Neg(value).String()
+			}
+
+			subAccount := ""
+			if op.Account.SubAccount != nil {
+				subAccount = op.Account.SubAccount.Address
+			}
+
+			if err := c.writer.Write([]string{
+				event,
+				strconv.FormatInt(block.BlockIdentifier.Index, 10),
+				block.BlockIdentifier.Hash,
+				tx.TransactionIdentifier.Hash,
+				strconv.FormatInt(op.OperationIdentifier.Index, 10),
+				op.Account.Address,
+				subAccount,
+				types.CurrencyString(op.Amount.Currency),
+				delta,
+			}); err != nil {
+				return fmt.Errorf("%w: unable to write balance change", err)
+			}
+		}
+	}
+
+	c.writer.Flush()
+	return c.writer.Error()
+}
+
+// skipOperation mirrors the checks the parser performs
+// before considering an operation a balance change.
+func (c *BalanceChangesCSV) skipOperation(op *types.Operation) (bool, error) {
+	if op.Account == nil || op.Amount == nil {
+		return true, nil
+	}
+
+	successful, err := c.parser.Asserter.OperationSuccessful(op)
+	if err != nil {
+		return false, err
+	}
+
+	if !successful {
+		return true, nil
+	}
+
+	return c.parser.ExemptFunc != nil && c.parser.ExemptFunc(op), nil
+}
+
+// Close flushes and closes the CSV file.
+func (c *BalanceChangesCSV) Close() error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.writer.Flush()
+	if err := c.writer.Error(); err != nil {
+		_ = c.f.Close()
+		return err
+	}
+
+	return c.f.Close()
+}
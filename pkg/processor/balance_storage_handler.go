@@ -16,8 +16,14 @@ package processor
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"math"
 	"math/big"
+	"sync"
 
+	"github.com/coinbase/rosetta-cli/configuration"
 	"github.com/coinbase/rosetta-cli/pkg/logger"
 
 	"github.com/coinbase/rosetta-sdk-go/parser"
@@ -39,6 +45,33 @@ type BalanceStorageHandler struct {
 
 	reconcile          bool
 	interestingAccount *types.AccountCurrency
+	balanceChangesCSV  *BalanceChangesCSV
+
+	// balanceStorage is used to look up computed balances.
+	balanceStorage *modules.BalanceStorage
+
+	// balanceThresholds are the minimum computed balances
+	// (by currency hash) of accounts that are reconciled.
+	balanceThresholds map[string]*big.Int
+	queue             *ReconciliationQueue
+
+	// disabledCurrencies are the currencies (by hash)
+	// of accounts that are not reconciled.
+	disabledCurrencies map[string]struct{}
+
+	// priority tracks the changes of accounts
+	// that are reconciled (if not nil).
+	priority *ReconciliationPriority
+
+	// sampleRate is the proportion of changes that are
+	// reconciled (all changes are reconciled if 0).
+	sampleRate float64
+	sampleSeed int64
+
+	// addedAccounts are interesting accounts added
+	// while running (see AddInterestingAccounts).
+	addedLock     sync.Mutex
+	addedAccounts map[string]*types.AccountCurrency
 }
 
 // NewBalanceStorageHandler returns a new *BalanceStorageHandler.
@@ -48,6 +81,7 @@ func NewBalanceStorageHandler(
 	counterStorage *modules.CounterStorage,
 	reconcile bool,
 	interestingAccount *types.AccountCurrency,
+	balanceChangesCSV *BalanceChangesCSV,
 ) *BalanceStorageHandler {
 	return &BalanceStorageHandler{
 		logger:             logger,
@@ -55,7 +89,277 @@ func NewBalanceStorageHandler(
 		counterStorage:     counterStorage,
 		reconcile:          reconcile,
 		interestingAccount: interestingAccount,
+		balanceChangesCSV:  balanceChangesCSV,
+		addedAccounts:      map[string]*types.AccountCurrency{},
+	}
+}
+
+// SetBalanceThresholds restricts reconciliation to accounts with
+// a computed balance (in balanceStorage) of at least the threshold
+// of their currency.
+func (h *BalanceStorageHandler) SetBalanceThresholds(
+	balanceStorage *modules.BalanceStorage,
+	thresholds []*configuration.BalanceThreshold,
+) error {
+	if len(thresholds) == 0 {
+		return nil
+	}
+
+	h.balanceStorage = balanceStorage
+	h.balanceThresholds = map[string]*big.Int{}
+	for _, threshold := range thresholds {
+		minimum, err := types.BigInt(threshold.Minimum)
+		if err != nil {
+			return fmt.Errorf("%w: unable to parse balance threshold", err)
+		}
+
+		h.balanceThresholds[types.Hash(threshold.Currency)] = minimum
+	}
+
+	return nil
+}
+
+// SetCurrencyReconciliation disables reconciliation of accounts
+// in currencies where reconciliation is disabled.
+func (h *BalanceStorageHandler) SetCurrencyReconciliation(
+	configs []*configuration.CurrencyReconciliationConfiguration,
+) {
+	h.disabledCurrencies = map[string]struct{}{}
+	for _, config := range configs {
+		if config.Disabled {
+			h.disabledCurrencies[types.Hash(config.Currency)] = struct{}{}
+		}
+	}
+}
+
+// SetReconciliationPriority sets the *ReconciliationPriority that
+// observes the changes of accounts that are reconciled. Computed
+// balances are read from balanceStorage.
+func (h *BalanceStorageHandler) SetReconciliationPriority(
+	priority *ReconciliationPriority,
+	balanceStorage *modules.BalanceStorage,
+) {
+	h.priority = priority
+	h.balanceStorage = balanceStorage
+}
+
+// observe provides the changes in block to
+// the *ReconciliationPriority (if any).
+func (h *BalanceStorageHandler) observe(
+	ctx context.Context,
+	block *types.BlockIdentifier,
+	changes []*parser.BalanceChange,
+) error {
+	if h.priority == nil {
+		return nil
+	}
+
+	for _, change := range changes {
+		var balance *types.Amount
+		if h.priority.NeedsBalance() {
+			var err error
+			balance, err = h.balanceStorage.GetBalance(
+				ctx,
+				change.Account,
+				change.Currency,
+				block.Index,
+			)
+			if err != nil {
+				return fmt.Errorf(
+					"%w: unable to get balance of %s",
+					err,
+					types.PrintStruct(change.Account),
+				)
+			}
+		}
+
+		if err := h.priority.Observe(&types.AccountCurrency{
+			Account:  change.Account,
+			Currency: change.Currency,
+		}, block.Index, balance); err != nil {
+			return fmt.Errorf("%w: unable to prioritize reconciliation", err)
+		}
+	}
+
+	return nil
+}
+
+// SetSampleRate restricts reconciliation to the proportion rate
+// of changes. Changes are sampled deterministically by hashing
+// them with seed.
+func (h *BalanceStorageHandler) SetSampleRate(rate float64, seed int64) {
+	h.sampleRate = rate
+	h.sampleSeed = seed
+}
+
+// sampled returns a boolean indicating if change
+// (at block) is included in the sample.
+func (h *BalanceStorageHandler) sampled(
+	block *types.BlockIdentifier,
+	change *parser.BalanceChange,
+) bool {
+	if h.sampleRate == 0 || h.sampleRate >= 1 {
+		return true
+	}
+
+	digest := sha256.Sum256([]byte(fmt.Sprintf(
+		"%d/%s/%s/%d",
+		h.sampleSeed,
+		types.Hash(change.Account),
+		types.Hash(change.Currency),
+		block.Index,
+	)))
+	position := float64(binary.BigEndian.Uint64(digest[:8])) / math.MaxUint64
+
+	return position < h.sampleRate
+}
+
+// SetReconciliationQueue sets the *ReconciliationQueue that is
+// notified of changes skipped because of a balance threshold,
+// a disabled currency, or sampling.
+func (h *BalanceStorageHandler) SetReconciliationQueue(queue *ReconciliationQueue) {
+	h.queue = queue
+}
+
+// reconcilable removes all changes that are not sampled and all
+// changes of accounts in a disabled currency or with a computed
+// balance below the threshold of their currency.
+func (h *BalanceStorageHandler) reconcilable(
+	ctx context.Context,
+	block *types.BlockIdentifier,
+	changes []*parser.BalanceChange,
+) ([]*parser.BalanceChange, error) {
+	if len(h.balanceThresholds) == 0 && len(h.disabledCurrencies) == 0 && h.sampleRate == 0 {
+		return changes, nil
 	}
+
+	filtered := []*parser.BalanceChange{}
+	for _, change := range changes {
+		reconcile, err := h.shouldReconcile(ctx, block, change)
+		if err != nil {
+			return nil, err
+		}
+
+		if reconcile {
+			filtered = append(filtered, change)
+			continue
+		}
+
+		// The change will never be reconciled, so it should
+		// not be queued again if check:data restarts.
+		if h.queue != nil {
+			h.queue.Reconciled(change.Account, change.Currency, block)
+		}
+	}
+
+	return filtered, nil
+}
+
+// shouldReconcile returns a boolean indicating if change is
+// sampled, is in an enabled currency, and its account has a
+// computed balance of at least the threshold of the currency.
+func (h *BalanceStorageHandler) shouldReconcile(
+	ctx context.Context,
+	block *types.BlockIdentifier,
+	change *parser.BalanceChange,
+) (bool, error) {
+	if !h.sampled(block, change) {
+		return false, nil
+	}
+
+	currencyKey := types.Hash(change.Currency)
+	if _, ok := h.disabledCurrencies[currencyKey]; ok {
+		return false, nil
+	}
+
+	minimum, ok := h.balanceThresholds[currencyKey]
+	if !ok {
+		return true, nil
+	}
+
+	balance, err := h.balanceStorage.GetBalance(
+		ctx,
+		change.Account,
+		change.Currency,
+		block.Index,
+	)
+	if err != nil {
+		return false, fmt.Errorf(
+			"%w: unable to get balance of %s",
+			err,
+			types.PrintStruct(change.Account),
+		)
+	}
+
+	value, err := types.AmountValue(balance)
+	if err != nil {
+		return false, fmt.Errorf("%w: unable to parse balance", err)
+	}
+
+	return value.Cmp(minimum) >= 0, nil
+}
+
+// AddInterestingAccounts adds accounts that are checked on each
+// block (in addition to the interesting accounts provided to the
+// reconciler) and returns the number of accounts that were not
+// already added.
+func (h *BalanceStorageHandler) AddInterestingAccounts(
+	accounts []*types.AccountCurrency,
+) int {
+	h.addedLock.Lock()
+	defer h.addedLock.Unlock()
+
+	added := 0
+	for _, account := range accounts {
+		key := types.Hash(account)
+		if _, ok := h.addedAccounts[key]; ok {
+			continue
+		}
+
+		h.addedAccounts[key] = account
+		added++
+	}
+
+	return added
+}
+
+// withAddedAccounts appends a change with a difference of 0 for
+// each added interesting account that did not change in block so
+// that it is reconciled (just like the reconciler does for the
+// interesting accounts it was created with).
+func (h *BalanceStorageHandler) withAddedAccounts(
+	block *types.BlockIdentifier,
+	changes []*parser.BalanceChange,
+) []*parser.BalanceChange {
+	h.addedLock.Lock()
+	defer h.addedLock.Unlock()
+
+	if len(h.addedAccounts) == 0 {
+		return changes
+	}
+
+	changed := map[string]struct{}{}
+	for _, change := range changes {
+		changed[types.Hash(&types.AccountCurrency{
+			Account:  change.Account,
+			Currency: change.Currency,
+		})] = struct{}{}
+	}
+
+	for key, account := range h.addedAccounts {
+		if _, ok := changed[key]; ok {
+			continue
+		}
+
+		changes = append(changes, &parser.BalanceChange{
+			Account:    account.Account,
+			Currency:   account.Currency,
+			Difference: "0",
+			Block:      block,
+		})
+	}
+
+	return changes
 }
 
 // BlockAdded is called whenever a block is committed to BlockStorage.
@@ -66,6 +370,12 @@ func (h *BalanceStorageHandler) BlockAdded(
 ) error {
 	_ = h.logger.BalanceStream(ctx, changes)
 
+	if h.balanceChangesCSV != nil {
+		if err := h.balanceChangesCSV.Write(block, false); err != nil {
+			return fmt.Errorf("%w: unable to export balance changes", err)
+		}
+	}
+
 	// When testing, it can be useful to not run any reconciliations to just check
 	// if blocks are well formatted and balances don't go negative.
 	if !h.reconcile {
@@ -92,6 +402,18 @@ func (h *BalanceStorageHandler) BlockAdded(
 		} else {
 			changes = []*parser.BalanceChange{}
 		}
+	} else {
+		var err error
+		changes, err = h.reconcilable(ctx, block.BlockIdentifier, changes)
+		if err != nil {
+			return err
+		}
+
+		if err := h.observe(ctx, block.BlockIdentifier, changes); err != nil {
+			return err
+		}
+
+		changes = h.withAddedAccounts(block.BlockIdentifier, changes)
 	}
 
 	// Mark accounts for reconciliation...this may be
@@ -107,6 +429,12 @@ func (h *BalanceStorageHandler) BlockRemoved(
 ) error {
 	_ = h.logger.BalanceStream(ctx, changes)
 
+	if h.balanceChangesCSV != nil {
+		if err := h.balanceChangesCSV.Write(block, true); err != nil {
+			return fmt.Errorf("%w: unable to export balance changes", err)
+		}
+	}
+
 	// We only attempt to reconciler changes when blocks are added,
 	// not removed
 	return nil
@@ -41,6 +41,9 @@ type BalanceStorageHelper struct {
 	// Configuration settings
 	lookupBalanceByBlock bool
 	exemptAccounts       map[string]struct{}
+	exemptOperationTypes map[string]struct{}
+	ignoreSubAccounts    bool
+	negativeAccounts     map[string]struct{}
 	balanceExemptions    []*types.BalanceExemption
 	initialFetchDisabled bool
 
@@ -136,9 +139,43 @@ func (h *BalanceStorageHelper) AddInterestingAddress(address string) {
 	h.interestingAddresses[address] = struct{}{}
 }
 
+// SetExemptOperationTypes causes operations of opTypes to
+// be ignored when computing balances (for operations that
+// carry an amount but don't move funds).
+func (h *BalanceStorageHelper) SetExemptOperationTypes(opTypes []string) {
+	h.exemptOperationTypes = map[string]struct{}{}
+	for _, opType := range opTypes {
+		h.exemptOperationTypes[opType] = struct{}{}
+	}
+}
+
+// SetIgnoreSubAccounts causes operations on sub-accounts
+// to be ignored when computing balances.
+func (h *BalanceStorageHelper) SetIgnoreSubAccounts(ignore bool) {
+	h.ignoreSubAccounts = ignore
+}
+
+// SetNegativeBalanceAccounts causes operations of accounts
+// that are allowed to go negative to be ignored (their
+// balances are computed by NegativeBalances instead).
+func (h *BalanceStorageHelper) SetNegativeBalanceAccounts(accounts []*types.AccountCurrency) {
+	h.negativeAccounts = map[string]struct{}{}
+	for _, account := range accounts {
+		h.negativeAccounts[types.Hash(account)] = struct{}{}
+	}
+}
+
 // ExemptFunc returns a parser.ExemptOperation.
 func (h *BalanceStorageHelper) ExemptFunc() parser.ExemptOperation {
 	return func(op *types.Operation) bool {
+		if _, exists := h.exemptOperationTypes[op.Type]; exists {
+			return true
+		}
+
+		if h.ignoreSubAccounts && op.Account.SubAccount != nil {
+			return true
+		}
+
 		if h.interestingOnly {
 			if _, exists := h.interestingAddresses[op.Account.Address]; !exists {
 				return true
@@ -150,6 +187,10 @@ func (h *BalanceStorageHelper) ExemptFunc() parser.ExemptOperation {
 			Currency: op.Amount.Currency,
 		})
 
+		if _, exists := h.negativeAccounts[thisAcct]; exists {
+			return true
+		}
+
 		_, exists := h.exemptAccounts[thisAcct]
 		return exists
 	}
@@ -148,3 +148,49 @@ func TestExemptFuncInterestingParsing(t *testing.T) {
 		})
 	}
 }
+
+func TestExemptFuncOperationTypes(t *testing.T) {
+	var tests = map[string]struct {
+		exemptOperationTypes []string
+		exempt               bool
+	}{
+		"no exempt operation types": {
+			exempt: false,
+		},
+		"operation type exempt": {
+			exemptOperationTypes: []string{"Informational", "Fee"},
+			exempt:               true,
+		},
+		"operation type not exempt": {
+			exemptOperationTypes: []string{"Fee"},
+			exempt:               false,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			helper := NewBalanceStorageHelper(
+				nil,
+				nil,
+				nil,
+				false,
+				nil,
+				false,
+				nil,
+				false,
+			)
+			helper.SetExemptOperationTypes(test.exemptOperationTypes)
+
+			result := helper.ExemptFunc()(&types.Operation{
+				Type:    "Informational",
+				Account: opAmountCurrency.Account,
+				Amount: &types.Amount{
+					Value:    "100",
+					Currency: opAmountCurrency.Currency,
+				},
+			})
+
+			assert.Equal(t, test.exempt, result)
+		})
+	}
+}
@@ -0,0 +1,101 @@
+// Copyright 2022 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package processor
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	cliErrs "github.com/coinbase/rosetta-cli/pkg/errors"
+
+	"github.com/coinbase/rosetta-sdk-go/storage/database"
+	"github.com/coinbase/rosetta-sdk-go/storage/modules"
+	"github.com/coinbase/rosetta-sdk-go/types"
+	"github.com/neilotoole/errgroup"
+)
+
+const (
+	blockHashPrefix = "block_hash_index"
+)
+
+var _ modules.BlockWorker = (*BlockHashWorker)(nil)
+
+// BlockHashWorker implements the modules.BlockWorker interface.
+// It keeps an index of block hash to block index for every
+// block in the canonical chain so that we can detect when two
+// distinct block indexes report the same hash. BlockStorage only
+// catches this while the conflicting block has not been pruned,
+// after which the new block silently overwrites the old one.
+type BlockHashWorker struct{}
+
+// NewBlockHashWorker returns a new *BlockHashWorker.
+func NewBlockHashWorker() *BlockHashWorker {
+	return &BlockHashWorker{}
+}
+
+func blockHashKey(hash string) []byte {
+	return []byte(fmt.Sprintf("%s/%s", blockHashPrefix, hash))
+}
+
+// AddingBlock is called by BlockStorage when adding a block.
+func (w *BlockHashWorker) AddingBlock(
+	ctx context.Context,
+	g *errgroup.Group,
+	block *types.Block,
+	dbTx database.Transaction,
+) (database.CommitWorker, error) {
+	key := blockHashKey(block.BlockIdentifier.Hash)
+	exists, val, err := dbTx.Get(ctx, key)
+	if err != nil {
+		return nil, fmt.Errorf("%w: unable to get block hash index", err)
+	}
+
+	if !exists {
+		index := []byte(strconv.FormatInt(block.BlockIdentifier.Index, 10))
+		return nil, dbTx.Set(ctx, key, index, true)
+	}
+
+	existingIndex, err := strconv.ParseInt(string(val), 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("%w: unable to parse block hash index", err)
+	}
+
+	if existingIndex == block.BlockIdentifier.Index {
+		return nil, nil
+	}
+
+	return nil, fmt.Errorf(
+		"%w: %s conflicts with %s",
+		cliErrs.ErrDuplicateBlockHash,
+		types.PrintStruct(block.BlockIdentifier),
+		types.PrintStruct(&types.BlockIdentifier{
+			Index: existingIndex,
+			Hash:  block.BlockIdentifier.Hash,
+		}),
+	)
+}
+
+// RemovingBlock is called by BlockStorage when removing a block.
+// Orphaned blocks are no longer part of the canonical chain,
+// so we remove them from the index.
+func (w *BlockHashWorker) RemovingBlock(
+	ctx context.Context,
+	g *errgroup.Group,
+	block *types.Block,
+	dbTx database.Transaction,
+) (database.CommitWorker, error) {
+	return nil, dbTx.Delete(ctx, blockHashKey(block.BlockIdentifier.Hash))
+}
@@ -16,17 +16,20 @@ package processor
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"math/big"
 	"reflect"
 
 	"github.com/coinbase/rosetta-cli/configuration"
+	"github.com/coinbase/rosetta-cli/pkg/results"
 
 	"github.com/coinbase/rosetta-sdk-go/constructor/coordinator"
 	"github.com/coinbase/rosetta-sdk-go/parser"
 	"github.com/coinbase/rosetta-sdk-go/storage/database"
 	"github.com/coinbase/rosetta-sdk-go/storage/modules"
 	"github.com/coinbase/rosetta-sdk-go/types"
+	"github.com/fatih/color"
 )
 
 var _ modules.BroadcastStorageHandler = (*BroadcastStorageHandler)(nil)
@@ -95,6 +98,10 @@ func (h *BroadcastStorageHandler) TransactionConfirmed(
 		}
 	}
 
+	if err := h.checkSuggestedFee(ctx, dbTx, identifier, intent, observed); err != nil {
+		return err
+	}
+
 	_, _ = h.counterStorage.UpdateTransactional(
 		ctx,
 		dbTx,
@@ -114,6 +121,64 @@ func (h *BroadcastStorageHandler) TransactionConfirmed(
 	return nil
 }
 
+// checkSuggestedFee compares the suggested fee stored at broadcast
+// with the fee observed on-chain, reporting (but not failing on)
+// any mismatch.
+func (h *BroadcastStorageHandler) checkSuggestedFee(
+	ctx context.Context,
+	dbTx database.Transaction,
+	identifier string,
+	intent []*types.Operation,
+	observed []*types.Operation,
+) error {
+	key := suggestedFeeKey(identifier)
+	exists, val, err := dbTx.Get(ctx, key)
+	if err != nil {
+		return fmt.Errorf("%w: unable to get suggested fee", err)
+	}
+
+	if !exists {
+		return nil
+	}
+
+	if err := dbTx.Delete(ctx, key); err != nil {
+		return fmt.Errorf("%w: unable to delete suggested fee", err)
+	}
+
+	if h.config.Construction.SuggestedFeeTolerance == nil {
+		return nil
+	}
+
+	var suggestedFee []*types.Amount
+	if err := json.Unmarshal(val, &suggestedFee); err != nil {
+		return fmt.Errorf("%w: unable to decode suggested fee", err)
+	}
+
+	fee, err := observedFee(h.parser, intent, observed)
+	if err != nil {
+		return fmt.Errorf("%w: unable to compute observed fee", err)
+	}
+
+	mismatch := compareSuggestedFee(
+		suggestedFee,
+		fee,
+		*h.config.Construction.SuggestedFeeTolerance,
+	)
+	if mismatch == nil {
+		return nil
+	}
+
+	color.Yellow("suggested fee mismatch for %s: %s", identifier, mismatch.Error())
+	_, _ = h.counterStorage.UpdateTransactional(
+		ctx,
+		dbTx,
+		results.SuggestedFeeMismatchCounter,
+		big.NewInt(1),
+	)
+
+	return nil
+}
+
 // TransactionStale is called when a transaction has not yet been
 // seen on-chain and is considered stale. This occurs when
 // current block height - last broadcast > staleDepth.
@@ -149,6 +214,10 @@ func (h *BroadcastStorageHandler) BroadcastFailed(
 		big.NewInt(1),
 	)
 
+	if err := dbTx.Delete(ctx, suggestedFeeKey(identifier)); err != nil {
+		return fmt.Errorf("%w: unable to delete suggested fee", err)
+	}
+
 	if err := h.coordinator.BroadcastComplete(
 		ctx,
 		dbTx,
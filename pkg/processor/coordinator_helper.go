@@ -16,10 +16,14 @@ package processor
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"log"
 	"math/big"
+	"sync"
+
+	cliErrs "github.com/coinbase/rosetta-cli/pkg/errors"
 
 	"github.com/coinbase/rosetta-sdk-go/constructor/coordinator"
 	"github.com/coinbase/rosetta-sdk-go/fetcher"
@@ -59,6 +63,15 @@ const (
 
 var _ coordinator.Helper = (*CoordinatorHelper)(nil)
 
+// supportedSignatureTypes are the types.SignatureType that
+// can be produced by the keys.Signer for each types.CurveType.
+var supportedSignatureTypes = map[types.CurveType][]types.SignatureType{
+	types.Secp256k1:    {types.Ecdsa, types.EcdsaRecovery, types.Schnorr1},
+	types.Secp256r1:    {types.Ecdsa},
+	types.Edwards25519: {types.Ed25519},
+	types.Pallas:       {types.SchnorrPoseidon},
+}
+
 // CoordinatorHelper implements the Coordinator.Helper
 // interface.
 type CoordinatorHelper struct {
@@ -78,6 +91,13 @@ type CoordinatorHelper struct {
 	// quiet determines if requests/responses logging
 	// should be silenced.
 	quiet bool
+
+	// metadataFees and intentFees track the suggested fee returned
+	// by /construction/metadata until the resulting transaction is
+	// broadcast, at which point it is persisted with the broadcast.
+	feeLock      sync.Mutex
+	metadataFees map[string][]*types.Amount
+	intentFees   map[string][]*types.Amount
 }
 
 // NewCoordinatorHelper returns a new *CoordinatorHelper.
@@ -106,6 +126,8 @@ func NewCoordinatorHelper(
 		counterStorage:       counterStorage,
 		balanceStorageHelper: balanceStorageHelper,
 		quiet:                quiet,
+		metadataFees:         map[string][]*types.Amount{},
+		intentFees:           map[string][]*types.Amount{},
 	}
 }
 
@@ -224,6 +246,13 @@ func (c *CoordinatorHelper) Metadata(
 		arg{argMetadata, metadata},
 		arg{"suggested_fee", suggestedFee},
 	)
+
+	if len(suggestedFee) > 0 {
+		c.feeLock.Lock()
+		c.metadataFees[types.Hash(metadata)] = suggestedFee
+		c.feeLock.Unlock()
+	}
+
 	return metadata, suggestedFee, nil
 }
 
@@ -258,6 +287,15 @@ func (c *CoordinatorHelper) Payloads(
 		arg{argUnsignedTransaction, res},
 		arg{"payloads", payloads},
 	)
+
+	c.feeLock.Lock()
+	metadataKey := types.Hash(requiredMetadata)
+	if suggestedFee, ok := c.metadataFees[metadataKey]; ok {
+		c.intentFees[types.Hash(intent)] = suggestedFee
+		delete(c.metadataFees, metadataKey)
+	}
+	c.feeLock.Unlock()
+
 	return res, payloads, nil
 }
 
@@ -350,12 +388,125 @@ func (c *CoordinatorHelper) Hash(
 }
 
 // Sign invokes the KeyStorage backend
-// to sign some payloads.
+// to sign some payloads. All produced signatures
+// are verified locally before they are returned.
 func (c *CoordinatorHelper) Sign(
 	ctx context.Context,
 	payloads []*types.SigningPayload,
 ) ([]*types.Signature, error) {
-	return c.keyStorage.Sign(ctx, payloads)
+	for i, payload := range payloads {
+		if err := c.assertSignatureTypeSupported(ctx, i, payload); err != nil {
+			return nil, err
+		}
+	}
+
+	signatures, err := c.keyStorage.Sign(ctx, payloads)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, signature := range signatures {
+		if err := c.verifySignature(ctx, i, payloads[i], signature); err != nil {
+			return nil, err
+		}
+	}
+
+	return signatures, nil
+}
+
+// assertSignatureTypeSupported ensures the types.SignatureType
+// requested in a *types.SigningPayload can be produced by the key
+// of the signing account. This surfaces implementations that advertise
+// one signature type for a curve but expect another.
+func (c *CoordinatorHelper) assertSignatureTypeSupported(
+	ctx context.Context,
+	index int,
+	payload *types.SigningPayload,
+) error {
+	if len(payload.SignatureType) == 0 {
+		return nil
+	}
+
+	keyPair, err := c.keyStorage.Get(ctx, payload.AccountIdentifier)
+	if err != nil {
+		return fmt.Errorf(
+			"%w: unable to get key for %s",
+			err,
+			types.PrintStruct(payload.AccountIdentifier),
+		)
+	}
+
+	curve := keyPair.PublicKey.CurveType
+	for _, sigType := range supportedSignatureTypes[curve] {
+		if sigType == payload.SignatureType {
+			return nil
+		}
+	}
+
+	return fmt.Errorf(
+		"%w: payload %d requests signature type %s but key for %s uses curve %s (supports %s)",
+		cliErrs.ErrSignatureTypeUnsupported,
+		index,
+		payload.SignatureType,
+		types.PrintStruct(payload.AccountIdentifier),
+		curve,
+		types.PrintStruct(supportedSignatureTypes[curve]),
+	)
+}
+
+// verifySignature verifies a *types.Signature against the public key
+// of the signing account and the requested types.SignatureType.
+func (c *CoordinatorHelper) verifySignature(
+	ctx context.Context,
+	index int,
+	payload *types.SigningPayload,
+	signature *types.Signature,
+) error {
+	if len(payload.SignatureType) > 0 && signature.SignatureType != payload.SignatureType {
+		return fmt.Errorf(
+			"%w: payload %d requested signature type %s but signature is %s",
+			cliErrs.ErrSignatureVerificationFailed,
+			index,
+			payload.SignatureType,
+			signature.SignatureType,
+		)
+	}
+
+	keyPair, err := c.keyStorage.Get(ctx, payload.AccountIdentifier)
+	if err != nil {
+		return fmt.Errorf(
+			"%w: unable to get key for %s",
+			err,
+			types.PrintStruct(payload.AccountIdentifier),
+		)
+	}
+
+	if types.Hash(signature.PublicKey) != types.Hash(keyPair.PublicKey) {
+		return fmt.Errorf(
+			"%w: signature %d public key %s does not match declared public key %s",
+			cliErrs.ErrSignatureVerificationFailed,
+			index,
+			types.PrintStruct(signature.PublicKey),
+			types.PrintStruct(keyPair.PublicKey),
+		)
+	}
+
+	signer, err := keyPair.Signer()
+	if err != nil {
+		return fmt.Errorf("%w: unable to create signer", err)
+	}
+
+	if err := signer.Verify(signature); err != nil {
+		return fmt.Errorf(
+			"%w: signature %d for %s: %s",
+			cliErrs.ErrSignatureVerificationFailed,
+			index,
+			types.PrintStruct(payload.AccountIdentifier),
+			err.Error(),
+		)
+	}
+
+	return nil
 }
 
 // GetKey is called to get the *types.KeyPair
@@ -482,6 +633,24 @@ func (c *CoordinatorHelper) Broadcast(
 		arg{argNetworkTransaction, payload},
 		arg{argMetadata, transactionMetadata},
 	)
+
+	c.feeLock.Lock()
+	intentKey := types.Hash(intent)
+	suggestedFee, ok := c.intentFees[intentKey]
+	delete(c.intentFees, intentKey)
+	c.feeLock.Unlock()
+
+	if ok {
+		val, err := json.Marshal(suggestedFee)
+		if err != nil {
+			return fmt.Errorf("%w: unable to encode suggested fee", err)
+		}
+
+		if err := dbTx.Set(ctx, suggestedFeeKey(identifier), val, true); err != nil {
+			return fmt.Errorf("%w: unable to store suggested fee", err)
+		}
+	}
+
 	return c.broadcastStorage.Broadcast(
 		ctx,
 		dbTx,
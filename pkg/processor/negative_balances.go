@@ -0,0 +1,221 @@
+// Copyright 2022 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package processor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/coinbase/rosetta-sdk-go/parser"
+	"github.com/coinbase/rosetta-sdk-go/reconciler"
+	"github.com/coinbase/rosetta-sdk-go/storage/database"
+	storageErrs "github.com/coinbase/rosetta-sdk-go/storage/errors"
+	"github.com/coinbase/rosetta-sdk-go/storage/modules"
+	"github.com/coinbase/rosetta-sdk-go/types"
+	"github.com/neilotoole/errgroup"
+)
+
+const (
+	negativeBalancePrefix = "negative_balance"
+)
+
+var (
+	_ modules.BlockWorker = (*NegativeBalances)(nil)
+
+	errNegativeBalanceFound = errors.New("negative balance found")
+)
+
+// NegativeBalances implements the modules.BlockWorker interface.
+// It computes the balances of accounts that are allowed to go
+// negative (which BalanceStorage rejects) by storing each balance
+// as a signed value at every block where it changes. The operations
+// of these accounts must be exempted from BalanceStorage.
+type NegativeBalances struct {
+	helper     *BalanceStorageHelper
+	parser     *parser.Parser
+	accounts   map[string]struct{}
+	reconciler *reconciler.Reconciler
+}
+
+// NewNegativeBalances returns a new *NegativeBalances for
+// accounts. Initial balances are fetched with helper.
+func NewNegativeBalances(
+	helper *BalanceStorageHelper,
+	accounts []*types.AccountCurrency,
+) *NegativeBalances {
+	n := &NegativeBalances{
+		helper:   helper,
+		accounts: map[string]struct{}{},
+	}
+	for _, account := range accounts {
+		n.accounts[types.Hash(account)] = struct{}{}
+	}
+
+	n.parser = parser.New(
+		helper.Asserter(),
+		func(op *types.Operation) bool {
+			return !n.Allowed(op.Account, op.Amount.Currency)
+		},
+		nil,
+	)
+
+	return n
+}
+
+// SetReconciler queues the balance changes of
+// each block for active reconciliation in r.
+func (n *NegativeBalances) SetReconciler(r *reconciler.Reconciler) {
+	n.reconciler = r
+}
+
+// Allowed returns true if the balance of account
+// in currency is allowed to go negative.
+func (n *NegativeBalances) Allowed(
+	account *types.AccountIdentifier,
+	currency *types.Currency,
+) bool {
+	_, ok := n.accounts[types.Hash(&types.AccountCurrency{
+		Account:  account,
+		Currency: currency,
+	})]
+	return ok
+}
+
+func negativeBalancePrefixKey(account *types.AccountIdentifier, currency *types.Currency) []byte {
+	return []byte(fmt.Sprintf(
+		"%s/%s/%s/",
+		negativeBalancePrefix,
+		types.Hash(account),
+		types.Hash(currency),
+	))
+}
+
+func negativeBalanceKey(
+	account *types.AccountIdentifier,
+	currency *types.Currency,
+	index int64,
+) []byte {
+	return append(
+		negativeBalancePrefixKey(account, currency),
+		[]byte(fmt.Sprintf("%020d", index))...,
+	)
+}
+
+// Balance returns the balance of account in currency at
+// index (or storageErrs.ErrAccountMissing if its balance
+// has never changed).
+func (n *NegativeBalances) Balance(
+	ctx context.Context,
+	dbTx database.Transaction,
+	account *types.AccountIdentifier,
+	currency *types.Currency,
+	index int64,
+) (*types.Amount, error) {
+	var value string
+	_, err := dbTx.Scan(
+		ctx,
+		negativeBalancePrefixKey(account, currency),
+		negativeBalanceKey(account, currency, index),
+		func(k []byte, v []byte) error {
+			value = string(v)
+			return errNegativeBalanceFound
+		},
+		false,
+		true,
+	)
+	if errors.Is(err, errNegativeBalanceFound) {
+		return &types.Amount{Value: value, Currency: currency}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: unable to scan balances", err)
+	}
+
+	return nil, storageErrs.ErrAccountMissing
+}
+
+// AddingBlock is called by BlockStorage when adding a block.
+func (n *NegativeBalances) AddingBlock(
+	ctx context.Context,
+	g *errgroup.Group,
+	block *types.Block,
+	dbTx database.Transaction,
+) (database.CommitWorker, error) {
+	changes, err := n.parser.BalanceChanges(ctx, block, false)
+	if err != nil {
+		return nil, fmt.Errorf("%w: unable to calculate balance changes", err)
+	}
+
+	for _, change := range changes {
+		existing, err := n.Balance(
+			ctx,
+			dbTx,
+			change.Account,
+			change.Currency,
+			block.BlockIdentifier.Index-1,
+		)
+		if errors.Is(err, storageErrs.ErrAccountMissing) {
+			existing, err = n.helper.AccountBalance(
+				ctx,
+				change.Account,
+				change.Currency,
+				block.ParentBlockIdentifier,
+			)
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		newValue, err := types.AddValues(existing.Value, change.Difference)
+		if err != nil {
+			return nil, err
+		}
+
+		key := negativeBalanceKey(change.Account, change.Currency, block.BlockIdentifier.Index)
+		if err := dbTx.Set(ctx, key, []byte(newValue), true); err != nil {
+			return nil, fmt.Errorf("%w: unable to store balance", err)
+		}
+	}
+
+	if n.reconciler == nil || len(changes) == 0 {
+		return nil, nil
+	}
+
+	return func(ctx context.Context) error {
+		return n.reconciler.QueueChanges(ctx, block.BlockIdentifier, changes)
+	}, nil
+}
+
+// RemovingBlock is called by BlockStorage when removing a block.
+func (n *NegativeBalances) RemovingBlock(
+	ctx context.Context,
+	g *errgroup.Group,
+	block *types.Block,
+	dbTx database.Transaction,
+) (database.CommitWorker, error) {
+	changes, err := n.parser.BalanceChanges(ctx, block, true)
+	if err != nil {
+		return nil, fmt.Errorf("%w: unable to calculate balance changes", err)
+	}
+
+	for _, change := range changes {
+		key := negativeBalanceKey(change.Account, change.Currency, block.BlockIdentifier.Index)
+		if err := dbTx.Delete(ctx, key); err != nil {
+			return nil, fmt.Errorf("%w: unable to remove balance", err)
+		}
+	}
+
+	return nil, nil
+}
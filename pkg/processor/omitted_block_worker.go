@@ -0,0 +1,127 @@
+// Copyright 2022 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package processor
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	cliErrs "github.com/coinbase/rosetta-cli/pkg/errors"
+
+	"github.com/coinbase/rosetta-sdk-go/storage/database"
+	"github.com/coinbase/rosetta-sdk-go/storage/modules"
+	"github.com/coinbase/rosetta-sdk-go/types"
+	"github.com/neilotoole/errgroup"
+)
+
+// maxUnchangedOrphans is the number of times in a row the
+// same block can be orphaned and synced again unchanged
+// before the syncer is considered stuck on an omitted parent.
+const maxUnchangedOrphans = 3
+
+var _ modules.BlockWorker = (*OmittedBlockWorker)(nil)
+
+// OmittedBlockWorker implements the modules.BlockWorker interface.
+// It validates the block indexes skipped by the syncer (omitted by
+// /block). If omitted blocks are disabled, any block whose parent
+// is not the previous index is an error.
+//
+// When a block claims an omitted index as its parent, the syncer
+// orphans the head (the parent does not match), syncs it again
+// unchanged, and repeats forever. If the same block is orphaned
+// and synced again unchanged too many times in a row, it is an
+// error instead.
+type OmittedBlockWorker struct {
+	disabled bool
+
+	lock     sync.Mutex
+	orphaned *types.BlockIdentifier
+	resynced *types.BlockIdentifier
+	repeats  int
+}
+
+// NewOmittedBlockWorker returns a new *OmittedBlockWorker.
+func NewOmittedBlockWorker(disabled bool) *OmittedBlockWorker {
+	return &OmittedBlockWorker{
+		disabled: disabled,
+	}
+}
+
+// AddingBlock is called by BlockStorage when adding a block.
+func (w *OmittedBlockWorker) AddingBlock(
+	ctx context.Context,
+	g *errgroup.Group,
+	block *types.Block,
+	dbTx database.Transaction,
+) (database.CommitWorker, error) {
+	index := block.BlockIdentifier.Index
+	parent := block.ParentBlockIdentifier.Index
+	if w.disabled && parent < index-1 {
+		return nil, fmt.Errorf(
+			"%w: block %d has parent %d",
+			cliErrs.ErrUnexpectedBlockGap,
+			index,
+			parent,
+		)
+	}
+
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	if w.orphaned != nil && types.Hash(w.orphaned) == types.Hash(block.BlockIdentifier) {
+		w.resynced = block.BlockIdentifier
+	} else {
+		w.resynced = nil
+		w.repeats = 0
+	}
+	w.orphaned = nil
+
+	return nil, nil
+}
+
+// RemovingBlock is called by BlockStorage when removing a block.
+func (w *OmittedBlockWorker) RemovingBlock(
+	ctx context.Context,
+	g *errgroup.Group,
+	block *types.Block,
+	dbTx database.Transaction,
+) (database.CommitWorker, error) {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	w.orphaned = block.BlockIdentifier
+	if w.resynced == nil || types.Hash(w.resynced) != types.Hash(block.BlockIdentifier) {
+		w.repeats = 0
+		return nil, nil
+	}
+
+	w.repeats++
+	if w.repeats < maxUnchangedOrphans {
+		return nil, nil
+	}
+
+	err := cliErrs.ErrOmittedParentBlock
+	if w.disabled {
+		err = cliErrs.ErrUnexpectedBlockGap
+	}
+
+	return nil, fmt.Errorf(
+		"%w: block %s was orphaned again after syncing unchanged %d times (the parent of the next block does not match it)",
+		err,
+		types.PrintStruct(block.BlockIdentifier),
+		w.repeats,
+	)
+}
@@ -0,0 +1,94 @@
+// Copyright 2022 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package processor
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	cliErrs "github.com/coinbase/rosetta-cli/pkg/errors"
+
+	"github.com/coinbase/rosetta-sdk-go/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func testBlock(index int64, parent int64) *types.Block {
+	return &types.Block{
+		BlockIdentifier:       &types.BlockIdentifier{Index: index, Hash: "block"},
+		ParentBlockIdentifier: &types.BlockIdentifier{Index: parent, Hash: "parent"},
+	}
+}
+
+func TestOmittedBlockWorker_Gap(t *testing.T) {
+	ctx := context.Background()
+
+	w := NewOmittedBlockWorker(false)
+	_, err := w.AddingBlock(ctx, nil, testBlock(5, 3), nil)
+	assert.NoError(t, err)
+
+	w = NewOmittedBlockWorker(true)
+	_, err = w.AddingBlock(ctx, nil, testBlock(4, 3), nil)
+	assert.NoError(t, err)
+
+	_, err = w.AddingBlock(ctx, nil, testBlock(5, 3), nil)
+	assert.True(t, errors.Is(err, cliErrs.ErrUnexpectedBlockGap))
+}
+
+func TestOmittedBlockWorker_OmittedParent(t *testing.T) {
+	ctx := context.Background()
+	w := NewOmittedBlockWorker(false)
+	block := testBlock(3, 2)
+
+	_, err := w.AddingBlock(ctx, nil, block, nil)
+	assert.NoError(t, err)
+
+	// A block that is orphaned and synced again
+	// unchanged is allowed a few times (a reorg
+	// may still be in progress).
+	for i := 0; i < maxUnchangedOrphans; i++ {
+		_, err = w.RemovingBlock(ctx, nil, block, nil)
+		assert.NoError(t, err)
+
+		_, err = w.AddingBlock(ctx, nil, block, nil)
+		assert.NoError(t, err)
+	}
+
+	_, err = w.RemovingBlock(ctx, nil, block, nil)
+	assert.True(t, errors.Is(err, cliErrs.ErrOmittedParentBlock))
+}
+
+func TestOmittedBlockWorker_Reorg(t *testing.T) {
+	ctx := context.Background()
+	w := NewOmittedBlockWorker(false)
+	block := testBlock(3, 2)
+	next := testBlock(4, 3)
+	next.BlockIdentifier.Hash = "next"
+
+	// Syncing another block resets the count.
+	for i := 0; i < 2*maxUnchangedOrphans; i++ {
+		_, err := w.AddingBlock(ctx, nil, block, nil)
+		assert.NoError(t, err)
+
+		_, err = w.AddingBlock(ctx, nil, next, nil)
+		assert.NoError(t, err)
+
+		_, err = w.RemovingBlock(ctx, nil, next, nil)
+		assert.NoError(t, err)
+
+		_, err = w.RemovingBlock(ctx, nil, block, nil)
+		assert.NoError(t, err)
+	}
+}
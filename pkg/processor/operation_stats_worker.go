@@ -0,0 +1,92 @@
+// Copyright 2022 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package processor
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+
+	"github.com/coinbase/rosetta-cli/pkg/results"
+
+	"github.com/coinbase/rosetta-sdk-go/storage/database"
+	"github.com/coinbase/rosetta-sdk-go/storage/modules"
+	"github.com/coinbase/rosetta-sdk-go/types"
+	"github.com/neilotoole/errgroup"
+)
+
+var _ modules.BlockWorker = (*OperationStatsWorker)(nil)
+
+// OperationStatsWorker implements the modules.BlockWorker interface.
+// It counts the operations of each type in CounterStorage so that
+// sudden changes in the mix of operations (like blocks that stop
+// including fee operations) are visible in check:data stats.
+type OperationStatsWorker struct {
+	counterStorage *modules.CounterStorage
+}
+
+// NewOperationStatsWorker returns a new *OperationStatsWorker.
+func NewOperationStatsWorker(counterStorage *modules.CounterStorage) *OperationStatsWorker {
+	return &OperationStatsWorker{counterStorage: counterStorage}
+}
+
+// AddingBlock is called by BlockStorage when adding a block.
+func (w *OperationStatsWorker) AddingBlock(
+	ctx context.Context,
+	g *errgroup.Group,
+	block *types.Block,
+	dbTx database.Transaction,
+) (database.CommitWorker, error) {
+	return nil, w.update(ctx, block, dbTx, 1)
+}
+
+// RemovingBlock is called by BlockStorage when removing a block.
+func (w *OperationStatsWorker) RemovingBlock(
+	ctx context.Context,
+	g *errgroup.Group,
+	block *types.Block,
+	dbTx database.Transaction,
+) (database.CommitWorker, error) {
+	return nil, w.update(ctx, block, dbTx, -1)
+}
+
+// update adds sign * the number of operations of each
+// type in block to the operation type counters.
+func (w *OperationStatsWorker) update(
+	ctx context.Context,
+	block *types.Block,
+	dbTx database.Transaction,
+	sign int64,
+) error {
+	counts := map[string]int64{}
+	for _, tx := range block.Transactions {
+		for _, op := range tx.Operations {
+			counts[op.Type]++
+		}
+	}
+
+	for opType, count := range counts {
+		if _, err := w.counterStorage.UpdateTransactional(
+			ctx,
+			dbTx,
+			results.OperationTypeCounter(opType),
+			big.NewInt(sign*count),
+		); err != nil {
+			return fmt.Errorf("%w: unable to update %s operation counter", err, opType)
+		}
+	}
+
+	return nil
+}
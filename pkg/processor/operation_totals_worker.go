@@ -0,0 +1,212 @@
+// Copyright 2022 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package processor
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+
+	"github.com/coinbase/rosetta-cli/pkg/results"
+
+	"github.com/coinbase/rosetta-sdk-go/asserter"
+	"github.com/coinbase/rosetta-sdk-go/storage/database"
+	"github.com/coinbase/rosetta-sdk-go/storage/modules"
+	"github.com/coinbase/rosetta-sdk-go/types"
+	"github.com/neilotoole/errgroup"
+)
+
+const (
+	operationTotalsPrefix = "operation_totals"
+)
+
+var _ modules.BlockWorker = (*OperationTotalsWorker)(nil)
+
+// OperationTotalsWorker implements the modules.BlockWorker interface.
+// It keeps the total value moved by successful operations of each
+// type in each currency (reverting totals when blocks are orphaned).
+type OperationTotalsWorker struct {
+	db       database.Database
+	asserter *asserter.Asserter
+}
+
+// NewOperationTotalsWorker returns a new *OperationTotalsWorker.
+func NewOperationTotalsWorker(
+	db database.Database,
+	asserter *asserter.Asserter,
+) *OperationTotalsWorker {
+	return &OperationTotalsWorker{
+		db:       db,
+		asserter: asserter,
+	}
+}
+
+func operationTotalKey(opType string, currency *types.Currency) []byte {
+	return []byte(
+		fmt.Sprintf("%s/%s/%s", operationTotalsPrefix, opType, types.Hash(currency)),
+	)
+}
+
+// AddingBlock is called by BlockStorage when adding a block.
+func (w *OperationTotalsWorker) AddingBlock(
+	ctx context.Context,
+	g *errgroup.Group,
+	block *types.Block,
+	dbTx database.Transaction,
+) (database.CommitWorker, error) {
+	return nil, w.update(ctx, block, dbTx, false)
+}
+
+// RemovingBlock is called by BlockStorage when removing a block.
+func (w *OperationTotalsWorker) RemovingBlock(
+	ctx context.Context,
+	g *errgroup.Group,
+	block *types.Block,
+	dbTx database.Transaction,
+) (database.CommitWorker, error) {
+	return nil, w.update(ctx, block, dbTx, true)
+}
+
+// update adds the value moved by each operation in block
+// to the stored totals (or subtracts it if removed).
+func (w *OperationTotalsWorker) update(
+	ctx context.Context,
+	block *types.Block,
+	dbTx database.Transaction,
+	removed bool,
+) error {
+	changes := map[string]*results.OperationTotal{}
+	for _, tx := range block.Transactions {
+		for _, op := range tx.Operations {
+			if op.Amount == nil {
+				continue
+			}
+
+			successful, err := w.asserter.OperationSuccessful(op)
+			if err != nil {
+				return fmt.Errorf("%w: unable to check operation status", err)
+			}
+
+			if !successful {
+				continue
+			}
+
+			value, ok := new(big.Int).SetString(op.Amount.Value, 10)
+			if !ok {
+				return fmt.Errorf("%s is not an integer", op.Amount.Value)
+			}
+
+			count := int64(1)
+			if removed {
+				value.Neg(value)
+				count = -1
+			}
+
+			key := string(operationTotalKey(op.Type, op.Amount.Currency))
+			change, ok := changes[key]
+			if !ok {
+				change = &results.OperationTotal{
+					Type:     op.Type,
+					Currency: op.Amount.Currency,
+					Value:    "0",
+				}
+				changes[key] = change
+			}
+
+			total, _ := new(big.Int).SetString(change.Value, 10)
+			change.Value = total.Add(total, value).String()
+			change.Count += count
+		}
+	}
+
+	for key, change := range changes {
+		if err := w.apply(ctx, dbTx, []byte(key), change); err != nil {
+			return fmt.Errorf(
+				"%w: unable to update %s total",
+				err,
+				change.Type,
+			)
+		}
+	}
+
+	return nil
+}
+
+// apply adds change to the total stored at key.
+func (w *OperationTotalsWorker) apply(
+	ctx context.Context,
+	dbTx database.Transaction,
+	key []byte,
+	change *results.OperationTotal,
+) error {
+	exists, val, err := dbTx.Get(ctx, key)
+	if err != nil {
+		return err
+	}
+
+	if exists {
+		var stored results.OperationTotal
+		if err := decodeStoredValue(w.db, val, &stored); err != nil {
+			return err
+		}
+
+		total, ok := new(big.Int).SetString(stored.Value, 10)
+		if !ok {
+			return fmt.Errorf("stored total %s is not an integer", stored.Value)
+		}
+
+		delta, _ := new(big.Int).SetString(change.Value, 10)
+		change.Value = total.Add(total, delta).String()
+		change.Count += stored.Count
+	}
+
+	b, err := encodeStoredValue(w.db, change)
+	if err != nil {
+		return err
+	}
+
+	return dbTx.Set(ctx, key, b, true)
+}
+
+// GetAll returns the totals of all operation types
+// and currencies, sorted by operation type.
+func (w *OperationTotalsWorker) GetAll(ctx context.Context) ([]*results.OperationTotal, error) {
+	dbTx := w.db.ReadTransaction(ctx)
+	defer dbTx.Discard(ctx)
+
+	totals := []*results.OperationTotal{}
+	_, err := dbTx.Scan(
+		ctx,
+		[]byte(operationTotalsPrefix),
+		[]byte(operationTotalsPrefix),
+		func(k []byte, v []byte) error {
+			var total results.OperationTotal
+			if err := decodeStoredValue(w.db, v, &total); err != nil {
+				return fmt.Errorf("%w: unable to parse operation total", err)
+			}
+
+			totals = append(totals, &total)
+			return nil
+		},
+		false,
+		false,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("%w: unable to scan operation totals", err)
+	}
+
+	results.SortOperationTotals(totals)
+	return totals, nil
+}
@@ -0,0 +1,129 @@
+// Copyright 2022 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package processor
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"strconv"
+
+	"github.com/coinbase/rosetta-cli/pkg/results"
+
+	"github.com/coinbase/rosetta-sdk-go/storage/database"
+	"github.com/coinbase/rosetta-sdk-go/storage/modules"
+	"github.com/coinbase/rosetta-sdk-go/types"
+	"github.com/neilotoole/errgroup"
+)
+
+const otherTransactionsPrefix = "other_transactions"
+
+var _ modules.BlockWorker = (*OtherTransactionWorker)(nil)
+
+// OtherTransactionWorker implements the modules.BlockWorker interface.
+// It counts the transactions of each block that were fetched with
+// /block/transaction (rather than returned by /block). The count of
+// each block is stored so it can be reverted if the block is orphaned.
+type OtherTransactionWorker struct {
+	counterStorage *modules.CounterStorage
+	fetched        func(block *types.BlockIdentifier) int64
+}
+
+// NewOtherTransactionWorker returns a new *OtherTransactionWorker.
+// fetched returns the number of other transactions fetched
+// for a block.
+func NewOtherTransactionWorker(
+	counterStorage *modules.CounterStorage,
+	fetched func(block *types.BlockIdentifier) int64,
+) *OtherTransactionWorker {
+	return &OtherTransactionWorker{
+		counterStorage: counterStorage,
+		fetched:        fetched,
+	}
+}
+
+func otherTransactionsKey(block *types.BlockIdentifier) []byte {
+	return []byte(fmt.Sprintf("%s/%s", otherTransactionsPrefix, block.Hash))
+}
+
+// AddingBlock is called by BlockStorage when adding a block.
+func (w *OtherTransactionWorker) AddingBlock(
+	ctx context.Context,
+	g *errgroup.Group,
+	block *types.Block,
+	dbTx database.Transaction,
+) (database.CommitWorker, error) {
+	count := w.fetched(block.BlockIdentifier)
+	if count == 0 {
+		return nil, nil
+	}
+
+	if err := dbTx.Set(
+		ctx,
+		otherTransactionsKey(block.BlockIdentifier),
+		[]byte(strconv.FormatInt(count, 10)),
+		true,
+	); err != nil {
+		return nil, fmt.Errorf("%w: unable to store other transaction count", err)
+	}
+
+	return nil, w.update(ctx, dbTx, count)
+}
+
+// RemovingBlock is called by BlockStorage when removing a block.
+func (w *OtherTransactionWorker) RemovingBlock(
+	ctx context.Context,
+	g *errgroup.Group,
+	block *types.Block,
+	dbTx database.Transaction,
+) (database.CommitWorker, error) {
+	key := otherTransactionsKey(block.BlockIdentifier)
+	exists, value, err := dbTx.Get(ctx, key)
+	if err != nil {
+		return nil, fmt.Errorf("%w: unable to get other transaction count", err)
+	}
+
+	if !exists {
+		return nil, nil
+	}
+
+	count, err := strconv.ParseInt(string(value), 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("%w: unable to parse other transaction count", err)
+	}
+
+	if err := dbTx.Delete(ctx, key); err != nil {
+		return nil, fmt.Errorf("%w: unable to delete other transaction count", err)
+	}
+
+	return nil, w.update(ctx, dbTx, -count)
+}
+
+func (w *OtherTransactionWorker) update(
+	ctx context.Context,
+	dbTx database.Transaction,
+	count int64,
+) error {
+	if _, err := w.counterStorage.UpdateTransactional(
+		ctx,
+		dbTx,
+		results.OtherTransactionCounter,
+		big.NewInt(count),
+	); err != nil {
+		return fmt.Errorf("%w: unable to update other transaction counter", err)
+	}
+
+	return nil
+}
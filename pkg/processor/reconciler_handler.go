@@ -21,12 +21,15 @@ import (
 	"sync"
 	"time"
 
+	"github.com/coinbase/rosetta-cli/configuration"
 	"github.com/coinbase/rosetta-cli/pkg/logger"
 	"github.com/coinbase/rosetta-cli/pkg/results"
 
+	"github.com/coinbase/rosetta-sdk-go/parser"
 	"github.com/coinbase/rosetta-sdk-go/reconciler"
 	"github.com/coinbase/rosetta-sdk-go/storage/modules"
 	"github.com/coinbase/rosetta-sdk-go/types"
+	"github.com/fatih/color"
 )
 
 const (
@@ -50,13 +53,47 @@ type ReconcilerHandler struct {
 	logger                    *logger.Logger
 	counterStorage            *modules.CounterStorage
 	balanceStorage            *modules.BalanceStorage
+	history                   *ReconciliationHistory
+	queue                     *ReconciliationQueue
 	haltOnReconciliationError bool
 
+	// failureLimit is the number of distinct accounts
+	// that must fail reconciliation before halting.
+	failureLimit      int
+	failureLock       sync.Mutex
+	failedAccounts    map[string]struct{}
+	toleratedFailures []*ReconciliationAttempt
+
+	// failureReport records each failed reconciliation
+	// (if not nil).
+	failureReport *results.FailureReport
+
+	// reverification checks failed reconciliations
+	// again before they are reported (if not nil).
+	reverification *reverification
+
+	// tolerances are the largest differences between computed
+	// and live balances (by currency hash) that are exempt.
+	tolerances map[string]*big.Int
+
+	// accountExemptions are the balance exemptions of accounts
+	// (by account hash) that the parser can't match.
+	accountExemptions map[string][]*configuration.BalanceExemptionConfiguration
+
+	// reference is queried for the balance of each
+	// failed reconciliation (if not nil).
+	reference *ReferenceBalance
+
 	InactiveFailure      *types.AccountCurrency
 	InactiveFailureBlock *types.BlockIdentifier
 
+	ActiveFailure      *types.AccountCurrency
 	ActiveFailureBlock *types.BlockIdentifier
 
+	// FailureAttempt is the reconciliation that caused
+	// processing to halt (if any).
+	FailureAttempt *ReconciliationAttempt
+
 	counterLock sync.Mutex
 	counts      map[string]int64
 }
@@ -66,6 +103,7 @@ func NewReconcilerHandler(
 	logger *logger.Logger,
 	counterStorage *modules.CounterStorage,
 	balanceStorage *modules.BalanceStorage,
+	history *ReconciliationHistory,
 	haltOnReconciliationError bool,
 ) *ReconcilerHandler {
 	counts := map[string]int64{}
@@ -77,11 +115,222 @@ func NewReconcilerHandler(
 		logger:                    logger,
 		counterStorage:            counterStorage,
 		balanceStorage:            balanceStorage,
+		history:                   history,
 		haltOnReconciliationError: haltOnReconciliationError,
+		failureLimit:              1,
+		failedAccounts:            map[string]struct{}{},
 		counts:                    counts,
 	}
 }
 
+// SetFailureLimit sets the number of distinct accounts (and
+// currencies) that must fail reconciliation before halting (if
+// haltOnReconciliationError is true).
+func (h *ReconcilerHandler) SetFailureLimit(limit int) {
+	if limit < 1 {
+		limit = 1
+	}
+
+	h.failureLimit = limit
+}
+
+// withinFailureLimit records a failed reconciliation and returns a
+// boolean indicating if fewer distinct accounts (and currencies) than
+// the failure limit have failed. Failures within the limit are kept
+// so they are still reported (see ToleratedFailures).
+func (h *ReconcilerHandler) withinFailureLimit(attempt *ReconciliationAttempt) bool {
+	h.failureLock.Lock()
+	defer h.failureLock.Unlock()
+
+	h.failedAccounts[types.Hash(&types.AccountCurrency{
+		Account:  attempt.Account,
+		Currency: attempt.Currency,
+	})] = struct{}{}
+	if len(h.failedAccounts) >= h.failureLimit {
+		return false
+	}
+
+	h.toleratedFailures = append(h.toleratedFailures, attempt)
+	return true
+}
+
+// ToleratedFailures returns the failed reconciliations that did
+// not halt processing because they were within the failure limit
+// (in the order they failed).
+func (h *ReconcilerHandler) ToleratedFailures() []*ReconciliationAttempt {
+	h.failureLock.Lock()
+	defer h.failureLock.Unlock()
+
+	return append([]*ReconciliationAttempt{}, h.toleratedFailures...)
+}
+
+// SetCurrencyReconciliation sets the tolerated difference between
+// computed and live balances in each configured currency.
+func (h *ReconcilerHandler) SetCurrencyReconciliation(
+	configs []*configuration.CurrencyReconciliationConfiguration,
+) error {
+	h.tolerances = map[string]*big.Int{}
+	for _, config := range configs {
+		if len(config.Tolerance) == 0 {
+			continue
+		}
+
+		tolerance, err := types.BigInt(config.Tolerance)
+		if err != nil {
+			return fmt.Errorf("%w: unable to parse tolerance", err)
+		}
+
+		h.tolerances[types.Hash(config.Currency)] = tolerance
+	}
+
+	return nil
+}
+
+// withinTolerance returns a boolean indicating if the difference
+// between computedBalance and liveBalance is within the tolerance
+// of currency.
+func (h *ReconcilerHandler) withinTolerance(
+	currency *types.Currency,
+	computedBalance string,
+	liveBalance string,
+) bool {
+	tolerance, ok := h.tolerances[types.Hash(currency)]
+	if !ok {
+		return false
+	}
+
+	difference, err := types.SubtractValues(computedBalance, liveBalance)
+	if err != nil {
+		return false
+	}
+
+	value, err := types.BigInt(difference)
+	if err != nil {
+		return false
+	}
+
+	return new(big.Int).Abs(value).Cmp(tolerance) <= 0
+}
+
+// SetBalanceExemptions sets the balance exemptions of accounts
+// (exemptions without an account are applied by the parser).
+func (h *ReconcilerHandler) SetBalanceExemptions(
+	exemptions []*configuration.BalanceExemptionConfiguration,
+) {
+	h.accountExemptions = map[string][]*configuration.BalanceExemptionConfiguration{}
+	for _, exemption := range exemptions {
+		if exemption.Account == nil {
+			continue
+		}
+
+		key := types.Hash(exemption.Account)
+		h.accountExemptions[key] = append(h.accountExemptions[key], exemption)
+	}
+}
+
+// accountExemption returns the balance exemption of account
+// and currency that allows the difference between computedBalance
+// and liveBalance (if any).
+func (h *ReconcilerHandler) accountExemption(
+	account *types.AccountIdentifier,
+	currency *types.Currency,
+	computedBalance string,
+	liveBalance string,
+) *types.BalanceExemption {
+	matches := []*types.BalanceExemption{}
+	for _, exemption := range h.accountExemptions[types.Hash(account)] {
+		if exemption.Currency != nil && types.Hash(exemption.Currency) != types.Hash(currency) {
+			continue
+		}
+
+		matches = append(matches, &types.BalanceExemption{
+			Currency:      exemption.Currency,
+			ExemptionType: exemption.ExemptionType,
+		})
+	}
+
+	if len(matches) == 0 {
+		return nil
+	}
+
+	difference, err := types.SubtractValues(liveBalance, computedBalance)
+	if err != nil {
+		return nil
+	}
+
+	return parser.MatchBalanceExemption(matches, difference)
+}
+
+// SetReferenceBalance queries reference for the
+// balance of each failed reconciliation.
+func (h *ReconcilerHandler) SetReferenceBalance(reference *ReferenceBalance) {
+	h.reference = reference
+}
+
+// referenceBalance returns the reference balance of a failed
+// reconciliation (or an empty string if there is no reference
+// or it could not be queried) and prints how it compares.
+func (h *ReconcilerHandler) referenceBalance(
+	ctx context.Context,
+	account *types.AccountIdentifier,
+	currency *types.Currency,
+	computedBalance string,
+	liveBalance string,
+	block *types.BlockIdentifier,
+) string {
+	if h.reference == nil {
+		return ""
+	}
+
+	balance, err := h.reference.Balance(ctx, account, currency, block)
+	if err != nil {
+		color.Yellow(
+			"%s: unable to get reference balance of %s at %d",
+			err.Error(),
+			types.AccountString(account),
+			block.Index,
+		)
+		return ""
+	}
+
+	color.Cyan(
+		"Reference balance of %s at %d is %s (computed: %s, live: %s): %s",
+		types.AccountString(account),
+		block.Index,
+		balance,
+		computedBalance,
+		liveBalance,
+		ReferenceComparison(computedBalance, liveBalance, balance),
+	)
+	return balance
+}
+
+// SetFailureReport sets the *results.FailureReport
+// that each failed reconciliation is recorded in.
+func (h *ReconcilerHandler) SetFailureReport(report *results.FailureReport) {
+	h.failureReport = report
+}
+
+// SetReconciliationQueue sets the *ReconciliationQueue
+// that is notified of each reconciliation.
+func (h *ReconcilerHandler) SetReconciliationQueue(queue *ReconciliationQueue) {
+	h.queue = queue
+}
+
+// dequeue marks a change of account and currency
+// as reconciled in the *ReconciliationQueue (if any).
+func (h *ReconcilerHandler) dequeue(
+	account *types.AccountIdentifier,
+	currency *types.Currency,
+	block *types.BlockIdentifier,
+) {
+	if h.queue == nil {
+		return
+	}
+
+	h.queue.Reconciled(account, currency, block)
+}
+
 // Updater periodically updates modules.with cached counts.
 func (h *ReconcilerHandler) Updater(ctx context.Context) error {
 	tc := time.NewTicker(updateFrequency)
@@ -119,6 +368,37 @@ func (h *ReconcilerHandler) UpdateCounts(ctx context.Context) error {
 	return nil
 }
 
+// recordAttempt stores a reconciliation attempt
+// if reconciliation history is enabled.
+func (h *ReconcilerHandler) recordAttempt(
+	ctx context.Context,
+	reconciliationType string,
+	account *types.AccountIdentifier,
+	currency *types.Currency,
+	computedBalance string,
+	liveBalance string,
+	block *types.BlockIdentifier,
+	outcome string,
+) error {
+	if h.history == nil {
+		return nil
+	}
+
+	if err := h.history.Record(ctx, &ReconciliationAttempt{
+		Type:            reconciliationType,
+		Account:         account,
+		Currency:        currency,
+		Block:           block,
+		ComputedBalance: computedBalance,
+		LiveBalance:     liveBalance,
+		Outcome:         outcome,
+	}); err != nil {
+		return fmt.Errorf("%w: unable to record reconciliation attempt", err)
+	}
+
+	return nil
+}
+
 // ReconciliationFailed is called each time a reconciliation fails.
 // In this Handler implementation, we halt if haltOnReconciliationError
 // was set to true. We also cancel the context.
@@ -131,10 +411,101 @@ func (h *ReconcilerHandler) ReconciliationFailed(
 	liveBalance string,
 	block *types.BlockIdentifier,
 ) error {
+	if h.withinTolerance(currency, computedBalance, liveBalance) {
+		return h.ReconciliationExempt(
+			ctx,
+			reconciliationType,
+			account,
+			currency,
+			computedBalance,
+			liveBalance,
+			block,
+			nil,
+		)
+	}
+
+	if exemption := h.accountExemption(
+		account,
+		currency,
+		computedBalance,
+		liveBalance,
+	); exemption != nil {
+		return h.ReconciliationExempt(
+			ctx,
+			reconciliationType,
+			account,
+			currency,
+			computedBalance,
+			liveBalance,
+			block,
+			exemption,
+		)
+	}
+
+	if h.reverification != nil {
+		balance, liveBlock, passed, err := h.reverify(ctx, account, currency, block)
+		if err != nil {
+			return err
+		}
+
+		if passed {
+			color.Yellow(
+				"%s reconciliation of %s passed on re-verification at %d",
+				reconciliationType,
+				types.AccountString(account),
+				liveBlock.Index,
+			)
+
+			return h.ReconciliationSucceeded(
+				ctx,
+				reconciliationType,
+				account,
+				currency,
+				balance,
+				liveBlock,
+			)
+		}
+	}
+
 	h.counterLock.Lock()
 	h.counts[modules.FailedReconciliationCounter]++
 	h.counterLock.Unlock()
 
+	if err := h.recordAttempt(
+		ctx,
+		reconciliationType,
+		account,
+		currency,
+		computedBalance,
+		liveBalance,
+		block,
+		ReconciliationFailure,
+	); err != nil {
+		return err
+	}
+	h.dequeue(account, currency, block)
+
+	referenceBalance := h.referenceBalance(
+		ctx,
+		account,
+		currency,
+		computedBalance,
+		liveBalance,
+		block,
+	)
+
+	if h.failureReport != nil {
+		h.failureReport.RecordReconciliation(
+			reconciliationType,
+			account,
+			currency,
+			computedBalance,
+			liveBalance,
+			referenceBalance,
+			block,
+		)
+	}
+
 	err := h.logger.ReconcileFailureStream(
 		ctx,
 		reconciliationType,
@@ -148,10 +519,34 @@ func (h *ReconcilerHandler) ReconciliationFailed(
 		return err
 	}
 
+	attempt := &ReconciliationAttempt{
+		Type:             reconciliationType,
+		Account:          account,
+		Currency:         currency,
+		Block:            block,
+		ComputedBalance:  computedBalance,
+		LiveBalance:      liveBalance,
+		ReferenceBalance: referenceBalance,
+		Outcome:          ReconciliationFailure,
+		Timestamp:        time.Now().UnixNano(),
+	}
+	if h.haltOnReconciliationError && h.withinFailureLimit(attempt) {
+		color.Yellow(
+			"continuing after %s reconciliation failure for %s (halting after %d distinct failures)",
+			reconciliationType,
+			types.PrintStruct(account),
+			h.failureLimit,
+		)
+
+		return nil
+	}
+
 	if h.haltOnReconciliationError {
 		// Update counts before exiting
 		_ = h.UpdateCounts(ctx)
 
+		h.FailureAttempt = attempt
+
 		if reconciliationType == reconciler.InactiveReconciliation {
 			// Populate inactive failure information so we can try to find block with
 			// missing ops.
@@ -173,6 +568,10 @@ func (h *ReconcilerHandler) ReconciliationFailed(
 		}
 
 		// If we halt on an active reconciliation error, store in the handler.
+		h.ActiveFailure = &types.AccountCurrency{
+			Account:  account,
+			Currency: currency,
+		}
 		h.ActiveFailureBlock = block
 		return fmt.Errorf(
 			"%w: active reconciliation error for %s at %d (computed: %s%s, live: %s%s)",
@@ -205,6 +604,20 @@ func (h *ReconcilerHandler) ReconciliationExempt(
 	h.counts[modules.ExemptReconciliationCounter]++
 	h.counterLock.Unlock()
 
+	if err := h.recordAttempt(
+		ctx,
+		reconciliationType,
+		account,
+		currency,
+		computedBalance,
+		liveBalance,
+		block,
+		ReconciliationExempt,
+	); err != nil {
+		return err
+	}
+	h.dequeue(account, currency, block)
+
 	// Although the reconciliation was exempt (non-zero difference that was ignored),
 	// we still mark the account as being reconciled because the balance was in the range
 	// specified by exemption.
@@ -227,6 +640,12 @@ func (h *ReconcilerHandler) ReconciliationSkipped(
 	h.counts[modules.SkippedReconciliationsCounter]++
 	h.counterLock.Unlock()
 
+	// Skipped active reconciliations are not retried
+	// by the reconciler.
+	if reconciliationType == reconciler.ActiveReconciliation {
+		h.dequeue(account, currency, nil)
+	}
+
 	return nil
 }
 
@@ -249,6 +668,20 @@ func (h *ReconcilerHandler) ReconciliationSucceeded(
 	h.counts[counter]++
 	h.counterLock.Unlock()
 
+	if err := h.recordAttempt(
+		ctx,
+		reconciliationType,
+		account,
+		currency,
+		balance,
+		balance,
+		block,
+		ReconciliationSuccess,
+	); err != nil {
+		return err
+	}
+	h.dequeue(account, currency, block)
+
 	if err := h.balanceStorage.Reconciled(ctx, account, currency, block); err != nil {
 		return fmt.Errorf("%w: unable to store updated reconciliation", err)
 	}
@@ -0,0 +1,83 @@
+// Copyright 2022 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package processor
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/coinbase/rosetta-cli/pkg/logger"
+	"github.com/coinbase/rosetta-cli/pkg/results"
+
+	"github.com/coinbase/rosetta-sdk-go/reconciler"
+	"github.com/coinbase/rosetta-sdk-go/storage/modules"
+	"github.com/coinbase/rosetta-sdk-go/types"
+	"github.com/coinbase/rosetta-sdk-go/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReconcilerHandler_FailureLimit(t *testing.T) {
+	ctx := context.Background()
+	dir, err := utils.CreateTempDir()
+	assert.NoError(t, err)
+	defer utils.RemoveTempDir(dir)
+
+	db := openQueueTestDatabase(t, dir)
+	defer db.Close(ctx)
+
+	l, err := logger.NewLogger(
+		dir,
+		false,
+		false,
+		false,
+		false,
+		logger.Data,
+		&types.NetworkIdentifier{Blockchain: "bitcoin", Network: "mainnet"},
+	)
+	assert.NoError(t, err)
+
+	h := NewReconcilerHandler(l, modules.NewCounterStorage(db), nil, nil, true)
+	h.SetFailureLimit(2)
+
+	fail := func(address string, index int64) error {
+		return h.ReconciliationFailed(
+			ctx,
+			reconciler.ActiveReconciliation,
+			&types.AccountIdentifier{Address: address},
+			queueTestCurrency,
+			"100",
+			"90",
+			queueTestBlockIdentifier(index),
+		)
+	}
+
+	// Failures of the first account are tolerated but kept.
+	assert.NoError(t, fail("addr1", 1))
+	assert.NoError(t, fail("addr1", 2))
+	tolerated := h.ToleratedFailures()
+	assert.Len(t, tolerated, 2)
+	assert.Equal(t, "addr1", tolerated[0].Account.Address)
+	assert.Equal(t, queueTestBlockIdentifier(2), tolerated[1].Block)
+	assert.Equal(t, ReconciliationFailure, tolerated[1].Outcome)
+	assert.Nil(t, h.FailureAttempt)
+
+	// Processing halts on the failure of a second account.
+	err = fail("addr2", 3)
+	assert.True(t, errors.Is(err, results.ErrReconciliationFailure))
+	assert.Len(t, h.ToleratedFailures(), 2)
+	assert.Equal(t, "addr2", h.FailureAttempt.Account.Address)
+	assert.Equal(t, queueTestBlockIdentifier(3), h.ActiveFailureBlock)
+}
@@ -16,8 +16,10 @@ package processor
 
 import (
 	"context"
+	"fmt"
 
 	"github.com/coinbase/rosetta-cli/configuration"
+	"github.com/coinbase/rosetta-cli/pkg/tracing"
 
 	"github.com/coinbase/rosetta-sdk-go/fetcher"
 	"github.com/coinbase/rosetta-sdk-go/reconciler"
@@ -25,6 +27,7 @@ import (
 	"github.com/coinbase/rosetta-sdk-go/storage/modules"
 	"github.com/coinbase/rosetta-sdk-go/types"
 	"github.com/coinbase/rosetta-sdk-go/utils"
+	"golang.org/x/sync/singleflight"
 )
 
 var _ reconciler.Helper = (*ReconcilerHelper)(nil)
@@ -41,6 +44,22 @@ type ReconcilerHelper struct {
 	blockStorage                *modules.BlockStorage
 	balanceStorage              *modules.BalanceStorage
 	forceInactiveReconciliation *bool
+	tracer                      *tracing.Tracer
+	balanceCache                *BalanceCache
+	subAccounts                 *SubAccountRegistry
+	negativeBalances            *NegativeBalances
+
+	limiter            *reconciliationLimiter
+	inactiveSweepIndex int64
+
+	// currencyLimiters bound the live balances fetched
+	// at once in some currencies (by currency hash).
+	currencyLimiters map[string]*reconciliationLimiter
+
+	// balanceLookups coalesces concurrent live balance lookups
+	// of the same account and currency at the same index (which
+	// happen when an account changes in consecutive blocks).
+	balanceLookups singleflight.Group
 }
 
 // NewReconcilerHelper returns a new ReconcilerHelper.
@@ -52,7 +71,17 @@ func NewReconcilerHelper(
 	blockStorage *modules.BlockStorage,
 	balanceStorage *modules.BalanceStorage,
 	forceInactiveReconciliation *bool,
+	tracer *tracing.Tracer,
 ) *ReconcilerHelper {
+	currencyLimiters := map[string]*reconciliationLimiter{}
+	for _, currencyConfig := range config.Data.CurrencyReconciliation {
+		if currencyConfig.Concurrency > 0 {
+			currencyLimiters[types.Hash(currencyConfig.Currency)] = newReconciliationLimiter(
+				currencyConfig.Concurrency,
+			)
+		}
+	}
+
 	return &ReconcilerHelper{
 		config:                      config,
 		network:                     network,
@@ -61,13 +90,44 @@ func NewReconcilerHelper(
 		blockStorage:                blockStorage,
 		balanceStorage:              balanceStorage,
 		forceInactiveReconciliation: forceInactiveReconciliation,
+		tracer:                      tracer,
+		limiter: newReconciliationLimiter(int64(
+			config.Data.ActiveReconciliationConcurrency +
+				config.Data.InactiveReconciliationConcurrency,
+		)),
+		inactiveSweepIndex: noInactiveSweep,
+		currencyLimiters:   currencyLimiters,
 	}
 }
 
+// SetBalanceCache caches computed balances in cache. The
+// cache must also be added to the block workers of the syncer
+// so that it is invalidated on reorgs.
+func (h *ReconcilerHelper) SetBalanceCache(cache *BalanceCache) {
+	h.balanceCache = cache
+}
+
+// SetNegativeBalances returns the computed balances of accounts
+// that are allowed to go negative from negativeBalances.
+func (h *ReconcilerHelper) SetNegativeBalances(negativeBalances *NegativeBalances) {
+	h.negativeBalances = negativeBalances
+}
+
+// SetSubAccountRegistry includes the live balance of all sub-accounts
+// in registry in the live balance of their parent (when sub-accounts
+// are aggregated into their parent).
+func (h *ReconcilerHelper) SetSubAccountRegistry(registry *SubAccountRegistry) {
+	h.subAccounts = registry
+}
+
 // DatabaseTransaction returns a new read-only database.Transaction.
 func (h *ReconcilerHelper) DatabaseTransaction(
 	ctx context.Context,
 ) database.Transaction {
+	if h.balanceCache != nil {
+		return h.balanceCache.Transaction(ctx, h.database)
+	}
+
 	return h.database.ReadTransaction(ctx)
 }
 
@@ -119,16 +179,107 @@ func (h *ReconcilerHelper) ComputedBalance(
 	currency *types.Currency,
 	index int64,
 ) (*types.Amount, error) {
-	return h.balanceStorage.GetBalanceTransactional(ctx, dbTx, account, currency, index)
+	ctx, span := h.tracer.StartBlockSpan(
+		ctx,
+		"reconcile computed balance",
+		index,
+		tracing.String("account", types.AccountString(account)),
+		tracing.String("currency", types.CurrencyString(currency)),
+	)
+
+	if h.negativeBalances != nil && h.negativeBalances.Allowed(account, currency) {
+		amt, err := h.negativeBalances.Balance(ctx, dbTx, account, currency, index)
+		span.End(err)
+		return amt, err
+	}
+
+	if h.balanceCache != nil {
+		if amt, ok := h.balanceCache.Get(dbTx, account, currency, index); ok {
+			span.End(nil)
+			return amt, nil
+		}
+	}
+
+	amt, err := h.balanceStorage.GetBalanceTransactional(ctx, dbTx, account, currency, index)
+	span.End(err)
+	if err != nil || h.balanceCache == nil {
+		return amt, err
+	}
+
+	// Balances above the head block can still change.
+	head, err := h.blockStorage.GetHeadBlockIdentifierTransactional(ctx, dbTx)
+	if err == nil && index <= head.Index {
+		h.balanceCache.Set(dbTx, account, currency, index, amt)
+	}
+
+	return amt, nil
+}
+
+// liveBalanceResult is the result of a live balance lookup.
+type liveBalanceResult struct {
+	amount *types.Amount
+	block  *types.BlockIdentifier
 }
 
-// LiveBalance returns the live balance of an account.
+// LiveBalance returns the live balance of an account. Concurrent
+// lookups of the same account and currency at the same index
+// share a single request.
 func (h *ReconcilerHelper) LiveBalance(
 	ctx context.Context,
 	account *types.AccountIdentifier,
 	currency *types.Currency,
 	index int64,
 ) (*types.Amount, *types.BlockIdentifier, error) {
+	key := fmt.Sprintf("%s/%s/%d", types.Hash(account), types.Hash(currency), index)
+	result, err, _ := h.balanceLookups.Do(key, func() (interface{}, error) {
+		amt, block, err := h.liveBalance(ctx, account, currency, index)
+		if err != nil {
+			return nil, err
+		}
+
+		return &liveBalanceResult{amount: amt, block: block}, nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	balance := result.(*liveBalanceResult)
+	return &types.Amount{
+		Value:    balance.amount.Value,
+		Currency: balance.amount.Currency,
+		Metadata: balance.amount.Metadata,
+	}, balance.block, nil
+}
+
+// liveBalance fetches the live balance of an account.
+func (h *ReconcilerHelper) liveBalance(
+	ctx context.Context,
+	account *types.AccountIdentifier,
+	currency *types.Currency,
+	index int64,
+) (*types.Amount, *types.BlockIdentifier, error) {
+	// The currency limit is acquired first so that waiting on
+	// it doesn't hold up reconciliations in other currencies.
+	if currencyLimiter, ok := h.currencyLimiters[types.Hash(currency)]; ok {
+		if err := currencyLimiter.acquire(ctx); err != nil {
+			return nil, nil, err
+		}
+		defer currencyLimiter.release()
+	}
+
+	if err := h.limiter.acquire(ctx); err != nil {
+		return nil, nil, err
+	}
+	defer h.limiter.release()
+
+	ctx, span := h.tracer.StartBlockSpan(
+		ctx,
+		"reconcile live balance",
+		index,
+		tracing.String("account", types.AccountString(account)),
+		tracing.String("currency", types.CurrencyString(currency)),
+	)
+
 	amt, block, err := utils.CurrencyBalance(
 		ctx,
 		h.network,
@@ -137,12 +288,61 @@ func (h *ReconcilerHelper) LiveBalance(
 		currency,
 		index,
 	)
+	if err == nil && h.subAccounts != nil {
+		amt, err = h.withSubAccountBalances(ctx, account, currency, amt, block)
+	}
+	span.End(err)
 	if err != nil {
 		return nil, nil, err
 	}
 	return amt, block, nil
 }
 
+// withSubAccountBalances returns amt with the live balance of
+// each sub-account of account at block added to it.
+func (h *ReconcilerHelper) withSubAccountBalances(
+	ctx context.Context,
+	account *types.AccountIdentifier,
+	currency *types.Currency,
+	amt *types.Amount,
+	block *types.BlockIdentifier,
+) (*types.Amount, error) {
+	total := amt.Value
+	for _, subAccount := range h.subAccounts.SubAccounts(account) {
+		subAmt, subBlock, err := utils.CurrencyBalance(
+			ctx,
+			h.network,
+			h.fetcher,
+			subAccount,
+			currency,
+			block.Index,
+		)
+		if err != nil {
+			return nil, fmt.Errorf(
+				"%w: unable to get live balance of sub-account %s",
+				err,
+				types.AccountString(subAccount),
+			)
+		}
+
+		if types.Hash(subBlock) != types.Hash(block) {
+			return nil, fmt.Errorf(
+				"live balance of sub-account %s is at block %s (expected %s)",
+				types.AccountString(subAccount),
+				types.PrintStruct(subBlock),
+				types.PrintStruct(block),
+			)
+		}
+
+		total, err = types.AddValues(total, subAmt.Value)
+		if err != nil {
+			return nil, fmt.Errorf("%w: unable to add sub-account balance", err)
+		}
+	}
+
+	return &types.Amount{Value: total, Currency: currency}, nil
+}
+
 // PruneBalances removes all historical balance states
 // <= some index. This can significantly reduce storage
 // usage in scenarios where historical balances are only
@@ -174,9 +374,16 @@ func (h *ReconcilerHelper) ForceInactiveReconciliation(
 	currency *types.Currency,
 	lastChecked *types.BlockIdentifier,
 ) bool {
-	if h.forceInactiveReconciliation == nil {
+	if h.forceInactiveReconciliation != nil && *h.forceInactiveReconciliation {
+		return true
+	}
+
+	// Force reconciliation of any account that has not been
+	// checked since the last inactive sweep was started.
+	sweepIndex, ok := h.InactiveSweepIndex()
+	if !ok {
 		return false
 	}
 
-	return *h.forceInactiveReconciliation
+	return lastChecked == nil || lastChecked.Index < sweepIndex
 }
@@ -0,0 +1,143 @@
+// Copyright 2022 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package processor
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+)
+
+// noInactiveSweep is the sweep index when
+// no inactive sweep has been requested.
+const noInactiveSweep = -1
+
+// reconciliationLimiter bounds the number of reconciliations
+// fetching live balances at once. Unlike the concurrency of the
+// reconciler, the limit can be adjusted while reconciliation is
+// running.
+type reconciliationLimiter struct {
+	lock  sync.Mutex
+	limit int64
+	inUse int64
+
+	// changed is closed (and replaced) whenever a slot
+	// is released or the limit is changed.
+	changed chan struct{}
+}
+
+func newReconciliationLimiter(limit int64) *reconciliationLimiter {
+	return &reconciliationLimiter{
+		limit:   limit,
+		changed: make(chan struct{}),
+	}
+}
+
+// notify must be called while holding the lock.
+func (l *reconciliationLimiter) notify() {
+	close(l.changed)
+	l.changed = make(chan struct{})
+}
+
+func (l *reconciliationLimiter) acquire(ctx context.Context) error {
+	for {
+		l.lock.Lock()
+		if l.inUse < l.limit {
+			l.inUse++
+			l.lock.Unlock()
+			return nil
+		}
+
+		changed := l.changed
+		l.lock.Unlock()
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-changed:
+		}
+	}
+}
+
+func (l *reconciliationLimiter) release() {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	l.inUse--
+	l.notify()
+}
+
+func (l *reconciliationLimiter) setLimit(limit int64) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	l.limit = limit
+	l.notify()
+}
+
+func (l *reconciliationLimiter) getLimit() int64 {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	return l.limit
+}
+
+// MaxReconciliationConcurrency is the number of reconciler
+// goroutines (active and inactive) and is the largest
+// concurrency that can be set while running.
+func (h *ReconcilerHelper) MaxReconciliationConcurrency() int64 {
+	return int64(h.config.Data.ActiveReconciliationConcurrency +
+		h.config.Data.InactiveReconciliationConcurrency)
+}
+
+// ReconciliationConcurrency returns the number of
+// reconciliations that may fetch live balances at once.
+func (h *ReconcilerHelper) ReconciliationConcurrency() int64 {
+	return h.limiter.getLimit()
+}
+
+// SetReconciliationConcurrency adjusts the number of
+// reconciliations that may fetch live balances at once.
+func (h *ReconcilerHelper) SetReconciliationConcurrency(concurrency int64) error {
+	max := h.MaxReconciliationConcurrency()
+	if concurrency <= 0 || concurrency > max {
+		return fmt.Errorf("concurrency %d must be > 0 and <= %d", concurrency, max)
+	}
+
+	h.limiter.setLimit(concurrency)
+	return nil
+}
+
+// StartInactiveSweep causes all accounts to be inactively
+// reconciled as soon as possible (instead of waiting for
+// the inactive reconciliation frequency), at or after the
+// current head block. The head index is returned.
+func (h *ReconcilerHelper) StartInactiveSweep(ctx context.Context) (int64, error) {
+	head, err := h.blockStorage.GetHeadBlockIdentifier(ctx)
+	if err != nil {
+		return noInactiveSweep, fmt.Errorf("%w: unable to get head block identifier", err)
+	}
+
+	atomic.StoreInt64(&h.inactiveSweepIndex, head.Index)
+	return head.Index, nil
+}
+
+// InactiveSweepIndex returns the head index when the last
+// inactive sweep was started (if any).
+func (h *ReconcilerHelper) InactiveSweepIndex() (int64, bool) {
+	index := atomic.LoadInt64(&h.inactiveSweepIndex)
+	return index, index != noInactiveSweep
+}
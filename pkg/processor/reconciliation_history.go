@@ -0,0 +1,256 @@
+// Copyright 2022 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package processor
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/coinbase/rosetta-sdk-go/storage/database"
+	"github.com/coinbase/rosetta-sdk-go/types"
+	"github.com/coinbase/rosetta-sdk-go/utils"
+)
+
+const (
+	reconciliationHistoryPrefix = "reconciliation_history"
+
+	// ReconciliationSuccess is the outcome of a
+	// reconciliation where computed and live balances matched.
+	ReconciliationSuccess = "success"
+
+	// ReconciliationFailure is the outcome of a
+	// reconciliation where computed and live balances differed.
+	ReconciliationFailure = "failure"
+
+	// ReconciliationExempt is the outcome of a reconciliation
+	// where balances differed but the difference was exempt.
+	ReconciliationExempt = "exempt"
+)
+
+// ReconciliationAttempt is a single reconciliation
+// of an account and currency at some block.
+type ReconciliationAttempt struct {
+	Type             string                   `json:"type"`
+	Account          *types.AccountIdentifier `json:"account_identifier"`
+	Currency         *types.Currency          `json:"currency"`
+	Block            *types.BlockIdentifier   `json:"block_identifier"`
+	ComputedBalance  string                   `json:"computed_balance"`
+	LiveBalance      string                   `json:"live_balance"`
+	ReferenceBalance string                   `json:"reference_balance,omitempty"`
+	Outcome          string                   `json:"outcome"`
+	Timestamp        int64                    `json:"timestamp"`
+}
+
+// ReconciliationHistory persists every *ReconciliationAttempt
+// so that the reconciliation coverage of an account can be
+// demonstrated after a run.
+type ReconciliationHistory struct {
+	db database.Database
+}
+
+// NewReconciliationHistory returns a new *ReconciliationHistory.
+func NewReconciliationHistory(db database.Database) *ReconciliationHistory {
+	return &ReconciliationHistory{db: db}
+}
+
+func reconciliationHistoryAccountPrefix(account *types.AccountIdentifier) []byte {
+	return []byte(fmt.Sprintf("%s/%s/", reconciliationHistoryPrefix, types.Hash(account)))
+}
+
+func reconciliationHistoryKey(attempt *ReconciliationAttempt) []byte {
+	return []byte(fmt.Sprintf(
+		"%s%020d/%020d",
+		reconciliationHistoryAccountPrefix(attempt.Account),
+		attempt.Block.Index,
+		attempt.Timestamp,
+	))
+}
+
+// Record stores a *ReconciliationAttempt.
+func (h *ReconciliationHistory) Record(
+	ctx context.Context,
+	attempt *ReconciliationAttempt,
+) error {
+	if attempt.Timestamp == 0 {
+		attempt.Timestamp = time.Now().UnixNano()
+	}
+
+	val, err := encodeStoredValue(h.db, attempt)
+	if err != nil {
+		return fmt.Errorf("%w: unable to encode reconciliation attempt", err)
+	}
+
+	dbTx := h.db.WriteTransaction(ctx, types.Hash(attempt.Account), false)
+	defer dbTx.Discard(ctx)
+
+	if err := dbTx.Set(ctx, reconciliationHistoryKey(attempt), val, true); err != nil {
+		return fmt.Errorf("%w: unable to store reconciliation attempt", err)
+	}
+
+	return dbTx.Commit(ctx)
+}
+
+// Get returns all *ReconciliationAttempt for an account
+// (in order of increasing block index).
+func (h *ReconciliationHistory) Get(
+	ctx context.Context,
+	account *types.AccountIdentifier,
+) ([]*ReconciliationAttempt, error) {
+	dbTx := h.db.ReadTransaction(ctx)
+	defer dbTx.Discard(ctx)
+
+	prefix := reconciliationHistoryAccountPrefix(account)
+	attempts := []*ReconciliationAttempt{}
+	_, err := dbTx.Scan(
+		ctx,
+		prefix,
+		prefix,
+		func(k []byte, v []byte) error {
+			var attempt ReconciliationAttempt
+			if err := decodeStoredValue(h.db, v, &attempt); err != nil {
+				return fmt.Errorf("%w: unable to decode reconciliation attempt", err)
+			}
+
+			attempts = append(attempts, &attempt)
+			return nil
+		},
+		false,
+		false,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("%w: unable to scan reconciliation history", err)
+	}
+
+	return attempts, nil
+}
+
+// GetRange returns all *ReconciliationAttempt at blocks with
+// an index between startIndex and endIndex (inclusive), in order
+// of increasing block index. Attempts are stored by account, so
+// this scans the entire history.
+func (h *ReconciliationHistory) GetRange(
+	ctx context.Context,
+	startIndex int64,
+	endIndex int64,
+) ([]*ReconciliationAttempt, error) {
+	dbTx := h.db.ReadTransaction(ctx)
+	defer dbTx.Discard(ctx)
+
+	attempts := []*ReconciliationAttempt{}
+	_, err := dbTx.Scan(
+		ctx,
+		[]byte(reconciliationHistoryPrefix),
+		[]byte(reconciliationHistoryPrefix),
+		func(k []byte, v []byte) error {
+			var attempt ReconciliationAttempt
+			if err := decodeStoredValue(h.db, v, &attempt); err != nil {
+				return fmt.Errorf("%w: unable to decode reconciliation attempt", err)
+			}
+
+			if attempt.Block.Index < startIndex || attempt.Block.Index > endIndex {
+				return nil
+			}
+
+			attempts = append(attempts, &attempt)
+			return nil
+		},
+		false,
+		false,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("%w: unable to scan reconciliation history", err)
+	}
+
+	sort.SliceStable(attempts, func(i, j int) bool {
+		if attempts[i].Block.Index != attempts[j].Block.Index {
+			return attempts[i].Block.Index < attempts[j].Block.Index
+		}
+
+		return attempts[i].Timestamp < attempts[j].Timestamp
+	})
+
+	return attempts, nil
+}
+
+// Prune removes all *ReconciliationAttempt at blocks with an
+// index less than minIndex or recorded before minTimestamp (in
+// nanoseconds) and returns the number of attempts removed.
+func (h *ReconciliationHistory) Prune(
+	ctx context.Context,
+	minIndex int64,
+	minTimestamp int64,
+) (int, error) {
+	expired := [][]byte{}
+	dbTx := h.db.ReadTransaction(ctx)
+	_, err := dbTx.Scan(
+		ctx,
+		[]byte(reconciliationHistoryPrefix),
+		[]byte(reconciliationHistoryPrefix),
+		func(k []byte, v []byte) error {
+			var attempt ReconciliationAttempt
+			if err := decodeStoredValue(h.db, v, &attempt); err != nil {
+				return fmt.Errorf("%w: unable to decode reconciliation attempt", err)
+			}
+
+			if attempt.Block.Index < minIndex || attempt.Timestamp < minTimestamp {
+				expired = append(expired, append([]byte{}, k...))
+			}
+
+			return nil
+		},
+		false,
+		false,
+	)
+	dbTx.Discard(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("%w: unable to scan reconciliation history", err)
+	}
+
+	removed := 0
+	for len(expired) > 0 {
+		batch := expired
+		if len(batch) > utils.MaxEntrySizePerTxn {
+			batch = batch[:utils.MaxEntrySizePerTxn]
+		}
+		expired = expired[len(batch):]
+
+		if err := h.remove(ctx, batch); err != nil {
+			return removed, err
+		}
+		removed += len(batch)
+	}
+
+	return removed, nil
+}
+
+// remove deletes keys in a single transaction.
+func (h *ReconciliationHistory) remove(ctx context.Context, keys [][]byte) error {
+	dbTx := h.db.WriteTransaction(ctx, reconciliationHistoryPrefix, false)
+	defer dbTx.Discard(ctx)
+
+	for _, key := range keys {
+		if err := dbTx.Delete(ctx, key); err != nil {
+			return fmt.Errorf("%w: unable to remove reconciliation attempt", err)
+		}
+	}
+
+	if err := dbTx.Commit(ctx); err != nil {
+		return fmt.Errorf("%w: unable to commit removed reconciliation attempts", err)
+	}
+
+	return nil
+}
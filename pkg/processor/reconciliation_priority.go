@@ -0,0 +1,182 @@
+// Copyright 2022 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package processor
+
+import (
+	"math/big"
+	"sort"
+	"sync"
+
+	"github.com/coinbase/rosetta-cli/configuration"
+
+	"github.com/coinbase/rosetta-sdk-go/types"
+	"github.com/coinbase/rosetta-sdk-go/utils"
+)
+
+const (
+	// priorityTrackingMultiplier is the multiple of the
+	// accounts prioritized at once that are tracked.
+	priorityTrackingMultiplier = 10
+
+	// neverPrioritized is the lastPrioritized index of
+	// an account that has not been prioritized.
+	neverPrioritized = -1
+)
+
+type priorityEntry struct {
+	accountCurrency *types.AccountCurrency
+	lastChanged     int64
+	balance         *big.Float
+	lastPrioritized int64
+}
+
+// ReconciliationPriority tracks the accounts that should be
+// reconciled before others (according to a strategy). Only a
+// bounded number of accounts are tracked, so the lowest priority
+// accounts are forgotten when too many accounts change.
+type ReconciliationPriority struct {
+	strategy  configuration.ReconciliationPriorityStrategy
+	accounts  int
+	frequency int64
+
+	lock    sync.Mutex
+	entries map[string]*priorityEntry
+}
+
+// NewReconciliationPriority returns a new *ReconciliationPriority.
+// An account is not returned by Next again until frequency blocks
+// have been added.
+func NewReconciliationPriority(
+	config *configuration.ReconciliationPriorityConfiguration,
+	frequency int64,
+) *ReconciliationPriority {
+	return &ReconciliationPriority{
+		strategy:  config.Strategy,
+		accounts:  config.Accounts,
+		frequency: frequency,
+		entries:   map[string]*priorityEntry{},
+	}
+}
+
+// NeedsBalance returns a boolean indicating if the
+// computed balance must be provided to Observe.
+func (p *ReconciliationPriority) NeedsBalance() bool {
+	return p.strategy == configuration.LargestBalancePriority
+}
+
+// Observe records a change of accountCurrency at index (with the
+// resulting computed balance, if NeedsBalance).
+func (p *ReconciliationPriority) Observe(
+	accountCurrency *types.AccountCurrency,
+	index int64,
+	balance *types.Amount,
+) error {
+	var value *big.Float
+	if balance != nil {
+		amount, err := types.AmountValue(balance)
+		if err != nil {
+			return err
+		}
+
+		value = new(big.Float).SetInt(amount)
+		if accountCurrency.Currency.Decimals > 0 {
+			value = new(big.Float).Quo(value, utils.BigPow10(accountCurrency.Currency.Decimals))
+		}
+	}
+
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	key := types.Hash(accountCurrency)
+	entry, ok := p.entries[key]
+	if !ok {
+		entry = &priorityEntry{
+			accountCurrency: accountCurrency,
+			lastPrioritized: neverPrioritized,
+		}
+		p.entries[key] = entry
+	}
+
+	entry.lastChanged = index
+	entry.balance = value
+
+	if len(p.entries) > p.accounts*priorityTrackingMultiplier {
+		p.evict()
+	}
+
+	return nil
+}
+
+// higher returns a boolean indicating if a has a
+// higher priority than b.
+func (p *ReconciliationPriority) higher(a *priorityEntry, b *priorityEntry) bool {
+	if p.strategy == configuration.LargestBalancePriority &&
+		a.balance != nil && b.balance != nil {
+		if cmp := a.balance.Cmp(b.balance); cmp != 0 {
+			return cmp > 0
+		}
+	}
+
+	return a.lastChanged > b.lastChanged
+}
+
+// sorted returns entries from the highest to the lowest
+// priority. It must be called while holding the lock.
+func (p *ReconciliationPriority) sorted() []*priorityEntry {
+	entries := make([]*priorityEntry, 0, len(p.entries))
+	for _, entry := range p.entries {
+		entries = append(entries, entry)
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		return p.higher(entries[i], entries[j])
+	})
+
+	return entries
+}
+
+// evict forgets the lowest priority accounts until half of the
+// tracked accounts remain (so eviction isn't performed on each
+// change). It must be called while holding the lock.
+func (p *ReconciliationPriority) evict() {
+	entries := p.sorted()
+	for _, entry := range entries[len(entries)/2:] { // nolint:gomnd
+		delete(p.entries, types.Hash(entry.accountCurrency))
+	}
+}
+
+// Next returns the highest priority accounts that have not
+// been returned within frequency blocks of head.
+func (p *ReconciliationPriority) Next(head int64) []*types.AccountCurrency {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	accounts := []*types.AccountCurrency{}
+	for _, entry := range p.sorted() {
+		if len(accounts) == p.accounts {
+			break
+		}
+
+		if entry.lastPrioritized != neverPrioritized &&
+			head-entry.lastPrioritized < p.frequency {
+			continue
+		}
+
+		entry.lastPrioritized = head
+		accounts = append(accounts, entry.accountCurrency)
+	}
+
+	return accounts
+}
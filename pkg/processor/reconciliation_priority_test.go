@@ -0,0 +1,84 @@
+// Copyright 2022 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package processor
+
+import (
+	"testing"
+
+	"github.com/coinbase/rosetta-cli/configuration"
+
+	"github.com/coinbase/rosetta-sdk-go/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReconciliationPriority(t *testing.T) {
+	accounts := []*types.AccountCurrency{
+		{Account: &types.AccountIdentifier{Address: "addr1"}, Currency: feeCurrency},
+		{Account: &types.AccountIdentifier{Address: "addr2"}, Currency: feeCurrency},
+		{Account: &types.AccountIdentifier{Address: "addr3"}, Currency: otherFeeCurrency},
+	}
+
+	t.Run("recent activity", func(t *testing.T) {
+		priority := NewReconciliationPriority(
+			&configuration.ReconciliationPriorityConfiguration{
+				Strategy: configuration.RecentActivityPriority,
+				Accounts: 2,
+			},
+			10,
+		)
+		assert.False(t, priority.NeedsBalance())
+
+		assert.NoError(t, priority.Observe(accounts[0], 1, nil))
+		assert.NoError(t, priority.Observe(accounts[1], 2, nil))
+		assert.NoError(t, priority.Observe(accounts[2], 3, nil))
+
+		assert.Equal(t, []*types.AccountCurrency{accounts[2], accounts[1]}, priority.Next(3))
+
+		// Prioritized accounts are skipped until
+		// the frequency has elapsed.
+		assert.Equal(t, []*types.AccountCurrency{accounts[0]}, priority.Next(4))
+		assert.Equal(t, []*types.AccountCurrency{}, priority.Next(5))
+		assert.Equal(t, []*types.AccountCurrency{accounts[2], accounts[1]}, priority.Next(13))
+	})
+
+	t.Run("largest balance", func(t *testing.T) {
+		priority := NewReconciliationPriority(
+			&configuration.ReconciliationPriorityConfiguration{
+				Strategy: configuration.LargestBalancePriority,
+				Accounts: 2,
+			},
+			10,
+		)
+		assert.True(t, priority.NeedsBalance())
+
+		// 2 BTC
+		assert.NoError(t, priority.Observe(accounts[0], 1, &types.Amount{
+			Value:    "200000000",
+			Currency: feeCurrency,
+		}))
+		// 0.5 BTC
+		assert.NoError(t, priority.Observe(accounts[1], 2, &types.Amount{
+			Value:    "50000000",
+			Currency: feeCurrency,
+		}))
+		// 1 ETH
+		assert.NoError(t, priority.Observe(accounts[2], 3, &types.Amount{
+			Value:    "1000000000000000000",
+			Currency: otherFeeCurrency,
+		}))
+
+		assert.Equal(t, []*types.AccountCurrency{accounts[0], accounts[2]}, priority.Next(3))
+	})
+}
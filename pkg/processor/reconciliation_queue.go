@@ -0,0 +1,308 @@
+// Copyright 2022 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package processor
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/coinbase/rosetta-sdk-go/parser"
+	"github.com/coinbase/rosetta-sdk-go/reconciler"
+	"github.com/coinbase/rosetta-sdk-go/storage/database"
+	"github.com/coinbase/rosetta-sdk-go/storage/modules"
+	"github.com/coinbase/rosetta-sdk-go/types"
+	"github.com/neilotoole/errgroup"
+)
+
+const (
+	reconciliationQueuePrefix = "reconciliation_queue"
+)
+
+var _ modules.BlockWorker = (*ReconciliationQueue)(nil)
+
+// QueuedReconciliation is an account and currency
+// waiting for active reconciliation at some block.
+type QueuedReconciliation struct {
+	Account  *types.AccountIdentifier `json:"account_identifier"`
+	Currency *types.Currency          `json:"currency"`
+	Block    *types.BlockIdentifier   `json:"block_identifier"`
+}
+
+// ReconciliationQueue implements the modules.BlockWorker interface.
+// It stores the balance changes of each block (in the same transaction
+// as the block) until they are reconciled, so that changes queued
+// in the reconciler when `check:data` stops can be queued again
+// when it restarts.
+type ReconciliationQueue struct {
+	db                 database.Database
+	parser             *parser.Parser
+	interestingAccount *types.AccountCurrency
+
+	reconciledLock sync.Mutex
+
+	// reconciled are the changes reconciled since the last
+	// block was added (by key). These are removed in the
+	// transaction of the next block so that reconciling
+	// doesn't require a separate write.
+	reconciled map[string]*QueuedReconciliation
+}
+
+// NewReconciliationQueue returns a new *ReconciliationQueue. Balance
+// changes are calculated like in modules.BalanceStorage (with helper).
+// When interestingAccount is provided, only its changes are stored
+// (like in BalanceStorageHandler).
+func NewReconciliationQueue(
+	db database.Database,
+	helper modules.BalanceStorageHelper,
+	interestingAccount *types.AccountCurrency,
+) *ReconciliationQueue {
+	return &ReconciliationQueue{
+		db: db,
+		parser: parser.New(
+			helper.Asserter(),
+			helper.ExemptFunc(),
+			helper.BalanceExemptions(),
+		),
+		interestingAccount: interestingAccount,
+		reconciled:         map[string]*QueuedReconciliation{},
+	}
+}
+
+func reconciliationQueueKey(account *types.AccountIdentifier, currency *types.Currency) []byte {
+	return []byte(fmt.Sprintf(
+		"%s/%s/%s",
+		reconciliationQueuePrefix,
+		types.Hash(account),
+		types.Hash(currency),
+	))
+}
+
+// AddingBlock is called by BlockStorage when adding a block.
+func (q *ReconciliationQueue) AddingBlock(
+	ctx context.Context,
+	g *errgroup.Group,
+	block *types.Block,
+	dbTx database.Transaction,
+) (database.CommitWorker, error) {
+	if err := q.removeReconciled(ctx, dbTx); err != nil {
+		return nil, err
+	}
+
+	changes, err := q.parser.BalanceChanges(ctx, block, false)
+	if err != nil {
+		return nil, fmt.Errorf("%w: unable to calculate balance changes", err)
+	}
+
+	for _, change := range changes {
+		if q.interestingAccount != nil && types.Hash(&types.AccountCurrency{
+			Account:  change.Account,
+			Currency: change.Currency,
+		}) != types.Hash(q.interestingAccount) {
+			continue
+		}
+
+		b, err := encodeStoredValue(q.db, &QueuedReconciliation{
+			Account:  change.Account,
+			Currency: change.Currency,
+			Block:    block.BlockIdentifier,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		key := reconciliationQueueKey(change.Account, change.Currency)
+		if err := dbTx.Set(ctx, key, b, true); err != nil {
+			return nil, fmt.Errorf("%w: unable to queue reconciliation", err)
+		}
+	}
+
+	return nil, nil
+}
+
+// RemovingBlock is called by BlockStorage when removing a block.
+// Changes queued at an orphaned block are removed (the reconciler
+// skips them).
+func (q *ReconciliationQueue) RemovingBlock(
+	ctx context.Context,
+	g *errgroup.Group,
+	block *types.Block,
+	dbTx database.Transaction,
+) (database.CommitWorker, error) {
+	changes, err := q.parser.BalanceChanges(ctx, block, true)
+	if err != nil {
+		return nil, fmt.Errorf("%w: unable to calculate balance changes", err)
+	}
+
+	for _, change := range changes {
+		key := reconciliationQueueKey(change.Account, change.Currency)
+		queued, err := q.get(ctx, dbTx, key)
+		if err != nil {
+			return nil, err
+		}
+
+		if queued == nil || types.Hash(queued.Block) != types.Hash(block.BlockIdentifier) {
+			continue
+		}
+
+		if err := dbTx.Delete(ctx, key); err != nil {
+			return nil, fmt.Errorf("%w: unable to remove queued reconciliation", err)
+		}
+	}
+
+	return nil, nil
+}
+
+// get returns the *QueuedReconciliation stored at key (if any).
+func (q *ReconciliationQueue) get(
+	ctx context.Context,
+	dbTx database.Transaction,
+	key []byte,
+) (*QueuedReconciliation, error) {
+	exists, val, err := dbTx.Get(ctx, key)
+	if err != nil {
+		return nil, fmt.Errorf("%w: unable to get queued reconciliation", err)
+	}
+
+	if !exists {
+		return nil, nil
+	}
+
+	var queued QueuedReconciliation
+	if err := decodeStoredValue(q.db, val, &queued); err != nil {
+		return nil, fmt.Errorf("%w: unable to parse queued reconciliation", err)
+	}
+
+	return &queued, nil
+}
+
+// Reconciled marks the change of account and currency queued
+// at or before block as reconciled. If block is nil, any queued
+// change is considered reconciled.
+func (q *ReconciliationQueue) Reconciled(
+	account *types.AccountIdentifier,
+	currency *types.Currency,
+	block *types.BlockIdentifier,
+) {
+	q.reconciledLock.Lock()
+	defer q.reconciledLock.Unlock()
+
+	q.reconciled[string(reconciliationQueueKey(account, currency))] = &QueuedReconciliation{
+		Account:  account,
+		Currency: currency,
+		Block:    block,
+	}
+}
+
+// removeReconciled removes all reconciled changes that have
+// not been queued again at a later block.
+func (q *ReconciliationQueue) removeReconciled(
+	ctx context.Context,
+	dbTx database.Transaction,
+) error {
+	q.reconciledLock.Lock()
+	reconciled := q.reconciled
+	q.reconciled = map[string]*QueuedReconciliation{}
+	q.reconciledLock.Unlock()
+
+	for key, done := range reconciled {
+		queued, err := q.get(ctx, dbTx, []byte(key))
+		if err != nil {
+			return err
+		}
+
+		if queued == nil {
+			continue
+		}
+
+		if done.Block != nil && queued.Block.Index > done.Block.Index {
+			continue
+		}
+
+		if err := dbTx.Delete(ctx, []byte(key)); err != nil {
+			return fmt.Errorf("%w: unable to remove queued reconciliation", err)
+		}
+	}
+
+	return nil
+}
+
+// Pending returns all changes waiting for reconciliation,
+// sorted by block index.
+func (q *ReconciliationQueue) Pending(ctx context.Context) ([]*QueuedReconciliation, error) {
+	dbTx := q.db.ReadTransaction(ctx)
+	defer dbTx.Discard(ctx)
+
+	pending := []*QueuedReconciliation{}
+	_, err := dbTx.Scan(
+		ctx,
+		[]byte(reconciliationQueuePrefix),
+		[]byte(reconciliationQueuePrefix),
+		func(k []byte, v []byte) error {
+			var queued QueuedReconciliation
+			if err := decodeStoredValue(q.db, v, &queued); err != nil {
+				return fmt.Errorf("%w: unable to parse queued reconciliation", err)
+			}
+
+			pending = append(pending, &queued)
+			return nil
+		},
+		false,
+		false,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("%w: unable to scan queued reconciliations", err)
+	}
+
+	sort.SliceStable(pending, func(i, j int) bool {
+		return pending[i].Block.Index < pending[j].Block.Index
+	})
+
+	return pending, nil
+}
+
+// Requeue queues all pending changes in r (grouped
+// by block) and returns the number of changes queued.
+// This blocks until r has accepted all changes, so it
+// should be called once r is reconciling.
+func (q *ReconciliationQueue) Requeue(
+	ctx context.Context,
+	r *reconciler.Reconciler,
+) (int, error) {
+	pending, err := q.Pending(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	for i := 0; i < len(pending); {
+		block := pending[i].Block
+		changes := []*parser.BalanceChange{}
+		for ; i < len(pending) && types.Hash(pending[i].Block) == types.Hash(block); i++ {
+			changes = append(changes, &parser.BalanceChange{
+				Account:    pending[i].Account,
+				Currency:   pending[i].Currency,
+				Block:      block,
+				Difference: "0",
+			})
+		}
+
+		if err := r.QueueChanges(ctx, block, changes); err != nil {
+			return 0, fmt.Errorf("%w: unable to queue pending reconciliations", err)
+		}
+	}
+
+	return len(pending), nil
+}
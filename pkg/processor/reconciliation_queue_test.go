@@ -0,0 +1,169 @@
+// Copyright 2022 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package processor
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/coinbase/rosetta-sdk-go/asserter"
+	"github.com/coinbase/rosetta-sdk-go/fetcher"
+	"github.com/coinbase/rosetta-sdk-go/reconciler"
+	"github.com/coinbase/rosetta-sdk-go/storage/database"
+	"github.com/coinbase/rosetta-sdk-go/types"
+	"github.com/coinbase/rosetta-sdk-go/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+var queueTestCurrency = &types.Currency{Symbol: "BTC", Decimals: 8}
+
+func openQueueTestDatabase(t *testing.T, dir string) database.Database {
+	db, err := database.NewBadgerDatabase(
+		context.Background(),
+		dir,
+		database.WithIndexCacheSize(database.TinyIndexCacheSize),
+	)
+	assert.NoError(t, err)
+
+	return db
+}
+
+func newQueueTestQueue(t *testing.T, db database.Database) *ReconciliationQueue {
+	a, err := asserter.NewClientWithOptions(
+		&types.NetworkIdentifier{Blockchain: "bitcoin", Network: "mainnet"},
+		&types.BlockIdentifier{Index: 0, Hash: "block 0"},
+		[]string{"Transfer"},
+		[]*types.OperationStatus{{Status: "Success", Successful: true}},
+		nil,
+		nil,
+		&asserter.Validations{Enabled: false},
+	)
+	assert.NoError(t, err)
+
+	helper := NewBalanceStorageHelper(
+		nil,
+		&fetcher.Fetcher{Asserter: a},
+		nil,
+		false,
+		nil,
+		false,
+		nil,
+		false,
+	)
+
+	return NewReconciliationQueue(db, helper, nil)
+}
+
+// queueTestBlock returns a block at index with
+// a transfer to each of addresses.
+func queueTestBlock(index int64, addresses ...string) *types.Block {
+	ops := []*types.Operation{}
+	for i, address := range addresses {
+		ops = append(ops, &types.Operation{
+			OperationIdentifier: &types.OperationIdentifier{Index: int64(i)},
+			Type:                "Transfer",
+			Status:              types.String("Success"),
+			Account:             &types.AccountIdentifier{Address: address},
+			Amount:              &types.Amount{Value: "10", Currency: queueTestCurrency},
+		})
+	}
+
+	return &types.Block{
+		BlockIdentifier: queueTestBlockIdentifier(index),
+		Transactions: []*types.Transaction{
+			{
+				TransactionIdentifier: &types.TransactionIdentifier{Hash: fmt.Sprintf("tx %d", index)},
+				Operations:            ops,
+			},
+		},
+	}
+}
+
+func queueTestBlockIdentifier(index int64) *types.BlockIdentifier {
+	return &types.BlockIdentifier{Index: index, Hash: fmt.Sprintf("block %d", index)}
+}
+
+func queueTestPending(address string, index int64) *QueuedReconciliation {
+	return &QueuedReconciliation{
+		Account:  &types.AccountIdentifier{Address: address},
+		Currency: queueTestCurrency,
+		Block:    queueTestBlockIdentifier(index),
+	}
+}
+
+func addQueueTestBlock(
+	ctx context.Context,
+	t *testing.T,
+	db database.Database,
+	q *ReconciliationQueue,
+	block *types.Block,
+) {
+	dbTx := db.Transaction(ctx)
+	defer dbTx.Discard(ctx)
+
+	_, err := q.AddingBlock(ctx, nil, block, dbTx)
+	assert.NoError(t, err)
+	assert.NoError(t, dbTx.Commit(ctx))
+}
+
+func TestReconciliationQueue_Reopen(t *testing.T) {
+	ctx := context.Background()
+	dir, err := utils.CreateTempDir()
+	assert.NoError(t, err)
+	defer utils.RemoveTempDir(dir)
+
+	db := openQueueTestDatabase(t, dir)
+	q := newQueueTestQueue(t, db)
+	addQueueTestBlock(ctx, t, db, q, queueTestBlock(1, "addr1", "addr2"))
+	addQueueTestBlock(ctx, t, db, q, queueTestBlock(2, "addr3"))
+
+	// Reconciled changes are removed with the next block.
+	q.Reconciled(
+		&types.AccountIdentifier{Address: "addr1"},
+		queueTestCurrency,
+		queueTestBlockIdentifier(1),
+	)
+	addQueueTestBlock(ctx, t, db, q, queueTestBlock(3))
+	expected := []*QueuedReconciliation{
+		queueTestPending("addr2", 1),
+		queueTestPending("addr3", 2),
+	}
+	pending, err := q.Pending(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, expected, pending)
+	assert.NoError(t, db.Close(ctx))
+
+	// The same changes are pending after reopening.
+	db = openQueueTestDatabase(t, dir)
+	defer db.Close(ctx)
+	q = newQueueTestQueue(t, db)
+	pending, err = q.Pending(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, expected, pending)
+
+	queued, err := q.Requeue(ctx, reconciler.New(nil, nil, nil))
+	assert.NoError(t, err)
+	assert.Equal(t, len(expected), queued)
+
+	// Draining the queue removes all pending changes.
+	for _, change := range expected {
+		q.Reconciled(change.Account, change.Currency, change.Block)
+	}
+	addQueueTestBlock(ctx, t, db, q, queueTestBlock(4))
+	pending, err = q.Pending(ctx)
+	assert.NoError(t, err)
+	assert.Empty(t, pending)
+}
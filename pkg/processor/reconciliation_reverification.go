@@ -0,0 +1,162 @@
+// Copyright 2022 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package processor
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/coinbase/rosetta-cli/configuration"
+
+	"github.com/coinbase/rosetta-sdk-go/reconciler"
+	"github.com/coinbase/rosetta-sdk-go/types"
+)
+
+const (
+	// reverificationPollInterval is the interval at which the
+	// head block is checked while waiting to re-verify.
+	reverificationPollInterval = 1 * time.Second
+
+	// reverificationMaxWait is the longest we wait for blocks
+	// to be added before re-verifying anyway (syncing may have
+	// stopped, for example when draining the reconciler queue).
+	reverificationMaxWait = 5 * time.Minute
+
+	// currentBalanceIndex is provided to LiveBalance
+	// to fetch the current balance.
+	currentBalanceIndex = -1
+)
+
+// reverification checks failed reconciliations again
+// before they are reported.
+type reverification struct {
+	config               *configuration.ReverificationConfiguration
+	reconciler           *reconciler.Reconciler
+	helper               *ReconcilerHelper
+	lookupBalanceByBlock bool
+}
+
+// SetReverification causes each failed reconciliation to be checked
+// again (as configured) with helper and r before it is reported.
+func (h *ReconcilerHandler) SetReverification(
+	config *configuration.ReverificationConfiguration,
+	r *reconciler.Reconciler,
+	helper *ReconcilerHelper,
+	lookupBalanceByBlock bool,
+) {
+	if config == nil {
+		return
+	}
+
+	h.reverification = &reverification{
+		config:               config,
+		reconciler:           r,
+		helper:               helper,
+		lookupBalanceByBlock: lookupBalanceByBlock,
+	}
+}
+
+// waitForBlock blocks until the head block index is
+// at least index (or reverificationMaxWait elapses).
+func (h *ReconcilerHelper) waitForBlock(ctx context.Context, index int64) error {
+	tc := time.NewTicker(reverificationPollInterval)
+	defer tc.Stop()
+
+	deadline := time.Now().Add(reverificationMaxWait)
+	for time.Now().Before(deadline) {
+		head, err := h.blockStorage.GetHeadBlockIdentifier(ctx)
+		if err != nil {
+			return fmt.Errorf("%w: unable to get head block identifier", err)
+		}
+
+		if head.Index >= index {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-tc.C:
+		}
+	}
+
+	log.Printf("head block did not reach %d, re-verifying anyway\n", index)
+	return nil
+}
+
+// reverify fetches the live balance of account in currency again
+// and compares it with the computed balance (up to the configured
+// number of attempts). If they match, the balance and the block
+// it was compared at are returned.
+func (h *ReconcilerHandler) reverify(
+	ctx context.Context,
+	account *types.AccountIdentifier,
+	currency *types.Currency,
+	block *types.BlockIdentifier,
+) (string, *types.BlockIdentifier, bool, error) {
+	v := h.reverification
+	lookupIndex := int64(currentBalanceIndex)
+	if v.lookupBalanceByBlock {
+		lookupIndex = block.Index
+	}
+
+	waitIndex := block.Index
+	for attempt := 1; attempt <= v.config.Attempts; attempt++ {
+		waitIndex += v.config.WaitBlocks
+		if err := v.helper.waitForBlock(ctx, waitIndex); err != nil {
+			return "", nil, false, err
+		}
+
+		live, liveBlock, err := v.helper.LiveBalance(ctx, account, currency, lookupIndex)
+		if err != nil {
+			if ctx.Err() != nil {
+				return "", nil, false, ctx.Err()
+			}
+
+			log.Printf(
+				"%s: unable to re-verify reconciliation of %s (attempt %d)\n",
+				err.Error(),
+				types.AccountString(account),
+				attempt,
+			)
+			continue
+		}
+
+		difference, _, _, err := v.reconciler.CompareBalance(
+			ctx,
+			account,
+			currency,
+			live.Value,
+			liveBlock,
+		)
+		if err != nil {
+			log.Printf(
+				"%s: unable to re-verify reconciliation of %s (attempt %d)\n",
+				err.Error(),
+				types.AccountString(account),
+				attempt,
+			)
+			continue
+		}
+
+		if difference == "0" {
+			return live.Value, liveBlock, true, nil
+		}
+	}
+
+	return "", nil, false, nil
+}
@@ -0,0 +1,224 @@
+// Copyright 2022 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package processor
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/coinbase/rosetta-cli/configuration"
+
+	"github.com/coinbase/rosetta-sdk-go/types"
+)
+
+// referenceTemplateData is provided to the URL
+// template of "rest" reference balances.
+type referenceTemplateData struct {
+	Address    string
+	SubAccount string
+	Symbol     string
+	Decimals   int32
+	Index      int64
+	Hash       string
+}
+
+// ReferenceBalance fetches balances from a reference
+// API to compare with failed reconciliations.
+type ReferenceBalance struct {
+	config   *configuration.ReferenceBalanceConfiguration
+	network  *types.NetworkIdentifier
+	client   *http.Client
+	template *template.Template
+}
+
+// NewReferenceBalance returns a new *ReferenceBalance. The
+// network is used for "rosetta" references unless the
+// configuration overrides it.
+func NewReferenceBalance(
+	config *configuration.ReferenceBalanceConfiguration,
+	network *types.NetworkIdentifier,
+	timeout time.Duration,
+) (*ReferenceBalance, error) {
+	r := &ReferenceBalance{
+		config:  config,
+		network: network,
+		client:  &http.Client{Timeout: timeout},
+	}
+	if config.Network != nil {
+		r.network = config.Network
+	}
+
+	if config.Type == configuration.RESTReference {
+		tmpl, err := template.New("reference").Parse(config.URL)
+		if err != nil {
+			return nil, fmt.Errorf("%w: unable to parse reference url template", err)
+		}
+
+		r.template = tmpl
+	}
+
+	return r, nil
+}
+
+// Balance returns the reference balance of
+// account in currency at block.
+func (r *ReferenceBalance) Balance(
+	ctx context.Context,
+	account *types.AccountIdentifier,
+	currency *types.Currency,
+	block *types.BlockIdentifier,
+) (string, error) {
+	if r.config.Type == configuration.RESTReference {
+		return r.restBalance(ctx, account, currency, block)
+	}
+
+	return r.rosettaBalance(ctx, account, currency, block)
+}
+
+// rosettaBalance fetches the balance from
+// /account/balance of a Rosetta implementation.
+func (r *ReferenceBalance) rosettaBalance(
+	ctx context.Context,
+	account *types.AccountIdentifier,
+	currency *types.Currency,
+	block *types.BlockIdentifier,
+) (string, error) {
+	body, err := json.Marshal(&types.AccountBalanceRequest{
+		NetworkIdentifier: r.network,
+		AccountIdentifier: account,
+		BlockIdentifier:   types.ConstructPartialBlockIdentifier(block),
+		Currencies:        []*types.Currency{currency},
+	})
+	if err != nil {
+		return "", fmt.Errorf("%w: unable to encode reference balance request", err)
+	}
+
+	req, err := http.NewRequestWithContext(
+		ctx,
+		http.MethodPost,
+		strings.TrimSuffix(r.config.URL, "/")+"/account/balance",
+		bytes.NewReader(body),
+	)
+	if err != nil {
+		return "", fmt.Errorf("%w: unable to create reference balance request", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	var response types.AccountBalanceResponse
+	if err := r.do(req, &response); err != nil {
+		return "", err
+	}
+
+	amount := types.ExtractAmount(response.Balances, currency)
+	return amount.Value, nil
+}
+
+// restBalance fetches the balance from the URL rendered
+// from the template and extracts the balance field.
+func (r *ReferenceBalance) restBalance(
+	ctx context.Context,
+	account *types.AccountIdentifier,
+	currency *types.Currency,
+	block *types.BlockIdentifier,
+) (string, error) {
+	data := &referenceTemplateData{
+		Address:  account.Address,
+		Symbol:   currency.Symbol,
+		Decimals: currency.Decimals,
+		Index:    block.Index,
+		Hash:     block.Hash,
+	}
+	if account.SubAccount != nil {
+		data.SubAccount = account.SubAccount.Address
+	}
+
+	var url bytes.Buffer
+	if err := r.template.Execute(&url, data); err != nil {
+		return "", fmt.Errorf("%w: unable to render reference url", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url.String(), nil)
+	if err != nil {
+		return "", fmt.Errorf("%w: unable to create reference balance request", err)
+	}
+
+	var response interface{}
+	if err := r.do(req, &response); err != nil {
+		return "", err
+	}
+
+	for _, field := range strings.Split(r.config.BalanceField, ".") {
+		object, ok := response.(map[string]interface{})
+		if !ok {
+			return "", fmt.Errorf("%s is not an object in reference response", field)
+		}
+
+		response, ok = object[field]
+		if !ok {
+			return "", fmt.Errorf("%s is missing from reference response", field)
+		}
+	}
+
+	switch balance := response.(type) {
+	case string:
+		return balance, nil
+	case json.Number:
+		return balance.String(), nil
+	default:
+		return "", fmt.Errorf("%s is not a string or number", r.config.BalanceField)
+	}
+}
+
+// do sends req and decodes the response body into v.
+func (r *ReferenceBalance) do(req *http.Request, v interface{}) error {
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("%w: unable to query reference", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		respBody, _ := ioutil.ReadAll(resp.Body)
+		return fmt.Errorf("received %d status with body %s", resp.StatusCode, respBody)
+	}
+
+	decoder := json.NewDecoder(resp.Body)
+	decoder.UseNumber()
+	if err := decoder.Decode(v); err != nil {
+		return fmt.Errorf("%w: unable to parse reference response", err)
+	}
+
+	return nil
+}
+
+// ReferenceComparison describes which of the computed and live
+// balances of a failed reconciliation agree with the reference.
+func ReferenceComparison(computedBalance, liveBalance, referenceBalance string) string {
+	switch referenceBalance {
+	case liveBalance:
+		return "reference agrees with live balance (computed balance is likely wrong)"
+	case computedBalance:
+		return "reference agrees with computed balance (live balance is likely wrong)"
+	default:
+		return "reference agrees with neither balance"
+	}
+}
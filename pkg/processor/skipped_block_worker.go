@@ -0,0 +1,176 @@
+// Copyright 2022 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package processor
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"sort"
+
+	"github.com/coinbase/rosetta-cli/pkg/results"
+
+	"github.com/coinbase/rosetta-sdk-go/storage/database"
+	"github.com/coinbase/rosetta-sdk-go/storage/modules"
+	"github.com/coinbase/rosetta-sdk-go/types"
+	"github.com/neilotoole/errgroup"
+)
+
+const (
+	skippedBlockPrefix = "skipped_block"
+)
+
+var _ modules.BlockWorker = (*SkippedBlockWorker)(nil)
+
+// SkipBlock returns a boolean indicating if block
+// matches the index or hash of any of skipBlocks.
+func SkipBlock(
+	skipBlocks []*types.PartialBlockIdentifier,
+	block *types.BlockIdentifier,
+) bool {
+	for _, skipBlock := range skipBlocks {
+		if skipBlock.Index != nil && *skipBlock.Index != block.Index {
+			continue
+		}
+
+		if skipBlock.Hash != nil && *skipBlock.Hash != block.Hash {
+			continue
+		}
+
+		return true
+	}
+
+	return false
+}
+
+// SkippedBlockWorker implements the modules.BlockWorker interface.
+// It stores the identifiers of the synced blocks whose transactions
+// were skipped (and counts them) so they are reported in check:data
+// results.
+type SkippedBlockWorker struct {
+	db             database.Database
+	counterStorage *modules.CounterStorage
+	skipBlocks     []*types.PartialBlockIdentifier
+}
+
+// NewSkippedBlockWorker returns a new *SkippedBlockWorker.
+func NewSkippedBlockWorker(
+	db database.Database,
+	counterStorage *modules.CounterStorage,
+	skipBlocks []*types.PartialBlockIdentifier,
+) *SkippedBlockWorker {
+	return &SkippedBlockWorker{
+		db:             db,
+		counterStorage: counterStorage,
+		skipBlocks:     skipBlocks,
+	}
+}
+
+func skippedBlockKey(block *types.BlockIdentifier) []byte {
+	return []byte(fmt.Sprintf("%s/%s", skippedBlockPrefix, block.Hash))
+}
+
+// AddingBlock is called by BlockStorage when adding a block.
+func (w *SkippedBlockWorker) AddingBlock(
+	ctx context.Context,
+	g *errgroup.Group,
+	block *types.Block,
+	dbTx database.Transaction,
+) (database.CommitWorker, error) {
+	if !SkipBlock(w.skipBlocks, block.BlockIdentifier) {
+		return nil, nil
+	}
+
+	b, err := encodeStoredValue(w.db, block.BlockIdentifier)
+	if err != nil {
+		return nil, fmt.Errorf("%w: unable to encode skipped block", err)
+	}
+
+	if err := dbTx.Set(ctx, skippedBlockKey(block.BlockIdentifier), b, true); err != nil {
+		return nil, fmt.Errorf("%w: unable to store skipped block", err)
+	}
+
+	return nil, w.updateCounter(ctx, dbTx, 1)
+}
+
+// RemovingBlock is called by BlockStorage when removing a block.
+func (w *SkippedBlockWorker) RemovingBlock(
+	ctx context.Context,
+	g *errgroup.Group,
+	block *types.Block,
+	dbTx database.Transaction,
+) (database.CommitWorker, error) {
+	if !SkipBlock(w.skipBlocks, block.BlockIdentifier) {
+		return nil, nil
+	}
+
+	if err := dbTx.Delete(ctx, skippedBlockKey(block.BlockIdentifier)); err != nil {
+		return nil, fmt.Errorf("%w: unable to delete skipped block", err)
+	}
+
+	return nil, w.updateCounter(ctx, dbTx, -1)
+}
+
+// updateCounter adds sign to the skipped block counter.
+func (w *SkippedBlockWorker) updateCounter(
+	ctx context.Context,
+	dbTx database.Transaction,
+	sign int64,
+) error {
+	if _, err := w.counterStorage.UpdateTransactional(
+		ctx,
+		dbTx,
+		results.SkippedBlockCounter,
+		big.NewInt(sign),
+	); err != nil {
+		return fmt.Errorf("%w: unable to update skipped block counter", err)
+	}
+
+	return nil
+}
+
+// GetAll returns the identifiers of all synced
+// blocks that were skipped (sorted by index).
+func (w *SkippedBlockWorker) GetAll(ctx context.Context) ([]*types.BlockIdentifier, error) {
+	dbTx := w.db.ReadTransaction(ctx)
+	defer dbTx.Discard(ctx)
+
+	blocks := []*types.BlockIdentifier{}
+	_, err := dbTx.Scan(
+		ctx,
+		[]byte(skippedBlockPrefix),
+		[]byte(skippedBlockPrefix),
+		func(k []byte, v []byte) error {
+			var block types.BlockIdentifier
+			if err := decodeStoredValue(w.db, v, &block); err != nil {
+				return fmt.Errorf("%w: unable to parse skipped block", err)
+			}
+
+			blocks = append(blocks, &block)
+			return nil
+		},
+		false,
+		false,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("%w: unable to scan skipped blocks", err)
+	}
+
+	sort.Slice(blocks, func(i, j int) bool {
+		return blocks[i].Index < blocks[j].Index
+	})
+
+	return blocks, nil
+}
@@ -0,0 +1,130 @@
+// Copyright 2022 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package processor
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/coinbase/rosetta-cli/pkg/results"
+
+	"github.com/coinbase/rosetta-sdk-go/storage/database"
+	"github.com/coinbase/rosetta-sdk-go/types"
+	"github.com/coinbase/rosetta-sdk-go/utils"
+)
+
+// jsonObjectStart is the first byte of values stored as
+// JSON by earlier versions. Values encoded by the database
+// encoder never start with this byte (it is the zstd magic
+// number when compressed and a msgpack map header otherwise).
+const jsonObjectStart = '{'
+
+// encodeStoredValue encodes v with the binary encoding
+// (msgpack and, unless disabled, zstd compression) that
+// the storage modules use for blocks and balances.
+func encodeStoredValue(db database.Database, v interface{}) ([]byte, error) {
+	return db.Encoder().Encode("", v)
+}
+
+// decodeStoredValue decodes a value encoded with encodeStoredValue
+// or stored as JSON by earlier versions (which are re-encoded the
+// next time they are written).
+func decodeStoredValue(db database.Database, b []byte, v interface{}) error {
+	if len(b) > 0 && b[0] == jsonObjectStart {
+		return json.Unmarshal(b, v)
+	}
+
+	return db.Encoder().Decode("", b, v, false)
+}
+
+// storedValuePrefixes are the prefixes of all values that are
+// encoded with encodeStoredValue (and a constructor of the
+// type of each value).
+var storedValuePrefixes = map[string]func() interface{}{
+	reconciliationHistoryPrefix: func() interface{} { return &ReconciliationAttempt{} },
+	operationTotalsPrefix:       func() interface{} { return &results.OperationTotal{} },
+	reconciliationQueuePrefix:   func() interface{} { return &QueuedReconciliation{} },
+	subAccountPrefix:            func() interface{} { return &types.AccountIdentifier{} },
+	skippedBlockPrefix:          func() interface{} { return &types.BlockIdentifier{} },
+}
+
+// ReencodeStoredValues re-encodes all values stored as
+// JSON by earlier versions and returns the number of values
+// that were re-encoded.
+func ReencodeStoredValues(ctx context.Context, db database.Database) (int, error) {
+	reencoded := 0
+	for prefix, newValue := range storedValuePrefixes {
+		stale := map[string][]byte{}
+		dbTx := db.ReadTransaction(ctx)
+		_, err := dbTx.Scan(
+			ctx,
+			[]byte(prefix),
+			[]byte(prefix),
+			func(k []byte, v []byte) error {
+				if len(v) > 0 && v[0] == jsonObjectStart {
+					stale[string(k)] = append([]byte{}, v...)
+				}
+
+				return nil
+			},
+			false,
+			false,
+		)
+		dbTx.Discard(ctx)
+		if err != nil {
+			return reencoded, fmt.Errorf("%w: unable to scan %s", err, prefix)
+		}
+
+		writeTx := db.Transaction(ctx)
+		pending := 0
+		for k, v := range stale {
+			value := newValue()
+			if err := json.Unmarshal(v, value); err != nil {
+				writeTx.Discard(ctx)
+				return reencoded, fmt.Errorf("%w: unable to decode %s", err, k)
+			}
+
+			b, err := encodeStoredValue(db, value)
+			if err != nil {
+				writeTx.Discard(ctx)
+				return reencoded, fmt.Errorf("%w: unable to encode %s", err, k)
+			}
+
+			if err := writeTx.Set(ctx, []byte(k), b, true); err != nil {
+				writeTx.Discard(ctx)
+				return reencoded, fmt.Errorf("%w: unable to store %s", err, k)
+			}
+
+			pending++
+			if pending == utils.MaxEntrySizePerTxn {
+				if err := writeTx.Commit(ctx); err != nil {
+					return reencoded, fmt.Errorf("%w: unable to commit re-encoded values", err)
+				}
+
+				reencoded += pending
+				pending = 0
+				writeTx = db.Transaction(ctx)
+			}
+		}
+
+		if err := writeTx.Commit(ctx); err != nil {
+			return reencoded, fmt.Errorf("%w: unable to commit re-encoded values", err)
+		}
+		reencoded += pending
+	}
+
+	return reencoded, nil
+}
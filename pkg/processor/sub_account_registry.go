@@ -0,0 +1,280 @@
+// Copyright 2022 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package processor
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/coinbase/rosetta-sdk-go/storage/database"
+	"github.com/coinbase/rosetta-sdk-go/storage/modules"
+	"github.com/coinbase/rosetta-sdk-go/types"
+	"github.com/neilotoole/errgroup"
+)
+
+const (
+	subAccountPrefix = "sub_account"
+)
+
+var (
+	_ modules.BlockWorker = (*SubAccountRegistry)(nil)
+	_ modules.BlockWorker = (*SubAccountAggregationWorker)(nil)
+)
+
+// SubAccountRegistry implements the modules.BlockWorker interface.
+// It stores the sub-accounts aggregated into each parent account
+// (in the transaction of the block where they are first seen), so
+// the live balance of a parent can include all of its sub-accounts
+// when `check:data` restarts.
+type SubAccountRegistry struct {
+	db database.Database
+
+	lock sync.RWMutex
+
+	// subAccounts are the sub-accounts of each
+	// parent account (by parent hash).
+	subAccounts map[string][]*types.AccountIdentifier
+	known       map[string]struct{}
+}
+
+// NewSubAccountRegistry returns a new *SubAccountRegistry with
+// all stored sub-accounts. Each time a block is added, any new
+// sub-accounts in its operations are stored.
+func NewSubAccountRegistry(
+	ctx context.Context,
+	db database.Database,
+) (*SubAccountRegistry, error) {
+	r := &SubAccountRegistry{
+		db:          db,
+		subAccounts: map[string][]*types.AccountIdentifier{},
+		known:       map[string]struct{}{},
+	}
+
+	dbTx := db.ReadTransaction(ctx)
+	defer dbTx.Discard(ctx)
+
+	_, err := dbTx.Scan(
+		ctx,
+		[]byte(subAccountPrefix),
+		[]byte(subAccountPrefix),
+		func(k []byte, v []byte) error {
+			var account types.AccountIdentifier
+			if err := decodeStoredValue(db, v, &account); err != nil {
+				return fmt.Errorf("%w: unable to parse sub-account %s", err, string(k))
+			}
+
+			r.add(&account)
+			return nil
+		},
+		false,
+		false,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("%w: unable to load sub-accounts", err)
+	}
+
+	return r, nil
+}
+
+// Copy returns a new *SubAccountRegistry with the sub-accounts
+// of r that stores any new sub-accounts in db (r is unchanged
+// when blocks are added to the copy).
+func (r *SubAccountRegistry) Copy(db database.Database) *SubAccountRegistry {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+
+	c := &SubAccountRegistry{
+		db:          db,
+		subAccounts: map[string][]*types.AccountIdentifier{},
+		known:       map[string]struct{}{},
+	}
+	for parent, accounts := range r.subAccounts {
+		c.subAccounts[parent] = append([]*types.AccountIdentifier{}, accounts...)
+	}
+	for key := range r.known {
+		c.known[key] = struct{}{}
+	}
+
+	return c
+}
+
+// parentAccount returns account without its sub-account.
+func parentAccount(account *types.AccountIdentifier) *types.AccountIdentifier {
+	return &types.AccountIdentifier{
+		Address:  account.Address,
+		Metadata: account.Metadata,
+	}
+}
+
+func subAccountKey(account *types.AccountIdentifier) []byte {
+	return []byte(fmt.Sprintf(
+		"%s/%s/%s",
+		subAccountPrefix,
+		types.Hash(parentAccount(account)),
+		types.Hash(account),
+	))
+}
+
+// add adds account to the sub-accounts of its parent
+// and returns false if it was already known.
+func (r *SubAccountRegistry) add(account *types.AccountIdentifier) bool {
+	key := types.Hash(account)
+	if _, ok := r.known[key]; ok {
+		return false
+	}
+
+	parent := types.Hash(parentAccount(account))
+	r.known[key] = struct{}{}
+	r.subAccounts[parent] = append(r.subAccounts[parent], account)
+	return true
+}
+
+// SubAccounts returns the sub-accounts aggregated into parent.
+func (r *SubAccountRegistry) SubAccounts(
+	parent *types.AccountIdentifier,
+) []*types.AccountIdentifier {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+
+	return append([]*types.AccountIdentifier{}, r.subAccounts[types.Hash(parent)]...)
+}
+
+// AddingBlock is called by BlockStorage when adding a block.
+func (r *SubAccountRegistry) AddingBlock(
+	ctx context.Context,
+	g *errgroup.Group,
+	block *types.Block,
+	dbTx database.Transaction,
+) (database.CommitWorker, error) {
+	pending := map[string]*types.AccountIdentifier{}
+	r.lock.RLock()
+	for _, tx := range block.Transactions {
+		for _, op := range tx.Operations {
+			if op.Account == nil || op.Account.SubAccount == nil {
+				continue
+			}
+
+			key := types.Hash(op.Account)
+			if _, ok := r.known[key]; !ok {
+				pending[key] = op.Account
+			}
+		}
+	}
+	r.lock.RUnlock()
+
+	for _, account := range pending {
+		b, err := encodeStoredValue(r.db, account)
+		if err != nil {
+			return nil, err
+		}
+
+		if err := dbTx.Set(ctx, subAccountKey(account), b, true); err != nil {
+			return nil, fmt.Errorf("%w: unable to store sub-account", err)
+		}
+	}
+
+	if len(pending) == 0 {
+		return nil, nil
+	}
+
+	return func(ctx context.Context) error {
+		r.lock.Lock()
+		defer r.lock.Unlock()
+
+		for _, account := range pending {
+			r.add(account)
+		}
+
+		return nil
+	}, nil
+}
+
+// RemovingBlock is called by BlockStorage when removing a block.
+// Sub-accounts are never removed (the live balance of a
+// sub-account without any operations is zero).
+func (r *SubAccountRegistry) RemovingBlock(
+	ctx context.Context,
+	g *errgroup.Group,
+	block *types.Block,
+	dbTx database.Transaction,
+) (database.CommitWorker, error) {
+	return nil, nil
+}
+
+// SubAccountAggregationWorker implements the modules.BlockWorker
+// interface. It removes the sub-account of each operation in the
+// block provided to balanceStorage, so the balance changes of each
+// sub-account are applied to its parent account (blocks are stored
+// unmodified).
+type SubAccountAggregationWorker struct {
+	balanceStorage modules.BlockWorker
+}
+
+// NewSubAccountAggregationWorker returns a new
+// *SubAccountAggregationWorker that must be added to
+// the block workers instead of balanceStorage.
+func NewSubAccountAggregationWorker(
+	balanceStorage modules.BlockWorker,
+) *SubAccountAggregationWorker {
+	return &SubAccountAggregationWorker{
+		balanceStorage: balanceStorage,
+	}
+}
+
+// aggregate returns block with the sub-account of each
+// operation removed (block is not modified).
+func (w *SubAccountAggregationWorker) aggregate(block *types.Block) *types.Block {
+	aggregated := *block
+	aggregated.Transactions = make([]*types.Transaction, len(block.Transactions))
+	for i, tx := range block.Transactions {
+		aggregatedTx := *tx
+		aggregatedTx.Operations = make([]*types.Operation, len(tx.Operations))
+		for j, op := range tx.Operations {
+			if op.Account == nil || op.Account.SubAccount == nil {
+				aggregatedTx.Operations[j] = op
+				continue
+			}
+
+			aggregatedOp := *op
+			aggregatedOp.Account = parentAccount(op.Account)
+			aggregatedTx.Operations[j] = &aggregatedOp
+		}
+
+		aggregated.Transactions[i] = &aggregatedTx
+	}
+
+	return &aggregated
+}
+
+// AddingBlock is called by BlockStorage when adding a block.
+func (w *SubAccountAggregationWorker) AddingBlock(
+	ctx context.Context,
+	g *errgroup.Group,
+	block *types.Block,
+	dbTx database.Transaction,
+) (database.CommitWorker, error) {
+	return w.balanceStorage.AddingBlock(ctx, g, w.aggregate(block), dbTx)
+}
+
+// RemovingBlock is called by BlockStorage when removing a block.
+func (w *SubAccountAggregationWorker) RemovingBlock(
+	ctx context.Context,
+	g *errgroup.Group,
+	block *types.Block,
+	dbTx database.Transaction,
+) (database.CommitWorker, error) {
+	return w.balanceStorage.RemovingBlock(ctx, g, w.aggregate(block), dbTx)
+}
@@ -0,0 +1,83 @@
+// Copyright 2022 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package processor
+
+import (
+	"testing"
+
+	"github.com/coinbase/rosetta-sdk-go/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSubAccountAggregationWorker_Aggregate(t *testing.T) {
+	subAccount := &types.AccountIdentifier{
+		Address:    "addr",
+		SubAccount: &types.SubAccountIdentifier{Address: "staked"},
+	}
+	block := &types.Block{
+		BlockIdentifier: &types.BlockIdentifier{Index: 1, Hash: "1"},
+		Transactions: []*types.Transaction{
+			{
+				TransactionIdentifier: &types.TransactionIdentifier{Hash: "tx"},
+				Operations: []*types.Operation{
+					{
+						OperationIdentifier: &types.OperationIdentifier{Index: 0},
+						Type:                "Stake",
+						Account:             subAccount,
+					},
+					{
+						OperationIdentifier: &types.OperationIdentifier{Index: 1},
+						Type:                "Stake",
+						Account:             &types.AccountIdentifier{Address: "addr"},
+					},
+				},
+			},
+		},
+	}
+
+	w := NewSubAccountAggregationWorker(nil)
+	aggregated := w.aggregate(block)
+	for _, op := range aggregated.Transactions[0].Operations {
+		assert.Equal(t, &types.AccountIdentifier{Address: "addr"}, op.Account)
+	}
+
+	// The block is stored unmodified.
+	assert.Equal(t, subAccount, block.Transactions[0].Operations[0].Account)
+}
+
+func TestSubAccountRegistry_Copy(t *testing.T) {
+	parent := &types.AccountIdentifier{Address: "addr"}
+	staked := &types.AccountIdentifier{
+		Address:    "addr",
+		SubAccount: &types.SubAccountIdentifier{Address: "staked"},
+	}
+	locked := &types.AccountIdentifier{
+		Address:    "addr",
+		SubAccount: &types.SubAccountIdentifier{Address: "locked"},
+	}
+
+	r := &SubAccountRegistry{
+		subAccounts: map[string][]*types.AccountIdentifier{},
+		known:       map[string]struct{}{},
+	}
+	assert.True(t, r.add(staked))
+
+	// Sub-accounts added to the copy are not added to r.
+	c := r.Copy(nil)
+	assert.False(t, c.add(staked))
+	assert.True(t, c.add(locked))
+	assert.Equal(t, []*types.AccountIdentifier{staked, locked}, c.SubAccounts(parent))
+	assert.Equal(t, []*types.AccountIdentifier{staked}, r.SubAccounts(parent))
+}
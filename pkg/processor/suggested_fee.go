@@ -0,0 +1,165 @@
+// Copyright 2022 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package processor
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/coinbase/rosetta-sdk-go/parser"
+	"github.com/coinbase/rosetta-sdk-go/types"
+)
+
+const (
+	suggestedFeePrefix = "suggested_fee"
+)
+
+func suggestedFeeKey(identifier string) []byte {
+	return []byte(fmt.Sprintf("%s/%s", suggestedFeePrefix, identifier))
+}
+
+// observedFee returns the fee paid (per currency) by a confirmed
+// transaction. Because fees are not represented in the intent, we
+// consider any net decrease in balance not accounted for by the
+// intent to be the fee.
+func observedFee(
+	p *parser.Parser,
+	intent []*types.Operation,
+	observed []*types.Operation,
+) (map[string]*types.Amount, error) {
+	net := map[string]*big.Int{}
+	currencies := map[string]*types.Currency{}
+	add := func(amount *types.Amount, negate bool) error {
+		value, err := types.AmountValue(amount)
+		if err != nil {
+			return err
+		}
+
+		if negate {
+			value.Neg(value)
+		}
+
+		key := types.Hash(amount.Currency)
+		if _, ok := net[key]; !ok {
+			net[key] = big.NewInt(0)
+			currencies[key] = amount.Currency
+		}
+		net[key].Add(net[key], value)
+		return nil
+	}
+
+	for _, op := range observed {
+		if op.Amount == nil {
+			continue
+		}
+
+		success, err := p.Asserter.OperationSuccessful(op)
+		if err != nil {
+			return nil, fmt.Errorf("%w: unable to determine if operation succeeded", err)
+		}
+
+		if !success {
+			continue
+		}
+
+		if err := add(op.Amount, false); err != nil {
+			return nil, fmt.Errorf("%w: unable to parse observed amount", err)
+		}
+	}
+
+	for _, op := range intent {
+		if op.Amount == nil {
+			continue
+		}
+
+		if err := add(op.Amount, true); err != nil {
+			return nil, fmt.Errorf("%w: unable to parse intent amount", err)
+		}
+	}
+
+	fees := map[string]*types.Amount{}
+	for key, value := range net {
+		if value.Sign() >= 0 {
+			continue
+		}
+
+		fees[key] = &types.Amount{
+			Value:    new(big.Int).Neg(value).String(),
+			Currency: currencies[key],
+		}
+	}
+
+	return fees, nil
+}
+
+// compareSuggestedFee returns an error if the observed fee is in a
+// different currency than the suggested fee or if the observed fee
+// is not within [suggested / tolerance, suggested * tolerance].
+func compareSuggestedFee(
+	suggested []*types.Amount,
+	observed map[string]*types.Amount,
+	tolerance float64,
+) error {
+	seen := map[string]struct{}{}
+	for _, amount := range suggested {
+		key := types.Hash(amount.Currency)
+		seen[key] = struct{}{}
+
+		suggestedValue, err := types.AmountValue(amount)
+		if err != nil {
+			return fmt.Errorf("%w: unable to parse suggested fee", err)
+		}
+
+		observedValue := big.NewInt(0)
+		if observedAmount, ok := observed[key]; ok {
+			observedValue, err = types.AmountValue(observedAmount)
+			if err != nil {
+				return fmt.Errorf("%w: unable to parse observed fee", err)
+			}
+		}
+
+		suggestedFloat := new(big.Float).SetInt(suggestedValue)
+		observedFloat := new(big.Float).SetInt(observedValue)
+		toleranceFloat := big.NewFloat(tolerance)
+
+		upper := new(big.Float).Mul(suggestedFloat, toleranceFloat)
+		lower := new(big.Float).Quo(suggestedFloat, toleranceFloat)
+		if observedFloat.Cmp(upper) > 0 || observedFloat.Cmp(lower) < 0 {
+			return fmt.Errorf(
+				"suggested fee %s %s but observed fee %s %s (tolerance %.2fx)",
+				amount.Value,
+				amount.Currency.Symbol,
+				observedValue.String(),
+				amount.Currency.Symbol,
+				tolerance,
+			)
+		}
+	}
+
+	for key, amount := range observed {
+		if _, ok := seen[key]; ok {
+			continue
+		}
+
+		return fmt.Errorf(
+			"observed fee %s paid in currency %s which was not in suggested fee %s",
+			amount.Value,
+			types.PrintStruct(amount.Currency),
+			types.PrintStruct(suggested),
+		)
+	}
+
+	return nil
+}
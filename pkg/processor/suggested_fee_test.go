@@ -0,0 +1,93 @@
+// Copyright 2022 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package processor
+
+import (
+	"testing"
+
+	"github.com/coinbase/rosetta-sdk-go/types"
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	feeCurrency = &types.Currency{
+		Symbol:   "BTC",
+		Decimals: 8,
+	}
+
+	otherFeeCurrency = &types.Currency{
+		Symbol:   "ETH",
+		Decimals: 18,
+	}
+)
+
+func TestCompareSuggestedFee(t *testing.T) {
+	var tests = map[string]struct {
+		suggested []*types.Amount
+		observed  map[string]*types.Amount
+		err       bool
+	}{
+		"no fee": {},
+		"exact match": {
+			suggested: []*types.Amount{{Value: "100", Currency: feeCurrency}},
+			observed: map[string]*types.Amount{
+				types.Hash(feeCurrency): {Value: "100", Currency: feeCurrency},
+			},
+		},
+		"within tolerance": {
+			suggested: []*types.Amount{{Value: "100", Currency: feeCurrency}},
+			observed: map[string]*types.Amount{
+				types.Hash(feeCurrency): {Value: "150", Currency: feeCurrency},
+			},
+		},
+		"too high": {
+			suggested: []*types.Amount{{Value: "100", Currency: feeCurrency}},
+			observed: map[string]*types.Amount{
+				types.Hash(feeCurrency): {Value: "201", Currency: feeCurrency},
+			},
+			err: true,
+		},
+		"too low": {
+			suggested: []*types.Amount{{Value: "100", Currency: feeCurrency}},
+			observed: map[string]*types.Amount{
+				types.Hash(feeCurrency): {Value: "49", Currency: feeCurrency},
+			},
+			err: true,
+		},
+		"missing observed fee": {
+			suggested: []*types.Amount{{Value: "100", Currency: feeCurrency}},
+			err:       true,
+		},
+		"inconsistent currency": {
+			suggested: []*types.Amount{{Value: "100", Currency: feeCurrency}},
+			observed: map[string]*types.Amount{
+				types.Hash(feeCurrency):      {Value: "100", Currency: feeCurrency},
+				types.Hash(otherFeeCurrency): {Value: "1", Currency: otherFeeCurrency},
+			},
+			err: true,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			err := compareSuggestedFee(test.suggested, test.observed, 2)
+			if test.err {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
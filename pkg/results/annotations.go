@@ -0,0 +1,79 @@
+// Copyright 2022 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package results
+
+import (
+	"fmt"
+	"strings"
+)
+
+// escapeAnnotationData escapes the message of a GitHub
+// Actions workflow command.
+func escapeAnnotationData(s string) string {
+	return strings.NewReplacer(
+		"%", "%25",
+		"\r", "%0D",
+		"\n", "%0A",
+	).Replace(s)
+}
+
+// escapeAnnotationProperty escapes a property (like title)
+// of a GitHub Actions workflow command.
+func escapeAnnotationProperty(s string) string {
+	return strings.NewReplacer(
+		"%", "%25",
+		"\r", "%0D",
+		"\n", "%0A",
+		":", "%3A",
+		",", "%2C",
+	).Replace(s)
+}
+
+// Annotations returns a GitHub Actions error annotation
+// (without a file) for each failed test case in the
+// *JUnitTestSuite.
+func (s *JUnitTestSuite) Annotations() []string {
+	annotations := []string{}
+	for _, testCase := range s.Cases {
+		if testCase.Failure == nil {
+			continue
+		}
+
+		message := testCase.Failure.Body
+		if len(message) == 0 {
+			message = testCase.Failure.Type
+		}
+
+		annotations = append(annotations, fmt.Sprintf(
+			"::error title=%s::%s",
+			escapeAnnotationProperty(fmt.Sprintf("%s %s", s.Name, testCase.Name)),
+			escapeAnnotationData(message),
+		))
+	}
+
+	return annotations
+}
+
+// PrintAnnotations prints GitHub Actions annotations for
+// the *JUnitTestSuite to the console if enabled.
+func (s *JUnitTestSuite) PrintAnnotations(enabled bool) {
+	if !enabled {
+		return
+	}
+
+	for _, annotation := range s.Annotations() {
+		fmt.Println(annotation)
+	}
+}
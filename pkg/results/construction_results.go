@@ -24,6 +24,7 @@ import (
 	pkgError "github.com/pkg/errors"
 
 	"github.com/coinbase/rosetta-cli/configuration"
+	"github.com/coinbase/rosetta-cli/pkg/notifier"
 
 	"github.com/coinbase/rosetta-sdk-go/storage/modules"
 	"github.com/coinbase/rosetta-sdk-go/types"
@@ -59,6 +60,29 @@ func (c *CheckConstructionResults) Print() {
 	}
 }
 
+// Notification converts *CheckConstructionResults into
+// a *notifier.Message.
+func (c *CheckConstructionResults) Notification(
+	config *configuration.Configuration,
+) *notifier.Message {
+	message := &notifier.Message{
+		Command: "check:construction",
+		Event:   notifier.SuccessEvent,
+		Network: types.PrintStruct(config.Network),
+		Error:   c.Error,
+	}
+
+	if len(c.Error) > 0 {
+		message.Event = notifier.FailureEvent
+	}
+
+	if c.Stats != nil {
+		message.Stats = types.PrettyPrintStruct(c.Stats)
+	}
+
+	return message
+}
+
 // Output writes CheckConstructionResults to the provided
 // path.
 func (c *CheckConstructionResults) Output(path string) {
@@ -106,6 +130,8 @@ type CheckConstructionStats struct {
 	FailedBroadcasts      int64 `json:"failed_broadcasts"`
 	AddressesCreated      int64 `json:"addresses_created"`
 
+	SuggestedFeeMismatches int64 `json:"suggested_fee_mismatches"`
+
 	WorkflowsCompleted map[string]int64 `json:"workflows_completed"`
 }
 
@@ -140,6 +166,11 @@ func (c *CheckConstructionStats) PrintCounts() {
 		"# of transactions that exceeded broadcast limit",
 		strconv.FormatInt(c.FailedBroadcasts, 10),
 	})
+	table.Append([]string{
+		"Suggested Fee Mismatches",
+		"# of transactions where the suggested fee did not match the fee paid",
+		strconv.FormatInt(c.SuggestedFeeMismatches, 10),
+	})
 
 	table.Render()
 }
@@ -208,6 +239,12 @@ func ComputeCheckConstructionStats(
 		return nil
 	}
 
+	suggestedFeeMismatches, err := counters.Get(ctx, SuggestedFeeMismatchCounter)
+	if err != nil {
+		log.Printf("%s cannot get suggested fee mismatches counter\n", err.Error())
+		return nil
+	}
+
 	workflowsCompleted := map[string]int64{}
 	for _, workflow := range config.Construction.Workflows {
 		completed, err := jobs.Completed(ctx, workflow.Name)
@@ -220,12 +257,13 @@ func ComputeCheckConstructionStats(
 	}
 
 	return &CheckConstructionStats{
-		TransactionsCreated:   transactionsCreated.Int64(),
-		TransactionsConfirmed: transactionsConfirmed.Int64(),
-		StaleBroadcasts:       staleBroadcasts.Int64(),
-		FailedBroadcasts:      failedBroadcasts.Int64(),
-		AddressesCreated:      addressesCreated.Int64(),
-		WorkflowsCompleted:    workflowsCompleted,
+		TransactionsCreated:    transactionsCreated.Int64(),
+		TransactionsConfirmed:  transactionsConfirmed.Int64(),
+		StaleBroadcasts:        staleBroadcasts.Int64(),
+		FailedBroadcasts:       failedBroadcasts.Int64(),
+		AddressesCreated:       addressesCreated.Int64(),
+		SuggestedFeeMismatches: suggestedFeeMismatches.Int64(),
+		WorkflowsCompleted:     workflowsCompleted,
 	}
 }
 
@@ -313,9 +351,17 @@ func ExitConstruction(
 	)
 	if results != nil {
 		results.Print()
+		resultsFile := ""
 		if config.Construction != nil {
-			results.Output(config.Construction.ResultsOutputFile)
+			resultsFile = config.Construction.ResultsOutputFile
+			results.Output(resultsFile)
 		}
+		junit := results.JUnit()
+		junit.Output(config.JUnitOutputFile)
+		junit.PrintAnnotations(config.GitHubAnnotations)
+		message := results.Notification(config)
+		notifier.Notify(config, message)
+		notifier.Email(config, message, resultsFile)
 	}
 
 	return err
@@ -21,11 +21,14 @@ import (
 	"log"
 	"math/big"
 	"os"
+	"sort"
 	"strconv"
 
 	pkgError "github.com/pkg/errors"
 
 	"github.com/coinbase/rosetta-cli/configuration"
+	cliErrs "github.com/coinbase/rosetta-cli/pkg/errors"
+	"github.com/coinbase/rosetta-cli/pkg/notifier"
 
 	"github.com/coinbase/rosetta-sdk-go/asserter"
 	"github.com/coinbase/rosetta-sdk-go/fetcher"
@@ -60,6 +63,16 @@ type CheckDataResults struct {
 	EndCondition *EndCondition   `json:"end_condition"`
 	Tests        *CheckDataTests `json:"tests"`
 	Stats        *CheckDataStats `json:"stats"`
+
+	OperationTotals []*OperationTotal `json:"operation_totals,omitempty"`
+
+	// SkippedBlocks are the synced blocks in data.skip_blocks
+	// (whose transactions were not asserted or applied).
+	SkippedBlocks []*types.BlockIdentifier `json:"skipped_blocks,omitempty"`
+
+	// Failures are the distinct failures recorded when
+	// data.continue_on_error is enabled.
+	Failures []*Failure `json:"failures,omitempty"`
 }
 
 // Print logs CheckDataResults to the console.
@@ -83,6 +96,59 @@ func (c *CheckDataResults) Print() {
 		c.Stats.Print()
 		fmt.Printf("\n")
 	}
+	if len(c.OperationTotals) > 0 {
+		PrintOperationTotals(c.OperationTotals)
+		fmt.Printf("\n")
+	}
+	if len(c.SkippedBlocks) > 0 {
+		PrintSkippedBlocks(c.SkippedBlocks)
+		fmt.Printf("\n")
+	}
+	if len(c.Failures) > 0 {
+		color.Red("%d distinct failures recorded:", len(c.Failures))
+		PrintFailures(c.Failures)
+		fmt.Printf("\n")
+	}
+}
+
+// PrintSkippedBlocks logs the skipped blocks to the console.
+func PrintSkippedBlocks(blocks []*types.BlockIdentifier) {
+	if len(blocks) == 0 {
+		return
+	}
+
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetRowLine(true)
+	table.SetRowSeparator("-")
+	table.SetHeader([]string{"Skipped Block Index", "Hash"})
+	for _, block := range blocks {
+		table.Append([]string{strconv.FormatInt(block.Index, 10), block.Hash})
+	}
+
+	table.Render()
+}
+
+// Notification converts *CheckDataResults into
+// a *notifier.Message.
+func (c *CheckDataResults) Notification(
+	config *configuration.Configuration,
+) *notifier.Message {
+	message := &notifier.Message{
+		Command: "check:data",
+		Event:   notifier.SuccessEvent,
+		Network: types.PrintStruct(config.Network),
+		Error:   c.Error,
+	}
+
+	if len(c.Error) > 0 {
+		message.Event = notifier.FailureEvent
+	}
+
+	if c.Stats != nil {
+		message.Stats = types.PrettyPrintStruct(c.Stats)
+	}
+
+	return message
 }
 
 // Output writes *CheckDataResults to the provided
@@ -101,15 +167,22 @@ func (c *CheckDataResults) Output(path string) {
 type CheckDataStats struct {
 	Blocks                  int64   `json:"blocks"`
 	Orphans                 int64   `json:"orphans"`
+	SkippedBlocks           int64   `json:"skipped_blocks"`
 	Transactions            int64   `json:"transactions"`
+	OtherTransactions       int64   `json:"other_transactions"`
 	Operations              int64   `json:"operations"`
 	Accounts                int64   `json:"accounts"`
+	ReconciledAccounts      int64   `json:"reconciled_accounts"`
 	ActiveReconciliations   int64   `json:"active_reconciliations"`
 	InactiveReconciliations int64   `json:"inactive_reconciliations"`
 	ExemptReconciliations   int64   `json:"exempt_reconciliations"`
 	FailedReconciliations   int64   `json:"failed_reconciliations"`
 	SkippedReconciliations  int64   `json:"skipped_reconciliations"`
 	ReconciliationCoverage  float64 `json:"reconciliation_coverage"`
+
+	OperationsPerBlock       float64          `json:"operations_per_block"`
+	OperationsPerTransaction float64          `json:"operations_per_transaction"`
+	OperationTypes           map[string]int64 `json:"operation_types,omitempty"`
 }
 
 // Print logs CheckDataStats to the console.
@@ -120,6 +193,13 @@ func (c *CheckDataStats) Print() {
 	table.SetHeader([]string{"check:data Stats", "Description", "Value"})
 	table.Append([]string{"Blocks", "# of blocks synced", strconv.FormatInt(c.Blocks, 10)})
 	table.Append([]string{"Orphans", "# of blocks orphaned", strconv.FormatInt(c.Orphans, 10)})
+	table.Append(
+		[]string{
+			"Skipped Blocks",
+			"# of blocks whose transactions were skipped",
+			strconv.FormatInt(c.SkippedBlocks, 10),
+		},
+	)
 	table.Append(
 		[]string{
 			"Transactions",
@@ -127,12 +207,26 @@ func (c *CheckDataStats) Print() {
 			strconv.FormatInt(c.Transactions, 10),
 		},
 	)
+	table.Append(
+		[]string{
+			"Other Transactions",
+			"# of transactions fetched with /block/transaction",
+			strconv.FormatInt(c.OtherTransactions, 10),
+		},
+	)
 	table.Append(
 		[]string{"Operations", "# of operations processed", strconv.FormatInt(c.Operations, 10)},
 	)
 	table.Append(
 		[]string{"Accounts", "# of accounts seen", strconv.FormatInt(c.Accounts, 10)},
 	)
+	table.Append(
+		[]string{
+			"Reconciled Accounts",
+			"# of accounts seen that have been reconciled",
+			strconv.FormatInt(c.ReconciledAccounts, 10),
+		},
+	)
 	table.Append(
 		[]string{
 			"Active Reconciliations",
@@ -175,15 +269,47 @@ func (c *CheckDataStats) Print() {
 			fmt.Sprintf("%f%%", c.ReconciliationCoverage*utils.OneHundred),
 		},
 	)
+	table.Append(
+		[]string{
+			"Operations Per Block",
+			"average # of operations in each block",
+			fmt.Sprintf("%f", c.OperationsPerBlock),
+		},
+	)
+	table.Append(
+		[]string{
+			"Operations Per Transaction",
+			"average # of operations in each transaction",
+			fmt.Sprintf("%f", c.OperationsPerTransaction),
+		},
+	)
+
+	opTypes := make([]string, 0, len(c.OperationTypes))
+	for opType := range c.OperationTypes {
+		opTypes = append(opTypes, opType)
+	}
+	sort.Strings(opTypes)
+	for _, opType := range opTypes {
+		table.Append(
+			[]string{
+				fmt.Sprintf("%s Operations", opType),
+				fmt.Sprintf("# of %s operations processed", opType),
+				strconv.FormatInt(c.OperationTypes[opType], 10),
+			},
+		)
+	}
 
 	table.Render()
 }
 
 // ComputeCheckDataStats returns a populated CheckDataStats.
+// The number of operations of each of operationTypes (usually
+// all operation types supported by the network) is included.
 func ComputeCheckDataStats(
 	ctx context.Context,
 	counters *modules.CounterStorage,
 	balances *modules.BalanceStorage,
+	operationTypes []string,
 ) *CheckDataStats {
 	if counters == nil {
 		return nil
@@ -201,12 +327,24 @@ func ComputeCheckDataStats(
 		return nil
 	}
 
+	skippedBlocks, err := counters.Get(ctx, SkippedBlockCounter)
+	if err != nil {
+		log.Printf("%s: cannot get skipped block counter", err.Error())
+		return nil
+	}
+
 	txs, err := counters.Get(ctx, modules.TransactionCounter)
 	if err != nil {
 		log.Printf("%s: cannot get transaction counter", err.Error())
 		return nil
 	}
 
+	otherTxs, err := counters.Get(ctx, OtherTransactionCounter)
+	if err != nil {
+		log.Printf("%s: cannot get other transaction counter", err.Error())
+		return nil
+	}
+
 	ops, err := counters.Get(ctx, modules.OperationCounter)
 	if err != nil {
 		log.Printf("%s: cannot get operations counter", err.Error())
@@ -219,6 +357,12 @@ func ComputeCheckDataStats(
 		return nil
 	}
 
+	reconciledAccounts, err := counters.Get(ctx, modules.ReconciledAccounts)
+	if err != nil {
+		log.Printf("%s: cannot get reconciled accounts counter", err.Error())
+		return nil
+	}
+
 	activeReconciliations, err := counters.Get(ctx, modules.ActiveReconciliationCounter)
 	if err != nil {
 		log.Printf("%s: cannot get active reconciliations counter", err.Error())
@@ -252,9 +396,12 @@ func ComputeCheckDataStats(
 	stats := &CheckDataStats{
 		Blocks:                  blocks.Int64(),
 		Orphans:                 orphans.Int64(),
+		SkippedBlocks:           skippedBlocks.Int64(),
 		Transactions:            txs.Int64(),
+		OtherTransactions:       otherTxs.Int64(),
 		Operations:              ops.Int64(),
 		Accounts:                accounts.Int64(),
+		ReconciledAccounts:      reconciledAccounts.Int64(),
 		ActiveReconciliations:   activeReconciliations.Int64(),
 		InactiveReconciliations: inactiveReconciliations.Int64(),
 		ExemptReconciliations:   exemptReconciliations.Int64(),
@@ -262,6 +409,28 @@ func ComputeCheckDataStats(
 		SkippedReconciliations:  skippedReconciliations.Int64(),
 	}
 
+	if stats.Blocks > 0 {
+		stats.OperationsPerBlock = float64(stats.Operations) / float64(stats.Blocks)
+	}
+
+	if stats.Transactions > 0 {
+		stats.OperationsPerTransaction = float64(stats.Operations) / float64(stats.Transactions)
+	}
+
+	if len(operationTypes) > 0 {
+		stats.OperationTypes = map[string]int64{}
+	}
+
+	for _, opType := range operationTypes {
+		count, err := counters.Get(ctx, OperationTypeCounter(opType))
+		if err != nil {
+			log.Printf("%s: cannot get %s operation counter", err.Error(), opType)
+			return nil
+		}
+
+		stats.OperationTypes[opType] = count.Int64()
+	}
+
 	if balances != nil {
 		coverage, err := balances.EstimatedReconciliationCoverage(ctx)
 		switch {
@@ -390,11 +559,24 @@ func ComputeCheckDataProgress(
 	}
 }
 
+// CheckDataStorage contains statistics about the
+// files of check:data storage on disk.
+type CheckDataStorage struct {
+	Size               int64 `json:"size"`
+	TablesSize         int64 `json:"tables_size"`
+	ValueLogSize       int64 `json:"value_log_size"`
+	Tables             int   `json:"tables"`
+	ValueLogs          int   `json:"value_logs"`
+	ValueLogsReclaimed int   `json:"value_logs_reclaimed"`
+}
+
 // CheckDataStatus contains both CheckDataStats
-// and CheckDataProgress.
+// and CheckDataProgress (and CheckDataStorage,
+// if storage is on disk).
 type CheckDataStatus struct {
 	Stats    *CheckDataStats    `json:"stats"`
 	Progress *CheckDataProgress `json:"progress"`
+	Storage  *CheckDataStorage  `json:"storage,omitempty"`
 }
 
 // ComputeCheckDataStatus returns a populated
@@ -404,6 +586,7 @@ func ComputeCheckDataStatus(
 	blocks *modules.BlockStorage,
 	counters *modules.CounterStorage,
 	balances *modules.BalanceStorage,
+	operationTypes []string,
 	fetcher *fetcher.Fetcher,
 	network *types.NetworkIdentifier,
 	reconciler *reconciler.Reconciler,
@@ -413,6 +596,7 @@ func ComputeCheckDataStatus(
 			ctx,
 			counters,
 			balances,
+			operationTypes,
 		),
 		Progress: ComputeCheckDataProgress(
 			ctx,
@@ -445,6 +629,10 @@ type CheckDataTests struct {
 	BlockSyncing      *bool `json:"block_syncing"`
 	BalanceTracking   *bool `json:"balance_tracking"`
 	Reconciliation    *bool `json:"reconciliation"`
+
+	// ReconciliationCoverage is only populated if
+	// data.minimum_reconciliation_coverage is set.
+	ReconciliationCoverage *bool `json:"reconciliation_coverage,omitempty"`
 }
 
 // convertBool converts a *bool
@@ -502,6 +690,15 @@ func (c *CheckDataTests) Print() {
 			convertBool(c.Reconciliation),
 		},
 	)
+	if c.ReconciliationCoverage != nil {
+		table.Append(
+			[]string{
+				"Reconciliation Coverage",
+				"Enough of the accounts seen were reconciled",
+				convertBool(c.ReconciliationCoverage),
+			},
+		)
+	}
 
 	table.Render()
 }
@@ -531,6 +728,9 @@ func BlockSyncingTest(err error, blocksSynced bool) *bool {
 	syncPass := true
 	storageFailed, _ := storageErrs.Err(err)
 	if syncer.Err(err) ||
+		errors.Is(err, cliErrs.ErrDuplicateBlockHash) ||
+		errors.Is(err, cliErrs.ErrUnexpectedBlockGap) ||
+		errors.Is(err, cliErrs.ErrOmittedParentBlock) ||
 		(storageFailed && !errors.Is(err, storageErrs.ErrNegativeBalance)) {
 		syncPass = false
 	}
@@ -586,6 +786,24 @@ func ReconciliationTest(
 	return &tr
 }
 
+// ReconciliationCoverageTest returns a boolean indicating
+// if the reconciliation coverage in stats met the configured
+// minimum (nil if no minimum is configured).
+func ReconciliationCoverageTest(
+	cfg *configuration.Configuration,
+	stats *CheckDataStats,
+) *bool {
+	if cfg.Data.MinimumReconciliationCoverage == 0 || stats == nil {
+		return nil
+	}
+
+	if stats.ReconciliationCoverage < cfg.Data.MinimumReconciliationCoverage {
+		return &f
+	}
+
+	return &tr
+}
+
 // ComputeCheckDataTests returns a populated CheckDataTests.
 func ComputeCheckDataTests( // nolint:gocognit
 	ctx context.Context,
@@ -659,15 +877,21 @@ func ComputeCheckDataResults(
 	err error,
 	counterStorage *modules.CounterStorage,
 	balanceStorage *modules.BalanceStorage,
+	operationTypes []string,
+	operationTotals []*OperationTotal,
+	skippedBlocks []*types.BlockIdentifier,
 	endCondition configuration.CheckDataEndCondition,
 	endConditionDetail string,
 ) *CheckDataResults {
 	ctx := context.Background()
 	tests := ComputeCheckDataTests(ctx, cfg, err, counterStorage)
-	stats := ComputeCheckDataStats(ctx, counterStorage, balanceStorage)
+	stats := ComputeCheckDataStats(ctx, counterStorage, balanceStorage, operationTypes)
+	tests.ReconciliationCoverage = ReconciliationCoverageTest(cfg, stats)
 	results := &CheckDataResults{
-		Tests: tests,
-		Stats: stats,
+		Tests:           tests,
+		Stats:           stats,
+		OperationTotals: operationTotals,
+		SkippedBlocks:   skippedBlocks,
 	}
 
 	if err != nil {
@@ -700,11 +924,16 @@ func ComputeCheckDataResults(
 }
 
 // ExitData exits check:data, logs the test results to the console,
-// and to a provided output path.
+// and to a provided output path. If failureReport is not nil, err is
+// added to it and the consolidated report is included in the results.
 func ExitData(
 	config *configuration.Configuration,
 	counterStorage *modules.CounterStorage,
 	balanceStorage *modules.BalanceStorage,
+	operationTypes []string,
+	operationTotals []*OperationTotal,
+	skippedBlocks []*types.BlockIdentifier,
+	failureReport *FailureReport,
 	err error,
 	endCondition configuration.CheckDataEndCondition,
 	endConditionDetail string,
@@ -718,12 +947,45 @@ func ExitData(
 		err,
 		counterStorage,
 		balanceStorage,
+		operationTypes,
+		operationTotals,
+		skippedBlocks,
 		endCondition,
 		endConditionDetail,
 	)
+	if failureReport != nil && err != nil {
+		failureReport.RecordError(err)
+	}
 	if results != nil {
+		results.Failures = failureReport.Failures()
 		results.Print()
 		results.Output(config.Data.ResultsOutputFile)
+		junit := results.JUnit()
+		junit.Output(config.JUnitOutputFile)
+		junit.PrintAnnotations(config.GitHubAnnotations)
+		message := results.Notification(config)
+		notifier.Notify(config, message)
+		notifier.Email(config, message, config.Data.ResultsOutputFile)
+	}
+
+	if err == nil && results != nil && results.Tests != nil &&
+		results.Tests.ReconciliationCoverage != nil && !*results.Tests.ReconciliationCoverage {
+		return fmt.Errorf(
+			"%w: %f%% of accounts reconciled (minimum %f%%)",
+			ErrInsufficientReconciliationCoverage,
+			results.Stats.ReconciliationCoverage*utils.OneHundred,
+			config.Data.MinimumReconciliationCoverage*utils.OneHundred,
+		)
+	}
+
+	// Failures recorded while continuing past errors must
+	// still fail the run.
+	if err == nil && results != nil && len(results.Failures) > 0 {
+		return fmt.Errorf(
+			"%w: %d distinct failures recorded",
+			ErrReconciliationFailure,
+			len(results.Failures),
+		)
 	}
 
 	return err
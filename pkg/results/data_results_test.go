@@ -23,6 +23,7 @@ import (
 	"testing"
 
 	"github.com/coinbase/rosetta-cli/configuration"
+	cliErrs "github.com/coinbase/rosetta-cli/pkg/errors"
 
 	"github.com/coinbase/rosetta-sdk-go/asserter"
 	"github.com/coinbase/rosetta-sdk-go/fetcher"
@@ -154,6 +155,7 @@ func TestComputeCheckDataResults(t *testing.T) {
 				syncer.ErrOutOfOrder,
 				storageErrs.ErrDuplicateKey,
 				storageErrs.ErrDuplicateTransactionHash,
+				cliErrs.ErrDuplicateBlockHash,
 			},
 			result: &CheckDataResults{
 				Tests: &CheckDataTests{
@@ -223,8 +225,9 @@ func TestComputeCheckDataResults(t *testing.T) {
 					BalanceTracking:   &tr,
 				},
 				Stats: &CheckDataStats{
-					Blocks:     100,
-					Operations: 1,
+					Blocks:             100,
+					Operations:         1,
+					OperationsPerBlock: 0.01,
 				},
 			},
 		},
@@ -249,6 +252,7 @@ func TestComputeCheckDataResults(t *testing.T) {
 				Stats: &CheckDataStats{
 					Blocks:                  100,
 					Operations:              1,
+					OperationsPerBlock:      0.01,
 					InactiveReconciliations: 1,
 					ReconciliationCoverage:  0.25,
 				},
@@ -275,6 +279,7 @@ func TestComputeCheckDataResults(t *testing.T) {
 				Stats: &CheckDataStats{
 					Blocks:                 100,
 					Operations:             1,
+					OperationsPerBlock:     0.01,
 					ActiveReconciliations:  1,
 					ReconciliationCoverage: 0.5,
 				},
@@ -308,6 +313,7 @@ func TestComputeCheckDataResults(t *testing.T) {
 				Stats: &CheckDataStats{
 					Blocks:                  100,
 					Operations:              1,
+					OperationsPerBlock:      0.01,
 					InactiveReconciliations: 1,
 					ActiveReconciliations:   1,
 					ReconciliationCoverage:  0.25,
@@ -478,6 +484,9 @@ func TestComputeCheckDataResults(t *testing.T) {
 						testErr,
 						counterStorage,
 						balanceStorage,
+						nil,
+						nil,
+						nil,
 						test.endCondition,
 						test.endConditionDetail,
 					)
@@ -0,0 +1,118 @@
+// Copyright 2022 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package results
+
+import (
+	"errors"
+
+	cliErrs "github.com/coinbase/rosetta-cli/pkg/errors"
+
+	"github.com/coinbase/rosetta-sdk-go/asserter"
+	storageErrs "github.com/coinbase/rosetta-sdk-go/storage/errors"
+)
+
+// Exit codes returned by the rosetta-cli so that
+// wrappers can branch on the cause of a failure.
+const (
+	// ExitCodeSuccess is returned when a command succeeds.
+	ExitCodeSuccess = 0
+
+	// ExitCodeFailure is returned when a command fails
+	// for a reason not covered by another exit code.
+	ExitCodeFailure = 1
+
+	// ExitCodeReconciliationFailure is returned when a
+	// computed balance did not match a live balance.
+	ExitCodeReconciliationFailure = 2
+
+	// ExitCodeAssertionFailure is returned when a response
+	// was malformed, a check:spec requirement was not met,
+	// or some configured assertion did not hold.
+	ExitCodeAssertionFailure = 3
+
+	// ExitCodeNodeUnreachable is returned when the Rosetta
+	// implementation could not be reached or returned
+	// an error for a request.
+	ExitCodeNodeUnreachable = 4
+
+	// ExitCodeStorageFailure is returned when the local
+	// storage of the rosetta-cli failed.
+	ExitCodeStorageFailure = 5
+
+	// ExitCodeInterrupted is returned when a check is halted
+	// by an operator (128 + SIGINT by convention).
+	ExitCodeInterrupted = 130
+)
+
+// assertionErrs are returned when some assertion
+// on the Rosetta implementation fails.
+var assertionErrs = []error{
+	cliErrs.ErrCheckSpecFailed,
+	cliErrs.ErrDuplicateBlockHash,
+	cliErrs.ErrGenesisBlockMismatch,
+	cliErrs.ErrNetworkStatusAssertion,
+	cliErrs.ErrUnexpectedBlockGap,
+	cliErrs.ErrOmittedParentBlock,
+	cliErrs.ErrAsserterConfigError,
+	cliErrs.ErrOfflineEndpointOnline,
+	cliErrs.ErrSignatureTypeUnsupported,
+	cliErrs.ErrSignatureVerificationFailed,
+}
+
+// ExitCode returns the exit code for an error
+// returned by a command.
+func ExitCode(err error) int {
+	if err == nil {
+		return ExitCodeSuccess
+	}
+
+	if errors.Is(err, cliErrs.ErrDataCheckHalt) ||
+		errors.Is(err, cliErrs.ErrConstructionCheckHalt) {
+		return ExitCodeInterrupted
+	}
+
+	if errors.Is(err, ErrReconciliationFailure) ||
+		errors.Is(err, ErrInsufficientReconciliationCoverage) {
+		return ExitCodeReconciliationFailure
+	}
+
+	if is, _ := asserter.Err(err); is {
+		return ExitCodeAssertionFailure
+	}
+
+	for _, assertionErr := range assertionErrs {
+		if errors.Is(err, assertionErr) {
+			return ExitCodeAssertionFailure
+		}
+	}
+
+	// Balance storage errors (like a negative balance)
+	// indicate invalid operations were returned.
+	for _, balanceStorageErr := range storageErrs.BalanceStorageErrs {
+		if errors.Is(err, balanceStorageErr) {
+			return ExitCodeAssertionFailure
+		}
+	}
+
+	if !RequestResponseTest(err) {
+		return ExitCodeNodeUnreachable
+	}
+
+	if is, _ := storageErrs.Err(err); is {
+		return ExitCodeStorageFailure
+	}
+
+	return ExitCodeFailure
+}
@@ -0,0 +1,253 @@
+// Copyright 2022 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package results
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	storageErrs "github.com/coinbase/rosetta-sdk-go/storage/errors"
+	"github.com/coinbase/rosetta-sdk-go/types"
+	"github.com/olekukonko/tablewriter"
+)
+
+// Failure is a distinct failure recorded while
+// check:data continued past errors (see
+// data.continue_on_error).
+type Failure struct {
+	Class       string                   `json:"class"`
+	Account     *types.AccountIdentifier `json:"account,omitempty"`
+	Currency    *types.Currency          `json:"currency,omitempty"`
+	Occurrences int64                    `json:"occurrences"`
+	FirstBlock  *types.BlockIdentifier   `json:"first_block,omitempty"`
+	LastBlock   *types.BlockIdentifier   `json:"last_block,omitempty"`
+	Detail      string                   `json:"detail"`
+}
+
+// FailureReport aggregates failures encountered during
+// a run, deduplicated by class, account, and currency.
+// It is safe to use concurrently.
+type FailureReport struct {
+	lock     sync.Mutex
+	failures map[string]*Failure
+}
+
+// NewFailureReport returns a new *FailureReport.
+func NewFailureReport() *FailureReport {
+	return &FailureReport{
+		failures: map[string]*Failure{},
+	}
+}
+
+// RecordReconciliation records a failed reconciliation. Only
+// the balances of the first failure of each account and
+// currency are kept in the report. The reference balance
+// is only included if it is populated.
+func (r *FailureReport) RecordReconciliation(
+	reconciliationType string,
+	account *types.AccountIdentifier,
+	currency *types.Currency,
+	computedBalance string,
+	liveBalance string,
+	referenceBalance string,
+	block *types.BlockIdentifier,
+) {
+	detail := fmt.Sprintf("computed: %s, live: %s", computedBalance, liveBalance)
+	if len(referenceBalance) > 0 {
+		detail = fmt.Sprintf("%s, reference: %s", detail, referenceBalance)
+	}
+
+	r.record(&Failure{
+		Class:      fmt.Sprintf("%s reconciliation", strings.ToLower(reconciliationType)),
+		Account:    account,
+		Currency:   currency,
+		FirstBlock: block,
+		LastBlock:  block,
+		Detail:     detail,
+	})
+}
+
+// RecordAssertion records a block that failed assertion (and
+// whose transactions were skipped). Failures are deduplicated
+// by the assertion error they wrap.
+func (r *FailureReport) RecordAssertion(block *types.BlockIdentifier, err error) {
+	root := err
+	for errors.Unwrap(root) != nil {
+		root = errors.Unwrap(root)
+	}
+
+	r.record(&Failure{
+		Class:      FailureClass(err),
+		FirstBlock: block,
+		LastBlock:  block,
+		Detail:     root.Error(),
+	})
+}
+
+// RecordError records an error that halted the run.
+func (r *FailureReport) RecordError(err error) {
+	r.record(&Failure{
+		Class:  FailureClass(err),
+		Detail: err.Error(),
+	})
+}
+
+func (r *FailureReport) record(failure *Failure) {
+	key := types.Hash(failure.Class) + types.Hash(failure.Account) + types.Hash(failure.Currency)
+	if failure.Account == nil {
+		key += types.Hash(failure.Detail)
+	}
+
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	existing, ok := r.failures[key]
+	if !ok {
+		failure.Occurrences = 1
+		r.failures[key] = failure
+		return
+	}
+
+	existing.Occurrences++
+	if failure.LastBlock != nil {
+		existing.LastBlock = failure.LastBlock
+	}
+}
+
+// Failures returns all recorded failures, sorted
+// by class and then by account and currency.
+func (r *FailureReport) Failures() []*Failure {
+	if r == nil {
+		return nil
+	}
+
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	failures := make([]*Failure, 0, len(r.failures))
+	for _, failure := range r.failures {
+		copied := *failure
+		failures = append(failures, &copied)
+	}
+
+	sort.Slice(failures, func(i, j int) bool {
+		if failures[i].Class != failures[j].Class {
+			return failures[i].Class < failures[j].Class
+		}
+
+		if failures[i].Account != nil && failures[j].Account != nil {
+			iAccount := types.AccountString(failures[i].Account)
+			jAccount := types.AccountString(failures[j].Account)
+			if iAccount != jAccount {
+				return iAccount < jAccount
+			}
+
+			return types.CurrencyString(failures[i].Currency) <
+				types.CurrencyString(failures[j].Currency)
+		}
+
+		return failures[i].Detail < failures[j].Detail
+	})
+
+	return failures
+}
+
+// FailureClass returns a short description of
+// the kind of check that err failed.
+func FailureClass(err error) string {
+	switch {
+	case errors.Is(err, ErrReconciliationFailure):
+		return "reconciliation"
+	case !ResponseAssertionTest(err):
+		return "response assertion"
+	case !RequestResponseTest(err):
+		return "request/response"
+	}
+
+	for _, balanceStorageErr := range storageErrs.BalanceStorageErrs {
+		if errors.Is(err, balanceStorageErr) {
+			return "balance tracking"
+		}
+	}
+
+	if is, _ := storageErrs.Err(err); is {
+		return "storage"
+	}
+
+	return "other"
+}
+
+// PrintFailures logs all failures recorded
+// during a run to the console.
+func PrintFailures(failures []*Failure) {
+	if len(failures) == 0 {
+		return
+	}
+
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetRowLine(true)
+	table.SetRowSeparator("-")
+	table.SetHeader([]string{
+		"Class",
+		"Account",
+		"Currency",
+		"Occurrences",
+		"First Block",
+		"Last Block",
+		"Detail",
+	})
+	for _, failure := range failures {
+		table.Append([]string{
+			failure.Class,
+			accountCell(failure.Account),
+			currencyCell(failure.Currency),
+			strconv.FormatInt(failure.Occurrences, 10),
+			blockCell(failure.FirstBlock),
+			blockCell(failure.LastBlock),
+			failure.Detail,
+		})
+	}
+
+	table.Render()
+}
+
+func accountCell(account *types.AccountIdentifier) string {
+	if account == nil {
+		return "-"
+	}
+
+	return types.AccountString(account)
+}
+
+func currencyCell(currency *types.Currency) string {
+	if currency == nil {
+		return "-"
+	}
+
+	return types.CurrencyString(currency)
+}
+
+func blockCell(block *types.BlockIdentifier) string {
+	if block == nil {
+		return "-"
+	}
+
+	return strconv.FormatInt(block.Index, 10)
+}
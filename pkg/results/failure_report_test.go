@@ -0,0 +1,114 @@
+// Copyright 2022 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package results
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/coinbase/rosetta-sdk-go/asserter"
+	"github.com/coinbase/rosetta-sdk-go/reconciler"
+	"github.com/coinbase/rosetta-sdk-go/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFailureReport(t *testing.T) {
+	report := NewFailureReport()
+	account := &types.AccountIdentifier{Address: "addr1"}
+	currency := &types.Currency{Symbol: "BTC", Decimals: 8}
+
+	report.RecordReconciliation(
+		reconciler.ActiveReconciliation,
+		account,
+		currency,
+		"100",
+		"90",
+		"",
+		&types.BlockIdentifier{Index: 10, Hash: "block 10"},
+	)
+	report.RecordReconciliation(
+		reconciler.ActiveReconciliation,
+		account,
+		currency,
+		"110",
+		"80",
+		"",
+		&types.BlockIdentifier{Index: 20, Hash: "block 20"},
+	)
+	report.RecordReconciliation(
+		reconciler.InactiveReconciliation,
+		account,
+		currency,
+		"110",
+		"80",
+		"80",
+		&types.BlockIdentifier{Index: 25, Hash: "block 25"},
+	)
+	report.RecordError(errors.New("unexpected error"))
+
+	assert.Equal(t, []*Failure{
+		{
+			Class:       "active reconciliation",
+			Account:     account,
+			Currency:    currency,
+			Occurrences: 2,
+			FirstBlock:  &types.BlockIdentifier{Index: 10, Hash: "block 10"},
+			LastBlock:   &types.BlockIdentifier{Index: 20, Hash: "block 20"},
+			Detail:      "computed: 100, live: 90",
+		},
+		{
+			Class:       "inactive reconciliation",
+			Account:     account,
+			Currency:    currency,
+			Occurrences: 1,
+			FirstBlock:  &types.BlockIdentifier{Index: 25, Hash: "block 25"},
+			LastBlock:   &types.BlockIdentifier{Index: 25, Hash: "block 25"},
+			Detail:      "computed: 110, live: 80, reference: 80",
+		},
+		{
+			Class:       "other",
+			Occurrences: 1,
+			Detail:      "unexpected error",
+		},
+	}, report.Failures())
+
+	var nilReport *FailureReport
+	assert.Nil(t, nilReport.Failures())
+}
+
+func TestFailureReport_Assertion(t *testing.T) {
+	report := NewFailureReport()
+	for _, index := range []int64{5, 7} {
+		report.RecordAssertion(
+			&types.BlockIdentifier{Index: index, Hash: fmt.Sprintf("block %d", index)},
+			fmt.Errorf(
+				"%w: transaction %d is invalid",
+				asserter.ErrTxIdentifierIsNil,
+				index,
+			),
+		)
+	}
+
+	assert.Equal(t, []*Failure{
+		{
+			Class:       "response assertion",
+			Occurrences: 2,
+			FirstBlock:  &types.BlockIdentifier{Index: 5, Hash: "block 5"},
+			LastBlock:   &types.BlockIdentifier{Index: 7, Hash: "block 7"},
+			Detail:      asserter.ErrTxIdentifierIsNil.Error(),
+		},
+	}, report.Failures())
+}
@@ -0,0 +1,274 @@
+// Copyright 2022 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package results
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+	"io/ioutil"
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/coinbase/rosetta-cli/configuration"
+
+	"github.com/coinbase/rosetta-sdk-go/types"
+)
+
+const (
+	chartWidth  = 720
+	chartHeight = 200
+
+	// maxDataReportSamples is the number of samples
+	// kept for an HTML report (at most one per pixel
+	// of chart width).
+	maxDataReportSamples = chartWidth
+)
+
+// DataReportSample is a point-in-time snapshot of
+// check:data progress used to chart a run.
+type DataReportSample struct {
+	Time                  time.Time
+	Blocks                int64
+	Rate                  float64
+	Reconciliations       int64
+	FailedReconciliations int64
+}
+
+// NewDataReportSample creates a *DataReportSample
+// from a *CheckDataStatus.
+func NewDataReportSample(status *CheckDataStatus) *DataReportSample {
+	sample := &DataReportSample{Time: time.Now()}
+	if status.Stats != nil {
+		sample.Blocks = status.Stats.Blocks
+		sample.Reconciliations = status.Stats.ActiveReconciliations +
+			status.Stats.InactiveReconciliations
+		sample.FailedReconciliations = status.Stats.FailedReconciliations
+	}
+
+	if status.Progress != nil {
+		sample.Rate = status.Progress.Rate
+	}
+
+	return sample
+}
+
+// DataReportSamples collects *DataReportSample for an HTML
+// report in bounded memory. Once maxDataReportSamples are
+// collected, every other sample is dropped and the interval
+// between kept samples doubles, so a long run is still charted
+// from start to finish (at a lower resolution).
+type DataReportSamples struct {
+	interval int
+	added    int
+	samples  []*DataReportSample
+}
+
+// NewDataReportSamples returns an empty *DataReportSamples.
+func NewDataReportSamples() *DataReportSamples {
+	return &DataReportSamples{interval: 1}
+}
+
+// Add records sample if it falls on the current interval.
+func (s *DataReportSamples) Add(sample *DataReportSample) {
+	position := s.added
+	s.added++
+	if position%s.interval != 0 {
+		return
+	}
+
+	if len(s.samples) == maxDataReportSamples {
+		kept := s.samples[:0]
+		for i := 0; i < len(s.samples); i += 2 {
+			kept = append(kept, s.samples[i])
+		}
+
+		s.samples = kept
+		s.interval *= 2
+		if position%s.interval != 0 {
+			return
+		}
+	}
+
+	s.samples = append(s.samples, sample)
+}
+
+// Samples returns the collected samples (oldest first).
+func (s *DataReportSamples) Samples() []*DataReportSample {
+	return s.samples
+}
+
+// chart is a line chart rendered as an inline SVG.
+type chart struct {
+	Title  string
+	Points string
+	Max    string
+	Width  int
+	Height int
+}
+
+func newChart(title string, samples []*DataReportSample, value func(*DataReportSample) float64) *chart {
+	c := &chart{Title: title, Width: chartWidth, Height: chartHeight}
+	if len(samples) == 0 {
+		return c
+	}
+
+	max := 0.0
+	for _, sample := range samples {
+		if v := value(sample); v > max {
+			max = v
+		}
+	}
+	c.Max = fmt.Sprintf("%.2f", max)
+
+	points := make([]string, len(samples))
+	for i, sample := range samples {
+		x := 0.0
+		if len(samples) > 1 {
+			x = float64(i) * float64(chartWidth) / float64(len(samples)-1)
+		}
+
+		y := float64(chartHeight)
+		if max > 0 {
+			y = float64(chartHeight) - value(sample)*float64(chartHeight)/max
+		}
+
+		points[i] = fmt.Sprintf("%.1f,%.1f", x, y)
+	}
+	c.Points = strings.Join(points, " ")
+
+	return c
+}
+
+// HTMLReport is a self-contained HTML summary
+// of a check:data run.
+type HTMLReport struct {
+	Generated     string
+	Results       *CheckDataResults
+	Charts        []*chart
+	Tests         [][]string
+	Configuration string
+}
+
+// NewHTMLReport returns a new *HTMLReport.
+func NewHTMLReport(
+	config *configuration.Configuration,
+	results *CheckDataResults,
+	samples []*DataReportSample,
+) *HTMLReport {
+	report := &HTMLReport{
+		Generated:     time.Now().Format(time.RFC1123),
+		Results:       results,
+		Configuration: types.PrettyPrintStruct(config),
+		Charts: []*chart{
+			newChart("Sync Throughput (blocks/sec)", samples, func(s *DataReportSample) float64 {
+				return s.Rate
+			}),
+			newChart("Blocks Synced", samples, func(s *DataReportSample) float64 {
+				return float64(s.Blocks)
+			}),
+			newChart("Reconciliations", samples, func(s *DataReportSample) float64 {
+				return float64(s.Reconciliations)
+			}),
+			newChart("Failed Reconciliations", samples, func(s *DataReportSample) float64 {
+				return float64(s.FailedReconciliations)
+			}),
+		},
+	}
+
+	if results.Tests != nil {
+		report.Tests = [][]string{
+			{"Request/Response", convertBool(&results.Tests.RequestResponse)},
+			{"Response Assertion", convertBool(&results.Tests.ResponseAssertion)},
+			{"Block Syncing", convertBool(results.Tests.BlockSyncing)},
+			{"Balance Tracking", convertBool(results.Tests.BalanceTracking)},
+			{"Reconciliation", convertBool(results.Tests.Reconciliation)},
+		}
+	}
+
+	return report
+}
+
+// Output renders the *HTMLReport to the provided path.
+func (r *HTMLReport) Output(path string) {
+	if len(path) == 0 {
+		return
+	}
+
+	var buf bytes.Buffer
+	if err := htmlReportTemplate.Execute(&buf, r); err != nil {
+		log.Printf("%s: unable to render html report\n", err.Error())
+		return
+	}
+
+	if err := ioutil.WriteFile(path, buf.Bytes(), os.FileMode(0600)); err != nil {
+		log.Printf("%s: unable to save html report\n", err.Error())
+	}
+}
+
+var htmlReportTemplate = template.Must(template.New("report").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>rosetta-cli check:data report</title>
+<style>
+body { font-family: sans-serif; margin: 2em; color: #222; }
+table { border-collapse: collapse; margin-bottom: 1em; }
+td, th { border: 1px solid #ccc; padding: 4px 10px; text-align: left; }
+.PASSED { color: #1a7f37; } .FAILED { color: #cf222e; } .error { color: #cf222e; }
+svg { border: 1px solid #ccc; background: #fafafa; }
+pre { background: #f6f8fa; padding: 1em; overflow-x: auto; }
+</style>
+</head>
+<body>
+<h1>rosetta-cli check:data report</h1>
+<p>Generated {{.Generated}}</p>
+{{with .Results}}
+{{if .Error}}<h2 class="error">Failed</h2>
+<details><summary>{{printf "%.200s" .Error}}</summary><pre>{{.Error}}</pre></details>
+{{end}}
+{{with .EndCondition}}<h2 class="PASSED">Success: {{.Type}} [{{.Detail}}]</h2>{{end}}
+{{end}}
+{{if .Tests}}<h2>Tests</h2>
+<table><tr><th>Test</th><th>Status</th></tr>
+{{range .Tests}}<tr><td>{{index . 0}}</td><td class="{{index . 1}}">{{index . 1}}</td></tr>{{end}}
+</table>{{end}}
+{{with .Results.Stats}}<h2>Stats</h2>
+<table>
+<tr><td>Blocks</td><td>{{.Blocks}}</td></tr>
+<tr><td>Orphans</td><td>{{.Orphans}}</td></tr>
+<tr><td>Transactions</td><td>{{.Transactions}}</td></tr>
+<tr><td>Operations</td><td>{{.Operations}}</td></tr>
+<tr><td>Accounts</td><td>{{.Accounts}}</td></tr>
+<tr><td>Active Reconciliations</td><td>{{.ActiveReconciliations}}</td></tr>
+<tr><td>Inactive Reconciliations</td><td>{{.InactiveReconciliations}}</td></tr>
+<tr><td>Exempt Reconciliations</td><td>{{.ExemptReconciliations}}</td></tr>
+<tr><td>Failed Reconciliations</td><td>{{.FailedReconciliations}}</td></tr>
+<tr><td>Skipped Reconciliations</td><td>{{.SkippedReconciliations}}</td></tr>
+<tr><td>Reconciliation Coverage</td><td>{{printf "%.2f" .ReconciliationCoverage}}</td></tr>
+</table>{{end}}
+<h2>Charts</h2>
+{{range .Charts}}<h3>{{.Title}}{{if .Max}} (max {{.Max}}){{end}}</h3>
+<svg width="{{.Width}}" height="{{.Height}}" viewBox="0 0 {{.Width}} {{.Height}}">
+<polyline fill="none" stroke="#0969da" stroke-width="2" points="{{.Points}}"/>
+</svg>
+{{end}}
+<h2>Configuration</h2>
+<details><summary>Show configuration</summary><pre>{{.Configuration}}</pre></details>
+</body>
+</html>
+`))
@@ -0,0 +1,84 @@
+// Copyright 2022 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package results
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDataReportSamples(t *testing.T) {
+	var tests = map[string]struct {
+		added    int
+		expected []int64
+	}{
+		"empty": {},
+		"below limit": {
+			added:    3,
+			expected: []int64{0, 1, 2},
+		},
+		"at limit": {
+			added: maxDataReportSamples,
+		},
+		"one over limit": {
+			added: maxDataReportSamples + 1,
+		},
+		"many times limit": {
+			added: 10 * maxDataReportSamples,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			samples := NewDataReportSamples()
+			for i := 0; i < test.added; i++ {
+				samples.Add(&DataReportSample{Blocks: int64(i)})
+			}
+
+			kept := samples.Samples()
+			assert.LessOrEqual(t, len(kept), maxDataReportSamples)
+			if test.expected != nil {
+				blocks := make([]int64, len(kept))
+				for i, sample := range kept {
+					blocks[i] = sample.Blocks
+				}
+
+				assert.Equal(t, test.expected, blocks)
+			}
+
+			if test.added == 0 {
+				assert.Empty(t, kept)
+				return
+			}
+
+			// The run is charted from start to finish at
+			// evenly spaced samples.
+			interval := int64(1)
+			if len(kept) > 1 {
+				interval = kept[1].Blocks - kept[0].Blocks
+			}
+
+			assert.Equal(t, int64(0), kept[0].Blocks)
+			for i := 1; i < len(kept); i++ {
+				assert.Equal(t, interval, kept[i].Blocks-kept[i-1].Blocks)
+			}
+			assert.Less(t, int64(test.added-1)-kept[len(kept)-1].Blocks, interval)
+			if test.added >= maxDataReportSamples {
+				assert.Greater(t, len(kept), maxDataReportSamples/4)
+			}
+		})
+	}
+}
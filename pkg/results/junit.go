@@ -0,0 +1,180 @@
+// Copyright 2022 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package results
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"sort"
+)
+
+// JUnitTestSuites is the root element of a JUnit XML report.
+type JUnitTestSuites struct {
+	XMLName xml.Name          `xml:"testsuites"`
+	Suites  []*JUnitTestSuite `xml:"testsuite"`
+}
+
+// JUnitTestSuite is a collection of JUnitTestCases
+// (usually all the tests performed by a single command).
+type JUnitTestSuite struct {
+	Name     string           `xml:"name,attr"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Skipped  int              `xml:"skipped,attr"`
+	Cases    []*JUnitTestCase `xml:"testcase"`
+}
+
+// JUnitTestCase is a single test in a JUnitTestSuite. A
+// JUnitTestCase without a Failure or Skipped element passed.
+type JUnitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Failure   *JUnitFailure `xml:"failure,omitempty"`
+	Skipped   *JUnitSkipped `xml:"skipped,omitempty"`
+}
+
+// JUnitFailure describes why a JUnitTestCase failed.
+type JUnitFailure struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Body    string `xml:",chardata"`
+}
+
+// JUnitSkipped indicates a JUnitTestCase was not run.
+type JUnitSkipped struct {
+	Message string `xml:"message,attr"`
+}
+
+// NewJUnitTestSuite returns a new *JUnitTestSuite.
+func NewJUnitTestSuite(name string) *JUnitTestSuite {
+	return &JUnitTestSuite{
+		Name:  name,
+		Cases: []*JUnitTestCase{},
+	}
+}
+
+// AddCase adds a *JUnitTestCase to the *JUnitTestSuite. If passed
+// is nil, the test case is considered skipped. If passed is false,
+// the test case is considered failed with the provided message.
+func (s *JUnitTestSuite) AddCase(name string, passed *bool, message string) {
+	testCase := &JUnitTestCase{
+		Name:      name,
+		ClassName: s.Name,
+	}
+
+	switch {
+	case passed == nil:
+		testCase.Skipped = &JUnitSkipped{Message: "NOT TESTED"}
+		s.Skipped++
+	case !*passed:
+		testCase.Failure = &JUnitFailure{
+			Message: name,
+			Type:    "FAILED",
+			Body:    message,
+		}
+		s.Failures++
+	}
+
+	s.Tests++
+	s.Cases = append(s.Cases, testCase)
+}
+
+// Output writes a JUnit XML report containing
+// the *JUnitTestSuite to the provided path.
+func (s *JUnitTestSuite) Output(path string) {
+	if len(path) == 0 {
+		return
+	}
+
+	report := &JUnitTestSuites{Suites: []*JUnitTestSuite{s}}
+	b, err := xml.MarshalIndent(report, "", "  ")
+	if err != nil {
+		log.Printf("%s: unable to encode junit report\n", err.Error())
+		return
+	}
+
+	b = append([]byte(xml.Header), b...)
+	if err := ioutil.WriteFile(path, b, os.FileMode(0600)); err != nil {
+		log.Printf("%s: unable to save junit report\n", err.Error())
+	}
+}
+
+// JUnit converts *CheckDataResults into a *JUnitTestSuite
+// with a test case for each of the CheckDataTests.
+func (c *CheckDataResults) JUnit() *JUnitTestSuite {
+	suite := NewJUnitTestSuite("check:data")
+	if c.Tests == nil {
+		suite.AddCase("check:data", &f, c.Error)
+		return suite
+	}
+
+	suite.AddCase("Request/Response", &c.Tests.RequestResponse, c.Error)
+	suite.AddCase("Response Assertion", &c.Tests.ResponseAssertion, c.Error)
+	suite.AddCase("Block Syncing", c.Tests.BlockSyncing, c.Error)
+	suite.AddCase("Balance Tracking", c.Tests.BalanceTracking, c.Error)
+	suite.AddCase("Reconciliation", c.Tests.Reconciliation, c.Error)
+	if c.Tests.ReconciliationCoverage != nil {
+		suite.AddCase("Reconciliation Coverage", c.Tests.ReconciliationCoverage, c.Error)
+	}
+
+	return suite
+}
+
+// JUnit converts *CheckConstructionResults into a *JUnitTestSuite
+// with a test case for the run and each workflow end condition.
+func (c *CheckConstructionResults) JUnit() *JUnitTestSuite {
+	suite := NewJUnitTestSuite("check:construction")
+	suite.AddCase("check:construction", convertError(c.Error), c.Error)
+
+	if c.Stats == nil {
+		return suite
+	}
+
+	workflows := make([]string, 0, len(c.Stats.WorkflowsCompleted))
+	for workflow := range c.Stats.WorkflowsCompleted {
+		workflows = append(workflows, workflow)
+	}
+	sort.Strings(workflows)
+
+	for _, workflow := range workflows {
+		required, ok := c.EndConditions[workflow]
+		if !ok {
+			continue
+		}
+
+		completed := c.Stats.WorkflowsCompleted[workflow]
+		passed := completed >= int64(required)
+		suite.AddCase(
+			fmt.Sprintf("Workflow %s", workflow),
+			&passed,
+			fmt.Sprintf("completed %d of %d", completed, required),
+		)
+	}
+
+	return suite
+}
+
+// convertError converts an error string
+// to a test result.
+func convertError(err string) *bool {
+	if len(err) > 0 {
+		return &f
+	}
+
+	return &tr
+}
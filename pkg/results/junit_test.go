@@ -0,0 +1,89 @@
+// Copyright 2022 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package results
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckDataResultsJUnit(t *testing.T) {
+	var tests = map[string]struct {
+		results  *CheckDataResults
+		tests    int
+		failures int
+		skipped  int
+	}{
+		"no tests": {
+			results: &CheckDataResults{
+				Error: "unable to initialize",
+			},
+			tests:    1,
+			failures: 1,
+		},
+		"all passed": {
+			results: &CheckDataResults{
+				Tests: &CheckDataTests{
+					RequestResponse:   true,
+					ResponseAssertion: true,
+					BlockSyncing:      &tr,
+					BalanceTracking:   &tr,
+					Reconciliation:    &tr,
+				},
+			},
+			tests: 5,
+		},
+		"reconciliation failed": {
+			results: &CheckDataResults{
+				Error: "reconciliation failure",
+				Tests: &CheckDataTests{
+					RequestResponse:   true,
+					ResponseAssertion: true,
+					BlockSyncing:      &tr,
+					Reconciliation:    &f,
+				},
+			},
+			tests:    5,
+			failures: 1,
+			skipped:  1,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			suite := test.results.JUnit()
+			assert.Equal(t, test.tests, suite.Tests)
+			assert.Equal(t, test.failures, suite.Failures)
+			assert.Equal(t, test.skipped, suite.Skipped)
+			assert.Len(t, suite.Cases, test.tests)
+		})
+	}
+}
+
+func TestJUnitTestSuiteAnnotations(t *testing.T) {
+	suite := NewJUnitTestSuite("check:data")
+	suite.AddCase("Block Syncing", &tr, "")
+	suite.AddCase("Reconciliation", &f, "balance mismatch: 10%\naccount: abc")
+	suite.AddCase("Balance Tracking", nil, "")
+
+	assert.Equal(
+		t,
+		[]string{
+			"::error title=check%3Adata Reconciliation::balance mismatch: 10%25%0Aaccount: abc",
+		},
+		suite.Annotations(),
+	)
+}
@@ -0,0 +1,71 @@
+// Copyright 2022 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package results
+
+import (
+	"os"
+	"sort"
+	"strconv"
+
+	"github.com/coinbase/rosetta-sdk-go/types"
+	"github.com/olekukonko/tablewriter"
+)
+
+// OperationTotal is the total value moved by all
+// successful operations of a type in a currency.
+type OperationTotal struct {
+	Type     string          `json:"type"`
+	Currency *types.Currency `json:"currency"`
+	Value    string          `json:"value"`
+	Count    int64           `json:"count"`
+}
+
+// SortOperationTotals sorts totals by operation
+// type and then by currency.
+func SortOperationTotals(totals []*OperationTotal) {
+	sort.Slice(totals, func(i, j int) bool {
+		if totals[i].Type != totals[j].Type {
+			return totals[i].Type < totals[j].Type
+		}
+
+		return types.CurrencyString(totals[i].Currency) <
+			types.CurrencyString(totals[j].Currency)
+	})
+}
+
+// PrintOperationTotals logs the total value moved by
+// each operation type to the console. This makes it easy
+// to sanity check aggregates (like the sum of all "reward"
+// operations against the expected emission schedule).
+func PrintOperationTotals(totals []*OperationTotal) {
+	if len(totals) == 0 {
+		return
+	}
+
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetRowLine(true)
+	table.SetRowSeparator("-")
+	table.SetHeader([]string{"Operation Type", "Currency", "Operations", "Total Value"})
+	for _, total := range totals {
+		table.Append([]string{
+			total.Type,
+			types.CurrencyString(total.Currency),
+			strconv.FormatInt(total.Count, 10),
+			total.Value,
+		})
+	}
+
+	table.Render()
+}
@@ -0,0 +1,118 @@
+// Copyright 2022 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package results
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/coinbase/rosetta-sdk-go/utils"
+)
+
+const (
+	// progressBarWidth is the number of
+	// characters inside a progress bar.
+	progressBarWidth = 40
+
+	// minProgressSamples is the number of samples
+	// needed to compute a rate.
+	minProgressSamples = 2
+)
+
+type progressSample struct {
+	index int64
+	time  time.Time
+}
+
+// ProgressTracker computes the recent sync rate from
+// periodic samples of the head index so that the ETA of a
+// run reflects current throughput (instead of the average
+// since the run started).
+type ProgressTracker struct {
+	window  int
+	samples []*progressSample
+}
+
+// NewProgressTracker returns a *ProgressTracker that
+// computes the sync rate over the last window samples.
+func NewProgressTracker(window int) *ProgressTracker {
+	return &ProgressTracker{window: window}
+}
+
+// Add records the head index at some time.
+func (p *ProgressTracker) Add(index int64, t time.Time) {
+	p.samples = append(p.samples, &progressSample{index: index, time: t})
+	if len(p.samples) > p.window {
+		p.samples = p.samples[len(p.samples)-p.window:]
+	}
+}
+
+// Rate returns the blocks synced per second over the
+// window (or 0 if there are not enough samples).
+func (p *ProgressTracker) Rate() float64 {
+	if len(p.samples) < minProgressSamples {
+		return 0
+	}
+
+	first := p.samples[0]
+	last := p.samples[len(p.samples)-1]
+	elapsed := last.time.Sub(first.time).Seconds()
+	if elapsed <= 0 {
+		return 0
+	}
+
+	return float64(last.index-first.index) / elapsed
+}
+
+// Render returns a progress bar with the percent complete
+// and ETA of syncing from start to target, where index is
+// the last synced index.
+func (p *ProgressTracker) Render(start int64, index int64, target int64) string {
+	completed := float64(1)
+	if target > start {
+		completed = float64(index-start) / float64(target-start)
+	}
+
+	if completed < 0 {
+		completed = 0
+	}
+
+	if completed > 1 {
+		completed = 1
+	}
+
+	filled := int(completed * progressBarWidth)
+	bar := strings.Repeat("=", filled)
+	if filled < progressBarWidth {
+		bar += ">" + strings.Repeat(" ", progressBarWidth-filled-1)
+	}
+
+	rate := p.Rate()
+	eta := "unknown"
+	if rate > 0 {
+		eta = utils.TimeToTip(rate, index, target).String()
+	}
+
+	return fmt.Sprintf(
+		"[%s] %.2f%% %d/%d ETA: %s (%.2f blocks/second)",
+		bar,
+		completed*utils.OneHundred,
+		index,
+		target,
+		eta,
+		rate,
+	)
+}
@@ -16,11 +16,29 @@ package results
 
 import (
 	"errors"
+	"fmt"
 )
 
 const (
 	// TimeElapsedCounter tracks the total time elapsed in seconds.
 	TimeElapsedCounter = "time_elapsed"
+
+	// SuggestedFeeMismatchCounter tracks the number of confirmed
+	// transactions where the fee paid on-chain did not match the
+	// fee suggested by /construction/metadata.
+	SuggestedFeeMismatchCounter = "suggested_fee_mismatches"
+
+	// SkippedBlockCounter tracks the number of synced blocks
+	// whose transactions were skipped (see data.skip_blocks).
+	SkippedBlockCounter = "skipped_blocks"
+
+	// OtherTransactionCounter tracks the number of transactions
+	// fetched with /block/transaction (other_transactions).
+	OtherTransactionCounter = "other_transactions"
+
+	// operationTypeCounterPrefix is the prefix of each
+	// counter that tracks the operations of a single type.
+	operationTypeCounterPrefix = "operation_type"
 )
 
 var (
@@ -28,4 +46,15 @@ var (
 	// TODO: Move to reconciler package (had to remove from processor
 	// to prevent circular dependency)
 	ErrReconciliationFailure = errors.New("reconciliation failure")
+
+	// ErrInsufficientReconciliationCoverage is returned if fewer
+	// accounts were reconciled than data.minimum_reconciliation_coverage
+	// requires.
+	ErrInsufficientReconciliationCoverage = errors.New("insufficient reconciliation coverage")
 )
+
+// OperationTypeCounter returns the name of the counter
+// that tracks the number of operations of opType.
+func OperationTypeCounter(opType string) string {
+	return fmt.Sprintf("%s/%s", operationTypeCounterPrefix, opType)
+}
@@ -0,0 +1,301 @@
+// Copyright 2022 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sentry
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/coinbase/rosetta-cli/configuration"
+)
+
+const (
+	// eventIDSize is the number of random bytes in an event ID.
+	eventIDSize = 16
+
+	// maxFrames is the maximum number of stack
+	// frames reported with a panic.
+	maxFrames = 64
+
+	// callersSkip omits runtime.Callers, stacktrace,
+	// and CapturePanic from a stack trace.
+	callersSkip = 3
+
+	// reportTimeout is the timeout for reporting an event.
+	reportTimeout = 10 * time.Second
+
+	// modulePrefix identifies frames that are part of the rosetta-cli.
+	modulePrefix = "github.com/coinbase/rosetta-cli"
+
+	levelFatal = "fatal"
+)
+
+// Frame is a single frame of a stack trace.
+type Frame struct {
+	Function string `json:"function"`
+	Module   string `json:"module,omitempty"`
+	Filename string `json:"filename"`
+	AbsPath  string `json:"abs_path"`
+	Lineno   int    `json:"lineno"`
+	InApp    bool   `json:"in_app"`
+}
+
+// Stacktrace is a stack trace (ordered from
+// the outermost to the innermost call).
+type Stacktrace struct {
+	Frames []*Frame `json:"frames"`
+}
+
+// Exception describes an error or panic.
+type Exception struct {
+	Type       string      `json:"type"`
+	Value      string      `json:"value"`
+	Stacktrace *Stacktrace `json:"stacktrace,omitempty"`
+}
+
+// Event is reported to Sentry.
+type Event struct {
+	EventID     string                 `json:"event_id"`
+	Timestamp   string                 `json:"timestamp"`
+	Level       string                 `json:"level"`
+	Platform    string                 `json:"platform"`
+	Logger      string                 `json:"logger"`
+	Release     string                 `json:"release"`
+	Environment string                 `json:"environment,omitempty"`
+	ServerName  string                 `json:"server_name,omitempty"`
+	Tags        map[string]string      `json:"tags,omitempty"`
+	Extra       map[string]interface{} `json:"extra,omitempty"`
+	Exception   struct {
+		Values []*Exception `json:"values"`
+	} `json:"exception"`
+}
+
+// Client reports panics and fatal errors to Sentry. All
+// methods are no-ops on a nil *Client.
+type Client struct {
+	endpoint    string
+	auth        string
+	release     string
+	environment string
+	tags        map[string]string
+	extra       map[string]interface{}
+	client      *http.Client
+}
+
+// New returns a new *Client. If config is nil,
+// nil is returned.
+func New(
+	config *configuration.SentryConfiguration,
+	release string,
+	tags map[string]string,
+	extra map[string]interface{},
+) (*Client, error) {
+	if config == nil {
+		return nil, nil
+	}
+
+	dsn, err := url.Parse(config.DSN)
+	if err != nil {
+		return nil, fmt.Errorf("%w: unable to parse dsn", err)
+	}
+
+	if dsn.User == nil || len(dsn.User.Username()) == 0 {
+		return nil, errors.New("dsn must include a public key")
+	}
+
+	// The project ID is the last element of the path. Any
+	// preceding elements are a prefix for the API.
+	path := strings.Trim(dsn.Path, "/")
+	prefix, project := "", path
+	if i := strings.LastIndex(path, "/"); i >= 0 {
+		prefix, project = "/"+path[:i], path[i+1:]
+	}
+
+	return &Client{
+		endpoint: fmt.Sprintf(
+			"%s://%s%s/api/%s/store/",
+			dsn.Scheme,
+			dsn.Host,
+			prefix,
+			project,
+		),
+		auth: fmt.Sprintf(
+			"Sentry sentry_version=7, sentry_client=rosetta-cli/%s, sentry_key=%s",
+			release,
+			dsn.User.Username(),
+		),
+		release:     release,
+		environment: config.Environment,
+		tags:        tags,
+		extra:       extra,
+		client:      &http.Client{Timeout: reportTimeout},
+	}, nil
+}
+
+// CaptureError reports a fatal error. Errors do not carry
+// a stack trace, so the type of the innermost wrapped error
+// is reported instead.
+func (c *Client) CaptureError(err error) {
+	if c == nil || err == nil {
+		return
+	}
+
+	root := err
+	for unwrapped := errors.Unwrap(root); unwrapped != nil; unwrapped = errors.Unwrap(root) {
+		root = unwrapped
+	}
+
+	c.report(&Exception{
+		Type:  fmt.Sprintf("%T", root),
+		Value: err.Error(),
+	})
+}
+
+// CapturePanic reports a recovered panic with the stack trace
+// of the panicking goroutine. It must be called from the function
+// deferred where the panic was recovered.
+func (c *Client) CapturePanic(recovered interface{}) {
+	if c == nil || recovered == nil {
+		return
+	}
+
+	c.report(&Exception{
+		Type:       "panic",
+		Value:      fmt.Sprint(recovered),
+		Stacktrace: stacktrace(),
+	})
+}
+
+func (c *Client) report(exception *Exception) {
+	event, err := c.event(exception)
+	if err != nil {
+		log.Printf("%s: unable to create sentry event\n", err.Error())
+		return
+	}
+
+	if err := c.send(event); err != nil {
+		log.Printf("%s: unable to report to sentry\n", err.Error())
+	}
+}
+
+func (c *Client) event(exception *Exception) (*Event, error) {
+	id := make([]byte, eventIDSize)
+	if _, err := rand.Read(id); err != nil {
+		return nil, fmt.Errorf("%w: unable to generate event id", err)
+	}
+
+	hostname, _ := os.Hostname()
+	event := &Event{
+		EventID:     hex.EncodeToString(id),
+		Timestamp:   time.Now().UTC().Format(time.RFC3339),
+		Level:       levelFatal,
+		Platform:    "go",
+		Logger:      "rosetta-cli",
+		Release:     c.release,
+		Environment: c.environment,
+		ServerName:  hostname,
+		Tags:        c.tags,
+		Extra:       c.extra,
+	}
+	event.Exception.Values = []*Exception{exception}
+
+	return event, nil
+}
+
+func (c *Client) send(event *Event) error {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("%w: unable to encode event", err)
+	}
+
+	req, err := http.NewRequest(http.MethodPost, c.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("%w: unable to create request", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Sentry-Auth", c.auth)
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("%w: unable to send event", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("received status code %d", resp.StatusCode)
+	}
+
+	return nil
+}
+
+// stacktrace returns the *Stacktrace of the caller of
+// CapturePanic (omitting the runtime frames of the
+// panic itself).
+func stacktrace() *Stacktrace {
+	pcs := make([]uintptr, maxFrames)
+	n := runtime.Callers(callersSkip, pcs)
+
+	frames := []*Frame{}
+	iter := runtime.CallersFrames(pcs[:n])
+	for {
+		frame, more := iter.Next()
+		if !strings.HasPrefix(frame.Function, "runtime.") {
+			module, function := splitFunction(frame.Function)
+			frames = append(frames, &Frame{
+				Function: function,
+				Module:   module,
+				Filename: frame.File[strings.LastIndex(frame.File, "/")+1:],
+				AbsPath:  frame.File,
+				Lineno:   frame.Line,
+				InApp:    strings.HasPrefix(module, modulePrefix),
+			})
+		}
+
+		if !more {
+			break
+		}
+	}
+
+	// Sentry expects the innermost frame last.
+	for i, j := 0, len(frames)-1; i < j; i, j = i+1, j-1 {
+		frames[i], frames[j] = frames[j], frames[i]
+	}
+
+	return &Stacktrace{Frames: frames}
+}
+
+// splitFunction splits a fully qualified function name
+// into its package path and function name.
+func splitFunction(name string) (string, string) {
+	slash := strings.LastIndex(name, "/")
+	dot := strings.Index(name[slash+1:], ".")
+	if dot < 0 {
+		return "", name
+	}
+
+	dot += slash + 1
+	return name[:dot], name[dot+1:]
+}
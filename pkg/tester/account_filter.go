@@ -0,0 +1,406 @@
+// Copyright 2022 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tester
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"path"
+	"sync"
+	"time"
+
+	"github.com/coinbase/rosetta-cli/configuration"
+	"github.com/coinbase/rosetta-cli/pkg/bloom"
+
+	"github.com/coinbase/rosetta-sdk-go/storage/database"
+)
+
+const (
+	// accountFilterFile is the file in the check:data
+	// storage path where the account filter is persisted.
+	accountFilterFile = "account_filter"
+
+	// accountFilterFileMode is the permission
+	// of the account filter file.
+	accountFilterFileMode = 0600
+
+	// accountFilterJournalBatch is the maximum number of journal
+	// entries deleted in a single transaction.
+	accountFilterJournalBatch = 1000
+)
+
+var (
+	// accountBalancePrefix is the prefix of the current balance
+	// of each account stored by modules.BalanceStorage. An account
+	// is new if this key does not exist.
+	accountBalancePrefix = []byte("bal/")
+
+	// accountFilterJournalPrefix is the prefix of each balance key
+	// added to the filter since it was last persisted. It must not
+	// start with "acc" (modules.BalanceStorage scans that prefix
+	// for all accounts).
+	accountFilterJournalPrefix = []byte("filterjournal/")
+)
+
+var _ database.Database = (*accountFilterDatabase)(nil)
+
+// accountFilterDatabase implements the database.Database interface
+// by maintaining a bloom filter of the balance keys of all seen
+// accounts. Lookups of balance keys not in the filter (the common
+// case when deciding if an account is new) return without reading
+// from the underlying database.
+//
+// The filter is persisted periodically. Each balance key that is
+// not in the filter of committed keys is also written to a journal
+// in the same transaction, so the filter can be recovered from the
+// last persisted filter and the journal after an unclean shutdown.
+// If a transaction that added keys to the filter is not
+// committed, the persisted filter is removed (so it is rebuilt
+// from storage on the next start).
+type accountFilterDatabase struct {
+	database.Database
+
+	filter   *bloom.Filter
+	filePath string
+
+	// committed only contains the keys of committed
+	// transactions. A key that is in filter but not in
+	// committed may only be there because of a key that
+	// is not committed yet, so it must still be journaled.
+	committed *bloom.Filter
+
+	// lock is held while the persisted
+	// filter is written or removed.
+	lock    sync.Mutex
+	invalid bool
+
+	done chan struct{}
+}
+
+// newAccountFilterDatabase loads the persisted account filter
+// (rebuilding it from storage if it is missing or sized for
+// different parameters) and starts persisting it periodically.
+// If filePath is empty, the filter is never persisted.
+func newAccountFilterDatabase(
+	ctx context.Context,
+	db database.Database,
+	config *configuration.AccountFilterConfiguration,
+	filePath string,
+) (*accountFilterDatabase, error) {
+	d := &accountFilterDatabase{
+		Database: db,
+		filePath: filePath,
+		done:     make(chan struct{}),
+	}
+
+	filter, persisted, err := d.load(ctx, config)
+	if err != nil {
+		return nil, err
+	}
+	d.filter = filter
+	d.committed = filter.Copy()
+
+	if len(filePath) > 0 {
+		if !persisted {
+			if err := d.writeFilter(); err != nil {
+				return nil, fmt.Errorf("%w: unable to persist account filter", err)
+			}
+		}
+
+		go d.persistLoop(time.Duration(config.PersistFrequency) * time.Second)
+	}
+
+	return d, nil
+}
+
+// load returns the persisted filter with all journal entries
+// added or (if there is no usable persisted filter) a filter
+// of all balance keys in storage. The returned boolean indicates
+// if the persisted filter was used.
+func (d *accountFilterDatabase) load(
+	ctx context.Context,
+	config *configuration.AccountFilterConfiguration,
+) (*bloom.Filter, bool, error) {
+	persisted := false
+	prefix := accountBalancePrefix
+	filter := bloom.New(config.ExpectedAccounts, config.FalsePositiveRate)
+
+	if len(d.filePath) > 0 {
+		b, err := ioutil.ReadFile(path.Clean(d.filePath))
+		switch {
+		case err == nil:
+			stored := &bloom.Filter{}
+			if err := stored.UnmarshalBinary(b); err != nil {
+				log.Printf("%s: rebuilding account filter\n", err.Error())
+			} else if stored.Matches(config.ExpectedAccounts, config.FalsePositiveRate) {
+				filter = stored
+				persisted = true
+				prefix = accountFilterJournalPrefix
+			}
+		case !os.IsNotExist(err):
+			return nil, false, fmt.Errorf("%w: unable to read account filter", err)
+		}
+	}
+
+	dbTx := d.Database.ReadTransaction(ctx)
+	defer dbTx.Discard(ctx)
+
+	_, err := dbTx.Scan(
+		ctx,
+		prefix,
+		prefix,
+		func(k []byte, v []byte) error {
+			filter.Add(bytes.TrimPrefix(k, accountFilterJournalPrefix))
+			return nil
+		},
+		false,
+		false,
+	)
+	if err != nil {
+		return nil, false, fmt.Errorf("%w: unable to load account filter", err)
+	}
+
+	return filter, persisted, nil
+}
+
+func (d *accountFilterDatabase) persistLoop(frequency time.Duration) {
+	tc := time.NewTicker(frequency)
+	defer tc.Stop()
+
+	for {
+		select {
+		case <-d.done:
+			return
+		case <-tc.C:
+			if err := d.persist(context.Background()); err != nil {
+				log.Printf("%s: unable to persist account filter\n", err.Error())
+			}
+		}
+	}
+}
+
+// persist writes the filter to disk and then removes
+// all journal entries it includes. Journal entries are
+// listed before the filter is copied (every committed
+// entry is already in the filter) so entries committed
+// while persisting are not removed.
+func (d *accountFilterDatabase) persist(ctx context.Context) error {
+	journal := [][]byte{}
+	dbTx := d.Database.ReadTransaction(ctx)
+	_, err := dbTx.Scan(
+		ctx,
+		accountFilterJournalPrefix,
+		accountFilterJournalPrefix,
+		func(k []byte, v []byte) error {
+			journal = append(journal, append([]byte{}, k...))
+			return nil
+		},
+		false,
+		false,
+	)
+	dbTx.Discard(ctx)
+	if err != nil {
+		return fmt.Errorf("%w: unable to list journal", err)
+	}
+
+	if len(journal) == 0 {
+		return nil
+	}
+
+	if err := d.writeFilter(); err != nil {
+		return err
+	}
+
+	for len(journal) > 0 {
+		size := accountFilterJournalBatch
+		if len(journal) < size {
+			size = len(journal)
+		}
+
+		dbTx := d.Database.WriteTransaction(ctx, string(accountFilterJournalPrefix), false)
+		for _, k := range journal[:size] {
+			if err := dbTx.Delete(ctx, k); err != nil {
+				dbTx.Discard(ctx)
+				return fmt.Errorf("%w: unable to remove journal entry", err)
+			}
+		}
+
+		if err := dbTx.Commit(ctx); err != nil {
+			return fmt.Errorf("%w: unable to remove journal entries", err)
+		}
+
+		journal = journal[size:]
+	}
+
+	return nil
+}
+
+// writeFilter atomically replaces the persisted filter.
+func (d *accountFilterDatabase) writeFilter() error {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	if d.invalid {
+		return nil
+	}
+
+	b, err := d.filter.MarshalBinary()
+	if err != nil {
+		return err
+	}
+
+	tmpPath := d.filePath + ".tmp"
+	if err := ioutil.WriteFile(tmpPath, b, accountFilterFileMode); err != nil {
+		return err
+	}
+
+	return os.Rename(tmpPath, d.filePath)
+}
+
+// invalidate removes the persisted filter because it may
+// be missing keys that are committed in the future (which
+// would cause lookups of those keys to be skipped).
+func (d *accountFilterDatabase) invalidate() {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	if d.invalid || len(d.filePath) == 0 {
+		return
+	}
+
+	d.invalid = true
+	if err := os.Remove(d.filePath); err != nil && !os.IsNotExist(err) {
+		log.Printf("%s: unable to remove account filter\n", err.Error())
+	}
+}
+
+// Transaction returns an exclusive transaction that
+// maintains the account filter.
+func (d *accountFilterDatabase) Transaction(ctx context.Context) database.Transaction {
+	return &accountFilterTransaction{
+		Transaction: d.Database.Transaction(ctx),
+		db:          d,
+	}
+}
+
+// ReadTransaction returns a read transaction
+// that consults the account filter.
+func (d *accountFilterDatabase) ReadTransaction(ctx context.Context) database.Transaction {
+	return &accountFilterTransaction{
+		Transaction: d.Database.ReadTransaction(ctx),
+		db:          d,
+	}
+}
+
+// WriteTransaction returns a granular write transaction
+// that maintains the account filter.
+func (d *accountFilterDatabase) WriteTransaction(
+	ctx context.Context,
+	identifier string,
+	priority bool,
+) database.Transaction {
+	return &accountFilterTransaction{
+		Transaction: d.Database.WriteTransaction(ctx, identifier, priority),
+		db:          d,
+	}
+}
+
+// Close persists the filter and closes
+// the underlying database.
+func (d *accountFilterDatabase) Close(ctx context.Context) error {
+	if len(d.filePath) > 0 {
+		close(d.done)
+		if err := d.persist(ctx); err != nil {
+			log.Printf("%s: unable to persist account filter\n", err.Error())
+		}
+	}
+
+	return d.Database.Close(ctx)
+}
+
+// accountFilterTransaction adds balance keys to the filter
+// (and the journal) when they are set and skips lookups of
+// balance keys that are not in the filter.
+type accountFilterTransaction struct {
+	database.Transaction
+
+	db        *accountFilterDatabase
+	added     [][]byte
+	committed bool
+}
+
+// Set stores value at key.
+func (t *accountFilterTransaction) Set(
+	ctx context.Context,
+	key []byte,
+	value []byte,
+	reclaimValue bool,
+) error {
+	// Keys that may already be in the committed filter must
+	// already be in the persisted filter or the journal (false
+	// positives set the same bits as the committed keys that
+	// caused them).
+	if bytes.HasPrefix(key, accountBalancePrefix) && !t.db.committed.Test(key) {
+		t.db.filter.Add(key)
+		t.added = append(t.added, append([]byte{}, key...))
+
+		journalKey := append(append([]byte{}, accountFilterJournalPrefix...), key...)
+		if err := t.Transaction.Set(ctx, journalKey, []byte{}, false); err != nil {
+			return fmt.Errorf("%w: unable to journal account", err)
+		}
+	}
+
+	return t.Transaction.Set(ctx, key, value, reclaimValue)
+}
+
+// Get returns the value stored at key.
+func (t *accountFilterTransaction) Get(ctx context.Context, key []byte) (bool, []byte, error) {
+	if bytes.HasPrefix(key, accountBalancePrefix) && !t.db.filter.Test(key) {
+		return false, nil, nil
+	}
+
+	return t.Transaction.Get(ctx, key)
+}
+
+// Commit commits the transaction.
+func (t *accountFilterTransaction) Commit(ctx context.Context) error {
+	t.committed = true
+	if err := t.Transaction.Commit(ctx); err != nil {
+		if len(t.added) > 0 {
+			t.db.invalidate()
+		}
+
+		return err
+	}
+
+	for _, key := range t.added {
+		t.db.committed.Add(key)
+	}
+
+	return nil
+}
+
+// Discard discards the transaction.
+func (t *accountFilterTransaction) Discard(ctx context.Context) {
+	if !t.committed && len(t.added) > 0 {
+		t.db.invalidate()
+	}
+
+	t.Transaction.Discard(ctx)
+}
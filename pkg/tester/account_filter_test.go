@@ -0,0 +1,190 @@
+// Copyright 2022 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tester
+
+import (
+	"context"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/coinbase/rosetta-cli/configuration"
+
+	"github.com/coinbase/rosetta-sdk-go/storage/database"
+	"github.com/stretchr/testify/assert"
+)
+
+var testAccountFilterConfig = &configuration.AccountFilterConfiguration{
+	ExpectedAccounts:  1000,
+	FalsePositiveRate: 0.001,
+	PersistFrequency:  3600,
+}
+
+func setKey(ctx context.Context, t *testing.T, db database.Database, key string) {
+	txn := db.WriteTransaction(ctx, key, false)
+	assert.NoError(t, txn.Set(ctx, []byte(key), []byte(key), false))
+	assert.NoError(t, txn.Commit(ctx))
+}
+
+func TestAccountFilterDatabase_Load(t *testing.T) {
+	ctx := context.Background()
+	underlying := newTestDatabase(t)
+	setKey(ctx, t, underlying, "bal/a")
+
+	db, err := newAccountFilterDatabase(ctx, underlying, testAccountFilterConfig, "")
+	assert.NoError(t, err)
+	defer db.Close(ctx)
+
+	assert.True(t, stored(ctx, t, db, "bal/a"))
+
+	// Balance keys not in the filter are
+	// never read from the underlying database.
+	setKey(ctx, t, underlying, "bal/b")
+	assert.False(t, stored(ctx, t, db, "bal/b"))
+
+	setKey(ctx, t, db, "bal/c")
+	assert.True(t, stored(ctx, t, db, "bal/c"))
+}
+
+func TestAccountFilterDatabase_Persist(t *testing.T) {
+	ctx := context.Background()
+	dir := newTestDir(t)
+	filePath := path.Join(dir, accountFilterFile)
+	storePath := path.Join(dir, "store")
+
+	db, err := newAccountFilterDatabase(
+		ctx,
+		openTestDatabase(t, storePath),
+		testAccountFilterConfig,
+		filePath,
+	)
+	assert.NoError(t, err)
+	assert.FileExists(t, filePath)
+
+	setKey(ctx, t, db, "bal/a")
+	assert.True(t, stored(ctx, t, db.Database, "filterjournal/bal/a"))
+
+	// Closing persists the filter and removes the journal.
+	assert.NoError(t, db.Close(ctx))
+
+	underlying := openTestDatabase(t, storePath)
+	assert.False(t, stored(ctx, t, underlying, "filterjournal/bal/a"))
+
+	// Keys that are not journaled are not loaded
+	// once the filter is persisted.
+	setKey(ctx, t, underlying, "bal/b")
+
+	db, err = newAccountFilterDatabase(ctx, underlying, testAccountFilterConfig, filePath)
+	assert.NoError(t, err)
+	defer db.Close(ctx)
+
+	assert.True(t, stored(ctx, t, db, "bal/a"))
+	assert.False(t, stored(ctx, t, db, "bal/b"))
+}
+
+func TestAccountFilterDatabase_JournalReplay(t *testing.T) {
+	ctx := context.Background()
+	dir := newTestDir(t)
+	filePath := path.Join(dir, accountFilterFile)
+	storePath := path.Join(dir, "store")
+
+	db, err := newAccountFilterDatabase(
+		ctx,
+		openTestDatabase(t, storePath),
+		testAccountFilterConfig,
+		filePath,
+	)
+	assert.NoError(t, err)
+
+	setKey(ctx, t, db, "bal/a")
+
+	// Stop without persisting the filter
+	// (like an unclean shutdown).
+	close(db.done)
+	assert.NoError(t, db.Database.Close(ctx))
+
+	db, err = newAccountFilterDatabase(
+		ctx,
+		openTestDatabase(t, storePath),
+		testAccountFilterConfig,
+		filePath,
+	)
+	assert.NoError(t, err)
+	defer db.Close(ctx)
+
+	assert.True(t, stored(ctx, t, db, "bal/a"))
+}
+
+func TestAccountFilterDatabase_JournalFalsePositives(t *testing.T) {
+	ctx := context.Background()
+
+	// A filter with a single bit reports every
+	// key as a false positive once a key is added.
+	config := &configuration.AccountFilterConfiguration{
+		ExpectedAccounts:  1,
+		FalsePositiveRate: 0.99,
+		PersistFrequency:  3600,
+	}
+
+	underlying := newTestDatabase(t)
+	db, err := newAccountFilterDatabase(
+		ctx,
+		underlying,
+		config,
+		path.Join(newTestDir(t), accountFilterFile),
+	)
+	assert.NoError(t, err)
+	defer db.Close(ctx)
+
+	pending := db.WriteTransaction(ctx, "pending", false)
+	assert.NoError(t, pending.Set(ctx, []byte("bal/a"), []byte("bal/a"), false))
+
+	// bal/b is a false positive of the uncommitted
+	// bal/a, so it must still be journaled.
+	setKey(ctx, t, db, "bal/b")
+	assert.True(t, stored(ctx, t, underlying, "filterjournal/bal/b"))
+
+	pending.Discard(ctx)
+}
+
+func TestAccountFilterDatabase_InvalidateOnDiscard(t *testing.T) {
+	ctx := context.Background()
+	filePath := path.Join(newTestDir(t), accountFilterFile)
+
+	db, err := newAccountFilterDatabase(ctx, newTestDatabase(t), testAccountFilterConfig, filePath)
+	assert.NoError(t, err)
+	defer db.Close(ctx)
+
+	// Discarding a transaction that did
+	// not add keys keeps the filter.
+	txn := db.WriteTransaction(ctx, "other", false)
+	assert.NoError(t, txn.Set(ctx, []byte("other"), []byte("other"), false))
+	txn.Discard(ctx)
+	assert.FileExists(t, filePath)
+
+	txn = db.WriteTransaction(ctx, "bal/a", false)
+	assert.NoError(t, txn.Set(ctx, []byte("bal/a"), []byte("bal/a"), false))
+	txn.Discard(ctx)
+
+	_, err = os.Stat(filePath)
+	assert.True(t, os.IsNotExist(err))
+
+	// The filter is not persisted again
+	// once it has been invalidated.
+	setKey(ctx, t, db, "bal/b")
+	assert.NoError(t, db.persist(ctx))
+	_, err = os.Stat(filePath)
+	assert.True(t, os.IsNotExist(err))
+}
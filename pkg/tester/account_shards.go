@@ -0,0 +1,119 @@
+// Copyright 2022 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tester
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"github.com/coinbase/rosetta-sdk-go/storage/database"
+	"github.com/coinbase/rosetta-sdk-go/types"
+	"golang.org/x/sync/errgroup"
+)
+
+const (
+	// accountNamespace is the prefix of accounts stored
+	// by BalanceStorage.
+	accountNamespace = "acc"
+
+	// accountShardDigits are the leading characters of
+	// the hex encoded account hash that follows
+	// accountNamespace in each account key.
+	accountShardDigits = "0123456789abcdef"
+)
+
+// accountShardPrefix returns the prefix of all account keys
+// in the shard of the account hash starting with digit.
+func accountShardPrefix(digit byte) []byte {
+	return []byte(fmt.Sprintf("%s/%c", accountNamespace, digit))
+}
+
+// getAllAccountCurrency returns all accounts stored by BalanceStorage.
+// BalanceStorage keys accounts by the hash of the account, so the
+// key range is split into disjoint shards on the first hash digit
+// which are scanned in parallel instead of with a single scan.
+// Accounts are returned in key order, like
+// BalanceStorage.GetAllAccountCurrency.
+func getAllAccountCurrency(
+	ctx context.Context,
+	db database.Database,
+) ([]*types.AccountCurrency, error) {
+	log.Println("Loading previously seen accounts (this could take a while)...")
+
+	shards := make([][]*types.AccountCurrency, len(accountShardDigits))
+	g, ctx := errgroup.WithContext(ctx)
+	for i := range accountShardDigits {
+		i := i
+		g.Go(func() error {
+			accounts, err := scanAccountShard(ctx, db, accountShardPrefix(accountShardDigits[i]))
+			if err != nil {
+				return err
+			}
+
+			shards[i] = accounts
+			return nil
+		})
+	}
+
+	if err := g.Wait(); err != nil {
+		return nil, err
+	}
+
+	accounts := []*types.AccountCurrency{}
+	for _, shard := range shards {
+		accounts = append(accounts, shard...)
+	}
+
+	return accounts, nil
+}
+
+// scanAccountShard returns all accounts stored under prefix.
+func scanAccountShard(
+	ctx context.Context,
+	db database.Database,
+	prefix []byte,
+) ([]*types.AccountCurrency, error) {
+	txn := db.ReadTransaction(ctx)
+	defer txn.Discard(ctx)
+
+	accounts := []*types.AccountCurrency{}
+	_, err := txn.Scan(
+		ctx,
+		prefix,
+		prefix,
+		func(k []byte, v []byte) error {
+			var accCurrency types.AccountCurrency
+			// Memory must not be reclaimed during a scan
+			if err := db.Encoder().DecodeAccountCurrency(v, &accCurrency, false); err != nil {
+				return fmt.Errorf(
+					"%w: unable to parse account entry %s",
+					err,
+					string(k),
+				)
+			}
+
+			accounts = append(accounts, &accCurrency)
+			return nil
+		},
+		false,
+		false,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("%w: unable to scan accounts in %s", err, string(prefix))
+	}
+
+	return accounts, nil
+}
@@ -0,0 +1,240 @@
+// Copyright 2022 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tester
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path"
+	"sort"
+	"sync"
+
+	"github.com/coinbase/rosetta-cli/configuration"
+	"github.com/coinbase/rosetta-cli/pkg/processor"
+
+	"github.com/coinbase/rosetta-sdk-go/storage/modules"
+	"github.com/coinbase/rosetta-sdk-go/types"
+	"github.com/coinbase/rosetta-sdk-go/utils"
+	"github.com/fatih/color"
+	"github.com/neilotoole/errgroup"
+)
+
+// AuditMismatch is an account whose computed balance
+// differs from its live balance at an audited block (or
+// whose balance could not be retrieved).
+type AuditMismatch struct {
+	Account  *types.AccountIdentifier `json:"account_identifier"`
+	Currency *types.Currency          `json:"currency"`
+	Computed string                   `json:"computed_balance,omitempty"`
+	Live     string                   `json:"live_balance,omitempty"`
+	Error    string                   `json:"error,omitempty"`
+}
+
+// AuditReport is the result of reconciling
+// every known account at a block.
+type AuditReport struct {
+	Block      *types.BlockIdentifier `json:"block_identifier"`
+	Accounts   int                    `json:"accounts"`
+	Reconciled int                    `json:"reconciled"`
+	Mismatches []*AuditMismatch       `json:"mismatches"`
+}
+
+// heightAuditor reconciles every known
+// account at configured block heights.
+type heightAuditor struct {
+	config         *configuration.AuditConfiguration
+	concurrency    int
+	helper         *processor.ReconcilerHelper
+	balanceStorage *modules.BalanceStorage
+	heights        map[int64]struct{}
+}
+
+// newHeightAuditor returns a new *heightAuditor
+// (or nil if no heights are audited).
+func newHeightAuditor(
+	config *configuration.Configuration,
+	helper *processor.ReconcilerHelper,
+	balanceStorage *modules.BalanceStorage,
+) *heightAuditor {
+	if config.Data.Audit == nil {
+		return nil
+	}
+
+	heights := map[int64]struct{}{}
+	for _, height := range config.Data.Audit.Heights {
+		heights[height] = struct{}{}
+	}
+
+	concurrency := int(config.Data.InactiveReconciliationConcurrency)
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	return &heightAuditor{
+		config:         config.Data.Audit,
+		concurrency:    concurrency,
+		helper:         helper,
+		balanceStorage: balanceStorage,
+		heights:        heights,
+	}
+}
+
+// shouldAudit returns a boolean indicating
+// if the block at index should be audited.
+func (a *heightAuditor) shouldAudit(index int64) bool {
+	if a.config.Interval > 0 && index%a.config.Interval == 0 {
+		return true
+	}
+
+	_, ok := a.heights[index]
+	return ok
+}
+
+// audit reconciles every known account at block
+// and writes the report to the audit directory.
+func (a *heightAuditor) audit(ctx context.Context, block *types.BlockIdentifier) error {
+	color.Cyan("Auditing all accounts at block %d", block.Index)
+
+	accounts, err := a.balanceStorage.GetAllAccountCurrency(ctx)
+	if err != nil {
+		return fmt.Errorf("%w: unable to load accounts to audit", err)
+	}
+
+	report := &AuditReport{
+		Block:      block,
+		Accounts:   len(accounts),
+		Mismatches: []*AuditMismatch{},
+	}
+
+	var lock sync.Mutex
+	g, gctx := errgroup.WithContextN(ctx, a.concurrency, a.concurrency)
+	for i := range accounts {
+		account := accounts[i]
+		g.Go(func() error {
+			mismatch := a.reconcile(gctx, account, block)
+
+			lock.Lock()
+			defer lock.Unlock()
+			if mismatch != nil {
+				report.Mismatches = append(report.Mismatches, mismatch)
+			} else {
+				report.Reconciled++
+			}
+
+			return nil
+		})
+	}
+
+	if err := g.Wait(); err != nil {
+		return err
+	}
+
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+
+	sort.Slice(report.Mismatches, func(i, j int) bool {
+		return types.Hash(report.Mismatches[i]) < types.Hash(report.Mismatches[j])
+	})
+
+	if err := os.MkdirAll(a.config.Directory, failureArtifactsDirMode); err != nil {
+		return fmt.Errorf("%w: unable to create audit directory", err)
+	}
+
+	reportPath := path.Join(a.config.Directory, fmt.Sprintf("%d.json", block.Index))
+	if err := utils.SerializeAndWrite(reportPath, report); err != nil {
+		return fmt.Errorf("%w: unable to write audit report", err)
+	}
+
+	if len(report.Mismatches) > 0 {
+		color.Red(
+			"Audit at block %d found %d mismatches in %d accounts (written to %s)",
+			block.Index,
+			len(report.Mismatches),
+			report.Accounts,
+			reportPath,
+		)
+		return nil
+	}
+
+	color.Green(
+		"Audit at block %d reconciled %d accounts (written to %s)",
+		block.Index,
+		report.Accounts,
+		reportPath,
+	)
+	return nil
+}
+
+// reconcile compares the computed and live balances of
+// account at block and returns the mismatch (if any).
+func (a *heightAuditor) reconcile(
+	ctx context.Context,
+	account *types.AccountCurrency,
+	block *types.BlockIdentifier,
+) *AuditMismatch {
+	mismatch := &AuditMismatch{
+		Account:  account.Account,
+		Currency: account.Currency,
+	}
+
+	dbTx := a.helper.DatabaseTransaction(ctx)
+	computed, err := a.helper.ComputedBalance(
+		ctx,
+		dbTx,
+		account.Account,
+		account.Currency,
+		block.Index,
+	)
+	dbTx.Discard(ctx)
+	if err != nil {
+		mismatch.Error = fmt.Sprintf("unable to get computed balance: %s", err.Error())
+		return mismatch
+	}
+	mismatch.Computed = computed.Value
+
+	live, liveBlock, err := a.helper.LiveBalance(
+		ctx,
+		account.Account,
+		account.Currency,
+		block.Index,
+	)
+	if err != nil {
+		mismatch.Error = fmt.Sprintf("unable to get live balance: %s", err.Error())
+		return mismatch
+	}
+	mismatch.Live = live.Value
+
+	if types.Hash(liveBlock) != types.Hash(block) {
+		mismatch.Error = fmt.Sprintf(
+			"live balance is at block %s",
+			types.PrintStruct(liveBlock),
+		)
+		return mismatch
+	}
+
+	difference, err := types.SubtractValues(computed.Value, live.Value)
+	if err != nil {
+		mismatch.Error = fmt.Sprintf("unable to compare balances: %s", err.Error())
+		return mismatch
+	}
+
+	if difference != "0" {
+		return mismatch
+	}
+
+	return nil
+}
@@ -0,0 +1,197 @@
+// Copyright 2022 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tester
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"github.com/coinbase/rosetta-cli/configuration"
+
+	"github.com/coinbase/rosetta-sdk-go/parser"
+	"github.com/coinbase/rosetta-sdk-go/types"
+)
+
+// backwardSyncLogInterval is the number of blocks
+// validated between each backward sync progress log.
+const backwardSyncLogInterval = 1000
+
+// syncBackward validates blocks from the current tip toward
+// genesis (or until the configured depth is reached). Nothing
+// is stored, so the forward sync is not affected.
+func (t *DataTester) syncBackward(ctx context.Context) error {
+	backwardSync := t.config.Data.BackwardSync
+	status, fetchErr := t.fetcher.NetworkStatusRetry(ctx, t.network, nil)
+	if fetchErr != nil {
+		return fmt.Errorf("%w: unable to get network status", fetchErr.Err)
+	}
+
+	tip := status.CurrentBlockIdentifier
+	log.Printf("Syncing backward from block %d (%s)\n", tip.Index, tip.Hash)
+
+	reconcile := t.historicalBalanceEnabled && shouldReconcile(t.config)
+	next := tip
+	var blocks, reconciliations int64
+	for {
+		block, fetchErr := t.fetcher.BlockRetry(
+			ctx,
+			t.network,
+			types.ConstructPartialBlockIdentifier(next),
+		)
+		if fetchErr != nil {
+			return fmt.Errorf("%w: unable to fetch block %d", fetchErr.Err, next.Index)
+		}
+
+		if block == nil {
+			return fmt.Errorf("block %d (%s) is omitted", next.Index, next.Hash)
+		}
+
+		if types.Hash(block.BlockIdentifier) != types.Hash(next) {
+			return fmt.Errorf(
+				"requested block %s but received %s",
+				types.PrintStruct(next),
+				types.PrintStruct(block.BlockIdentifier),
+			)
+		}
+
+		genesis := block.BlockIdentifier.Index == t.genesisBlock.Index
+		if reconcile && !genesis {
+			reconciled, err := t.reconcileBackward(ctx, block)
+			if err != nil {
+				return err
+			}
+
+			reconciliations += reconciled
+		}
+
+		blocks++
+		if blocks%backwardSyncLogInterval == 0 {
+			log.Printf(
+				"Synced backward to block %d (%d blocks, %d reconciliations)\n",
+				block.BlockIdentifier.Index,
+				blocks,
+				reconciliations,
+			)
+		}
+
+		if genesis || blocks == backwardSync.Depth {
+			break
+		}
+
+		next = block.ParentBlockIdentifier
+	}
+
+	log.Printf(
+		"Backward sync validated %d blocks (%d reconciliations) from %d to %d\n",
+		blocks,
+		reconciliations,
+		tip.Index,
+		next.Index,
+	)
+
+	if backwardSync.ForwardSyncDisabled {
+		t.endCondition = configuration.BackwardSyncEndCondition
+		t.endConditionDetail = fmt.Sprintf("Blocks: %d", blocks)
+		t.cancel()
+	}
+
+	return nil
+}
+
+// reconcileBackward compares the balance changes in block
+// with the difference between the live balances at block and
+// at its parent (allowing any matching balance exemption).
+// It returns the number of balances compared.
+func (t *DataTester) reconcileBackward(
+	ctx context.Context,
+	block *types.Block,
+) (int64, error) {
+	changes, err := t.parser.BalanceChanges(ctx, block, false)
+	if err != nil {
+		return -1, fmt.Errorf(
+			"%w: unable to compute balance changes in block %d",
+			err,
+			block.BlockIdentifier.Index,
+		)
+	}
+
+	for _, change := range changes {
+		current, err := t.liveBalance(ctx, change, block.BlockIdentifier)
+		if err != nil {
+			return -1, err
+		}
+
+		parent, err := t.liveBalance(ctx, change, block.ParentBlockIdentifier)
+		if err != nil {
+			return -1, err
+		}
+
+		difference, err := types.SubtractValues(current, parent)
+		if err != nil {
+			return -1, fmt.Errorf("%w: unable to subtract balances", err)
+		}
+
+		if difference == change.Difference {
+			continue
+		}
+
+		mismatch, err := types.SubtractValues(difference, change.Difference)
+		if err != nil {
+			return -1, fmt.Errorf("%w: unable to subtract balance changes", err)
+		}
+
+		exemptions := t.parser.FindExemptions(change.Account, change.Currency)
+		if parser.MatchBalanceExemption(exemptions, mismatch) != nil {
+			continue
+		}
+
+		return -1, fmt.Errorf(
+			"%s in block %d: balance changed by %s but operations changed it by %s",
+			types.PrintStruct(change.Account),
+			block.BlockIdentifier.Index,
+			difference,
+			change.Difference,
+		)
+	}
+
+	return int64(len(changes)), nil
+}
+
+// liveBalance returns the balance of the account and
+// currency of change at block (as reported by the node).
+func (t *DataTester) liveBalance(
+	ctx context.Context,
+	change *parser.BalanceChange,
+	block *types.BlockIdentifier,
+) (string, error) {
+	_, amounts, _, fetchErr := t.fetcher.AccountBalanceRetry(
+		ctx,
+		t.network,
+		change.Account,
+		types.ConstructPartialBlockIdentifier(block),
+		[]*types.Currency{change.Currency},
+	)
+	if fetchErr != nil {
+		return "", fmt.Errorf(
+			"%w: unable to get balance of %s at block %d",
+			fetchErr.Err,
+			types.PrintStruct(change.Account),
+			block.Index,
+		)
+	}
+
+	return types.ExtractAmount(amounts, change.Currency).Value, nil
+}
@@ -0,0 +1,287 @@
+// Copyright 2022 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tester
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/coinbase/rosetta-sdk-go/storage/database"
+)
+
+const (
+	// defaultBatchFlushInterval is the maximum time a committed
+	// block can wait in an open batch before it is written (if
+	// block_commit_flush_interval_ms is not populated).
+	defaultBatchFlushInterval = time.Second
+
+	// blockSyncIdentifier is the write transaction identifier
+	// used by modules.BlockStorage to add and remove blocks.
+	blockSyncIdentifier = "blockSyncIdentifier"
+)
+
+var _ database.Database = (*batchedDatabase)(nil)
+
+// batchedDatabase implements the database.Database interface by
+// applying up to size consecutive block commits (the block, its
+// transactions, balance changes, and counters) in a single write
+// to the underlying database.
+//
+// All other write transactions flush the open batch before they
+// start (so they never wait on it) and read transactions only
+// observe flushed batches. If a transaction in a batch is
+// discarded without being committed, the entire batch is
+// discarded (which leaves storage at the last flushed block).
+//
+// If a batch cannot be written, its blocks are lost. The error is
+// returned by every later block commit (so syncing halts instead
+// of continuing on storage that is missing those blocks).
+type batchedDatabase struct {
+	database.Database
+
+	size          int
+	flushInterval time.Duration
+
+	// writer is held while a block commit
+	// is open or a batch is flushed.
+	writer sync.Mutex
+
+	// batch is the open transaction of the
+	// underlying database (if any).
+	batch   database.Transaction
+	pending int
+	timer   *time.Timer
+
+	// err is the error of the first failed flush (if any).
+	err error
+}
+
+func newBatchedDatabase(
+	db database.Database,
+	size int,
+	flushInterval time.Duration,
+) *batchedDatabase {
+	if flushInterval <= 0 {
+		flushInterval = defaultBatchFlushInterval
+	}
+
+	return &batchedDatabase{Database: db, size: size, flushInterval: flushInterval}
+}
+
+// Transaction flushes the open batch and then returns an
+// exclusive transaction of the underlying database (whose Commit
+// returns the flush error if the open batch could not be written).
+func (d *batchedDatabase) Transaction(ctx context.Context) database.Transaction {
+	if err := d.Flush(ctx); err != nil {
+		return &failedTransaction{Transaction: d.Database.Transaction(ctx), err: err}
+	}
+
+	return d.Database.Transaction(ctx)
+}
+
+// WriteTransaction returns a transaction that is applied in the
+// open batch if it is a block commit. Otherwise, it flushes the
+// open batch and then returns a granular write transaction of
+// the underlying database (whose Commit returns the flush error
+// if the open batch could not be written).
+func (d *batchedDatabase) WriteTransaction(
+	ctx context.Context,
+	identifier string,
+	priority bool,
+) database.Transaction {
+	if identifier == blockSyncIdentifier && priority {
+		d.writer.Lock()
+		if d.batch == nil {
+			d.batch = d.Database.WriteTransaction(ctx, identifier, priority)
+		}
+
+		return &batchedTransaction{Transaction: d.batch, db: d}
+	}
+
+	if err := d.Flush(ctx); err != nil {
+		return &failedTransaction{
+			Transaction: d.Database.WriteTransaction(ctx, identifier, priority),
+			err:         err,
+		}
+	}
+
+	return d.Database.WriteTransaction(ctx, identifier, priority)
+}
+
+// Flush writes the open batch (if any).
+func (d *batchedDatabase) Flush(ctx context.Context) error {
+	d.writer.Lock()
+	defer d.writer.Unlock()
+
+	return d.flush(ctx)
+}
+
+// flush must be called while holding the writer lock.
+func (d *batchedDatabase) flush(ctx context.Context) error {
+	if d.timer != nil {
+		d.timer.Stop()
+		d.timer = nil
+	}
+
+	if d.batch == nil {
+		return nil
+	}
+
+	batch := d.batch
+	d.batch = nil
+	d.pending = 0
+
+	if err := batch.Commit(ctx); err != nil {
+		if d.err == nil {
+			d.err = fmt.Errorf("%w: unable to flush batch", err)
+		}
+
+		return d.err
+	}
+
+	return nil
+}
+
+// discard discards the open batch. It must
+// be called while holding the writer lock.
+func (d *batchedDatabase) discard(ctx context.Context) {
+	if d.timer != nil {
+		d.timer.Stop()
+		d.timer = nil
+	}
+
+	if d.batch != nil {
+		d.batch.Discard(ctx)
+	}
+
+	d.batch = nil
+	d.pending = 0
+}
+
+// Close flushes the open batch and closes the underlying
+// database. If any batch could not be written, the error
+// of that flush is returned.
+func (d *batchedDatabase) Close(ctx context.Context) error {
+	d.writer.Lock()
+	_ = d.flush(ctx) // any error is stored in d.err
+	flushErr := d.err
+	d.writer.Unlock()
+
+	if err := d.Database.Close(ctx); err != nil {
+		return err
+	}
+
+	return flushErr
+}
+
+// flushedReads returns a database.Database whose
+// read transactions flush the open batch first.
+func (d *batchedDatabase) flushedReads() database.Database {
+	return &flushedReadDatabase{batchedDatabase: d}
+}
+
+// flushedReadDatabase is used by components (like the
+// reconciler) that must observe every committed block.
+type flushedReadDatabase struct {
+	*batchedDatabase
+}
+
+// ReadTransaction flushes the open batch and then returns
+// a read transaction of the underlying database. A failed
+// flush is returned by the next block commit.
+func (d *flushedReadDatabase) ReadTransaction(ctx context.Context) database.Transaction {
+	if err := d.Flush(ctx); err != nil {
+		log.Printf("%s\n", err.Error())
+	}
+
+	return d.Database.ReadTransaction(ctx)
+}
+
+// batchedTransaction is a transaction in a batch. Commit
+// adds its changes to the batch and only writes the batch
+// once it is full.
+type batchedTransaction struct {
+	database.Transaction
+
+	db   *batchedDatabase
+	done bool
+}
+
+// Commit adds the transaction to the batch (and writes
+// the batch if it is full). If any earlier batch could
+// not be written, the batch is discarded and the error
+// of that flush is returned.
+func (t *batchedTransaction) Commit(ctx context.Context) error {
+	if t.done {
+		return nil
+	}
+
+	t.done = true
+	defer t.db.writer.Unlock()
+
+	if t.db.err != nil {
+		t.db.discard(ctx)
+		return t.db.err
+	}
+
+	t.db.pending++
+	if t.db.pending >= t.db.size {
+		return t.db.flush(ctx)
+	}
+
+	if t.db.timer == nil {
+		t.db.timer = time.AfterFunc(t.db.flushInterval, func() {
+			// A failed flush is returned by
+			// the next block commit.
+			if err := t.db.Flush(context.Background()); err != nil {
+				log.Printf("%s\n", err.Error())
+			}
+		})
+	}
+
+	return nil
+}
+
+// Discard discards the entire batch if the
+// transaction was not committed.
+func (t *batchedTransaction) Discard(ctx context.Context) {
+	if t.done {
+		return
+	}
+
+	t.done = true
+	defer t.db.writer.Unlock()
+
+	t.db.discard(ctx)
+}
+
+// failedTransaction is a transaction of the underlying
+// database that was started after the open batch could
+// not be written. Commit discards it and returns err.
+type failedTransaction struct {
+	database.Transaction
+
+	err error
+}
+
+// Commit discards the transaction and
+// returns the error of the failed flush.
+func (t *failedTransaction) Commit(ctx context.Context) error {
+	t.Transaction.Discard(ctx)
+	return t.err
+}
@@ -0,0 +1,185 @@
+// Copyright 2022 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tester
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/coinbase/rosetta-sdk-go/storage/database"
+	"github.com/coinbase/rosetta-sdk-go/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+var errTestCommit = errors.New("commit failed")
+
+func newTestDir(t *testing.T) string {
+	dir, err := utils.CreateTempDir()
+	assert.NoError(t, err)
+	t.Cleanup(func() { utils.RemoveTempDir(dir) })
+
+	return dir
+}
+
+func openTestDatabase(t *testing.T, dir string) database.Database {
+	db, err := database.NewBadgerDatabase(
+		context.Background(),
+		dir,
+		database.WithIndexCacheSize(database.TinyIndexCacheSize),
+	)
+	assert.NoError(t, err)
+
+	return db
+}
+
+func newTestDatabase(t *testing.T) database.Database {
+	return openTestDatabase(t, newTestDir(t))
+}
+
+func commitBlock(ctx context.Context, db database.Database, key string) error {
+	txn := db.WriteTransaction(ctx, blockSyncIdentifier, true)
+	if err := txn.Set(ctx, []byte(key), []byte(key), false); err != nil {
+		txn.Discard(ctx)
+		return err
+	}
+
+	return txn.Commit(ctx)
+}
+
+func stored(ctx context.Context, t *testing.T, db database.Database, key string) bool {
+	txn := db.ReadTransaction(ctx)
+	defer txn.Discard(ctx)
+
+	exists, _, err := txn.Get(ctx, []byte(key))
+	assert.NoError(t, err)
+
+	return exists
+}
+
+// failingDatabase fails every block commit.
+type failingDatabase struct {
+	database.Database
+}
+
+func (d *failingDatabase) WriteTransaction(
+	ctx context.Context,
+	identifier string,
+	priority bool,
+) database.Transaction {
+	txn := d.Database.WriteTransaction(ctx, identifier, priority)
+	if identifier != blockSyncIdentifier {
+		return txn
+	}
+
+	return &failedTransaction{Transaction: txn, err: errTestCommit}
+}
+
+func TestBatchedDatabase_FlushOnSize(t *testing.T) {
+	ctx := context.Background()
+	underlying := newTestDatabase(t)
+	db := newBatchedDatabase(underlying, 2, time.Hour)
+	defer db.Close(ctx)
+
+	assert.NoError(t, commitBlock(ctx, db, "block/1"))
+	assert.False(t, stored(ctx, t, underlying, "block/1"))
+
+	assert.NoError(t, commitBlock(ctx, db, "block/2"))
+	assert.True(t, stored(ctx, t, underlying, "block/1"))
+	assert.True(t, stored(ctx, t, underlying, "block/2"))
+}
+
+func TestBatchedDatabase_FlushOnInterval(t *testing.T) {
+	ctx := context.Background()
+	underlying := newTestDatabase(t)
+	db := newBatchedDatabase(underlying, 100, 10*time.Millisecond)
+	defer db.Close(ctx)
+
+	assert.NoError(t, commitBlock(ctx, db, "block/1"))
+	assert.Eventually(t, func() bool {
+		return stored(ctx, t, underlying, "block/1")
+	}, time.Second, 5*time.Millisecond)
+}
+
+func TestBatchedDatabase_FlushedReads(t *testing.T) {
+	ctx := context.Background()
+	db := newBatchedDatabase(newTestDatabase(t), 100, time.Hour)
+	defer db.Close(ctx)
+
+	assert.NoError(t, commitBlock(ctx, db, "block/1"))
+	assert.True(t, stored(ctx, t, db.flushedReads(), "block/1"))
+}
+
+func TestBatchedDatabase_Discard(t *testing.T) {
+	ctx := context.Background()
+	underlying := newTestDatabase(t)
+	db := newBatchedDatabase(underlying, 100, time.Hour)
+	defer db.Close(ctx)
+
+	assert.NoError(t, commitBlock(ctx, db, "block/1"))
+
+	txn := db.WriteTransaction(ctx, blockSyncIdentifier, true)
+	assert.NoError(t, txn.Set(ctx, []byte("block/2"), []byte("block/2"), false))
+	txn.Discard(ctx)
+
+	assert.NoError(t, db.Flush(ctx))
+	assert.False(t, stored(ctx, t, underlying, "block/1"))
+	assert.False(t, stored(ctx, t, underlying, "block/2"))
+
+	// Later batches are unaffected.
+	assert.NoError(t, commitBlock(ctx, db, "block/3"))
+	assert.NoError(t, db.Flush(ctx))
+	assert.True(t, stored(ctx, t, underlying, "block/3"))
+}
+
+func TestBatchedDatabase_FlushError(t *testing.T) {
+	ctx := context.Background()
+	underlying := &failingDatabase{Database: newTestDatabase(t)}
+	db := newBatchedDatabase(underlying, 100, time.Hour)
+
+	// The failed flush is returned by the write
+	// transaction that triggered it.
+	assert.NoError(t, commitBlock(ctx, db, "block/1"))
+	txn := db.WriteTransaction(ctx, "other", false)
+	assert.NoError(t, txn.Set(ctx, []byte("other"), []byte("other"), false))
+	assert.True(t, errors.Is(txn.Commit(ctx), errTestCommit))
+	assert.False(t, stored(ctx, t, underlying, "other"))
+
+	// Every later block commit returns the error.
+	assert.True(t, errors.Is(commitBlock(ctx, db, "block/2"), errTestCommit))
+	assert.True(t, errors.Is(commitBlock(ctx, db, "block/3"), errTestCommit))
+	assert.True(t, errors.Is(db.Close(ctx), errTestCommit))
+}
+
+func TestBatchedDatabase_IntervalFlushError(t *testing.T) {
+	ctx := context.Background()
+	db := newBatchedDatabase(
+		&failingDatabase{Database: newTestDatabase(t)},
+		100,
+		10*time.Millisecond,
+	)
+	defer db.Close(ctx)
+
+	assert.NoError(t, commitBlock(ctx, db, "block/1"))
+	assert.Eventually(t, func() bool {
+		db.writer.Lock()
+		defer db.writer.Unlock()
+
+		return db.err != nil
+	}, time.Second, 5*time.Millisecond)
+
+	assert.True(t, errors.Is(commitBlock(ctx, db, "block/2"), errTestCommit))
+}
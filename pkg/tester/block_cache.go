@@ -0,0 +1,86 @@
+// Copyright 2022 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tester
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/coinbase/rosetta-sdk-go/asserter"
+	storageErrs "github.com/coinbase/rosetta-sdk-go/storage/errors"
+	"github.com/coinbase/rosetta-sdk-go/types"
+)
+
+// blockCachePath is the path of the status server
+// that serves synced blocks to check:construction.
+const blockCachePath = "/block"
+
+// serveBlock answers a /block request with a block from
+// storage. Blocks requested by index are only served if they
+// are deeper than MaxReorgDepth (so they will not be orphaned).
+// A 404 is returned for any block that should be fetched from
+// the node instead.
+func (t *DataTester) serveBlock(w http.ResponseWriter, r *http.Request) {
+	var request types.BlockRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := asserter.PartialBlockIdentifier(request.BlockIdentifier); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if types.Hash(request.NetworkIdentifier) != types.Hash(t.network) {
+		http.Error(w, "network not synced", http.StatusNotFound)
+		return
+	}
+
+	ctx := r.Context()
+	head, err := t.blockStorage.GetHeadBlockIdentifier(ctx)
+	if errors.Is(err, storageErrs.ErrHeadBlockNotFound) {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if request.BlockIdentifier.Hash == nil &&
+		(request.BlockIdentifier.Index == nil ||
+			head.Index-*request.BlockIdentifier.Index < int64(t.config.MaxReorgDepth)) {
+		http.Error(w, "block may be orphaned", http.StatusNotFound)
+		return
+	}
+
+	block, err := t.blockStorage.GetBlock(ctx, request.BlockIdentifier)
+	if errors.Is(err, storageErrs.ErrBlockNotFound) {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(&types.BlockResponse{Block: block}); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,379 @@
+// Copyright 2022 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tester
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/coinbase/rosetta-cli/configuration"
+
+	"github.com/coinbase/rosetta-sdk-go/storage/modules"
+	"github.com/coinbase/rosetta-sdk-go/types"
+	"github.com/coinbase/rosetta-sdk-go/utils"
+)
+
+const (
+	// checkpointVersion is the version of the checkpoint
+	// format written by ExportCheckpoint.
+	checkpointVersion = 1
+
+	// checkpointManifest is the name of the manifest
+	// (the first entry) in a checkpoint archive.
+	checkpointManifest = "manifest.json"
+
+	// checkpointDataDirectory is the directory of storage
+	// files in a checkpoint archive.
+	checkpointDataDirectory = "data"
+)
+
+// CheckpointManifest describes the check:data storage
+// in a checkpoint archive.
+type CheckpointManifest struct {
+	Version    int                      `json:"version"`
+	Network    *types.NetworkIdentifier `json:"network_identifier"`
+	HeadBlock  *types.BlockIdentifier   `json:"head_block"`
+	ConfigHash string                   `json:"config_hash"`
+}
+
+// checkpointConfigHash returns the hash of the configuration
+// that determines the contents of check:data storage.
+func checkpointConfigHash(config *configuration.Configuration) string {
+	return types.Hash(&struct {
+		Network *types.NetworkIdentifier         `json:"network"`
+		Data    *configuration.DataConfiguration `json:"data"`
+	}{
+		Network: config.Network,
+		Data:    config.Data,
+	})
+}
+
+// checkpointPath returns the check:data storage path
+// in the configured data directory.
+func checkpointPath(config *configuration.Configuration) (string, error) {
+	if len(config.DataDirectory) == 0 {
+		return "", errors.New("data directory must be populated to transfer a checkpoint")
+	}
+
+	return path.Join(config.DataDirectory, dataCmdName, types.Hash(config.Network)), nil
+}
+
+// checkpointHead returns the head block of check:data storage.
+func checkpointHead(
+	ctx context.Context,
+	config *configuration.Configuration,
+) (*types.BlockIdentifier, error) {
+	localStore, err := openDataDatabase(ctx, config)
+	if err != nil {
+		return nil, err
+	}
+	defer localStore.Close(ctx)
+
+	head, err := modules.NewBlockStorage(
+		localStore,
+		config.SerialBlockWorkers,
+	).GetHeadBlockIdentifier(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("%w: unable to get head block", err)
+	}
+
+	return head, nil
+}
+
+// ExportCheckpoint writes a gzipped tar archive of the check:data
+// storage in the configured data directory to w. The first entry of
+// the archive is a *CheckpointManifest, so the archive can be verified
+// before it is extracted by ImportCheckpoint. This must not be called
+// while `check:data` is running on the same data directory.
+func ExportCheckpoint(
+	ctx context.Context,
+	config *configuration.Configuration,
+	w io.Writer,
+) (*CheckpointManifest, error) {
+	dataPath, err := checkpointPath(config)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := os.Stat(dataPath); err != nil {
+		return nil, fmt.Errorf("%w: unable to find check:data storage", err)
+	}
+
+	// The storage is closed before it is archived
+	// so all writes are flushed to disk.
+	head, err := checkpointHead(ctx, config)
+	if err != nil {
+		return nil, err
+	}
+
+	manifest := &CheckpointManifest{
+		Version:    checkpointVersion,
+		Network:    config.Network,
+		HeadBlock:  head,
+		ConfigHash: checkpointConfigHash(config),
+	}
+
+	manifestBytes, err := json.Marshal(manifest)
+	if err != nil {
+		return nil, fmt.Errorf("%w: unable to encode manifest", err)
+	}
+
+	gw := gzip.NewWriter(w)
+	tw := tar.NewWriter(gw)
+	if err := tw.WriteHeader(&tar.Header{
+		Name: checkpointManifest,
+		Mode: utils.DefaultFilePermissions,
+		Size: int64(len(manifestBytes)),
+	}); err != nil {
+		return nil, fmt.Errorf("%w: unable to write manifest", err)
+	}
+
+	if _, err := tw.Write(manifestBytes); err != nil {
+		return nil, fmt.Errorf("%w: unable to write manifest", err)
+	}
+
+	err = filepath.Walk(dataPath, func(filePath string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+
+		rel, err := filepath.Rel(dataPath, filePath)
+		if err != nil {
+			return err
+		}
+
+		return writeCheckpointFile(tw, filePath, path.Join(checkpointDataDirectory, rel), info)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%w: unable to archive storage", err)
+	}
+
+	if err := tw.Close(); err != nil {
+		return nil, fmt.Errorf("%w: unable to write checkpoint", err)
+	}
+
+	if err := gw.Close(); err != nil {
+		return nil, fmt.Errorf("%w: unable to write checkpoint", err)
+	}
+
+	return manifest, nil
+}
+
+func writeCheckpointFile(
+	tw *tar.Writer,
+	filePath string,
+	name string,
+	info os.FileInfo,
+) error {
+	f, err := os.Open(path.Clean(filePath))
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	if err := tw.WriteHeader(&tar.Header{
+		Name:    name,
+		Mode:    utils.DefaultFilePermissions,
+		Size:    info.Size(),
+		ModTime: info.ModTime(),
+	}); err != nil {
+		return err
+	}
+
+	_, err = io.Copy(tw, f)
+	return err
+}
+
+// ImportCheckpoint verifies the manifest of a checkpoint archive
+// written by ExportCheckpoint against the configuration and then
+// extracts the archived storage into the configured data directory
+// (which must not already contain check:data storage for the
+// network). The configuration must match the configuration the
+// checkpoint was created with.
+func ImportCheckpoint(
+	ctx context.Context,
+	config *configuration.Configuration,
+	r io.Reader,
+) (*CheckpointManifest, error) {
+	if err := assertWritableStorage(config); err != nil {
+		return nil, err
+	}
+
+	dataPath, err := checkpointPath(config)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := os.Stat(dataPath); err == nil {
+		return nil, fmt.Errorf("check:data storage already exists at %s", dataPath)
+	}
+
+	gr, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, fmt.Errorf("%w: unable to read checkpoint", err)
+	}
+	defer gr.Close()
+
+	tr := tar.NewReader(gr)
+	manifest, err := readCheckpointManifest(tr)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := verifyCheckpointManifest(config, manifest); err != nil {
+		return nil, err
+	}
+
+	if err := extractCheckpoint(ctx, config, tr, dataPath, manifest); err != nil {
+		// Partially extracted storage is
+		// removed so the import can be retried.
+		_ = os.RemoveAll(dataPath)
+		return nil, err
+	}
+
+	return manifest, nil
+}
+
+func extractCheckpoint(
+	ctx context.Context,
+	config *configuration.Configuration,
+	tr *tar.Reader,
+	dataPath string,
+	manifest *CheckpointManifest,
+) error {
+	for {
+		header, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("%w: unable to read checkpoint", err)
+		}
+
+		if err := extractCheckpointFile(tr, header, dataPath); err != nil {
+			return fmt.Errorf("%w: unable to extract %s", err, header.Name)
+		}
+	}
+
+	head, err := checkpointHead(ctx, config)
+	if err != nil {
+		return err
+	}
+
+	if types.Hash(head) != types.Hash(manifest.HeadBlock) {
+		return fmt.Errorf(
+			"extracted head block %s does not match manifest head block %s",
+			types.PrintStruct(head),
+			types.PrintStruct(manifest.HeadBlock),
+		)
+	}
+
+	return nil
+}
+
+func readCheckpointManifest(tr *tar.Reader) (*CheckpointManifest, error) {
+	header, err := tr.Next()
+	if err != nil {
+		return nil, fmt.Errorf("%w: unable to read checkpoint", err)
+	}
+
+	if header.Name != checkpointManifest {
+		return nil, fmt.Errorf("expected %s but found %s", checkpointManifest, header.Name)
+	}
+
+	manifest := &CheckpointManifest{}
+	if err := json.NewDecoder(tr).Decode(manifest); err != nil {
+		return nil, fmt.Errorf("%w: unable to decode manifest", err)
+	}
+
+	return manifest, nil
+}
+
+func verifyCheckpointManifest(
+	config *configuration.Configuration,
+	manifest *CheckpointManifest,
+) error {
+	if manifest.Version != checkpointVersion {
+		return fmt.Errorf("unsupported checkpoint version %d", manifest.Version)
+	}
+
+	if types.Hash(manifest.Network) != types.Hash(config.Network) {
+		return fmt.Errorf(
+			"checkpoint of %s cannot be imported for %s",
+			types.PrintStruct(manifest.Network),
+			types.PrintStruct(config.Network),
+		)
+	}
+
+	if manifest.HeadBlock == nil {
+		return errors.New("checkpoint head block is missing")
+	}
+
+	if manifest.ConfigHash != checkpointConfigHash(config) {
+		return errors.New("checkpoint was created with a different check:data configuration")
+	}
+
+	return nil
+}
+
+// extractCheckpointFile writes a storage file in a checkpoint
+// archive to dataPath. Entries outside of the data directory
+// of the archive are rejected.
+func extractCheckpointFile(tr *tar.Reader, header *tar.Header, dataPath string) error {
+	if header.Typeflag != tar.TypeReg {
+		return errors.New("unsupported archive entry")
+	}
+
+	name := path.Clean(header.Name)
+	rel := strings.TrimPrefix(name, checkpointDataDirectory+"/")
+	if rel == name || rel == ".." || strings.HasPrefix(rel, "../") {
+		return errors.New("archive entry is outside of the data directory")
+	}
+
+	target := path.Join(dataPath, rel)
+	if err := utils.EnsurePathExists(path.Dir(target)); err != nil {
+		return err
+	}
+
+	f, err := os.OpenFile(
+		target,
+		os.O_CREATE|os.O_EXCL|os.O_WRONLY,
+		os.FileMode(utils.DefaultFilePermissions),
+	)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	// #nosec
+	if _, err := io.Copy(f, tr); err != nil {
+		return err
+	}
+
+	return f.Sync()
+}
@@ -0,0 +1,85 @@
+// Copyright 2022 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tester
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"testing"
+
+	"github.com/coinbase/rosetta-sdk-go/storage/modules"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckpoint_Resume(t *testing.T) {
+	ctx := context.Background()
+	block := testBlock(5)
+
+	source := snapshotTestConfig(t)
+	_, err := ImportSnapshot(
+		ctx,
+		source,
+		writeTestSnapshot(t, source.Network, block, snapshotTestBalances),
+	)
+	assert.NoError(t, err)
+
+	var checkpoint bytes.Buffer
+	exported, err := ExportCheckpoint(ctx, source, &checkpoint)
+	assert.NoError(t, err)
+	assert.Equal(t, block.BlockIdentifier, exported.HeadBlock)
+
+	restored := snapshotTestConfig(t)
+	imported, err := ImportCheckpoint(ctx, restored, &checkpoint)
+	assert.NoError(t, err)
+	assert.Equal(t, exported, imported)
+	assertSnapshotStorage(ctx, t, restored, block.BlockIdentifier, snapshotTestBalances)
+
+	// Syncing resumes at the block after the checkpoint.
+	localStore, err := openDataDatabase(ctx, restored)
+	assert.NoError(t, err)
+	blockStorage := modules.NewBlockStorage(localStore, 1)
+	assert.NoError(t, blockStorage.AddBlock(ctx, testBlock(6)))
+	head, err := blockStorage.GetHeadBlockIdentifier(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, testBlock(6).BlockIdentifier, head)
+	assert.NoError(t, localStore.Close(ctx))
+}
+
+func TestCheckpoint_ConfigMismatch(t *testing.T) {
+	ctx := context.Background()
+
+	source := snapshotTestConfig(t)
+	_, err := ImportSnapshot(
+		ctx,
+		source,
+		writeTestSnapshot(t, source.Network, testBlock(5), snapshotTestBalances),
+	)
+	assert.NoError(t, err)
+
+	var checkpoint bytes.Buffer
+	_, err = ExportCheckpoint(ctx, source, &checkpoint)
+	assert.NoError(t, err)
+
+	restored := snapshotTestConfig(t)
+	restored.Data.SeparateBalanceStorage = true
+	_, err = ImportCheckpoint(ctx, restored, &checkpoint)
+	assert.Error(t, err)
+
+	dataPath, err := checkpointPath(restored)
+	assert.NoError(t, err)
+	_, err = os.Stat(dataPath)
+	assert.True(t, os.IsNotExist(err))
+}
@@ -0,0 +1,195 @@
+// Copyright 2022 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tester
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path"
+	"path/filepath"
+	"runtime"
+
+	"github.com/coinbase/rosetta-cli/configuration"
+
+	"github.com/coinbase/rosetta-sdk-go/storage/database"
+	"github.com/coinbase/rosetta-sdk-go/types"
+	"github.com/dgraph-io/badger/v2"
+)
+
+const (
+	// compactionDiscardRatio is the fraction of a value log
+	// file that must be stale for it to be rewritten.
+	compactionDiscardRatio = 0.5
+)
+
+// CompactionResult describes the space reclaimed by
+// compacting the database of a command.
+type CompactionResult struct {
+	Command     string `json:"command"`
+	Path        string `json:"path"`
+	SizeBefore  int64  `json:"size_before"`
+	SizeAfter   int64  `json:"size_after"`
+	ValueLogsGC int    `json:"value_logs_gc"`
+}
+
+// Reclaimed returns the number of bytes reclaimed.
+func (c *CompactionResult) Reclaimed() int64 {
+	return c.SizeBefore - c.SizeAfter
+}
+
+// CompactStorage compacts the LSM tree and garbage collects the
+// value logs of the check:data and check:construction databases
+// in the data directory (skipping any that do not exist). This
+// must not be called while either command is running on the same
+// data directory.
+func CompactStorage(
+	ctx context.Context,
+	config *configuration.Configuration,
+) ([]*CompactionResult, error) {
+	if err := assertWritableStorage(config); err != nil {
+		return nil, err
+	}
+
+	if len(config.DataDirectory) == 0 {
+		return nil, errors.New("data directory must be populated to compact storage")
+	}
+
+	compactionResults := []*CompactionResult{}
+	for _, cmdName := range []string{dataCmdName, constructionCmdName} {
+		dbPath := path.Join(config.DataDirectory, cmdName, types.Hash(config.Network))
+		if _, err := os.Stat(dbPath); os.IsNotExist(err) {
+			continue
+		}
+
+		result, err := compactDatabase(ctx, config, dbPath)
+		if err != nil {
+			return nil, fmt.Errorf("%w: unable to compact %s storage", err, cmdName)
+		}
+
+		result.Command = cmdName
+		compactionResults = append(compactionResults, result)
+
+		// Balances are stored in a subdirectory when
+		// separate_balance_storage is enabled.
+		balancePath := path.Join(dbPath, balanceDatabaseDirectory)
+		if _, err := os.Stat(balancePath); os.IsNotExist(err) {
+			continue
+		}
+
+		result, err = compactDatabase(ctx, config, balancePath)
+		if err != nil {
+			return nil, fmt.Errorf("%w: unable to compact %s balance storage", err, cmdName)
+		}
+
+		result.Command = cmdName
+		compactionResults = append(compactionResults, result)
+	}
+
+	return compactionResults, nil
+}
+
+func compactDatabase(
+	ctx context.Context,
+	config *configuration.Configuration,
+	dbPath string,
+) (*CompactionResult, error) {
+	sizeBefore, err := directorySize(dbPath)
+	if err != nil {
+		return nil, err
+	}
+
+	settings := database.DefaultBadgerOptions(dbPath)
+	if config.Badger != nil {
+		settings, err = applyBadgerConfiguration(settings, config.Badger)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// Compacting L0 on close ensures that all tables
+	// written during compaction are merged.
+	settings.CompactL0OnClose = true
+
+	db, err := badger.Open(settings)
+	if err != nil {
+		return nil, fmt.Errorf("%w: unable to open database", err)
+	}
+
+	log.Printf("Compacting %s (this could take a while)...\n", dbPath)
+	if err := db.Flatten(runtime.NumCPU()); err != nil {
+		_ = db.Close()
+		return nil, fmt.Errorf("%w: unable to flatten database", err)
+	}
+
+	valueLogsGC := 0
+	for ctx.Err() == nil {
+		err := db.RunValueLogGC(compactionDiscardRatio)
+		if errors.Is(err, badger.ErrNoRewrite) {
+			break
+		}
+
+		if err != nil {
+			_ = db.Close()
+			return nil, fmt.Errorf("%w: unable to garbage collect value log", err)
+		}
+
+		valueLogsGC++
+	}
+
+	if err := db.Close(); err != nil {
+		return nil, fmt.Errorf("%w: unable to close database", err)
+	}
+
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+
+	sizeAfter, err := directorySize(dbPath)
+	if err != nil {
+		return nil, err
+	}
+
+	return &CompactionResult{
+		Path:        dbPath,
+		SizeBefore:  sizeBefore,
+		SizeAfter:   sizeAfter,
+		ValueLogsGC: valueLogsGC,
+	}, nil
+}
+
+// directorySize returns the total size of all
+// files in a directory (in bytes).
+func directorySize(dir string) (int64, error) {
+	size := int64(0)
+	err := filepath.Walk(dir, func(_ string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if !info.IsDir() {
+			size += info.Size()
+		}
+
+		return nil
+	})
+	if err != nil {
+		return -1, fmt.Errorf("%w: unable to compute size of %s", err, dir)
+	}
+
+	return size, nil
+}
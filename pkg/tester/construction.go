@@ -36,7 +36,6 @@ import (
 	"github.com/coinbase/rosetta-sdk-go/storage/database"
 	storageErrs "github.com/coinbase/rosetta-sdk-go/storage/errors"
 	"github.com/coinbase/rosetta-sdk-go/storage/modules"
-	"github.com/coinbase/rosetta-sdk-go/syncer"
 	"github.com/coinbase/rosetta-sdk-go/types"
 	"github.com/coinbase/rosetta-sdk-go/utils"
 	"github.com/fatih/color"
@@ -73,6 +72,65 @@ type ConstructionTester struct {
 	reachedEndConditions bool
 }
 
+// assertOfflineEndpoint ensures the implementation at the configured
+// OfflineURL does not make network-dependent responses. An offline
+// implementation should never be able to return the current
+// /network/status, so a successful response indicates it is connected
+// to a node (or is the same server as the online implementation).
+// It must instead return one of its declared rosetta errors.
+func assertOfflineEndpoint(
+	ctx context.Context,
+	config *configuration.Configuration,
+	network *types.NetworkIdentifier,
+	offlineFetcher *fetcher.Fetcher,
+) error {
+	if config.Construction.OfflineURL == config.OnlineURL {
+		return fmt.Errorf(
+			"%w: offline url %s is the same as online url",
+			customErrs.ErrOfflineEndpointOnline,
+			config.Construction.OfflineURL,
+		)
+	}
+
+	status, fetchErr := offlineFetcher.NetworkStatus(ctx, network, nil)
+	if fetchErr == nil {
+		return fmt.Errorf(
+			"%w: /network/status returned current block %s",
+			customErrs.ErrOfflineEndpointOnline,
+			types.PrintStruct(status.CurrentBlockIdentifier),
+		)
+	}
+
+	// Only a rosetta error (one the implementation declares in
+	// /network/options) shows the offline implementation refused the
+	// request. Transport errors (like a refused connection) mean the
+	// endpoint could not be reached at all.
+	if fetchErr.ClientErr == nil {
+		return fmt.Errorf(
+			"%w: /network/status failed at %s: %s",
+			customErrs.ErrOfflineEndpointUnreachable,
+			config.Construction.OfflineURL,
+			fetchErr.Err.Error(),
+		)
+	}
+
+	if err := offlineFetcher.Asserter.Error(fetchErr.ClientErr); err != nil {
+		return fmt.Errorf(
+			"%w: /network/status returned invalid error %s: %s",
+			customErrs.ErrOfflineEndpointUnreachable,
+			types.PrintStruct(fetchErr.ClientErr),
+			err.Error(),
+		)
+	}
+
+	log.Printf(
+		"offline endpoint %s refused /network/status: %s\n",
+		config.Construction.OfflineURL,
+		types.PrintStruct(fetchErr.ClientErr),
+	)
+	return nil
+}
+
 // InitializeConstruction initiates the construction API tester.
 func InitializeConstruction(
 	ctx context.Context,
@@ -82,23 +140,21 @@ func InitializeConstruction(
 	cancel context.CancelFunc,
 	signalReceived *bool,
 ) (*ConstructionTester, error) {
+	if err := assertWritableStorage(config); err != nil {
+		return nil, err
+	}
+
 	dataPath, err := utils.CreateCommandPath(config.DataDirectory, constructionCmdName, network)
 	if err != nil {
 		return nil, fmt.Errorf("%s: cannot create command path", err.Error())
 	}
 
-	opts := []database.BadgerOption{}
-	if config.CompressionDisabled {
-		opts = append(opts, database.WithoutCompression())
-	}
-	if config.MemoryLimitDisabled {
-		opts = append(
-			opts,
-			database.WithCustomSettings(database.PerformanceBadgerOptions(dataPath)),
-		)
+	opts, err := storageOptions(config, dataPath)
+	if err != nil {
+		return nil, fmt.Errorf("%s: unable to configure database", err.Error())
 	}
 
-	localStore, err := database.NewBadgerDatabase(ctx, dataPath, opts...)
+	localStore, err := database.NewBadgerDatabase(storageContext(ctx, config), dataPath, opts...)
 	if err != nil {
 		return nil, fmt.Errorf("%s: unable to initialize database", err.Error())
 	}
@@ -127,6 +183,12 @@ func InitializeConstruction(
 		return nil, fmt.Errorf("unable to initialize logger with error: %s", err.Error())
 	}
 
+	if config.LogBackend != nil {
+		if err := logger.SetBackend(config.LogBackend); err != nil {
+			return nil, fmt.Errorf("%w: unable to configure log backend", err)
+		}
+	}
+
 	blockStorage := modules.NewBlockStorage(localStore, config.SerialBlockWorkers)
 	keyStorage := modules.NewKeyStorage(localStore)
 	coinStorageHelper := processor.NewCoinStorageHelper(blockStorage)
@@ -150,6 +212,7 @@ func InitializeConstruction(
 		counterStorage,
 		false,
 		nil,
+		nil,
 	)
 
 	balanceStorage.Initialize(balanceStorageHelper, balanceStorageHandler)
@@ -185,6 +248,12 @@ func InitializeConstruction(
 		fetcherOpts...,
 	)
 
+	if config.Construction.OfflineValidationEnabled {
+		if err := assertOfflineEndpoint(ctx, config, network, offlineFetcher); err != nil {
+			return nil, err
+		}
+	}
+
 	// Import prefunded account and save to database
 	err = keyStorage.ImportAccounts(ctx, config.Construction.PrefundedAccounts)
 	if err != nil {
@@ -312,7 +381,7 @@ func InitializeConstruction(
 		logger,
 		cancel,
 		[]modules.BlockWorker{counterStorage, balanceStorage, coinStorage, broadcastStorage},
-		statefulsyncer.WithCacheSize(syncer.DefaultCacheSize),
+		statefulsyncer.WithCacheSize(syncCacheSize(config)),
 		statefulsyncer.WithMaxConcurrency(config.MaxSyncConcurrency),
 		statefulsyncer.WithPastBlockLimit(config.MaxReorgDepth),
 		statefulsyncer.WithSeenConcurrency(int64(config.SeenBlockWorkers)),
@@ -340,6 +409,10 @@ func (t *ConstructionTester) CloseDatabase(ctx context.Context) {
 	if err := t.database.Close(ctx); err != nil {
 		log.Fatalf("%s: error closing database", err.Error())
 	}
+
+	if err := t.logger.Close(); err != nil {
+		log.Printf("%s: error closing log backend\n", err.Error())
+	}
 }
 
 // StartPeriodicLogger prints out periodic
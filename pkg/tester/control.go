@@ -0,0 +1,251 @@
+// Copyright 2022 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tester
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"sync"
+
+	"github.com/coinbase/rosetta-cli/pkg/logger"
+
+	"github.com/coinbase/rosetta-sdk-go/statefulsyncer"
+	"github.com/coinbase/rosetta-sdk-go/types"
+	"github.com/fatih/color"
+)
+
+// syncGate pauses syncing while closed.
+type syncGate struct {
+	lock   sync.Mutex
+	paused bool
+	resume chan struct{}
+}
+
+func newSyncGate() *syncGate {
+	return &syncGate{resume: make(chan struct{})}
+}
+
+func (g *syncGate) pause() {
+	g.lock.Lock()
+	defer g.lock.Unlock()
+
+	g.paused = true
+}
+
+func (g *syncGate) unpause() {
+	g.lock.Lock()
+	defer g.lock.Unlock()
+
+	if !g.paused {
+		return
+	}
+
+	g.paused = false
+	close(g.resume)
+	g.resume = make(chan struct{})
+}
+
+func (g *syncGate) isPaused() bool {
+	g.lock.Lock()
+	defer g.lock.Unlock()
+
+	return g.paused
+}
+
+// wait blocks until syncing is resumed
+// or the context is canceled.
+func (g *syncGate) wait(ctx context.Context) error {
+	g.lock.Lock()
+	if !g.paused {
+		g.lock.Unlock()
+		return nil
+	}
+
+	resume := g.resume
+	g.lock.Unlock()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-resume:
+		return nil
+	}
+}
+
+var _ statefulsyncer.Logger = (*syncGateLogger)(nil)
+
+// syncGateLogger is provided to the syncer in place of
+// *logger.Logger. The syncer adds blocks sequentially
+// and calls AddBlockStream after each block is stored,
+// so waiting here pauses syncing without holding any
+// database transaction open.
+type syncGateLogger struct {
+	*logger.Logger
+
+	gate    *syncGate
+	auditor *heightAuditor
+}
+
+// AddBlockStream logs the added block, audits it
+// if configured, and waits if syncing is paused.
+func (l *syncGateLogger) AddBlockStream(ctx context.Context, block *types.Block) error {
+	if err := l.Logger.AddBlockStream(ctx, block); err != nil {
+		return err
+	}
+
+	if l.auditor != nil && l.auditor.shouldAudit(block.BlockIdentifier.Index) {
+		if err := l.auditor.audit(ctx, block.BlockIdentifier); err != nil {
+			return fmt.Errorf("%w: unable to audit block %d", err, block.BlockIdentifier.Index)
+		}
+	}
+
+	return l.gate.wait(ctx)
+}
+
+// ControlState is returned by the check:data control API.
+type ControlState struct {
+	Paused                       bool   `json:"paused"`
+	ReconciliationConcurrency    int64  `json:"reconciliation_concurrency"`
+	MaxReconciliationConcurrency int64  `json:"max_reconciliation_concurrency"`
+	InactiveSweepIndex           *int64 `json:"inactive_sweep_index,omitempty"`
+}
+
+// ControlConcurrencyRequest is the body of a request
+// to adjust reconciliation concurrency.
+type ControlConcurrencyRequest struct {
+	Concurrency int64 `json:"concurrency"`
+}
+
+func (t *DataTester) controlState() *ControlState {
+	state := &ControlState{
+		Paused:                       t.syncGate.isPaused(),
+		ReconciliationConcurrency:    t.reconcilerHelper.ReconciliationConcurrency(),
+		MaxReconciliationConcurrency: t.reconcilerHelper.MaxReconciliationConcurrency(),
+	}
+
+	if index, ok := t.reconcilerHelper.InactiveSweepIndex(); ok {
+		state.InactiveSweepIndex = &index
+	}
+
+	return state
+}
+
+func writeControlState(w http.ResponseWriter, state *ControlState) {
+	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+	if err := json.NewEncoder(w).Encode(state); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// controlAction wraps a control API action so that it is only
+// invoked with POST and responds with the resulting *ControlState.
+func (t *DataTester) controlAction(
+	action func(r *http.Request) (int, error),
+) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		if code, err := action(r); err != nil {
+			http.Error(w, err.Error(), code)
+			return
+		}
+
+		writeControlState(w, t.controlState())
+	}
+}
+
+// ControlHandler returns an http.Handler that serves the check:data
+// control API:
+//
+//	GET  /                            returns the *ControlState
+//	POST /pause                       pauses syncing
+//	POST /resume                      resumes syncing
+//	POST /reconciliation/concurrency  sets reconciliation concurrency
+//	POST /reconciliation/sweep        starts an inactive reconciliation sweep
+//	POST /reconciliation/accounts     adds interesting accounts
+func (t *DataTester) ControlHandler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/" {
+			http.NotFound(w, r)
+			return
+		}
+
+		writeControlState(w, t.controlState())
+	})
+
+	mux.HandleFunc("/pause", t.controlAction(func(r *http.Request) (int, error) {
+		t.syncGate.pause()
+		color.Yellow("Syncing paused by control API")
+		return http.StatusOK, nil
+	}))
+
+	mux.HandleFunc("/resume", t.controlAction(func(r *http.Request) (int, error) {
+		t.syncGate.unpause()
+		color.Yellow("Syncing resumed by control API")
+		return http.StatusOK, nil
+	}))
+
+	mux.HandleFunc("/reconciliation/concurrency", t.controlAction(
+		func(r *http.Request) (int, error) {
+			var request ControlConcurrencyRequest
+			if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+				return http.StatusBadRequest, err
+			}
+
+			if err := t.reconcilerHelper.SetReconciliationConcurrency(request.Concurrency); err != nil {
+				return http.StatusBadRequest, err
+			}
+
+			log.Printf("Reconciliation concurrency set to %d by control API\n", request.Concurrency)
+			return http.StatusOK, nil
+		},
+	))
+
+	mux.HandleFunc("/reconciliation/sweep", t.controlAction(func(r *http.Request) (int, error) {
+		index, err := t.reconcilerHelper.StartInactiveSweep(r.Context())
+		if err != nil {
+			return http.StatusServiceUnavailable, err
+		}
+
+		log.Printf("Inactive reconciliation sweep started at block %d by control API\n", index)
+		return http.StatusOK, nil
+	}))
+
+	mux.HandleFunc("/reconciliation/accounts", t.controlAction(
+		func(r *http.Request) (int, error) {
+			accounts := []*types.AccountCurrency{}
+			if err := json.NewDecoder(r.Body).Decode(&accounts); err != nil {
+				return http.StatusBadRequest, err
+			}
+
+			added, err := t.addInterestingAccounts(accounts)
+			if err != nil {
+				return http.StatusBadRequest, err
+			}
+
+			log.Printf("%d interesting accounts added by control API\n", added)
+			return http.StatusOK, nil
+		},
+	))
+
+	return mux
+}
@@ -0,0 +1,169 @@
+// Copyright 2022 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tester
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"time"
+
+	"github.com/coinbase/rosetta-cli/pkg/results"
+)
+
+// counterSnapshotFileMode is the permission
+// of the counter snapshot file.
+const counterSnapshotFileMode = 0600
+
+// CounterSnapshot is the value of all counters
+// of a check:data run at some time.
+type CounterSnapshot struct {
+	Timestamp   int64                   `json:"timestamp"`
+	TimeElapsed int64                   `json:"time_elapsed"`
+	Stats       *results.CheckDataStats `json:"stats"`
+}
+
+// StartCounterSnapshots appends a *CounterSnapshot to the
+// configured file at the configured frequency (if any).
+func (t *DataTester) StartCounterSnapshots(ctx context.Context) error {
+	config := t.config.Data.CounterSnapshots
+	if config == nil {
+		return nil
+	}
+
+	f, err := openCounterSnapshotFile(config.File)
+	if err != nil {
+		return err
+	}
+	defer func() { f.Close() }()
+
+	encoder := json.NewEncoder(f)
+	tc := time.NewTicker(time.Duration(config.Frequency) * time.Second)
+	defer tc.Stop()
+
+	retention := t.config.Data.Retention
+	lastPruned := time.Now()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-tc.C:
+			snapshot := &CounterSnapshot{
+				Timestamp: time.Now().Unix(),
+				Stats: results.ComputeCheckDataStats(
+					ctx,
+					t.counterStorage,
+					t.balanceStorage,
+					t.operationTypes,
+				),
+			}
+
+			if elapsed, err := t.counterStorage.Get(ctx, results.TimeElapsedCounter); err == nil {
+				snapshot.TimeElapsed = elapsed.Int64()
+			}
+
+			if err := encoder.Encode(snapshot); err != nil {
+				return fmt.Errorf("%w: unable to write counter snapshot", err)
+			}
+
+			if retention == nil || retention.MaxAge == 0 ||
+				time.Since(lastPruned) < time.Duration(retention.Frequency)*time.Second {
+				continue
+			}
+			lastPruned = time.Now()
+
+			// The file is reopened because pruning replaces it.
+			f.Close()
+			cutoff := time.Now().Add(-time.Duration(retention.MaxAge) * time.Second)
+			if err := pruneCounterSnapshots(config.File, cutoff.Unix()); err != nil {
+				return fmt.Errorf("%w: unable to prune counter snapshots", err)
+			}
+
+			f, err = openCounterSnapshotFile(config.File)
+			if err != nil {
+				return err
+			}
+			encoder = json.NewEncoder(f)
+		}
+	}
+}
+
+// openCounterSnapshotFile opens the counter
+// snapshot file for appending.
+func openCounterSnapshotFile(file string) (*os.File, error) {
+	f, err := os.OpenFile(
+		path.Clean(file),
+		os.O_APPEND|os.O_CREATE|os.O_WRONLY,
+		counterSnapshotFileMode,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("%w: unable to open counter snapshot file %s", err, file)
+	}
+
+	return f, nil
+}
+
+// pruneCounterSnapshots rewrites the counter snapshot
+// file without snapshots taken before cutoff (in seconds).
+func pruneCounterSnapshots(file string, cutoff int64) error {
+	src, err := os.Open(path.Clean(file))
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	tmpFile := fmt.Sprintf("%s.tmp", file)
+	dst, err := os.OpenFile(
+		path.Clean(tmpFile),
+		os.O_CREATE|os.O_TRUNC|os.O_WRONLY,
+		counterSnapshotFileMode,
+	)
+	if err != nil {
+		return err
+	}
+	defer dst.Close()
+
+	decoder := json.NewDecoder(src)
+	encoder := json.NewEncoder(dst)
+	for {
+		snapshot := &CounterSnapshot{}
+		err := decoder.Decode(snapshot)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("%w: unable to read counter snapshot", err)
+		}
+
+		if snapshot.Timestamp < cutoff {
+			continue
+		}
+
+		if err := encoder.Encode(snapshot); err != nil {
+			return fmt.Errorf("%w: unable to write counter snapshot", err)
+		}
+	}
+
+	if err := dst.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmpFile, file)
+}
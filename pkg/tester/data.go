@@ -23,12 +23,16 @@ import (
 	"log"
 	"math/big"
 	"net/http"
+	"strconv"
 	"time"
 
 	"github.com/coinbase/rosetta-cli/configuration"
 	"github.com/coinbase/rosetta-cli/pkg/logger"
+	"github.com/coinbase/rosetta-cli/pkg/parquet"
 	"github.com/coinbase/rosetta-cli/pkg/processor"
 	"github.com/coinbase/rosetta-cli/pkg/results"
+	"github.com/coinbase/rosetta-cli/pkg/tracing"
+	"github.com/coinbase/rosetta-cli/pkg/transport"
 	"github.com/coinbase/rosetta-sdk-go/fetcher"
 	"github.com/coinbase/rosetta-sdk-go/parser"
 	"github.com/coinbase/rosetta-sdk-go/reconciler"
@@ -36,7 +40,6 @@ import (
 	"github.com/coinbase/rosetta-sdk-go/storage/database"
 	storageErrs "github.com/coinbase/rosetta-sdk-go/storage/errors"
 	"github.com/coinbase/rosetta-sdk-go/storage/modules"
-	"github.com/coinbase/rosetta-sdk-go/syncer"
 	"github.com/coinbase/rosetta-sdk-go/types"
 	"github.com/coinbase/rosetta-sdk-go/utils"
 	"github.com/fatih/color"
@@ -62,11 +65,19 @@ const (
 	// to the terminal.
 	PeriodicLoggingFrequency = periodicLoggingSeconds * time.Second
 
+	// progressWindow is the number of periodic samples used to
+	// compute the recent sync rate (1 minute of samples).
+	progressWindow = 6
+
 	// EndAtTipCheckInterval is the frequency that EndAtTip condition
 	// is evaludated
 	EndAtTipCheckInterval = 10 * time.Second
 )
 
+// errBalanceFound is returned to stop a scan
+// of historical balances at the first balance.
+var errBalanceFound = errors.New("balance found")
+
 var _ http.Handler = (*DataTester)(nil)
 var _ statefulsyncer.PruneHelper = (*DataTester)(nil)
 
@@ -82,6 +93,17 @@ type DataTester struct {
 	blockStorage                *modules.BlockStorage
 	counterStorage              *modules.CounterStorage
 	reconcilerHandler           *processor.ReconcilerHandler
+	reconcilerHelper            *processor.ReconcilerHelper
+	subAccountRegistry          *processor.SubAccountRegistry
+	balanceStorageHandler       *processor.BalanceStorageHandler
+	reconciliationPriority      *processor.ReconciliationPriority
+	reconciliationHistory       *processor.ReconciliationHistory
+	reconciliationQueue         *processor.ReconciliationQueue
+	balanceChangesCSV           *processor.BalanceChangesCSV
+	parquetExporter             *parquet.Exporter
+	operationTotals             *processor.OperationTotalsWorker
+	skippedBlocks               *processor.SkippedBlockWorker
+	syncGate                    *syncGate
 	fetcher                     *fetcher.Fetcher
 	signalReceived              *bool
 	genesisBlock                *types.BlockIdentifier
@@ -90,8 +112,29 @@ type DataTester struct {
 	parser                      *parser.Parser
 	forceInactiveReconciliation *bool
 
+	// operationTypes are all operation types supported
+	// by the network (used to report operation stats).
+	operationTypes []string
+
 	endCondition       configuration.CheckDataEndCondition
 	endConditionDetail string
+
+	// reportSamples are collected by the periodic logger
+	// when an HTML report is requested.
+	reportSamples *results.DataReportSamples
+
+	// progress tracks the recent sync rate
+	// for the progress bar.
+	progress *results.ProgressTracker
+
+	// storageMetrics is nil if storage
+	// is not on disk.
+	storageMetrics *storageMetrics
+
+	// failureReport is nil unless data.continue_on_error
+	// is enabled or data.reconciliation_failure_limit
+	// allows processing to continue past failures.
+	failureReport *results.FailureReport
 }
 
 func shouldReconcile(config *configuration.Configuration) bool {
@@ -106,6 +149,28 @@ func shouldReconcile(config *configuration.Configuration) bool {
 	return true
 }
 
+// withConfiguredExemptions returns exemptions with the configured
+// exemptions that the parser can apply (those without an account).
+func withConfiguredExemptions(
+	exemptions []*types.BalanceExemption,
+	configured []*configuration.BalanceExemptionConfiguration,
+) []*types.BalanceExemption {
+	all := append([]*types.BalanceExemption{}, exemptions...)
+	for _, exemption := range configured {
+		if exemption.Account != nil {
+			continue
+		}
+
+		all = append(all, &types.BalanceExemption{
+			SubAccountAddress: exemption.SubAccountAddress,
+			Currency:          exemption.Currency,
+			ExemptionType:     exemption.ExemptionType,
+		})
+	}
+
+	return all
+}
+
 // loadAccounts is a utility function to parse the []*types.AccountCurrency
 // in a file.
 func loadAccounts(filePath string) ([]*types.AccountCurrency, error) {
@@ -128,11 +193,240 @@ func loadAccounts(filePath string) ([]*types.AccountCurrency, error) {
 	return accounts, nil
 }
 
-// CloseDatabase closes the database used by DataTester.
-func (t *DataTester) CloseDatabase(ctx context.Context) {
-	if err := t.database.Close(ctx); err != nil {
-		log.Fatalf("%s: error closing database", err.Error())
+// withoutDisabledCurrencies removes all accounts in currencies
+// where reconciliation is disabled (so they are not inactively
+// reconciled).
+func withoutDisabledCurrencies(
+	accounts []*types.AccountCurrency,
+	configs []*configuration.CurrencyReconciliationConfiguration,
+) []*types.AccountCurrency {
+	disabled := map[string]struct{}{}
+	for _, currencyConfig := range configs {
+		if currencyConfig.Disabled {
+			disabled[types.Hash(currencyConfig.Currency)] = struct{}{}
+		}
+	}
+
+	if len(disabled) == 0 {
+		return accounts
 	}
+
+	filtered := []*types.AccountCurrency{}
+	for _, account := range accounts {
+		if _, ok := disabled[types.Hash(account.Currency)]; ok {
+			continue
+		}
+
+		filtered = append(filtered, account)
+	}
+
+	return filtered
+}
+
+// assertGenesis fetches the genesis block and verifies it against the
+// configured *configuration.GenesisAssertions. This is performed before
+// any syncing so that we abort early if pointed at the wrong network.
+func assertGenesis(
+	ctx context.Context,
+	config *configuration.Configuration,
+	network *types.NetworkIdentifier,
+	fetcher *fetcher.Fetcher,
+	genesisBlock *types.BlockIdentifier,
+) error {
+	assertions := config.Data.GenesisAssertions
+	if assertions == nil {
+		return nil
+	}
+
+	block, fetchErr := fetcher.BlockRetry(
+		ctx,
+		network,
+		types.ConstructPartialBlockIdentifier(genesisBlock),
+	)
+	if fetchErr != nil {
+		return fmt.Errorf("%w: unable to fetch genesis block", fetchErr.Err)
+	}
+
+	if block == nil {
+		return fmt.Errorf(
+			"%w: genesis block %d is omitted",
+			customErrs.ErrGenesisBlockMismatch,
+			genesisBlock.Index,
+		)
+	}
+
+	if len(assertions.Hash) > 0 && assertions.Hash != block.BlockIdentifier.Hash {
+		return fmt.Errorf(
+			"%w: expected hash %s but found %s",
+			customErrs.ErrGenesisBlockMismatch,
+			assertions.Hash,
+			block.BlockIdentifier.Hash,
+		)
+	}
+
+	if assertions.Timestamp != nil && *assertions.Timestamp != block.Timestamp {
+		return fmt.Errorf(
+			"%w: expected timestamp %d but found %d",
+			customErrs.ErrGenesisBlockMismatch,
+			*assertions.Timestamp,
+			block.Timestamp,
+		)
+	}
+
+	if len(assertions.BootstrapSupply) == 0 {
+		return nil
+	}
+
+	balances := []*modules.BootstrapBalance{}
+	if err := utils.LoadAndParse(config.Data.BootstrapBalances, &balances); err != nil {
+		return fmt.Errorf("%w: unable to load bootstrap balances", err)
+	}
+
+	// The supply is summed from the balances the node reports
+	// at the genesis block (not from the values in the bootstrap
+	// balances file) so that the check fails if the node was
+	// bootstrapped with a different genesis state.
+	supply := map[string]*big.Int{}
+	for _, balance := range balances {
+		amount, _, err := utils.CurrencyBalance(
+			ctx,
+			network,
+			fetcher,
+			balance.Account,
+			balance.Currency,
+			genesisBlock.Index,
+		)
+		if err != nil {
+			return fmt.Errorf(
+				"%w: unable to fetch genesis balance of %s",
+				err,
+				types.PrintStruct(balance.Account),
+			)
+		}
+
+		value, err := types.BigInt(amount.Value)
+		if err != nil {
+			return fmt.Errorf("%w: unable to parse genesis balance", err)
+		}
+
+		key := types.Hash(balance.Currency)
+		if _, ok := supply[key]; !ok {
+			supply[key] = big.NewInt(0)
+		}
+		supply[key].Add(supply[key], value)
+	}
+
+	for _, expected := range assertions.BootstrapSupply {
+		value, err := types.BigInt(expected.Value)
+		if err != nil {
+			return fmt.Errorf("%w: unable to parse bootstrap supply", err)
+		}
+
+		actual, ok := supply[types.Hash(expected.Currency)]
+		if !ok {
+			actual = big.NewInt(0)
+		}
+
+		if value.Cmp(actual) != 0 {
+			return fmt.Errorf(
+				"%w: expected bootstrap supply %s but found %s",
+				customErrs.ErrGenesisBlockMismatch,
+				utils.PrettyAmount(value, expected.Currency),
+				utils.PrettyAmount(actual, expected.Currency),
+			)
+		}
+	}
+
+	log.Printf(
+		"genesis block %d:%s matches genesis assertions\n",
+		block.BlockIdentifier.Index,
+		block.BlockIdentifier.Hash,
+	)
+	return nil
+}
+
+// CloseDatabase closes the database used by DataTester (and any
+// exporters), returning an error if the database could not be closed.
+func (t *DataTester) CloseDatabase(ctx context.Context) error {
+	// The log backend and exporters are closed
+	// even if the database could not be closed.
+	dbErr := t.database.Close(ctx)
+
+	if err := t.logger.Close(); err != nil {
+		log.Printf("%s: error closing log backend\n", err.Error())
+	}
+
+	if t.parquetExporter != nil {
+		if err := t.parquetExporter.Close(); err != nil {
+			log.Printf("%s: error exporting remaining blocks to parquet\n", err.Error())
+		}
+	}
+
+	if t.balanceChangesCSV != nil {
+		if err := t.balanceChangesCSV.Close(); err != nil {
+			log.Printf("%s: error closing balance changes csv\n", err.Error())
+		}
+	}
+
+	if dbErr != nil {
+		return fmt.Errorf("%w: unable to close database", dbErr)
+	}
+
+	return nil
+}
+
+// adjustedBalanceStorage returns the block worker that applies
+// balance changes to balanceStorage (including any balance
+// adjustments from config.Data.BalanceAdjustmentURL and aggregating
+// sub-accounts if config.Data.SubAccounts is "aggregate").
+func adjustedBalanceStorage(
+	config *configuration.Configuration,
+	network *types.NetworkIdentifier,
+	balanceStorage *modules.BalanceStorage,
+	statuses []*types.OperationStatus,
+) (modules.BlockWorker, error) {
+	worker, err := balanceAdjustmentWorker(config, network, balanceStorage, statuses)
+	if err != nil {
+		return nil, err
+	}
+
+	if config.Data.SubAccounts == configuration.AggregateSubAccounts {
+		worker = processor.NewSubAccountAggregationWorker(worker)
+	}
+
+	return worker, nil
+}
+
+// balanceAdjustmentWorker returns a *processor.BalanceAdjustmentWorker
+// wrapping balanceStorage if data.balance_adjustment_url is populated
+// (otherwise balanceStorage is returned).
+func balanceAdjustmentWorker(
+	config *configuration.Configuration,
+	network *types.NetworkIdentifier,
+	balanceStorage *modules.BalanceStorage,
+	statuses []*types.OperationStatus,
+) (modules.BlockWorker, error) {
+	if len(config.Data.BalanceAdjustmentURL) == 0 {
+		return balanceStorage, nil
+	}
+
+	for _, status := range statuses {
+		if !status.Successful {
+			continue
+		}
+
+		return processor.NewBalanceAdjustmentWorker(
+			balanceStorage,
+			processor.NewHTTPBalanceAdjuster(
+				config.Data.BalanceAdjustmentURL,
+				network,
+				time.Duration(config.HTTPTimeout)*time.Second,
+			),
+			status.Status,
+		), nil
+	}
+
+	return nil, errors.New("balance adjustments require a successful operation status")
 }
 
 // InitializeData returns a new *DataTester.
@@ -141,28 +435,28 @@ func InitializeData(
 	config *configuration.Configuration,
 	network *types.NetworkIdentifier,
 	fetcher *fetcher.Fetcher,
+	tracer *tracing.Tracer,
+	otherTransactions *transport.OtherTransactions,
+	assertionFailures *transport.AssertionFailures,
 	cancel context.CancelFunc,
 	genesisBlock *types.BlockIdentifier,
 	interestingAccount *types.AccountCurrency,
 	signalReceived *bool,
 ) (*DataTester, error) {
-	dataPath, err := utils.CreateCommandPath(config.DataDirectory, dataCmdName, network)
-	if err != nil {
-		return nil, fmt.Errorf("%s: cannot create command path", err.Error())
+	if err := assertWritableStorage(config); err != nil {
+		return nil, err
 	}
 
-	opts := []database.BadgerOption{}
-	if config.CompressionDisabled {
-		opts = append(opts, database.WithoutCompression())
+	if err := assertGenesis(ctx, config, network, fetcher, genesisBlock); err != nil {
+		return nil, fmt.Errorf("%w: genesis assertions failed", err)
 	}
-	if config.MemoryLimitDisabled {
-		opts = append(
-			opts,
-			database.WithCustomSettings(database.PerformanceBadgerOptions(dataPath)),
-		)
+
+	dataPath, err := utils.CreateCommandPath(config.DataDirectory, dataCmdName, network)
+	if err != nil {
+		return nil, fmt.Errorf("%s: cannot create command path", err.Error())
 	}
 
-	localStore, err := database.NewBadgerDatabase(ctx, dataPath, opts...)
+	localStore, err := openDataStorage(ctx, config, dataPath)
 	if err != nil {
 		return nil, fmt.Errorf("%s: unable to initialize database", err.Error())
 	}
@@ -177,10 +471,19 @@ func InitializeData(
 		return nil, fmt.Errorf("%s: unable to load interesting accounts", err.Error())
 	}
 
+	negativeBalanceAccounts, err := loadAccounts(config.Data.NegativeBalanceAccounts)
+	if err != nil {
+		return nil, fmt.Errorf("%w: unable to load negative balance accounts", err)
+	}
+
 	counterStorage := modules.NewCounterStorage(localStore)
 	blockStorage := modules.NewBlockStorage(localStore, config.SerialBlockWorkers)
 	balanceStorage := modules.NewBalanceStorage(localStore)
 
+	// The reconciler prints debug output only at
+	// the highest verbosity.
+	reconcilerDebugLogging := logger.Enabled(logger.VerbosityDebug)
+
 	logger, err := logger.NewLogger(
 		dataPath,
 		config.Data.LogBlocks,
@@ -194,43 +497,121 @@ func InitializeData(
 		return nil, fmt.Errorf("unable to initialize logger with error: %s", err.Error())
 	}
 
+	if config.Data.LogRotation != nil {
+		logger.SetRotation(config.Data.LogRotation)
+	}
+
+	if config.Data.LogFilter != nil {
+		logger.SetFilter(config.Data.LogFilter)
+	}
+
+	if config.LogBackend != nil {
+		if err := logger.SetBackend(config.LogBackend); err != nil {
+			return nil, fmt.Errorf("%w: unable to configure log backend", err)
+		}
+	}
+
+	// The reconciler must observe every committed block.
+	reconcilerStore := localStore
+	if batched, ok := localStore.(*batchedDatabase); ok {
+		reconcilerStore = batched.flushedReads()
+	}
+
 	var forceInactiveReconciliation bool
 	reconcilerHelper := processor.NewReconcilerHelper(
 		config,
 		network,
 		fetcher,
-		localStore,
+		reconcilerStore,
 		blockStorage,
 		balanceStorage,
 		&forceInactiveReconciliation,
+		tracer,
 	)
 
+	var balanceCache *processor.BalanceCache
+	if config.Data.BalanceCacheSize > 0 {
+		balanceCache = processor.NewBalanceCache(config.Data.BalanceCacheSize)
+		reconcilerHelper.SetBalanceCache(balanceCache)
+	}
+
+	var subAccountRegistry *processor.SubAccountRegistry
+	if config.Data.SubAccounts == configuration.AggregateSubAccounts {
+		subAccountRegistry, err = processor.NewSubAccountRegistry(ctx, localStore)
+		if err != nil {
+			return nil, fmt.Errorf("%w: unable to initialize sub-account registry", err)
+		}
+
+		reconcilerHelper.SetSubAccountRegistry(subAccountRegistry)
+	}
+
+	var reconciliationHistory *processor.ReconciliationHistory
+	if config.Data.ReconciliationHistoryEnabled {
+		reconciliationHistory = processor.NewReconciliationHistory(localStore)
+	}
+
 	reconcilerHandler := processor.NewReconcilerHandler(
 		logger,
 		counterStorage,
 		balanceStorage,
-		!config.Data.IgnoreReconciliationError,
+		reconciliationHistory,
+		!config.Data.IgnoreReconciliationError &&
+			!config.Data.FollowTip &&
+			!config.Data.ContinueOnError,
 	)
+	reconcilerHandler.SetFailureLimit(config.Data.ReconciliationFailureLimit)
+	reconcilerHandler.SetBalanceExemptions(config.Data.BalanceExemptions)
+	if config.Data.ReferenceBalance != nil {
+		reference, err := processor.NewReferenceBalance(
+			config.Data.ReferenceBalance,
+			network,
+			time.Duration(config.HTTPTimeout)*time.Second,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		reconcilerHandler.SetReferenceBalance(reference)
+	}
+	if err := reconcilerHandler.SetCurrencyReconciliation(
+		config.Data.CurrencyReconciliation,
+	); err != nil {
+		return nil, err
+	}
+
+	var failureReport *results.FailureReport
+	if config.Data.ContinueOnError || config.Data.ReconciliationFailureLimit > 1 {
+		failureReport = results.NewFailureReport()
+		reconcilerHandler.SetFailureReport(failureReport)
+	}
+	if assertionFailures != nil && failureReport != nil {
+		assertionFailures.Start(fetcher.Asserter, failureReport.RecordAssertion)
+	}
 
 	// Get all previously seen accounts
-	seenAccounts, err := balanceStorage.GetAllAccountCurrency(ctx)
+	seenAccounts, err := getAllAccountCurrency(ctx, localStore)
 	if err != nil {
 		return nil, fmt.Errorf("%s: unable to get previously seen accounts", err.Error())
 	}
+	seenAccounts = withoutDisabledCurrencies(seenAccounts, config.Data.CurrencyReconciliation)
 
 	networkOptions, fetchErr := fetcher.NetworkOptionsRetry(ctx, network, nil)
 	if fetchErr != nil {
-		log.Fatalf("%s: unable to get network options", fetchErr.Err.Error())
+		return nil, fmt.Errorf("%w: unable to get network options", fetchErr.Err)
 	}
 
-	if len(networkOptions.Allow.BalanceExemptions) > 0 && config.Data.InitialBalanceFetchDisabled {
+	balanceExemptions := withConfiguredExemptions(
+		networkOptions.Allow.BalanceExemptions,
+		config.Data.BalanceExemptions,
+	)
+	if len(balanceExemptions) > 0 && config.Data.InitialBalanceFetchDisabled {
 		return nil, fmt.Errorf("found balance exemptions but initial balance fetch disabled")
 	}
 
 	parser := parser.New(
 		fetcher.Asserter,
 		nil,
-		networkOptions.Allow.BalanceExemptions,
+		balanceExemptions,
 	)
 
 	// Determine if we should perform historical balance lookups
@@ -255,7 +636,7 @@ func InitializeData(
 	if historicalBalanceEnabled {
 		rOpts = append(rOpts, reconciler.WithLookupBalanceByBlock())
 	}
-	if config.Data.LogReconciliations {
+	if reconcilerDebugLogging {
 		rOpts = append(rOpts, reconciler.WithDebugLogging())
 	}
 
@@ -265,8 +646,75 @@ func InitializeData(
 		parser,
 		rOpts...,
 	)
+	reconcilerHandler.SetReverification(
+		config.Data.ReconciliationReverification,
+		r,
+		reconcilerHelper,
+		historicalBalanceEnabled,
+	)
+
+	var balanceChangesCSV *processor.BalanceChangesCSV
+	if len(config.Data.BalanceChangesCSVFile) > 0 {
+		balanceChangesCSV, err = processor.NewBalanceChangesCSV(
+			config.Data.BalanceChangesCSVFile,
+			parser,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("%w: unable to initialize balance changes export", err)
+		}
+	}
+
+	blockWorkers := []modules.BlockWorker{}
+	if tracer != nil {
+		// The tracing worker must run first to cover the other workers.
+		blockWorkers = append(blockWorkers, tracing.NewBlockWorker(tracer))
+	}
+	operationTotals := processor.NewOperationTotalsWorker(localStore, fetcher.Asserter)
+	blockWorkers = append(
+		blockWorkers,
+		counterStorage,
+		processor.NewOperationStatsWorker(counterStorage),
+		operationTotals,
+		processor.NewBlockHashWorker(),
+		processor.NewOmittedBlockWorker(config.Data.OmittedBlocksDisabled),
+	)
+	if otherTransactions != nil {
+		blockWorkers = append(
+			blockWorkers,
+			processor.NewOtherTransactionWorker(counterStorage, otherTransactions.Fetched),
+		)
+	}
+	if subAccountRegistry != nil {
+		blockWorkers = append(blockWorkers, subAccountRegistry)
+	}
+	var skippedBlocks *processor.SkippedBlockWorker
+	if len(config.Data.SkipBlocks) > 0 {
+		skippedBlocks = processor.NewSkippedBlockWorker(
+			localStore,
+			counterStorage,
+			config.Data.SkipBlocks,
+		)
+		blockWorkers = append(blockWorkers, skippedBlocks)
+	}
+	if balanceCache != nil {
+		blockWorkers = append(blockWorkers, balanceCache)
+	}
+
+	var parquetExporter *parquet.Exporter
+	if config.Data.ParquetExport != nil {
+		parquetExporter, err = parquet.NewExporter(
+			config.Data.ParquetExport,
+			int64(config.MaxReorgDepth),
+		)
+		if err != nil {
+			return nil, fmt.Errorf("%w: unable to initialize parquet export", err)
+		}
 
-	blockWorkers := []modules.BlockWorker{counterStorage}
+		blockWorkers = append(blockWorkers, parquetExporter)
+	}
+	var reconciliationQueue *processor.ReconciliationQueue
+	var balanceStorageHandler *processor.BalanceStorageHandler
+	var reconciliationPriority *processor.ReconciliationPriority
 	if !config.Data.BalanceTrackingDisabled {
 		balanceStorageHelper := processor.NewBalanceStorageHelper(
 			network,
@@ -275,21 +723,84 @@ func InitializeData(
 			historicalBalanceEnabled,
 			exemptAccounts,
 			false,
-			networkOptions.Allow.BalanceExemptions,
+			balanceExemptions,
 			config.Data.InitialBalanceFetchDisabled,
 		)
+		balanceStorageHelper.SetExemptOperationTypes(config.Data.ExemptOperationTypes)
+		balanceStorageHelper.SetIgnoreSubAccounts(
+			config.Data.SubAccounts == configuration.IgnoreSubAccounts,
+		)
+		balanceStorageHelper.SetNegativeBalanceAccounts(negativeBalanceAccounts)
+
+		if len(negativeBalanceAccounts) > 0 {
+			negativeBalances := processor.NewNegativeBalances(
+				balanceStorageHelper,
+				negativeBalanceAccounts,
+			)
+			if shouldReconcile(config) {
+				negativeBalances.SetReconciler(r)
+			}
+			reconcilerHelper.SetNegativeBalances(negativeBalances)
+
+			blockWorkers = append(blockWorkers, negativeBalances)
+		}
 
-		balanceStorageHandler := processor.NewBalanceStorageHandler(
+		balanceStorageHandler = processor.NewBalanceStorageHandler(
 			logger,
 			r,
 			counterStorage,
 			shouldReconcile(config),
 			interestingAccount,
+			balanceChangesCSV,
+		)
+
+		balanceStorageHandler.SetCurrencyReconciliation(config.Data.CurrencyReconciliation)
+		balanceStorageHandler.SetSampleRate(
+			config.Data.ActiveReconciliationSampleRate,
+			config.Data.ActiveReconciliationSampleSeed,
+		)
+		if config.Data.ReconciliationPriority != nil {
+			reconciliationPriority = processor.NewReconciliationPriority(
+				config.Data.ReconciliationPriority,
+				int64(config.Data.InactiveReconciliationFrequency),
+			)
+			balanceStorageHandler.SetReconciliationPriority(reconciliationPriority, balanceStorage)
+		}
+		err = balanceStorageHandler.SetBalanceThresholds(
+			balanceStorage,
+			config.Data.ReconciliationBalanceThresholds,
 		)
+		if err != nil {
+			return nil, err
+		}
 
 		balanceStorage.Initialize(balanceStorageHelper, balanceStorageHandler)
 
-		blockWorkers = append(blockWorkers, balanceStorage)
+		balanceStorageWorker, err := adjustedBalanceStorage(
+			config,
+			network,
+			balanceStorage,
+			networkOptions.Allow.OperationStatuses,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		blockWorkers = append(blockWorkers, balanceStorageWorker)
+
+		// Changes are stored until reconciled so that they
+		// are reconciled even if `check:data` restarts first.
+		if shouldReconcile(config) {
+			reconciliationQueue = processor.NewReconciliationQueue(
+				localStore,
+				balanceStorageHelper,
+				interestingAccount,
+			)
+			reconcilerHandler.SetReconciliationQueue(reconciliationQueue)
+			balanceStorageHandler.SetReconciliationQueue(reconciliationQueue)
+
+			blockWorkers = append(blockWorkers, reconciliationQueue)
+		}
 
 		// Bootstrap balances, if provided. We need to do before initializing
 		// the reconciler otherwise we won't reconcile bootstrapped accounts
@@ -325,7 +836,7 @@ func InitializeData(
 	}
 
 	statefulSyncerOptions := []statefulsyncer.Option{
-		statefulsyncer.WithCacheSize(syncer.DefaultCacheSize),
+		statefulsyncer.WithCacheSize(syncCacheSize(config)),
 		statefulsyncer.WithMaxConcurrency(config.MaxSyncConcurrency),
 		statefulsyncer.WithPastBlockLimit(config.MaxReorgDepth),
 		statefulsyncer.WithSeenConcurrency(int64(config.SeenBlockWorkers)),
@@ -337,18 +848,28 @@ func InitializeData(
 		)
 	}
 
+	gate := newSyncGate()
 	syncer := statefulsyncer.New(
 		ctx,
 		network,
 		fetcher,
 		blockStorage,
 		counterStorage,
-		logger,
+		&syncGateLogger{
+			Logger:  logger,
+			gate:    gate,
+			auditor: newHeightAuditor(config, reconcilerHelper, balanceStorage),
+		},
 		cancel,
 		blockWorkers,
 		statefulSyncerOptions...,
 	)
 
+	var metrics *storageMetrics
+	if !config.InMemoryStorage {
+		metrics = newStorageMetrics(dataPath)
+	}
+
 	return &DataTester{
 		network:                     network,
 		database:                    localStore,
@@ -361,33 +882,103 @@ func InitializeData(
 		blockStorage:                blockStorage,
 		counterStorage:              counterStorage,
 		reconcilerHandler:           reconcilerHandler,
+		reconcilerHelper:            reconcilerHelper,
+		subAccountRegistry:          subAccountRegistry,
+		balanceStorageHandler:       balanceStorageHandler,
+		reconciliationPriority:      reconciliationPriority,
+		reconciliationHistory:       reconciliationHistory,
+		reconciliationQueue:         reconciliationQueue,
+		balanceChangesCSV:           balanceChangesCSV,
+		parquetExporter:             parquetExporter,
+		operationTotals:             operationTotals,
+		skippedBlocks:               skippedBlocks,
+		syncGate:                    gate,
 		fetcher:                     fetcher,
 		signalReceived:              signalReceived,
 		genesisBlock:                genesisBlock,
 		historicalBalanceEnabled:    historicalBalanceEnabled,
 		parser:                      parser,
 		forceInactiveReconciliation: &forceInactiveReconciliation,
+		operationTypes:              networkOptions.Allow.OperationTypes,
+		progress:                    results.NewProgressTracker(progressWindow),
+		reportSamples:               results.NewDataReportSamples(),
+		storageMetrics:              metrics,
+		failureReport:               failureReport,
 	}, nil
 }
 
-// StartSyncing syncs from startIndex to endIndex.
+// StartSyncing syncs from startIndex to endIndex
+// (after syncing backward, if configured).
 // If startIndex is -1, it will start from the last
 // saved block. If endIndex is -1, it will sync
 // continuously (or until an error).
 func (t *DataTester) StartSyncing(
 	ctx context.Context,
 ) error {
+	if t.config.Data.BackwardSync != nil {
+		if err := t.syncBackward(ctx); err != nil {
+			return fmt.Errorf("%w: backward sync failed", err)
+		}
+
+		if t.config.Data.BackwardSync.ForwardSyncDisabled {
+			return nil
+		}
+	}
+
 	startIndex := int64(-1)
 	if t.config.Data.StartIndex != nil {
 		startIndex = *t.config.Data.StartIndex
 	}
 
+	if len(t.config.Data.StartBlockHash) > 0 {
+		var err error
+		startIndex, err = t.startBlockIndex(ctx, t.config.Data.StartBlockHash)
+		if err != nil {
+			return err
+		}
+	}
+
+	if t.config.Data.EndConditions != nil && len(t.config.Data.EndConditions.Timestamp) > 0 {
+		if err := t.resolveEndTimestamp(ctx); err != nil {
+			return err
+		}
+	}
+
 	endIndex := int64(-1)
 	if t.config.Data.EndConditions != nil && t.config.Data.EndConditions.Index != nil {
 		endIndex = *t.config.Data.EndConditions.Index
 	}
 
-	return t.syncer.Sync(ctx, startIndex, endIndex)
+	if t.config.Data.FollowTip {
+		log.Printf(
+			"Following tip (reorgs up to %d blocks deep are rolled back)\n",
+			t.config.MaxReorgDepth,
+		)
+	}
+
+	// Only the other transactions fetched to sync
+	// blocks are counted.
+	return t.syncer.Sync(transport.CountOtherTransactions(ctx), startIndex, endIndex)
+}
+
+// startBlockIndex returns the index of the
+// block with hash (as reported by /block).
+func (t *DataTester) startBlockIndex(ctx context.Context, hash string) (int64, error) {
+	block, fetchErr := t.fetcher.BlockRetry(
+		ctx,
+		t.network,
+		&types.PartialBlockIdentifier{Hash: &hash},
+	)
+	if fetchErr != nil {
+		return -1, fmt.Errorf("%w: unable to fetch start block %s", fetchErr.Err, hash)
+	}
+
+	if block == nil {
+		return -1, fmt.Errorf("start block %s is omitted", hash)
+	}
+
+	log.Printf("Starting at block %d (%s)\n", block.BlockIdentifier.Index, hash)
+	return block.BlockIdentifier.Index, nil
 }
 
 // StartPruning attempts to prune block storage
@@ -432,7 +1023,30 @@ func (t *DataTester) StartReconciler(
 		return nil
 	}
 
-	return t.reconciler.Reconcile(ctx)
+	if t.reconciliationQueue == nil {
+		return t.reconciler.Reconcile(ctx)
+	}
+
+	// Changes are only accepted once the reconciler
+	// is running, so pending changes are queued concurrently.
+	g, ctx := errgroup.WithContext(ctx)
+	g.Go(func() error {
+		return t.reconciler.Reconcile(ctx)
+	})
+	g.Go(func() error {
+		requeued, err := t.reconciliationQueue.Requeue(ctx, t.reconciler)
+		if err != nil {
+			return err
+		}
+
+		if requeued > 0 {
+			log.Printf("Queued %d pending reconciliations\n", requeued)
+		}
+
+		return nil
+	})
+
+	return g.Wait()
 }
 
 // StartPeriodicLogger prints out periodic
@@ -461,17 +1075,61 @@ func (t *DataTester) StartPeriodicLogger(
 				t.blockStorage,
 				t.counterStorage,
 				t.balanceStorage,
+				t.operationTypes,
 				t.fetcher,
 				t.config.Network,
 				t.reconciler,
 			)
+			if t.storageMetrics != nil {
+				storage, err := t.storageMetrics.compute()
+				if err != nil {
+					log.Printf("%s: unable to compute storage metrics\n", err.Error())
+				}
+
+				status.Storage = storage
+			}
+
 			t.logger.LogDataStatus(ctx, status)
+			t.logProgressBar(ctx, status)
+
+			if len(t.config.Data.HTMLReportFile) > 0 {
+				t.reportSamples.Add(results.NewDataReportSample(status))
+			}
 		}
 	}
 }
 
-// ServeHTTP serves a CheckDataStatus response on all paths.
+// logProgressBar logs a progress bar towards the end
+// index (if configured) or the tip, with an ETA computed
+// from the recent sync rate.
+func (t *DataTester) logProgressBar(ctx context.Context, status *results.CheckDataStatus) {
+	if status.Progress == nil {
+		return
+	}
+
+	t.progress.Add(status.Progress.Blocks, time.Now())
+
+	target := status.Progress.Tip
+	if t.config.Data.EndConditions != nil &&
+		t.config.Data.EndConditions.Index != nil &&
+		*t.config.Data.EndConditions.Index < target {
+		target = *t.config.Data.EndConditions.Index
+	}
+
+	t.logger.LogProgressBar(
+		ctx,
+		t.progress.Render(t.genesisBlock.Index, status.Progress.Blocks, target),
+	)
+}
+
+// ServeHTTP serves a CheckDataStatus response on all paths
+// (except /block, which serves synced blocks to check:construction).
 func (t *DataTester) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method == http.MethodPost && r.URL.Path == blockCachePath {
+		t.serveBlock(w, r)
+		return
+	}
+
 	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
 	w.WriteHeader(http.StatusOK)
 
@@ -480,6 +1138,7 @@ func (t *DataTester) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		t.blockStorage,
 		t.counterStorage,
 		t.balanceStorage,
+		t.operationTypes,
 		t.fetcher,
 		t.network,
 		t.reconciler,
@@ -490,6 +1149,60 @@ func (t *DataTester) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// OutputHTMLReport writes an HTML report of the run
+// to the configured HTMLReportFile (if populated). This
+// should be called after all goroutines have exited.
+func (t *DataTester) OutputHTMLReport(err error) {
+	if len(t.config.Data.HTMLReportFile) == 0 {
+		return
+	}
+
+	checkResults := results.ComputeCheckDataResults(
+		t.config,
+		err,
+		t.counterStorage,
+		t.balanceStorage,
+		t.operationTypes,
+		t.getOperationTotals(),
+		t.getSkippedBlocks(),
+		t.endCondition,
+		t.endConditionDetail,
+	)
+	results.NewHTMLReport(
+		t.config,
+		checkResults,
+		t.reportSamples.Samples(),
+	).Output(t.config.Data.HTMLReportFile)
+}
+
+// getOperationTotals returns the total value moved by
+// each operation type (or nil if totals cannot be loaded).
+func (t *DataTester) getOperationTotals() []*results.OperationTotal {
+	totals, err := t.operationTotals.GetAll(context.Background())
+	if err != nil {
+		log.Printf("%s: unable to get operation totals\n", err.Error())
+		return nil
+	}
+
+	return totals
+}
+
+// getSkippedBlocks returns the synced blocks that were
+// skipped (or nil if there are none or they cannot be loaded).
+func (t *DataTester) getSkippedBlocks() []*types.BlockIdentifier {
+	if t.skippedBlocks == nil {
+		return nil
+	}
+
+	blocks, err := t.skippedBlocks.GetAll(context.Background())
+	if err != nil {
+		log.Printf("%s: unable to get skipped blocks\n", err.Error())
+		return nil
+	}
+
+	return blocks
+}
+
 // syncedStatus returns a boolean indicating if we are synced to tip and
 // the last synced block.
 func (t *DataTester) syncedStatus(ctx context.Context) (bool, int64, error) {
@@ -548,6 +1261,50 @@ func (t *DataTester) EndAtTipLoop(
 	}
 }
 
+// EndTipDistanceLoop runs a loop that evaluates end condition TipDistance
+func (t *DataTester) EndTipDistanceLoop(
+	ctx context.Context,
+	distance int64,
+) {
+	tc := time.NewTicker(EndAtTipCheckInterval)
+	defer tc.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+
+		case <-tc.C:
+			head, err := t.blockStorage.GetHeadBlockIdentifier(ctx)
+			if errors.Is(err, storageErrs.ErrHeadBlockNotFound) {
+				continue
+			}
+			if err != nil {
+				log.Printf("%s: unable to get head block", err.Error())
+				continue
+			}
+
+			status, fetchErr := t.fetcher.NetworkStatusRetry(ctx, t.network, nil)
+			if fetchErr != nil {
+				log.Printf("%s: unable to get network status", fetchErr.Err.Error())
+				continue
+			}
+
+			tip := status.CurrentBlockIdentifier.Index
+			if tip-head.Index <= distance {
+				t.endCondition = configuration.TipDistanceEndCondition
+				t.endConditionDetail = fmt.Sprintf(
+					"Tip: %d, Synced: %d",
+					tip,
+					head.Index,
+				)
+				t.cancel()
+				return
+			}
+		}
+	}
+}
+
 // EndReconciliationCoverage runs a loop that evaluates ReconciliationEndCondition
 func (t *DataTester) EndReconciliationCoverage( // nolint:gocognit
 	ctx context.Context,
@@ -625,7 +1382,7 @@ func (t *DataTester) EndReconciliationCoverage( // nolint:gocognit
 
 			// Check if account count is above minimum index
 			if reconciliationCoverage.AccountCount != nil {
-				allAccounts, err := t.balanceStorage.GetAllAccountCurrency(ctx)
+				allAccounts, err := getAllAccountCurrency(ctx, t.database)
 				if err != nil {
 					log.Printf(
 						"%s: unable to get account count",
@@ -673,6 +1430,62 @@ func (t *DataTester) EndReconciliationCoverage( // nolint:gocognit
 	}
 }
 
+// EndReconciliationCount runs a loop that evaluates ReconciliationCountEndCondition
+func (t *DataTester) EndReconciliationCount(
+	ctx context.Context,
+	reconciliationCount *configuration.ReconciliationCount,
+) {
+	tc := time.NewTicker(EndAtTipCheckInterval)
+	defer tc.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+
+		case <-tc.C:
+			// We force cached counts to be written before
+			// determining if we should exit.
+			if err := t.reconcilerHandler.UpdateCounts(ctx); err != nil {
+				log.Printf("%s: unable to update reconciliation counts", err.Error())
+				continue
+			}
+
+			active, err := t.counterStorage.Get(ctx, modules.ActiveReconciliationCounter)
+			if err != nil {
+				log.Printf("%s: unable to get active reconciliations counter", err.Error())
+				continue
+			}
+
+			inactive, err := t.counterStorage.Get(ctx, modules.InactiveReconciliationCounter)
+			if err != nil {
+				log.Printf("%s: unable to get inactive reconciliations counter", err.Error())
+				continue
+			}
+
+			if active.Int64() >= reconciliationCount.Active &&
+				inactive.Int64() >= reconciliationCount.Inactive {
+				t.endCondition = configuration.ReconciliationCountEndCondition
+				t.endConditionDetail = fmt.Sprintf(
+					"Active: %d, Inactive: %d",
+					active.Int64(),
+					inactive.Int64(),
+				)
+				t.cancel()
+				return
+			}
+
+			color.Cyan(fmt.Sprintf(
+				"[END CONDITIONS] Waiting for reconciliations (active: %d/%d, inactive: %d/%d)",
+				active.Int64(),
+				reconciliationCount.Active,
+				inactive.Int64(),
+				reconciliationCount.Inactive,
+			))
+		}
+	}
+}
+
 // EndDurationLoop runs a loop that evaluates end condition EndDuration.
 func (t *DataTester) EndDurationLoop(
 	ctx context.Context,
@@ -712,6 +1525,12 @@ func (t *DataTester) WatchEndConditions(
 		go t.EndAtTipLoop(ctx)
 	}
 
+	if endConds.TipDistance != nil {
+		// runs a go routine that ends when within
+		// some number of blocks of tip
+		go t.EndTipDistanceLoop(ctx, *endConds.TipDistance)
+	}
+
 	if endConds.Duration != nil && *endConds.Duration != 0 {
 		// runs a go routine that ends after a duration
 		go t.EndDurationLoop(ctx, time.Duration(*endConds.Duration)*time.Second)
@@ -721,6 +1540,10 @@ func (t *DataTester) WatchEndConditions(
 		go t.EndReconciliationCoverage(ctx, endConds.ReconciliationCoverage)
 	}
 
+	if endConds.ReconciliationCount != nil {
+		go t.EndReconciliationCount(ctx, endConds.ReconciliationCount)
+	}
+
 	return nil
 }
 
@@ -859,6 +1682,10 @@ func (t *DataTester) HandleErr(err error, sigListeners *[]context.CancelFunc) er
 			t.config,
 			t.counterStorage,
 			t.balanceStorage,
+			t.operationTypes,
+			t.getOperationTotals(),
+			t.getSkippedBlocks(),
+			t.failureReport,
 			fmt.Errorf("%w: %v", customErrs.ErrDataCheckHalt, err.Error()),
 			"",
 			"",
@@ -892,6 +1719,10 @@ func (t *DataTester) HandleErr(err error, sigListeners *[]context.CancelFunc) er
 						t.config,
 						t.counterStorage,
 						t.balanceStorage,
+						t.operationTypes,
+						t.getOperationTotals(),
+						t.getSkippedBlocks(),
+						t.failureReport,
 						drainErr,
 						"",
 						"",
@@ -900,22 +1731,44 @@ func (t *DataTester) HandleErr(err error, sigListeners *[]context.CancelFunc) er
 			}
 		}
 
-		return results.ExitData(
-			t.config,
-			t.counterStorage,
-			t.balanceStorage,
-			nil,
-			t.endCondition,
-			t.endConditionDetail,
+		// Reconciliations that failed within the failure limit
+		// did not halt processing but still fail the check.
+		tolerated := len(t.reconcilerHandler.ToleratedFailures())
+		if tolerated == 0 {
+			return results.ExitData(
+				t.config,
+				t.counterStorage,
+				t.balanceStorage,
+				t.operationTypes,
+				t.getOperationTotals(),
+				t.getSkippedBlocks(),
+				t.failureReport,
+				nil,
+				t.endCondition,
+				t.endConditionDetail,
+			)
+		}
+
+		err = fmt.Errorf(
+			"%w: %d reconciliations failed within the reconciliation failure limit",
+			results.ErrReconciliationFailure,
+			tolerated,
 		)
 	}
 
 	fmt.Printf("\n")
-	if t.reconcilerHandler.InactiveFailure == nil {
+	t.WriteFailureArtifacts(ctx, err)
+
+	failure, _, inactive := t.missingOpsSearchTarget()
+	if failure == nil {
 		return results.ExitData(
 			t.config,
 			t.counterStorage,
 			t.balanceStorage,
+			t.operationTypes,
+			t.getOperationTotals(),
+			t.getSkippedBlocks(),
+			t.failureReport,
 			err,
 			"",
 			"",
@@ -930,18 +1783,42 @@ func (t *DataTester) HandleErr(err error, sigListeners *[]context.CancelFunc) er
 			t.config,
 			t.counterStorage,
 			t.balanceStorage,
+			t.operationTypes,
+			t.getOperationTotals(),
+			t.getSkippedBlocks(),
+			t.failureReport,
 			err,
 			"",
 			"",
 		)
 	}
 
-	if t.config.Data.InactiveDiscrepancySearchDisabled {
+	if inactive && t.config.Data.InactiveDiscrepancySearchDisabled {
 		color.Yellow("Search for inactive reconciliation discrepancy is disabled")
 		return results.ExitData(
 			t.config,
 			t.counterStorage,
 			t.balanceStorage,
+			t.operationTypes,
+			t.getOperationTotals(),
+			t.getSkippedBlocks(),
+			t.failureReport,
+			err,
+			"",
+			"",
+		)
+	}
+
+	if !inactive && t.config.Data.ActiveDiscrepancySearchDisabled {
+		color.Yellow("Search for active reconciliation discrepancy is disabled")
+		return results.ExitData(
+			t.config,
+			t.counterStorage,
+			t.balanceStorage,
+			t.operationTypes,
+			t.getOperationTotals(),
+			t.getSkippedBlocks(),
+			t.failureReport,
 			err,
 			"",
 			"",
@@ -951,21 +1828,49 @@ func (t *DataTester) HandleErr(err error, sigListeners *[]context.CancelFunc) er
 	return t.FindMissingOps(ctx, err, sigListeners)
 }
 
+// MissingOpsError is returned by FindMissingOps when the block
+// missing balance-changing operations for an account is found.
+// It wraps the error that halted check:data.
+type MissingOpsError struct {
+	Account *types.AccountCurrency
+	Block   *types.BlockIdentifier
+	Err     error
+}
+
+func (e *MissingOpsError) Error() string {
+	return fmt.Sprintf(
+		"%s: missing ops for %s in block %d:%s",
+		e.Err.Error(),
+		types.AccountString(e.Account.Account),
+		e.Block.Index,
+		e.Block.Hash,
+	)
+}
+
+// Unwrap returns the error that halted check:data.
+func (e *MissingOpsError) Unwrap() error {
+	return e.Err
+}
+
 // FindMissingOps logs the types.BlockIdentifier of a block
 // that is missing balance-changing operations for a
-// *types.AccountCurrency.
+// *types.AccountCurrency. If the block is found, the returned
+// error is a *MissingOpsError wrapping originalErr.
 func (t *DataTester) FindMissingOps(
 	ctx context.Context,
 	originalErr error,
 	sigListeners *[]context.CancelFunc,
 ) error {
+	failure, failureBlock, _ := t.missingOpsSearchTarget()
+	startIndex := t.missingOpsStartIndex(ctx, failure, failureBlock)
+
 	color.Cyan("Searching for block with missing operations...hold tight")
 	badBlock, err := t.recursiveOpSearch(
 		ctx,
 		sigListeners,
-		t.reconcilerHandler.InactiveFailure,
-		t.reconcilerHandler.InactiveFailureBlock.Index-InactiveFailureLookbackWindow,
-		t.reconcilerHandler.InactiveFailureBlock.Index,
+		failure,
+		startIndex,
+		failureBlock.Index,
 	)
 	if err != nil {
 		color.Yellow("%s: could not find block with missing ops", err.Error())
@@ -973,6 +1878,10 @@ func (t *DataTester) FindMissingOps(
 			t.config,
 			t.counterStorage,
 			t.balanceStorage,
+			t.operationTypes,
+			t.getOperationTotals(),
+			t.getSkippedBlocks(),
+			t.failureReport,
 			originalErr,
 			"",
 			"",
@@ -981,21 +1890,199 @@ func (t *DataTester) FindMissingOps(
 
 	color.Yellow(
 		"Missing ops for %s in block %d:%s",
-		types.AccountString(t.reconcilerHandler.InactiveFailure.Account),
+		types.AccountString(failure.Account),
 		badBlock.Index,
 		badBlock.Hash,
 	)
+	t.WriteMissingOpsArtifact(ctx, failure, badBlock)
 
 	return results.ExitData(
 		t.config,
 		t.counterStorage,
 		t.balanceStorage,
-		originalErr,
+		t.operationTypes,
+		t.getOperationTotals(),
+		t.getSkippedBlocks(),
+		t.failureReport,
+		&MissingOpsError{Account: failure, Block: badBlock, Err: originalErr},
 		"",
 		"",
 	)
 }
 
+// missingOpsStartIndex returns the start of the first window
+// searched for the block missing operations (as determined by
+// the configured MissingOpsSearchStrategy). Unless searching from
+// the block where the account was first seen, the first window is
+// never larger than InactiveFailureLookbackWindow.
+func (t *DataTester) missingOpsStartIndex(
+	ctx context.Context,
+	failure *types.AccountCurrency,
+	failureBlock *types.BlockIdentifier,
+) int64 {
+	startIndex := failureBlock.Index - InactiveFailureLookbackWindow
+	if t.config.Data.MissingOpsSearchStrategy == configuration.FirstSeenSearch {
+		startIndex = t.genesisBlock.Index
+		if firstSeen, ok := t.firstSeenIndex(ctx, failure); ok && firstSeen < failureBlock.Index {
+			color.Cyan(
+				"%s was first seen at block %d",
+				types.AccountString(failure.Account),
+				firstSeen,
+			)
+			startIndex = firstSeen
+		}
+	} else if lastReconciled, ok := t.lastReconciledIndex(ctx, failure); ok &&
+		lastReconciled < failureBlock.Index {
+		color.Cyan(
+			"%s was last reconciled at block %d",
+			types.AccountString(failure.Account),
+			lastReconciled,
+		)
+		if lastReconciled > startIndex {
+			startIndex = lastReconciled
+		}
+	}
+
+	if startIndex < t.genesisBlock.Index {
+		startIndex = t.genesisBlock.Index
+	}
+
+	return startIndex
+}
+
+// missingOpsSearchTarget returns the account and block of the
+// reconciliation failure that halted check:data (preferring an
+// inactive failure) or, if processing did not halt, of the first
+// failure within the reconciliation failure limit, and a boolean
+// indicating if it was an inactive reconciliation. The account
+// is nil if no reconciliation failed.
+func (t *DataTester) missingOpsSearchTarget() (*types.AccountCurrency, *types.BlockIdentifier, bool) {
+	if t.reconcilerHandler.InactiveFailure != nil {
+		return t.reconcilerHandler.InactiveFailure, t.reconcilerHandler.InactiveFailureBlock, true
+	}
+
+	if t.reconcilerHandler.ActiveFailure != nil {
+		return t.reconcilerHandler.ActiveFailure, t.reconcilerHandler.ActiveFailureBlock, false
+	}
+
+	tolerated := t.reconcilerHandler.ToleratedFailures()
+	if len(tolerated) == 0 {
+		return nil, nil, false
+	}
+
+	return &types.AccountCurrency{
+		Account:  tolerated[0].Account,
+		Currency: tolerated[0].Currency,
+	}, tolerated[0].Block, tolerated[0].Type == reconciler.InactiveReconciliation
+}
+
+// lastReconciledIndex returns the index of the last block where
+// accountCurrency was reconciled (as recorded by BalanceStorage).
+func (t *DataTester) lastReconciledIndex(
+	ctx context.Context,
+	accountCurrency *types.AccountCurrency,
+) (int64, bool) {
+	dbTx := t.database.ReadTransaction(ctx)
+	defer dbTx.Discard(ctx)
+
+	exists, index, err := modules.BigIntGet(
+		ctx,
+		modules.GetAccountKey(
+			reconciledAccountNamespace,
+			accountCurrency.Account,
+			accountCurrency.Currency,
+		),
+		dbTx,
+	)
+	if err != nil || !exists {
+		return 0, false
+	}
+
+	return index.Int64(), true
+}
+
+// firstSeenIndex returns the index of the earliest balance of
+// accountCurrency in storage (which is the block where it was
+// first seen unless older balances have been pruned).
+func (t *DataTester) firstSeenIndex(
+	ctx context.Context,
+	accountCurrency *types.AccountCurrency,
+) (int64, bool) {
+	dbTx := t.database.ReadTransaction(ctx)
+	defer dbTx.Discard(ctx)
+
+	prefix := modules.GetHistoricalBalancePrefix(accountCurrency.Account, accountCurrency.Currency)
+	var index int64
+	_, err := dbTx.Scan(
+		ctx,
+		prefix,
+		prefix,
+		func(k []byte, v []byte) error {
+			parsed, err := strconv.ParseInt(string(k[len(prefix):]), 10, 64)
+			if err != nil {
+				return err
+			}
+
+			index = parsed
+			return errBalanceFound
+		},
+		false,
+		false,
+	)
+	if !errors.Is(err, errBalanceFound) {
+		return 0, false
+	}
+
+	return index, true
+}
+
+// missingOpsWindow is a range of blocks synced while searching
+// for the block missing operations.
+type missingOpsWindow struct {
+	start int64
+	end   int64
+}
+
+// previousWindow returns the window preceding window (bounded
+// by genesis) for the configured MissingOpsSearchStrategy.
+func (t *DataTester) previousWindow(window *missingOpsWindow) (*missingOpsWindow, error) {
+	if window.start <= t.genesisBlock.Index {
+		return nil, errors.New("unable to find missing ops")
+	}
+
+	var newStart, newEnd int64
+	switch t.config.Data.MissingOpsSearchStrategy {
+	case configuration.FirstSeenSearch:
+		// The first window already starts where the account was first
+		// seen, so no preceding block can be missing operations.
+		return nil, errors.New("unable to find missing ops")
+	case configuration.ExponentialWindowSearch:
+		newEnd = window.start
+		newStart = window.start - 2*(window.end-window.start)
+	default:
+		newEnd = window.start
+		newStart = window.start - InactiveFailureLookbackWindow
+	}
+
+	if newStart < t.genesisBlock.Index {
+		newStart = t.genesisBlock.Index
+	}
+
+	if newEnd <= newStart {
+		return nil, fmt.Errorf(
+			"Next window to check has start index %d <= end index %d",
+			newStart,
+			newEnd,
+		)
+	}
+
+	return &missingOpsWindow{start: newStart, end: newEnd}, nil
+}
+
+// recursiveOpSearch searches up to MissingOpsSearchConcurrency
+// windows (starting at startIndex-endIndex and moving back toward
+// genesis) concurrently for the block missing operations. If none
+// of the windows contain it, the preceding windows are searched.
 func (t *DataTester) recursiveOpSearch(
 	ctx context.Context,
 	sigListeners *[]context.CancelFunc,
@@ -1003,9 +2090,86 @@ func (t *DataTester) recursiveOpSearch(
 	startIndex int64,
 	endIndex int64,
 ) (*types.BlockIdentifier, error) {
+	windows := []*missingOpsWindow{{start: startIndex, end: endIndex}}
+	for len(windows) < t.config.Data.MissingOpsSearchConcurrency {
+		previous, err := t.previousWindow(windows[len(windows)-1])
+		if err != nil {
+			break
+		}
+
+		windows = append(windows, previous)
+	}
+
 	// To cancel all execution, need to call multiple cancel functions.
 	ctx, cancel := context.WithCancel(ctx)
 	*sigListeners = append(*sigListeners, cancel)
+	defer cancel()
+
+	t.forceInactiveReconciliation = types.Bool(false)
+	badBlocks := make([]*types.BlockIdentifier, len(windows))
+	g, gctx := errgroup.WithContext(ctx)
+	for i, window := range windows {
+		i, window := i, window
+		g.Go(func() error {
+			badBlock, err := t.searchWindow(gctx, accountCurrency, window)
+			badBlocks[i] = badBlock
+			return err
+		})
+	}
+	err := g.Wait()
+
+	if *t.signalReceived {
+		return nil, errors.New("search for block with missing ops halted")
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	// Windows are ordered from closest to furthest from the failure,
+	// so the first block found is the one closest to the failure.
+	for _, badBlock := range badBlocks {
+		if badBlock != nil {
+			return badBlock, nil
+		}
+	}
+
+	first, last := windows[0], windows[len(windows)-1]
+	next, err := t.previousWindow(last)
+	if err != nil {
+		return nil, err
+	}
+
+	color.Cyan(
+		"Unable to find missing ops in block range %d-%d, now searching %d-%d",
+		last.start, first.end,
+		next.start,
+		next.end,
+	)
+
+	return t.recursiveOpSearch(
+		// We need to use new context for each invocation because the
+		// provided context may already be canceled.
+		context.Background(),
+		sigListeners,
+		accountCurrency,
+		next.start,
+		next.end,
+	)
+}
+
+// searchWindow syncs window into a temporary store and returns
+// the block where reconciling accountCurrency failed (nil if
+// the window synced without a failure).
+func (t *DataTester) searchWindow(
+	ctx context.Context,
+	accountCurrency *types.AccountCurrency,
+	window *missingOpsWindow,
+) (*types.BlockIdentifier, error) {
+	// The syncer cancels this context when it reaches the end
+	// of the window.
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
 
 	// Always use a temporary directory to find missing ops
 	tmpDir, err := utils.CreateTempDir()
@@ -1037,7 +2201,6 @@ func (t *DataTester) recursiveOpSearch(
 		return nil, fmt.Errorf("unable to initialize logger with error: %s", err.Error())
 	}
 
-	t.forceInactiveReconciliation = types.Bool(false)
 	reconcilerHelper := processor.NewReconcilerHelper(
 		t.config,
 		t.network,
@@ -1046,14 +2209,25 @@ func (t *DataTester) recursiveOpSearch(
 		blockStorage,
 		balanceStorage,
 		t.forceInactiveReconciliation,
+		nil, // don't trace search reconciliations
 	)
+	// Each window has its own copy of the sub-account registry
+	// (windows are searched concurrently), so sub-accounts seen
+	// while searching are only stored in the temporary store.
+	var subAccountRegistry *processor.SubAccountRegistry
+	if t.subAccountRegistry != nil {
+		subAccountRegistry = t.subAccountRegistry.Copy(localStore)
+		reconcilerHelper.SetSubAccountRegistry(subAccountRegistry)
+	}
 
 	reconcilerHandler := processor.NewReconcilerHandler(
 		logger,
 		counterStorage,
 		balanceStorage,
+		nil,  // don't record search reconciliations
 		true, // halt on reconciliation error
 	)
+	reconcilerHandler.SetBalanceExemptions(t.config.Data.BalanceExemptions)
 
 	r := reconciler.New(
 		reconcilerHelper,
@@ -1082,6 +2256,31 @@ func (t *DataTester) recursiveOpSearch(
 		t.parser.BalanceExemptions,
 		false, // we will need to perform an initial balance fetch when finding issues
 	)
+	balanceStorageHelper.SetExemptOperationTypes(t.config.Data.ExemptOperationTypes)
+	balanceStorageHelper.SetIgnoreSubAccounts(
+		t.config.Data.SubAccounts == configuration.IgnoreSubAccounts,
+	)
+
+	negativeBalanceAccounts, err := loadAccounts(t.config.Data.NegativeBalanceAccounts)
+	if err != nil {
+		return nil, fmt.Errorf("%w: unable to load negative balance accounts", err)
+	}
+	balanceStorageHelper.SetNegativeBalanceAccounts(negativeBalanceAccounts)
+
+	blockWorkers := []modules.BlockWorker{}
+	if subAccountRegistry != nil {
+		blockWorkers = append(blockWorkers, subAccountRegistry)
+	}
+	if len(negativeBalanceAccounts) > 0 {
+		negativeBalances := processor.NewNegativeBalances(
+			balanceStorageHelper,
+			negativeBalanceAccounts,
+		)
+		negativeBalances.SetReconciler(r)
+		reconcilerHelper.SetNegativeBalances(negativeBalances)
+
+		blockWorkers = append(blockWorkers, negativeBalances)
+	}
 
 	balanceStorageHandler := processor.NewBalanceStorageHandler(
 		logger,
@@ -1089,10 +2288,26 @@ func (t *DataTester) recursiveOpSearch(
 		counterStorage,
 		true,
 		accountCurrency,
+		nil,
 	)
 
 	balanceStorage.Initialize(balanceStorageHelper, balanceStorageHandler)
 
+	asserterConfiguration, err := t.fetcher.Asserter.ClientConfiguration()
+	if err != nil {
+		return nil, fmt.Errorf("%w: unable to get asserter configuration", err)
+	}
+
+	balanceStorageWorker, err := adjustedBalanceStorage(
+		t.config,
+		t.network,
+		balanceStorage,
+		asserterConfiguration.AllowedOperationStatuses,
+	)
+	if err != nil {
+		return nil, err
+	}
+
 	syncer := statefulsyncer.New(
 		ctx,
 		t.network,
@@ -1101,8 +2316,8 @@ func (t *DataTester) recursiveOpSearch(
 		counterStorage,
 		logger,
 		cancel,
-		[]modules.BlockWorker{balanceStorage},
-		statefulsyncer.WithCacheSize(syncer.DefaultCacheSize),
+		append(blockWorkers, balanceStorageWorker),
+		statefulsyncer.WithCacheSize(syncCacheSize(t.config)),
 		statefulsyncer.WithMaxConcurrency(t.config.MaxSyncConcurrency),
 		statefulsyncer.WithPastBlockLimit(t.config.MaxReorgDepth),
 		statefulsyncer.WithSeenConcurrency(int64(t.config.SeenBlockWorkers)),
@@ -1116,8 +2331,8 @@ func (t *DataTester) recursiveOpSearch(
 	g.Go(func() error {
 		return syncer.Sync(
 			ctx,
-			startIndex,
-			endIndex,
+			window.start,
+			window.end,
 		)
 	})
 
@@ -1129,46 +2344,8 @@ func (t *DataTester) recursiveOpSearch(
 		return nil, fmt.Errorf("%w: unable to close database", storageErr)
 	}
 
-	if *t.signalReceived {
-		return nil, errors.New("search for block with missing ops halted")
-	}
-
 	if err == nil || errors.Is(err, context.Canceled) {
-		if startIndex <= t.genesisBlock.Index {
-			return nil, errors.New("unable to find missing ops")
-		}
-
-		newStart := startIndex - InactiveFailureLookbackWindow
-		if newStart < t.genesisBlock.Index {
-			newStart = t.genesisBlock.Index
-		}
-
-		newEnd := endIndex - InactiveFailureLookbackWindow
-		if newEnd <= newStart {
-			return nil, fmt.Errorf(
-				"Next window to check has start index %d <= end index %d",
-				newStart,
-				newEnd,
-			)
-		}
-
-		color.Cyan(
-			"Unable to find missing ops in block range %d-%d, now searching %d-%d",
-			startIndex, endIndex,
-			newStart,
-			newEnd,
-		)
-
-		return t.recursiveOpSearch(
-			// We need to use new context for each invocation because the syncer
-			// cancels the provided context when it reaches the end of a syncing
-			// window.
-			context.Background(),
-			sigListeners,
-			accountCurrency,
-			startIndex-InactiveFailureLookbackWindow,
-			endIndex-InactiveFailureLookbackWindow,
-		)
+		return nil, nil
 	}
 
 	if reconcilerHandler.ActiveFailureBlock == nil {
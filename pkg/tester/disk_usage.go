@@ -0,0 +1,121 @@
+// Copyright 2022 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tester
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/coinbase/rosetta-cli/configuration"
+
+	"github.com/fatih/color"
+)
+
+const (
+	// diskUsageThreshold is the fraction of the maximum
+	// data directory size at which action is taken.
+	diskUsageThreshold = 0.9
+
+	bytesPerMB = 1 << 20
+)
+
+// StartDiskUsageMonitor periodically checks the size of the data
+// directory (if a maximum size is configured). Once the directory
+// approaches its maximum size, old blocks are pruned (if the action
+// is "prune") and syncing is paused if that does not free enough
+// space. Syncing is resumed once usage drops below the threshold.
+func (t *DataTester) StartDiskUsageMonitor(ctx context.Context) error {
+	config := t.config.Data.DiskUsage
+	if config == nil || t.config.InMemoryStorage {
+		return nil
+	}
+
+	maxSize := config.MaxSizeMB * bytesPerMB
+	threshold := int64(float64(maxSize) * diskUsageThreshold)
+	paused := false
+
+	tc := time.NewTicker(time.Duration(config.CheckFrequency) * time.Second)
+	defer tc.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-tc.C:
+		}
+
+		size, err := directorySize(t.config.DataDirectory)
+		if err != nil {
+			return fmt.Errorf("%w: unable to compute size of data directory", err)
+		}
+
+		if size < threshold {
+			if paused {
+				paused = false
+				t.syncGate.unpause()
+				color.Yellow("Syncing resumed: data directory is %d MB", size/bytesPerMB)
+			}
+
+			continue
+		}
+
+		if config.Action == configuration.DiskUsagePrune {
+			size, err = t.pruneForDiskUsage(ctx)
+			if err != nil {
+				return err
+			}
+
+			if size < maxSize {
+				continue
+			}
+		}
+
+		if !paused {
+			paused = true
+			t.syncGate.pause()
+			color.Yellow(
+				"Syncing paused: data directory is %d MB (limit is %d MB), free disk space or increase max_size_mb",
+				size/bytesPerMB,
+				config.MaxSizeMB,
+			)
+		}
+	}
+}
+
+// pruneForDiskUsage prunes all blocks deeper than max_reorg_depth
+// and returns the size of the data directory after pruning.
+func (t *DataTester) pruneForDiskUsage(ctx context.Context) (int64, error) {
+	head, err := t.blockStorage.GetHeadBlockIdentifier(ctx)
+	if err != nil {
+		return -1, fmt.Errorf("%w: unable to get head block", err)
+	}
+
+	index, err := t.PruneableIndex(ctx, head.Index)
+	if err != nil {
+		return -1, fmt.Errorf("%w: unable to get pruneable index", err)
+	}
+
+	first, last, err := t.blockStorage.Prune(ctx, index, int64(t.config.MaxReorgDepth))
+	if err != nil {
+		return -1, fmt.Errorf("%w: unable to prune blocks", err)
+	}
+
+	if first >= 0 {
+		color.Yellow("Pruned blocks %d-%d to bound disk usage", first, last)
+	}
+
+	return directorySize(t.config.DataDirectory)
+}
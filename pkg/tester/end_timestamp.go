@@ -0,0 +1,122 @@
+// Copyright 2022 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tester
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/coinbase/rosetta-cli/configuration"
+
+	"github.com/coinbase/rosetta-sdk-go/types"
+)
+
+// resolveEndTimestamp sets the end index to the last block
+// before the end timestamp (if it is before the end index).
+func (t *DataTester) resolveEndTimestamp(ctx context.Context) error {
+	endConds := t.config.Data.EndConditions
+	endTime, err := configuration.ParseEndTimestamp(endConds.Timestamp)
+	if err != nil {
+		return err
+	}
+
+	index, err := t.endTimestampIndex(ctx, endTime)
+	if err != nil {
+		return fmt.Errorf("%w: unable to find block before %s", err, endConds.Timestamp)
+	}
+
+	log.Printf("Ending at block %d (the last block before %s)\n", index, endConds.Timestamp)
+	if endConds.Index == nil || index < *endConds.Index {
+		endConds.Index = &index
+	}
+
+	return nil
+}
+
+// endTimestampIndex returns the index of the last block with a
+// timestamp before endTime by searching the blocks between
+// genesis and the current block (block timestamps are expected
+// to never decrease).
+func (t *DataTester) endTimestampIndex(ctx context.Context, endTime time.Time) (int64, error) {
+	end := endTime.UnixNano() / int64(time.Millisecond)
+	status, fetchErr := t.fetcher.NetworkStatusRetry(ctx, t.network, nil)
+	if fetchErr != nil {
+		return -1, fmt.Errorf("%w: unable to get network status", fetchErr.Err)
+	}
+
+	if status.CurrentBlockTimestamp < end {
+		return -1, fmt.Errorf(
+			"current block %d is before the end timestamp",
+			status.CurrentBlockIdentifier.Index,
+		)
+	}
+
+	// The last block before endTime is always in [low, high).
+	low := t.genesisBlock.Index
+	high := status.CurrentBlockIdentifier.Index
+	timestamp, _, err := t.blockTimestamp(ctx, low, high)
+	if err != nil {
+		return -1, err
+	}
+
+	if timestamp >= end {
+		return -1, fmt.Errorf("genesis block %d is not before the end timestamp", low)
+	}
+
+	for high-low > 1 {
+		mid := low + (high-low)/2
+		timestamp, index, err := t.blockTimestamp(ctx, mid, high)
+		if err != nil {
+			return -1, err
+		}
+
+		if index < high && timestamp < end {
+			low = index
+		} else {
+			high = mid
+		}
+	}
+
+	return low, nil
+}
+
+// blockTimestamp returns the timestamp and index of the first
+// block that is not omitted at or after index (or high if all
+// blocks before high are omitted).
+func (t *DataTester) blockTimestamp(
+	ctx context.Context,
+	index int64,
+	high int64,
+) (int64, int64, error) {
+	for ; index < high; index++ {
+		blockIndex := index
+		block, fetchErr := t.fetcher.BlockRetry(
+			ctx,
+			t.network,
+			&types.PartialBlockIdentifier{Index: &blockIndex},
+		)
+		if fetchErr != nil {
+			return -1, -1, fmt.Errorf("%w: unable to fetch block %d", fetchErr.Err, index)
+		}
+
+		if block != nil {
+			return block.Timestamp, index, nil
+		}
+	}
+
+	return -1, high, nil
+}
@@ -0,0 +1,190 @@
+// Copyright 2022 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tester
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path"
+
+	"github.com/coinbase/rosetta-cli/pkg/processor"
+	"github.com/coinbase/rosetta-cli/pkg/results"
+
+	"github.com/coinbase/rosetta-sdk-go/types"
+	"github.com/coinbase/rosetta-sdk-go/utils"
+	"github.com/fatih/color"
+)
+
+const (
+	failureFile        = "failure.json"
+	blockFile          = "block.json"
+	balanceHistoryFile = "balance_history.json"
+	liveBalanceFile    = "live_balance.json"
+	countersFile       = "counters.json"
+
+	// failureArtifactsDirMode is the permission
+	// of each failure artifact bundle.
+	failureArtifactsDirMode = 0750
+)
+
+// FailureArtifact describes the reconciliation
+// failure that a bundle was written for.
+type FailureArtifact struct {
+	Error   string                           `json:"error"`
+	Attempt *processor.ReconciliationAttempt `json:"reconciliation"`
+}
+
+// BalanceHistoryArtifact is the balance history
+// of the failing account from storage.
+type BalanceHistoryArtifact struct {
+	// Balance is the computed balance stored at the
+	// block where reconciliation failed.
+	Balance *types.Amount `json:"balance"`
+
+	// Reconciliations are all previous reconciliations of the
+	// account (only populated if reconciliation history is enabled).
+	Reconciliations []*processor.ReconciliationAttempt `json:"reconciliations,omitempty"`
+}
+
+// WriteFailureArtifacts writes a bundle of artifacts about a
+// reconciliation failure to the configured FailureArtifactsDirectory
+// (if populated) so that the failure can be reproduced without
+// re-running the entire sync. Any artifact that cannot be collected
+// is skipped.
+func (t *DataTester) WriteFailureArtifacts(ctx context.Context, err error) {
+	attempt := t.reconcilerHandler.FailureAttempt
+	if len(t.config.Data.FailureArtifactsDirectory) == 0 || attempt == nil {
+		return
+	}
+
+	dir := path.Join(
+		t.config.Data.FailureArtifactsDirectory,
+		fmt.Sprintf("%d-%d", attempt.Block.Index, attempt.Timestamp),
+	)
+	if err := os.MkdirAll(dir, failureArtifactsDirMode); err != nil {
+		color.Yellow("%s: unable to create failure artifacts directory", err.Error())
+		return
+	}
+
+	artifacts := map[string]func() (interface{}, error){
+		failureFile: func() (interface{}, error) {
+			return &FailureArtifact{Error: err.Error(), Attempt: attempt}, nil
+		},
+		blockFile: func() (interface{}, error) {
+			return t.failureBlock(ctx, attempt.Block)
+		},
+		balanceHistoryFile: func() (interface{}, error) {
+			return t.failureBalanceHistory(ctx, attempt)
+		},
+		liveBalanceFile: func() (interface{}, error) {
+			return t.failureLiveBalance(ctx, attempt)
+		},
+		countersFile: func() (interface{}, error) {
+			return results.ComputeCheckDataStats(
+				ctx,
+				t.counterStorage,
+				t.balanceStorage,
+				t.operationTypes,
+			), nil
+		},
+	}
+
+	for name, collect := range artifacts {
+		artifact, err := collect()
+		if err != nil {
+			color.Yellow("%s: unable to collect %s", err.Error(), name)
+			continue
+		}
+
+		if err := utils.SerializeAndWrite(path.Join(dir, name), artifact); err != nil {
+			color.Yellow("%s: unable to write %s", err.Error(), name)
+		}
+	}
+
+	color.Cyan("Failure artifacts written to %s", dir)
+}
+
+// failureBlock returns the block where reconciliation failed
+// from storage or, if it has been pruned, from the node.
+func (t *DataTester) failureBlock(
+	ctx context.Context,
+	block *types.BlockIdentifier,
+) (*types.Block, error) {
+	stored, err := t.blockStorage.GetBlock(ctx, types.ConstructPartialBlockIdentifier(block))
+	if err == nil {
+		return stored, nil
+	}
+
+	fetched, fetchErr := t.fetcher.BlockRetry(
+		ctx,
+		t.network,
+		types.ConstructPartialBlockIdentifier(block),
+	)
+	if fetchErr != nil {
+		return nil, fmt.Errorf("%w: unable to fetch block %d", fetchErr.Err, block.Index)
+	}
+
+	return fetched, nil
+}
+
+func (t *DataTester) failureBalanceHistory(
+	ctx context.Context,
+	attempt *processor.ReconciliationAttempt,
+) (*BalanceHistoryArtifact, error) {
+	balance, err := t.balanceStorage.GetBalance(
+		ctx,
+		attempt.Account,
+		attempt.Currency,
+		attempt.Block.Index,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("%w: unable to get balance", err)
+	}
+
+	artifact := &BalanceHistoryArtifact{Balance: balance}
+	if t.reconciliationHistory == nil {
+		return artifact, nil
+	}
+
+	artifact.Reconciliations, err = t.reconciliationHistory.Get(ctx, attempt.Account)
+	if err != nil {
+		return nil, fmt.Errorf("%w: unable to get reconciliation history", err)
+	}
+
+	return artifact, nil
+}
+
+func (t *DataTester) failureLiveBalance(
+	ctx context.Context,
+	attempt *processor.ReconciliationAttempt,
+) (*types.AccountBalanceResponse, error) {
+	block, amounts, metadata, fetchErr := t.fetcher.AccountBalanceRetry(
+		ctx,
+		t.network,
+		attempt.Account,
+		types.ConstructPartialBlockIdentifier(attempt.Block),
+		[]*types.Currency{attempt.Currency},
+	)
+	if fetchErr != nil {
+		return nil, fmt.Errorf("%w: unable to fetch live balance", fetchErr.Err)
+	}
+
+	return &types.AccountBalanceResponse{
+		BlockIdentifier: block,
+		Balances:        amounts,
+		Metadata:        metadata,
+	}, nil
+}
@@ -21,7 +21,10 @@ import (
 	"net/http"
 	"time"
 
+	"github.com/coinbase/rosetta-cli/configuration"
 	"github.com/coinbase/rosetta-cli/pkg/logger"
+
+	"github.com/coinbase/rosetta-sdk-go/syncer"
 )
 
 const (
@@ -48,6 +51,17 @@ func LogMemoryLoop(
 	}
 }
 
+// syncCacheSize returns the maximum number of bytes of fetched
+// blocks the syncer holds in memory. The syncer reduces its
+// concurrency (down to a single fetcher) to stay within it.
+func syncCacheSize(config *configuration.Configuration) int {
+	if config.SyncMemoryBudgetMB == 0 {
+		return syncer.DefaultCacheSize
+	}
+
+	return int(config.SyncMemoryBudgetMB << 20)
+}
+
 // StartServer stats a server at a port with a particular handler.
 // This is often used to support a status endpoint for a particular test.
 func StartServer(
@@ -55,9 +69,31 @@ func StartServer(
 	name string,
 	handler http.Handler,
 	port uint,
+) error {
+	return startServer(ctx, name, handler, fmt.Sprintf(":%d", port), port)
+}
+
+// StartLocalServer starts a server at a port with a particular
+// handler that only accepts connections from localhost. This
+// should be used for endpoints that modify a running test.
+func StartLocalServer(
+	ctx context.Context,
+	name string,
+	handler http.Handler,
+	port uint,
+) error {
+	return startServer(ctx, name, handler, fmt.Sprintf("localhost:%d", port), port)
+}
+
+func startServer(
+	ctx context.Context,
+	name string,
+	handler http.Handler,
+	addr string,
+	port uint,
 ) error {
 	server := &http.Server{
-		Addr:    fmt.Sprintf(":%d", port),
+		Addr:    addr,
 		Handler: handler,
 	}
 
@@ -0,0 +1,120 @@
+// Copyright 2022 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tester
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path"
+
+	"github.com/coinbase/rosetta-cli/configuration"
+	"github.com/coinbase/rosetta-cli/pkg/results"
+
+	storageErrs "github.com/coinbase/rosetta-sdk-go/storage/errors"
+	"github.com/coinbase/rosetta-sdk-go/storage/modules"
+	"github.com/coinbase/rosetta-sdk-go/types"
+)
+
+// inspectedCounters are included in a *StorageInspection.
+var inspectedCounters = []string{
+	modules.BlockCounter,
+	modules.OrphanCounter,
+	modules.TransactionCounter,
+	modules.OperationCounter,
+	modules.SeenAccounts,
+	modules.ReconciledAccounts,
+	modules.ActiveReconciliationCounter,
+	modules.InactiveReconciliationCounter,
+	modules.ExemptReconciliationCounter,
+	modules.FailedReconciliationCounter,
+	modules.SkippedReconciliationsCounter,
+	results.TimeElapsedCounter,
+}
+
+// StorageInspection describes the check:data
+// storage in a data directory.
+type StorageInspection struct {
+	Path            string                 `json:"path"`
+	Size            int64                  `json:"size"`
+	HeadBlock       *types.BlockIdentifier `json:"head_block,omitempty"`
+	OldestBlock     int64                  `json:"oldest_block"`
+	StoredBlocks    int64                  `json:"stored_blocks"`
+	TrackedAccounts int                    `json:"tracked_accounts"`
+	Counters        map[string]int64       `json:"counters"`
+}
+
+// InspectStorage returns a *StorageInspection of the storage
+// used by previous runs of `check:data`. This must not be called
+// while `check:data` is running on the same data directory.
+func InspectStorage(
+	ctx context.Context,
+	config *configuration.Configuration,
+) (*StorageInspection, error) {
+	localStore, err := openDataDatabase(ctx, config)
+	if err != nil {
+		return nil, err
+	}
+	defer localStore.Close(ctx)
+
+	inspection := &StorageInspection{
+		Path:     path.Join(config.DataDirectory, dataCmdName, types.Hash(config.Network)),
+		Counters: map[string]int64{},
+	}
+
+	blockStorage := modules.NewBlockStorage(localStore, config.SerialBlockWorkers)
+	head, err := blockStorage.GetHeadBlockIdentifier(ctx)
+	switch {
+	case err == nil:
+		inspection.HeadBlock = head
+	case errors.Is(err, storageErrs.ErrHeadBlockNotFound):
+		// Nothing has been synced yet.
+	default:
+		return nil, fmt.Errorf("%w: unable to get head block", err)
+	}
+
+	if head != nil {
+		oldest, err := blockStorage.GetOldestBlockIndex(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("%w: unable to get oldest block index", err)
+		}
+
+		inspection.OldestBlock = oldest
+		inspection.StoredBlocks = head.Index - oldest + 1
+	}
+
+	accounts, err := modules.NewBalanceStorage(localStore).GetAllAccountCurrency(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("%w: unable to get tracked accounts", err)
+	}
+	inspection.TrackedAccounts = len(accounts)
+
+	counterStorage := modules.NewCounterStorage(localStore)
+	for _, counter := range inspectedCounters {
+		value, err := counterStorage.Get(ctx, counter)
+		if err != nil {
+			return nil, fmt.Errorf("%w: unable to get %s counter", err, counter)
+		}
+
+		inspection.Counters[counter] = value.Int64()
+	}
+
+	inspection.Size, err = directorySize(inspection.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	return inspection, nil
+}
@@ -0,0 +1,97 @@
+// Copyright 2022 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tester
+
+import (
+	"context"
+	"errors"
+	"log"
+	"os"
+	"time"
+
+	"github.com/coinbase/rosetta-sdk-go/types"
+	"github.com/fatih/color"
+)
+
+// errInterestingAccountsUnsupported is returned when interesting
+// accounts are added while balance tracking is disabled.
+var errInterestingAccountsUnsupported = errors.New(
+	"interesting accounts cannot be added when balance tracking is disabled",
+)
+
+// addInterestingAccounts adds accounts that are
+// checked on each block from now on.
+func (t *DataTester) addInterestingAccounts(accounts []*types.AccountCurrency) (int, error) {
+	if t.balanceStorageHandler == nil {
+		return 0, errInterestingAccountsUnsupported
+	}
+
+	return t.balanceStorageHandler.AddInterestingAccounts(accounts), nil
+}
+
+// StartInterestingAccountsReloader periodically checks the interesting
+// accounts file for changes (if configured) and adds any newly
+// listed accounts.
+func (t *DataTester) StartInterestingAccountsReloader(ctx context.Context) error {
+	filePath := t.config.Data.InterestingAccounts
+	frequency := t.config.Data.InterestingAccountsReloadFrequency
+	if frequency == 0 || len(filePath) == 0 || t.balanceStorageHandler == nil {
+		return nil
+	}
+
+	var lastModified time.Time
+	if info, err := os.Stat(filePath); err == nil {
+		lastModified = info.ModTime()
+	}
+
+	tc := time.NewTicker(time.Duration(frequency) * time.Second)
+	defer tc.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-tc.C:
+		}
+
+		info, err := os.Stat(filePath)
+		if err != nil {
+			log.Printf("%s: unable to check interesting accounts file\n", err.Error())
+			continue
+		}
+
+		if !info.ModTime().After(lastModified) {
+			continue
+		}
+
+		// The file may be in the middle of being written,
+		// so we retry on the next tick if it can't be parsed.
+		accounts, err := loadAccounts(filePath)
+		if err != nil {
+			log.Printf("%s: unable to reload interesting accounts\n", err.Error())
+			continue
+		}
+
+		lastModified = info.ModTime()
+		added, err := t.addInterestingAccounts(accounts)
+		if err != nil {
+			return err
+		}
+
+		if added > 0 {
+			color.Cyan("Added %d interesting accounts from %s", added, filePath)
+		}
+	}
+}
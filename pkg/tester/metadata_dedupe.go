@@ -0,0 +1,446 @@
+// Copyright 2022 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tester
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/coinbase/rosetta-sdk-go/storage/database"
+	"github.com/coinbase/rosetta-sdk-go/types"
+)
+
+const (
+	// storedTransactionNamespace is the namespace of
+	// transactions stored by modules.BlockStorage.
+	storedTransactionNamespace = "transaction"
+
+	// metadataBlobKey is the only key of metadata
+	// that was replaced with a reference to a blob.
+	metadataBlobKey = "rosetta_cli_metadata_blob"
+
+	// dedupedValueMarker is prepended to stored transactions
+	// that reference blobs. Values encoded by the database
+	// encoder never start with this byte.
+	dedupedValueMarker = 0x00
+
+	// metadataRefSize is the size of an encoded
+	// reference count of a blob.
+	metadataRefSize = 8
+)
+
+var (
+	storedTransactionPrefix = []byte(storedTransactionNamespace + "/")
+	metadataBlobPrefix      = "metablob/"
+	metadataRefPrefix       = "metaref/"
+)
+
+// storedTransaction mirrors the transactions
+// stored by modules.BlockStorage.
+type storedTransaction struct {
+	Transaction *types.Transaction `json:"transaction"`
+	BlockIndex  int64              `json:"block_index"`
+}
+
+var _ database.Database = (*metadataDedupeDatabase)(nil)
+
+// metadataDedupeDatabase implements the database.Database interface
+// by storing each transaction or operation metadata object that is
+// at least threshold bytes (when encoded as JSON) once, keyed by its
+// hash, and replacing it with a reference in stored transactions.
+// Blobs are reference counted, so they are removed once all
+// transactions that reference them are pruned or orphaned.
+//
+// References are resolved whenever stored transactions are read
+// (even if deduplication is disabled after storing references).
+type metadataDedupeDatabase struct {
+	database.Database
+
+	threshold int
+}
+
+func newMetadataDedupeDatabase(db database.Database, threshold int) *metadataDedupeDatabase {
+	return &metadataDedupeDatabase{Database: db, threshold: threshold}
+}
+
+// Transaction returns an exclusive transaction
+// that deduplicates metadata.
+func (d *metadataDedupeDatabase) Transaction(ctx context.Context) database.Transaction {
+	return &metadataDedupeTransaction{Transaction: d.Database.Transaction(ctx), db: d}
+}
+
+// ReadTransaction returns a read transaction
+// that resolves metadata references.
+func (d *metadataDedupeDatabase) ReadTransaction(ctx context.Context) database.Transaction {
+	return &metadataDedupeTransaction{Transaction: d.Database.ReadTransaction(ctx), db: d}
+}
+
+// WriteTransaction returns a granular write
+// transaction that deduplicates metadata.
+func (d *metadataDedupeDatabase) WriteTransaction(
+	ctx context.Context,
+	identifier string,
+	priority bool,
+) database.Transaction {
+	return &metadataDedupeTransaction{
+		Transaction: d.Database.WriteTransaction(ctx, identifier, priority),
+		db:          d,
+	}
+}
+
+// metadataDedupeTransaction deduplicates metadata of stored
+// transactions when they are set and resolves references
+// when they are read.
+type metadataDedupeTransaction struct {
+	database.Transaction
+
+	db *metadataDedupeDatabase
+
+	// lock serializes updates of reference counts
+	// (modules may write from multiple goroutines).
+	lock sync.Mutex
+}
+
+func isStoredTransactionKey(key []byte) bool {
+	return bytes.HasPrefix(key, storedTransactionPrefix)
+}
+
+func isDeduped(value []byte) bool {
+	return len(value) > 0 && value[0] == dedupedValueMarker
+}
+
+// metadataReference returns the hash of the blob
+// referenced by metadata (if it is a reference).
+func metadataReference(metadata map[string]interface{}) (string, bool) {
+	if len(metadata) != 1 {
+		return "", false
+	}
+
+	hash, ok := metadata[metadataBlobKey].(string)
+	return hash, ok
+}
+
+// forEachMetadata calls fn with the metadata of tx and each of its
+// operations, replacing the metadata with the result of fn.
+func forEachMetadata(
+	tx *types.Transaction,
+	fn func(map[string]interface{}) (map[string]interface{}, error),
+) error {
+	metadata, err := fn(tx.Metadata)
+	if err != nil {
+		return err
+	}
+	tx.Metadata = metadata
+
+	for _, op := range tx.Operations {
+		metadata, err := fn(op.Metadata)
+		if err != nil {
+			return err
+		}
+		op.Metadata = metadata
+	}
+
+	return nil
+}
+
+func (t *metadataDedupeTransaction) decode(value []byte) (*storedTransaction, error) {
+	var stored storedTransaction
+	if err := t.db.Encoder().Decode(storedTransactionNamespace, value, &stored, false); err != nil {
+		return nil, fmt.Errorf("%w: unable to decode stored transaction", err)
+	}
+
+	if stored.Transaction == nil {
+		return nil, errors.New("stored transaction is missing")
+	}
+
+	return &stored, nil
+}
+
+// Set stores value at key (replacing large metadata
+// of stored transactions with references).
+func (t *metadataDedupeTransaction) Set(
+	ctx context.Context,
+	key []byte,
+	value []byte,
+	reclaimValue bool,
+) error {
+	if t.db.threshold <= 0 || !isStoredTransactionKey(key) {
+		return t.Transaction.Set(ctx, key, value, reclaimValue)
+	}
+
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	// Overwritten references must be released.
+	if err := t.release(ctx, key); err != nil {
+		return err
+	}
+
+	stored, err := t.decode(value)
+	if err != nil {
+		return err
+	}
+
+	deduped := false
+	err = forEachMetadata(
+		stored.Transaction,
+		func(metadata map[string]interface{}) (map[string]interface{}, error) {
+			if metadata == nil {
+				return nil, nil
+			}
+
+			b, err := json.Marshal(metadata)
+			if err != nil {
+				return nil, fmt.Errorf("%w: unable to encode metadata", err)
+			}
+
+			if len(b) < t.db.threshold {
+				return metadata, nil
+			}
+
+			digest := sha256.Sum256(b)
+			hash := hex.EncodeToString(digest[:])
+			if err := t.acquire(ctx, hash, metadata); err != nil {
+				return nil, err
+			}
+
+			deduped = true
+			return map[string]interface{}{metadataBlobKey: hash}, nil
+		},
+	)
+	if err != nil {
+		return err
+	}
+
+	if !deduped {
+		return t.Transaction.Set(ctx, key, value, reclaimValue)
+	}
+
+	encoded, err := t.db.Encoder().Encode(storedTransactionNamespace, stored)
+	if err != nil {
+		return fmt.Errorf("%w: unable to encode stored transaction", err)
+	}
+
+	return t.Transaction.Set(ctx, key, append([]byte{dedupedValueMarker}, encoded...), false)
+}
+
+// Get returns the value stored at key (resolving
+// metadata references of stored transactions).
+func (t *metadataDedupeTransaction) Get(ctx context.Context, key []byte) (bool, []byte, error) {
+	exists, value, err := t.Transaction.Get(ctx, key)
+	if err != nil || !exists || !isStoredTransactionKey(key) || !isDeduped(value) {
+		return exists, value, err
+	}
+
+	resolved, err := t.resolve(ctx, value[1:])
+	if err != nil {
+		return false, nil, err
+	}
+
+	return true, resolved, nil
+}
+
+// Scan calls worker with each key and value with prefix
+// (resolving metadata references of stored transactions).
+func (t *metadataDedupeTransaction) Scan(
+	ctx context.Context,
+	prefix []byte,
+	seekStart []byte,
+	worker func([]byte, []byte) error,
+	logEntries bool,
+	reverse bool,
+) (int, error) {
+	return t.Transaction.Scan(
+		ctx,
+		prefix,
+		seekStart,
+		func(k []byte, v []byte) error {
+			if !isStoredTransactionKey(k) || !isDeduped(v) {
+				return worker(k, v)
+			}
+
+			resolved, err := t.resolve(ctx, v[1:])
+			if err != nil {
+				return err
+			}
+
+			return worker(k, resolved)
+		},
+		logEntries,
+		reverse,
+	)
+}
+
+// Delete removes key (releasing metadata
+// references of stored transactions).
+func (t *metadataDedupeTransaction) Delete(ctx context.Context, key []byte) error {
+	if isStoredTransactionKey(key) {
+		t.lock.Lock()
+		defer t.lock.Unlock()
+
+		if err := t.release(ctx, key); err != nil {
+			return err
+		}
+	}
+
+	return t.Transaction.Delete(ctx, key)
+}
+
+// resolve replaces all metadata references
+// in an encoded stored transaction.
+func (t *metadataDedupeTransaction) resolve(ctx context.Context, value []byte) ([]byte, error) {
+	stored, err := t.decode(value)
+	if err != nil {
+		return nil, err
+	}
+
+	err = forEachMetadata(
+		stored.Transaction,
+		func(metadata map[string]interface{}) (map[string]interface{}, error) {
+			hash, ok := metadataReference(metadata)
+			if !ok {
+				return metadata, nil
+			}
+
+			exists, blob, err := t.Transaction.Get(ctx, []byte(metadataBlobPrefix+hash))
+			if err != nil {
+				return nil, fmt.Errorf("%w: unable to get metadata blob %s", err, hash)
+			}
+
+			if !exists {
+				return nil, fmt.Errorf("metadata blob %s is missing", hash)
+			}
+
+			var resolved map[string]interface{}
+			if err := t.db.Encoder().Decode("", blob, &resolved, false); err != nil {
+				return nil, fmt.Errorf("%w: unable to decode metadata blob %s", err, hash)
+			}
+
+			return resolved, nil
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return t.db.Encoder().Encode(storedTransactionNamespace, stored)
+}
+
+func (t *metadataDedupeTransaction) getRefCount(ctx context.Context, hash string) (uint64, error) {
+	exists, val, err := t.Transaction.Get(ctx, []byte(metadataRefPrefix+hash))
+	if err != nil {
+		return 0, fmt.Errorf("%w: unable to get references of metadata blob %s", err, hash)
+	}
+
+	if !exists {
+		return 0, nil
+	}
+
+	if len(val) != metadataRefSize {
+		return 0, fmt.Errorf("references of metadata blob %s have invalid length %d", hash, len(val))
+	}
+
+	return binary.BigEndian.Uint64(val), nil
+}
+
+func (t *metadataDedupeTransaction) setRefCount(
+	ctx context.Context,
+	hash string,
+	count uint64,
+) error {
+	if count == 0 {
+		if err := t.Transaction.Delete(ctx, []byte(metadataBlobPrefix+hash)); err != nil {
+			return fmt.Errorf("%w: unable to remove metadata blob %s", err, hash)
+		}
+
+		return t.Transaction.Delete(ctx, []byte(metadataRefPrefix+hash))
+	}
+
+	val := make([]byte, metadataRefSize)
+	binary.BigEndian.PutUint64(val, count)
+	return t.Transaction.Set(ctx, []byte(metadataRefPrefix+hash), val, true)
+}
+
+// acquire adds a reference to the blob of metadata
+// (storing the blob if it is not yet referenced).
+func (t *metadataDedupeTransaction) acquire(
+	ctx context.Context,
+	hash string,
+	metadata map[string]interface{},
+) error {
+	count, err := t.getRefCount(ctx, hash)
+	if err != nil {
+		return err
+	}
+
+	if count == 0 {
+		blob, err := t.db.Encoder().Encode("", metadata)
+		if err != nil {
+			return fmt.Errorf("%w: unable to encode metadata blob", err)
+		}
+
+		if err := t.Transaction.Set(ctx, []byte(metadataBlobPrefix+hash), blob, true); err != nil {
+			return fmt.Errorf("%w: unable to store metadata blob %s", err, hash)
+		}
+	}
+
+	return t.setRefCount(ctx, hash, count+1)
+}
+
+// release removes the references of the
+// stored transaction at key (if any).
+func (t *metadataDedupeTransaction) release(ctx context.Context, key []byte) error {
+	exists, value, err := t.Transaction.Get(ctx, key)
+	if err != nil {
+		return fmt.Errorf("%w: unable to get stored transaction", err)
+	}
+
+	if !exists || !isDeduped(value) {
+		return nil
+	}
+
+	stored, err := t.decode(value[1:])
+	if err != nil {
+		return err
+	}
+
+	return forEachMetadata(
+		stored.Transaction,
+		func(metadata map[string]interface{}) (map[string]interface{}, error) {
+			hash, ok := metadataReference(metadata)
+			if !ok {
+				return metadata, nil
+			}
+
+			count, err := t.getRefCount(ctx, hash)
+			if err != nil {
+				return nil, err
+			}
+
+			if count > 0 {
+				count--
+			}
+
+			return metadata, t.setRefCount(ctx, hash, count)
+		},
+	)
+}
@@ -0,0 +1,237 @@
+// Copyright 2022 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tester
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/coinbase/rosetta-sdk-go/storage/database"
+	"github.com/coinbase/rosetta-sdk-go/types"
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	largeMetadata = map[string]interface{}{"payload": strings.Repeat("a", 100)}
+	smallMetadata = map[string]interface{}{"memo": "b"}
+)
+
+func testStoredTransaction(hash string, metadata map[string]interface{}) *storedTransaction {
+	return &storedTransaction{
+		Transaction: &types.Transaction{
+			TransactionIdentifier: &types.TransactionIdentifier{Hash: hash},
+			Operations: []*types.Operation{
+				{
+					OperationIdentifier: &types.OperationIdentifier{Index: 0},
+					Type:                "Transfer",
+					Metadata:            metadata,
+				},
+			},
+			Metadata: metadata,
+		},
+		BlockIndex: 1,
+	}
+}
+
+func setStoredTransaction(
+	ctx context.Context,
+	t *testing.T,
+	db database.Database,
+	key string,
+	stored *storedTransaction,
+) {
+	value, err := db.Encoder().Encode(storedTransactionNamespace, stored)
+	assert.NoError(t, err)
+	assert.NotEqual(t, byte(dedupedValueMarker), value[0])
+
+	txn := db.WriteTransaction(ctx, key, false)
+	assert.NoError(t, txn.Set(ctx, []byte(key), value, false))
+	assert.NoError(t, txn.Commit(ctx))
+}
+
+func getStoredTransaction(
+	ctx context.Context,
+	t *testing.T,
+	db database.Database,
+	key string,
+) *storedTransaction {
+	txn := db.ReadTransaction(ctx)
+	defer txn.Discard(ctx)
+
+	exists, value, err := txn.Get(ctx, []byte(key))
+	assert.NoError(t, err)
+	assert.True(t, exists)
+
+	var stored storedTransaction
+	assert.NoError(t, db.Encoder().Decode(storedTransactionNamespace, value, &stored, false))
+	return &stored
+}
+
+func getRaw(ctx context.Context, t *testing.T, db database.Database, key string) []byte {
+	txn := db.ReadTransaction(ctx)
+	defer txn.Discard(ctx)
+
+	exists, value, err := txn.Get(ctx, []byte(key))
+	assert.NoError(t, err)
+	if !exists {
+		return nil
+	}
+
+	return value
+}
+
+func countPrefix(ctx context.Context, t *testing.T, db database.Database, prefix string) int {
+	txn := db.ReadTransaction(ctx)
+	defer txn.Discard(ctx)
+
+	count, err := txn.Scan(
+		ctx,
+		[]byte(prefix),
+		[]byte(prefix),
+		func(k []byte, v []byte) error { return nil },
+		false,
+		false,
+	)
+	assert.NoError(t, err)
+	return count
+}
+
+func TestMetadataDedupeDatabase_NotDeduped(t *testing.T) {
+	ctx := context.Background()
+	underlying := newTestDatabase(t)
+	db := newMetadataDedupeDatabase(underlying, 1000)
+
+	stored := testStoredTransaction("a", smallMetadata)
+	setStoredTransaction(ctx, t, db, "transaction/a", stored)
+
+	assert.False(t, isDeduped(getRaw(ctx, t, underlying, "transaction/a")))
+	assert.Equal(t, stored, getStoredTransaction(ctx, t, db, "transaction/a"))
+	assert.Equal(t, 0, countPrefix(ctx, t, underlying, metadataBlobPrefix))
+}
+
+func TestMetadataDedupeDatabase_Deduped(t *testing.T) {
+	ctx := context.Background()
+	underlying := newTestDatabase(t)
+	db := newMetadataDedupeDatabase(underlying, 50)
+
+	a := testStoredTransaction("a", largeMetadata)
+	b := testStoredTransaction("b", largeMetadata)
+	setStoredTransaction(ctx, t, db, "transaction/a", a)
+	setStoredTransaction(ctx, t, db, "transaction/b", b)
+
+	// Both transactions reference a single blob.
+	assert.True(t, isDeduped(getRaw(ctx, t, underlying, "transaction/a")))
+	assert.True(t, isDeduped(getRaw(ctx, t, underlying, "transaction/b")))
+	assert.Equal(t, 1, countPrefix(ctx, t, underlying, metadataBlobPrefix))
+
+	assert.Equal(t, a, getStoredTransaction(ctx, t, db, "transaction/a"))
+	assert.Equal(t, b, getStoredTransaction(ctx, t, db, "transaction/b"))
+
+	// References are resolved when scanning.
+	txn := db.ReadTransaction(ctx)
+	defer txn.Discard(ctx)
+
+	scanned := map[string]*storedTransaction{}
+	_, err := txn.Scan(
+		ctx,
+		storedTransactionPrefix,
+		storedTransactionPrefix,
+		func(k []byte, v []byte) error {
+			var stored storedTransaction
+			if err := db.Encoder().Decode(storedTransactionNamespace, v, &stored, false); err != nil {
+				return err
+			}
+
+			scanned[string(k)] = &stored
+			return nil
+		},
+		false,
+		false,
+	)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]*storedTransaction{
+		"transaction/a": a,
+		"transaction/b": b,
+	}, scanned)
+}
+
+func TestMetadataDedupeDatabase_MarkerByte(t *testing.T) {
+	ctx := context.Background()
+	underlying := newTestDatabase(t)
+	db := newMetadataDedupeDatabase(underlying, 50)
+
+	// Values of other keys that begin with the
+	// marker byte are never resolved.
+	value := []byte{dedupedValueMarker, 1, 2, 3}
+	txn := db.WriteTransaction(ctx, "other", false)
+	assert.NoError(t, txn.Set(ctx, []byte("other/a"), value, false))
+	assert.NoError(t, txn.Commit(ctx))
+
+	assert.Equal(t, value, getRaw(ctx, t, db, "other/a"))
+	assert.Equal(t, value, getRaw(ctx, t, underlying, "other/a"))
+
+	readTxn := db.ReadTransaction(ctx)
+	defer readTxn.Discard(ctx)
+
+	_, err := readTxn.Scan(
+		ctx,
+		[]byte("other/"),
+		[]byte("other/"),
+		func(k []byte, v []byte) error {
+			assert.Equal(t, value, v)
+			return nil
+		},
+		false,
+		false,
+	)
+	assert.NoError(t, err)
+}
+
+func TestMetadataDedupeDatabase_Delete(t *testing.T) {
+	ctx := context.Background()
+	underlying := newTestDatabase(t)
+	db := newMetadataDedupeDatabase(underlying, 50)
+
+	a := testStoredTransaction("a", largeMetadata)
+	b := testStoredTransaction("b", largeMetadata)
+	setStoredTransaction(ctx, t, db, "transaction/a", a)
+	setStoredTransaction(ctx, t, db, "transaction/b", b)
+
+	// The blob is kept while any transaction references it.
+	txn := db.WriteTransaction(ctx, "delete", false)
+	assert.NoError(t, txn.Delete(ctx, []byte("transaction/a")))
+	assert.NoError(t, txn.Commit(ctx))
+
+	assert.Nil(t, getRaw(ctx, t, db, "transaction/a"))
+	assert.Equal(t, 1, countPrefix(ctx, t, underlying, metadataBlobPrefix))
+	assert.Equal(t, b, getStoredTransaction(ctx, t, db, "transaction/b"))
+
+	// Overwriting a transaction releases its references.
+	setStoredTransaction(ctx, t, db, "transaction/b", testStoredTransaction("b", smallMetadata))
+	assert.Equal(t, 0, countPrefix(ctx, t, underlying, metadataBlobPrefix))
+	assert.Equal(t, 0, countPrefix(ctx, t, underlying, metadataRefPrefix))
+
+	// Deleting the last reference removes the blob.
+	setStoredTransaction(ctx, t, db, "transaction/c", testStoredTransaction("c", largeMetadata))
+	assert.Equal(t, 1, countPrefix(ctx, t, underlying, metadataBlobPrefix))
+
+	txn = db.WriteTransaction(ctx, "delete", false)
+	assert.NoError(t, txn.Delete(ctx, []byte("transaction/c")))
+	assert.NoError(t, txn.Commit(ctx))
+
+	assert.Equal(t, 0, countPrefix(ctx, t, underlying, metadataBlobPrefix))
+	assert.Equal(t, 0, countPrefix(ctx, t, underlying, metadataRefPrefix))
+}
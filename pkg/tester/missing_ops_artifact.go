@@ -0,0 +1,172 @@
+// Copyright 2022 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tester
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/coinbase/rosetta-sdk-go/parser"
+	"github.com/coinbase/rosetta-sdk-go/types"
+	"github.com/coinbase/rosetta-sdk-go/utils"
+	"github.com/fatih/color"
+)
+
+// MissingOpsArtifact describes the block found by the missing-ops
+// search. ExpectedDelta is the change in the account's live balance
+// across the block and ComputedDelta is the change computed from
+// the operations in the block (which should be equal if no
+// operations were missing).
+type MissingOpsArtifact struct {
+	Account       *types.AccountIdentifier `json:"account_identifier"`
+	Currency      *types.Currency          `json:"currency"`
+	Block         *types.Block             `json:"block"`
+	ExpectedDelta string                   `json:"expected_delta"`
+	ComputedDelta string                   `json:"computed_delta"`
+
+	// Operations are the operations in the block
+	// that affect the account and currency.
+	Operations []*types.Operation `json:"operations"`
+}
+
+// WriteMissingOpsArtifact writes a *MissingOpsArtifact about the
+// block found by the missing-ops search to the configured
+// MissingOpsArtifactFile (if populated).
+func (t *DataTester) WriteMissingOpsArtifact(
+	ctx context.Context,
+	accountCurrency *types.AccountCurrency,
+	badBlock *types.BlockIdentifier,
+) {
+	if len(t.config.Data.MissingOpsArtifactFile) == 0 {
+		return
+	}
+
+	artifact, err := t.missingOpsArtifact(ctx, accountCurrency, badBlock)
+	if err != nil {
+		color.Yellow("%s: unable to collect missing ops artifact", err.Error())
+		return
+	}
+
+	if err := utils.SerializeAndWrite(t.config.Data.MissingOpsArtifactFile, artifact); err != nil {
+		color.Yellow("%s: unable to write missing ops artifact", err.Error())
+		return
+	}
+
+	color.Cyan("Missing ops artifact written to %s", t.config.Data.MissingOpsArtifactFile)
+}
+
+func (t *DataTester) missingOpsArtifact(
+	ctx context.Context,
+	accountCurrency *types.AccountCurrency,
+	badBlock *types.BlockIdentifier,
+) (*MissingOpsArtifact, error) {
+	block, err := t.failureBlock(ctx, badBlock)
+	if err != nil {
+		return nil, err
+	}
+
+	computedDelta, err := t.computedDelta(ctx, accountCurrency, block)
+	if err != nil {
+		return nil, err
+	}
+
+	expectedDelta, err := t.expectedDelta(ctx, accountCurrency, block)
+	if err != nil {
+		return nil, err
+	}
+
+	return &MissingOpsArtifact{
+		Account:       accountCurrency.Account,
+		Currency:      accountCurrency.Currency,
+		Block:         block,
+		ExpectedDelta: expectedDelta,
+		ComputedDelta: computedDelta,
+		Operations:    accountOperations(accountCurrency, block),
+	}, nil
+}
+
+// computedDelta returns the balance change of accountCurrency
+// computed by the parser from the operations in block.
+func (t *DataTester) computedDelta(
+	ctx context.Context,
+	accountCurrency *types.AccountCurrency,
+	block *types.Block,
+) (string, error) {
+	changes, err := t.parser.BalanceChanges(ctx, block, false)
+	if err != nil {
+		return "", fmt.Errorf("%w: unable to compute balance changes", err)
+	}
+
+	for _, change := range changes {
+		if types.Hash(change.Account) == types.Hash(accountCurrency.Account) &&
+			types.Hash(change.Currency) == types.Hash(accountCurrency.Currency) {
+			return change.Difference, nil
+		}
+	}
+
+	return "0", nil
+}
+
+// expectedDelta returns the change in the live balance of
+// accountCurrency between the parent of block and block.
+func (t *DataTester) expectedDelta(
+	ctx context.Context,
+	accountCurrency *types.AccountCurrency,
+	block *types.Block,
+) (string, error) {
+	change := &parser.BalanceChange{
+		Account:  accountCurrency.Account,
+		Currency: accountCurrency.Currency,
+	}
+	current, err := t.liveBalance(ctx, change, block.BlockIdentifier)
+	if err != nil {
+		return "", err
+	}
+
+	// The genesis block is its own parent.
+	if block.BlockIdentifier.Index == block.ParentBlockIdentifier.Index {
+		return current, nil
+	}
+
+	parent, err := t.liveBalance(ctx, change, block.ParentBlockIdentifier)
+	if err != nil {
+		return "", err
+	}
+
+	return types.SubtractValues(current, parent)
+}
+
+// accountOperations returns the operations in block
+// that affect accountCurrency.
+func accountOperations(
+	accountCurrency *types.AccountCurrency,
+	block *types.Block,
+) []*types.Operation {
+	operations := []*types.Operation{}
+	for _, tx := range block.Transactions {
+		for _, op := range tx.Operations {
+			if op.Account == nil || op.Amount == nil {
+				continue
+			}
+
+			if types.Hash(op.Account) == types.Hash(accountCurrency.Account) &&
+				types.Hash(op.Amount.Currency) == types.Hash(accountCurrency.Currency) {
+				operations = append(operations, op)
+			}
+		}
+	}
+
+	return operations
+}
@@ -0,0 +1,233 @@
+// Copyright 2022 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tester
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/coinbase/rosetta-cli/configuration"
+	customErrs "github.com/coinbase/rosetta-cli/pkg/errors"
+
+	"github.com/coinbase/rosetta-sdk-go/types"
+	"github.com/fatih/color"
+)
+
+// networkStatusMonitor tracks the /network/status responses
+// observed during a run of `check:data` so that we can
+// assert the current block and sync status move forward.
+type networkStatusMonitor struct {
+	assertions    *configuration.NetworkStatusAssertions
+	genesisBlock  *types.BlockIdentifier
+	maxReorgDepth int64
+
+	lastBlock     *types.BlockIdentifier
+	lastAdvance   time.Time
+	lastSyncIndex *int64
+}
+
+// networkStatusFailure is a single failed assertion
+// and the strictness it was configured with.
+type networkStatusFailure struct {
+	strictness configuration.AssertionStrictness
+	err        error
+}
+
+func (m *networkStatusMonitor) fail(
+	failures []*networkStatusFailure,
+	strictness configuration.AssertionStrictness,
+	format string,
+	args ...interface{},
+) []*networkStatusFailure {
+	if strictness == configuration.StrictnessIgnore {
+		return failures
+	}
+
+	return append(failures, &networkStatusFailure{
+		strictness: strictness,
+		err: fmt.Errorf(
+			"%w: %s",
+			customErrs.ErrNetworkStatusAssertion,
+			fmt.Sprintf(format, args...),
+		),
+	})
+}
+
+// evaluate checks a *types.NetworkStatusResponse against the
+// configured assertions and the previously observed responses.
+func (m *networkStatusMonitor) evaluate( // nolint:gocognit
+	status *types.NetworkStatusResponse,
+	now time.Time,
+) []*networkStatusFailure {
+	failures := []*networkStatusFailure{}
+	current := status.CurrentBlockIdentifier
+
+	stalled := false
+	switch {
+	case m.lastBlock == nil || current.Index > m.lastBlock.Index:
+		m.lastAdvance = now
+	case now.Sub(m.lastAdvance) > time.Duration(m.assertions.StallTimeout)*time.Second:
+		stalled = true
+	}
+
+	if current.Index <= m.genesisBlock.Index || len(current.Hash) == 0 ||
+		status.CurrentBlockTimestamp <= 0 {
+		failures = m.fail(
+			failures,
+			m.assertions.CurrentBlock,
+			"current block %s with timestamp %d is not populated",
+			types.PrintStruct(current),
+			status.CurrentBlockTimestamp,
+		)
+	}
+
+	if m.lastBlock != nil && m.lastBlock.Index-current.Index > m.maxReorgDepth {
+		failures = m.fail(
+			failures,
+			m.assertions.CurrentBlock,
+			"current block moved from %d to %d (more than max reorg depth %d)",
+			m.lastBlock.Index,
+			current.Index,
+			m.maxReorgDepth,
+		)
+	}
+
+	if stalled {
+		failures = m.fail(
+			failures,
+			m.assertions.CurrentBlock,
+			"current block %d has not advanced in %s",
+			current.Index,
+			now.Sub(m.lastAdvance).Round(time.Second),
+		)
+	}
+
+	if len(status.Peers) == 0 {
+		failures = m.fail(failures, m.assertions.Peers, "no peers returned")
+	}
+
+	failures = m.evaluateSyncStatus(failures, status.SyncStatus, current, stalled)
+
+	m.lastBlock = current
+	return failures
+}
+
+func (m *networkStatusMonitor) evaluateSyncStatus(
+	failures []*networkStatusFailure,
+	syncStatus *types.SyncStatus,
+	current *types.BlockIdentifier,
+	stalled bool,
+) []*networkStatusFailure {
+	strictness := m.assertions.SyncStatus
+	if syncStatus == nil {
+		return m.fail(failures, strictness, "sync status is not populated")
+	}
+
+	if syncStatus.CurrentIndex != nil && syncStatus.TargetIndex != nil &&
+		*syncStatus.CurrentIndex > *syncStatus.TargetIndex {
+		failures = m.fail(
+			failures,
+			strictness,
+			"sync status current index %d is greater than target index %d",
+			*syncStatus.CurrentIndex,
+			*syncStatus.TargetIndex,
+		)
+	}
+
+	if syncStatus.CurrentIndex != nil && *syncStatus.CurrentIndex > current.Index {
+		failures = m.fail(
+			failures,
+			strictness,
+			"sync status current index %d is greater than current block %d",
+			*syncStatus.CurrentIndex,
+			current.Index,
+		)
+	}
+
+	if syncStatus.CurrentIndex != nil && m.lastSyncIndex != nil &&
+		*m.lastSyncIndex-*syncStatus.CurrentIndex > m.maxReorgDepth {
+		failures = m.fail(
+			failures,
+			strictness,
+			"sync status current index moved from %d to %d",
+			*m.lastSyncIndex,
+			*syncStatus.CurrentIndex,
+		)
+	}
+
+	if syncStatus.Synced != nil && !*syncStatus.Synced && stalled {
+		failures = m.fail(
+			failures,
+			strictness,
+			"sync status reports not synced but current block %d is not advancing",
+			current.Index,
+		)
+	}
+
+	m.lastSyncIndex = syncStatus.CurrentIndex
+	return failures
+}
+
+// StartNetworkStatusMonitor periodically fetches /network/status
+// and evaluates the configured *configuration.NetworkStatusAssertions.
+// Failures configured with configuration.StrictnessFail halt `check:data`.
+func (t *DataTester) StartNetworkStatusMonitor(
+	ctx context.Context,
+) error {
+	assertions := t.config.Data.NetworkStatusAssertions
+	if assertions == nil {
+		return nil
+	}
+
+	monitor := &networkStatusMonitor{
+		assertions:    assertions,
+		genesisBlock:  t.genesisBlock,
+		maxReorgDepth: int64(t.config.MaxReorgDepth),
+	}
+
+	tc := time.NewTicker(time.Duration(assertions.CheckInterval) * time.Second)
+	defer tc.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+
+		case <-tc.C:
+			status, fetchErr := t.fetcher.NetworkStatusRetry(
+				ctx,
+				t.network,
+				nil,
+			)
+			if fetchErr != nil {
+				log.Printf(
+					"%s: unable to fetch network status",
+					fetchErr.Err.Error(),
+				)
+				continue
+			}
+
+			for _, failure := range monitor.evaluate(status, time.Now()) {
+				if failure.strictness == configuration.StrictnessFail {
+					return failure.err
+				}
+
+				color.Yellow(failure.err.Error())
+			}
+		}
+	}
+}
@@ -0,0 +1,425 @@
+// Copyright 2022 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tester
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/coinbase/rosetta-cli/configuration"
+	"github.com/coinbase/rosetta-cli/pkg/logger"
+
+	"github.com/coinbase/rosetta-sdk-go/fetcher"
+	"github.com/coinbase/rosetta-sdk-go/parser"
+	"github.com/coinbase/rosetta-sdk-go/statefulsyncer"
+	"github.com/coinbase/rosetta-sdk-go/storage/database"
+	storageErrs "github.com/coinbase/rosetta-sdk-go/storage/errors"
+	"github.com/coinbase/rosetta-sdk-go/storage/modules"
+	"github.com/coinbase/rosetta-sdk-go/types"
+	"github.com/coinbase/rosetta-sdk-go/utils"
+	"github.com/neilotoole/errgroup"
+)
+
+// recheckPrefix is the prefix of the net balance
+// changes stored in the scratch store of a re-check.
+const recheckPrefix = "recheck_balance"
+
+// RecheckBlockMismatch is an index where the stored block
+// differs from the block fetched again. Either block is
+// nil if the block was omitted.
+type RecheckBlockMismatch struct {
+	Index     int64                  `json:"index"`
+	Stored    *types.BlockIdentifier `json:"stored_block_identifier"`
+	Refetched *types.BlockIdentifier `json:"refetched_block_identifier"`
+}
+
+// RecheckBalanceMismatch is an account whose net balance
+// change over the range differs between the stored blocks
+// and the blocks fetched again.
+type RecheckBalanceMismatch struct {
+	Account   *types.AccountIdentifier `json:"account_identifier"`
+	Currency  *types.Currency          `json:"currency"`
+	Stored    string                   `json:"stored_difference"`
+	Refetched string                   `json:"refetched_difference"`
+}
+
+// RecheckResult is the result of re-checking
+// a range of stored blocks.
+type RecheckResult struct {
+	StartIndex        int64                     `json:"start_index"`
+	EndIndex          int64                     `json:"end_index"`
+	Blocks            int64                     `json:"blocks"`
+	BlockMismatches   []*RecheckBlockMismatch   `json:"block_mismatches"`
+	BalanceMismatches []*RecheckBalanceMismatch `json:"balance_mismatches"`
+}
+
+// Consistent returns a boolean indicating if the blocks
+// fetched again matched the stored blocks.
+func (r *RecheckResult) Consistent() bool {
+	return len(r.BlockMismatches) == 0 && len(r.BalanceMismatches) == 0
+}
+
+// RecheckRange fetches the blocks from startIndex to endIndex
+// (inclusive) again and applies their balance changes to a scratch
+// store, then compares the blocks and the net balance change of each
+// account with the blocks stored by `check:data`. This detects
+// nondeterminism in an implementation without modifying check:data
+// storage. The range must not have been pruned.
+// This must not be called while `check:data` is running on the
+// same data directory.
+func RecheckRange(
+	ctx context.Context,
+	config *configuration.Configuration,
+	f *fetcher.Fetcher,
+	startIndex int64,
+	endIndex int64,
+) (*RecheckResult, error) {
+	if startIndex < 0 || startIndex > endIndex {
+		return nil, fmt.Errorf("invalid range %d to %d", startIndex, endIndex)
+	}
+
+	localStore, err := openDataDatabase(ctx, config)
+	if err != nil {
+		return nil, err
+	}
+	defer localStore.Close(ctx)
+
+	blockStorage := modules.NewBlockStorage(localStore, config.SerialBlockWorkers)
+	head, err := blockStorage.GetHeadBlockIdentifier(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("%w: unable to get head block", err)
+	}
+
+	if head.Index < endIndex {
+		return nil, fmt.Errorf("end index %d is after head block %d", endIndex, head.Index)
+	}
+
+	oldestIndex, err := blockStorage.GetOldestBlockIndex(ctx)
+	switch {
+	case errors.Is(err, storageErrs.ErrOldestIndexMissing):
+	case err != nil:
+		return nil, fmt.Errorf("%w: unable to get oldest block", err)
+	case oldestIndex > startIndex:
+		return nil, fmt.Errorf("blocks before %d have been pruned", oldestIndex)
+	}
+
+	// The same parser is used for stored and refetched
+	// blocks so that exemptions cannot cause a mismatch.
+	p := parser.New(f.Asserter, func(*types.Operation) bool { return false }, nil)
+
+	tmpDir, err := utils.CreateTempDir()
+	if err != nil {
+		return nil, fmt.Errorf("%w: unable to create temporary directory", err)
+	}
+	defer utils.RemoveTempDir(tmpDir)
+
+	scratchStore, err := database.NewBadgerDatabase(ctx, tmpDir)
+	if err != nil {
+		return nil, fmt.Errorf("%w: unable to initialize scratch database", err)
+	}
+	defer scratchStore.Close(ctx)
+
+	scratchBlocks := modules.NewBlockStorage(scratchStore, config.SerialBlockWorkers)
+	if err := syncScratch(
+		ctx,
+		config,
+		f,
+		tmpDir,
+		scratchStore,
+		scratchBlocks,
+		p,
+		startIndex,
+		endIndex,
+	); err != nil {
+		return nil, err
+	}
+
+	result := &RecheckResult{
+		StartIndex:        startIndex,
+		EndIndex:          endIndex,
+		BlockMismatches:   []*RecheckBlockMismatch{},
+		BalanceMismatches: []*RecheckBalanceMismatch{},
+	}
+	stored := map[string]*parser.BalanceChange{}
+	for index := startIndex; index <= endIndex; index++ {
+		storedBlock, err := getBlockAtIndex(ctx, blockStorage, index)
+		if err != nil {
+			return nil, err
+		}
+
+		refetchedBlock, err := getBlockAtIndex(ctx, scratchBlocks, index)
+		if err != nil {
+			return nil, err
+		}
+
+		if storedBlock == nil && refetchedBlock == nil {
+			continue
+		}
+		result.Blocks++
+
+		if storedBlock == nil || refetchedBlock == nil ||
+			types.Hash(storedBlock.BlockIdentifier) != types.Hash(refetchedBlock.BlockIdentifier) {
+			mismatch := &RecheckBlockMismatch{Index: index}
+			if storedBlock != nil {
+				mismatch.Stored = storedBlock.BlockIdentifier
+			}
+			if refetchedBlock != nil {
+				mismatch.Refetched = refetchedBlock.BlockIdentifier
+			}
+
+			result.BlockMismatches = append(result.BlockMismatches, mismatch)
+		}
+
+		if storedBlock == nil {
+			continue
+		}
+
+		changes, err := p.BalanceChanges(ctx, storedBlock, false)
+		if err != nil {
+			return nil, fmt.Errorf("%w: unable to calculate balance changes of block %d", err, index)
+		}
+
+		for _, change := range changes {
+			if err := addBalanceChange(stored, change); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	refetched, err := getRecheckBalances(ctx, scratchStore)
+	if err != nil {
+		return nil, err
+	}
+
+	for key, change := range stored {
+		other, ok := refetched[key]
+		if !ok {
+			other = &parser.BalanceChange{Difference: "0"}
+		}
+
+		if change.Difference != other.Difference {
+			result.BalanceMismatches = append(result.BalanceMismatches, &RecheckBalanceMismatch{
+				Account:   change.Account,
+				Currency:  change.Currency,
+				Stored:    change.Difference,
+				Refetched: other.Difference,
+			})
+		}
+	}
+
+	for key, change := range refetched {
+		if _, ok := stored[key]; ok || change.Difference == "0" {
+			continue
+		}
+
+		result.BalanceMismatches = append(result.BalanceMismatches, &RecheckBalanceMismatch{
+			Account:   change.Account,
+			Currency:  change.Currency,
+			Stored:    "0",
+			Refetched: change.Difference,
+		})
+	}
+
+	return result, nil
+}
+
+// syncScratch syncs the blocks from startIndex to endIndex
+// (inclusive) into the scratch store.
+func syncScratch(
+	ctx context.Context,
+	config *configuration.Configuration,
+	f *fetcher.Fetcher,
+	tmpDir string,
+	scratchStore database.Database,
+	scratchBlocks *modules.BlockStorage,
+	p *parser.Parser,
+	startIndex int64,
+	endIndex int64,
+) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	logger, err := logger.NewLogger(
+		tmpDir,
+		false,
+		false,
+		false,
+		false,
+		logger.Data,
+		config.Network,
+	)
+	if err != nil {
+		return fmt.Errorf("%w: unable to initialize logger", err)
+	}
+
+	syncer := statefulsyncer.New(
+		ctx,
+		config.Network,
+		f,
+		scratchBlocks,
+		modules.NewCounterStorage(scratchStore),
+		logger,
+		cancel,
+		[]modules.BlockWorker{&recheckWorker{db: scratchStore, parser: p}},
+		statefulsyncer.WithCacheSize(syncCacheSize(config)),
+		statefulsyncer.WithMaxConcurrency(config.MaxSyncConcurrency),
+		statefulsyncer.WithPastBlockLimit(config.MaxReorgDepth),
+		statefulsyncer.WithSeenConcurrency(int64(config.SeenBlockWorkers)),
+	)
+
+	if err := syncer.Sync(ctx, startIndex, endIndex); err != nil {
+		return fmt.Errorf("%w: unable to fetch blocks %d to %d", err, startIndex, endIndex)
+	}
+
+	return nil
+}
+
+func recheckKey(account *types.AccountIdentifier, currency *types.Currency) string {
+	return fmt.Sprintf("%s/%s/%s", recheckPrefix, types.Hash(account), types.Hash(currency))
+}
+
+// addBalanceChange adds change to the net change
+// of its account and currency in changes.
+func addBalanceChange(changes map[string]*parser.BalanceChange, change *parser.BalanceChange) error {
+	key := recheckKey(change.Account, change.Currency)
+	total, ok := changes[key]
+	if !ok {
+		total = &parser.BalanceChange{
+			Account:    change.Account,
+			Currency:   change.Currency,
+			Difference: "0",
+		}
+		changes[key] = total
+	}
+
+	sum, err := types.AddValues(total.Difference, change.Difference)
+	if err != nil {
+		return fmt.Errorf("%w: unable to add balance change", err)
+	}
+	total.Difference = sum
+
+	return nil
+}
+
+// getRecheckBalances returns the net balance changes
+// stored in the scratch store (by key).
+func getRecheckBalances(
+	ctx context.Context,
+	db database.Database,
+) (map[string]*parser.BalanceChange, error) {
+	dbTx := db.ReadTransaction(ctx)
+	defer dbTx.Discard(ctx)
+
+	changes := map[string]*parser.BalanceChange{}
+	_, err := dbTx.Scan(
+		ctx,
+		[]byte(recheckPrefix),
+		[]byte(recheckPrefix),
+		func(k []byte, v []byte) error {
+			var change parser.BalanceChange
+			if err := json.Unmarshal(v, &change); err != nil {
+				return fmt.Errorf("%w: unable to parse balance change", err)
+			}
+
+			changes[string(k)] = &change
+			return nil
+		},
+		false,
+		false,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("%w: unable to scan balance changes", err)
+	}
+
+	return changes, nil
+}
+
+var _ modules.BlockWorker = (*recheckWorker)(nil)
+
+// recheckWorker stores the net balance change of each
+// account in the scratch store of a re-check.
+type recheckWorker struct {
+	db     database.Database
+	parser *parser.Parser
+}
+
+// AddingBlock is called by BlockStorage when adding a block.
+func (w *recheckWorker) AddingBlock(
+	ctx context.Context,
+	g *errgroup.Group,
+	block *types.Block,
+	dbTx database.Transaction,
+) (database.CommitWorker, error) {
+	return nil, w.apply(ctx, block, dbTx, false)
+}
+
+// RemovingBlock is called by BlockStorage when removing a block.
+func (w *recheckWorker) RemovingBlock(
+	ctx context.Context,
+	g *errgroup.Group,
+	block *types.Block,
+	dbTx database.Transaction,
+) (database.CommitWorker, error) {
+	return nil, w.apply(ctx, block, dbTx, true)
+}
+
+func (w *recheckWorker) apply(
+	ctx context.Context,
+	block *types.Block,
+	dbTx database.Transaction,
+	removed bool,
+) error {
+	changes, err := w.parser.BalanceChanges(ctx, block, removed)
+	if err != nil {
+		return fmt.Errorf("%w: unable to calculate balance changes", err)
+	}
+
+	for _, change := range changes {
+		key := []byte(recheckKey(change.Account, change.Currency))
+		total := &parser.BalanceChange{
+			Account:    change.Account,
+			Currency:   change.Currency,
+			Difference: "0",
+		}
+		exists, val, err := dbTx.Get(ctx, key)
+		if err != nil {
+			return err
+		}
+
+		if exists {
+			if err := json.Unmarshal(val, total); err != nil {
+				return fmt.Errorf("%w: unable to parse balance change", err)
+			}
+		}
+
+		total.Difference, err = types.AddValues(total.Difference, change.Difference)
+		if err != nil {
+			return fmt.Errorf("%w: unable to add balance change", err)
+		}
+
+		b, err := json.Marshal(total)
+		if err != nil {
+			return err
+		}
+
+		if err := dbTx.Set(ctx, key, b, true); err != nil {
+			return fmt.Errorf("%w: unable to store balance change", err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,97 @@
+// Copyright 2022 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tester
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/coinbase/rosetta-cli/configuration"
+	"github.com/coinbase/rosetta-cli/pkg/processor"
+
+	"github.com/coinbase/rosetta-sdk-go/storage/database"
+	"github.com/coinbase/rosetta-sdk-go/types"
+	"github.com/coinbase/rosetta-sdk-go/utils"
+)
+
+// openDataDatabase opens the database used by previous
+// runs of `check:data`. This must not be called while
+// `check:data` is running on the same data directory.
+func openDataDatabase(
+	ctx context.Context,
+	config *configuration.Configuration,
+) (database.Database, error) {
+	if len(config.DataDirectory) == 0 {
+		return nil, errors.New("data directory must be populated to load check:data storage")
+	}
+
+	if config.InMemoryStorage {
+		return nil, errors.New("cannot load check:data storage when in_memory_storage is enabled")
+	}
+
+	dataPath, err := utils.CreateCommandPath(config.DataDirectory, dataCmdName, config.Network)
+	if err != nil {
+		return nil, fmt.Errorf("%w: cannot create command path", err)
+	}
+
+	localStore, err := openDataStorage(ctx, config, dataPath)
+	if err != nil {
+		return nil, fmt.Errorf("%w: unable to initialize database", err)
+	}
+
+	return localStore, nil
+}
+
+// LoadReconciliationHistory returns all *processor.ReconciliationAttempt
+// recorded for an account by previous runs of `check:data`. This
+// must not be called while `check:data` is running on the same
+// data directory.
+func LoadReconciliationHistory(
+	ctx context.Context,
+	config *configuration.Configuration,
+	account *types.AccountIdentifier,
+) ([]*processor.ReconciliationAttempt, error) {
+	localStore, err := openDataDatabase(ctx, config)
+	if err != nil {
+		return nil, err
+	}
+	defer localStore.Close(ctx)
+
+	return processor.NewReconciliationHistory(localStore).Get(ctx, account)
+}
+
+// LoadReconciliationAudit returns all *processor.ReconciliationAttempt
+// recorded by previous runs of `check:data` at blocks with an index
+// between startIndex and endIndex (inclusive). This must not be
+// called while `check:data` is running on the same data directory.
+func LoadReconciliationAudit(
+	ctx context.Context,
+	config *configuration.Configuration,
+	startIndex int64,
+	endIndex int64,
+) ([]*processor.ReconciliationAttempt, error) {
+	if startIndex > endIndex {
+		return nil, fmt.Errorf("start index %d is after end index %d", startIndex, endIndex)
+	}
+
+	localStore, err := openDataDatabase(ctx, config)
+	if err != nil {
+		return nil, err
+	}
+	defer localStore.Close(ctx)
+
+	return processor.NewReconciliationHistory(localStore).GetRange(ctx, startIndex, endIndex)
+}
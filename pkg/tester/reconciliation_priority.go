@@ -0,0 +1,75 @@
+// Copyright 2022 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tester
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/coinbase/rosetta-sdk-go/parser"
+	storageErrs "github.com/coinbase/rosetta-sdk-go/storage/errors"
+)
+
+// StartReconciliationPriority periodically queues the highest
+// priority accounts for reconciliation at the head block (if
+// reconciliation priority is configured).
+func (t *DataTester) StartReconciliationPriority(ctx context.Context) error {
+	if t.reconciliationPriority == nil {
+		return nil
+	}
+
+	frequency := t.config.Data.ReconciliationPriority.Frequency
+	tc := time.NewTicker(time.Duration(frequency) * time.Second)
+	defer tc.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-tc.C:
+		}
+
+		head, err := t.blockStorage.GetHeadBlockIdentifier(ctx)
+		if errors.Is(err, storageErrs.ErrHeadBlockNotFound) {
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("%w: unable to get head block identifier", err)
+		}
+
+		accounts := t.reconciliationPriority.Next(head.Index)
+		if len(accounts) == 0 {
+			continue
+		}
+
+		// Prioritized accounts are queued like interesting
+		// accounts (with a difference of 0 at the head block).
+		changes := make([]*parser.BalanceChange, len(accounts))
+		for i, account := range accounts {
+			changes[i] = &parser.BalanceChange{
+				Account:    account.Account,
+				Currency:   account.Currency,
+				Difference: "0",
+				Block:      head,
+			}
+		}
+
+		if err := t.reconciler.QueueChanges(ctx, head, changes); err != nil {
+			return fmt.Errorf("%w: unable to queue prioritized accounts", err)
+		}
+	}
+}
@@ -0,0 +1,226 @@
+// Copyright 2022 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tester
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path"
+
+	"github.com/coinbase/rosetta-cli/configuration"
+
+	"github.com/coinbase/rosetta-sdk-go/storage/database"
+	storageErrs "github.com/coinbase/rosetta-sdk-go/storage/errors"
+	"github.com/coinbase/rosetta-sdk-go/storage/modules"
+	"github.com/coinbase/rosetta-sdk-go/types"
+	"github.com/dgraph-io/badger/v2"
+)
+
+// RepairResult describes the state recovered
+// by repairing check:data storage.
+type RepairResult struct {
+	Path          string                 `json:"path"`
+	SizeBefore    int64                  `json:"size_before"`
+	SizeAfter     int64                  `json:"size_after"`
+	PreviousHead  *types.BlockIdentifier `json:"previous_head,omitempty"`
+	RecoveredHead *types.BlockIdentifier `json:"recovered_head,omitempty"`
+	OldestBlock   int64                  `json:"oldest_block"`
+	BlocksRewound int64                  `json:"blocks_rewound"`
+	HeadRecovered bool                   `json:"head_recovered"`
+}
+
+// RepairStorage attempts to recover check:data storage after an
+// unclean shutdown. Corrupted data at the end of the value logs is
+// truncated and the head block is rewound to the last block that
+// can still be read.
+//
+// Balance changes of rewound blocks cannot be reverted (their
+// contents are lost), so a reconciliation failure after a repair
+// that rewinds the head means the storage must be deleted.
+// This must not be called while `check:data` is running on the
+// same data directory.
+func RepairStorage(
+	ctx context.Context,
+	config *configuration.Configuration,
+) (*RepairResult, error) {
+	if err := assertWritableStorage(config); err != nil {
+		return nil, err
+	}
+
+	if len(config.DataDirectory) == 0 {
+		return nil, errors.New("data directory must be populated to repair storage")
+	}
+
+	dbPath := path.Join(config.DataDirectory, dataCmdName, types.Hash(config.Network))
+	if _, err := os.Stat(dbPath); err != nil {
+		return nil, fmt.Errorf("%w: unable to find check:data storage", err)
+	}
+
+	sizeBefore, err := directorySize(dbPath)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := truncateValueLogs(config, dbPath); err != nil {
+		return nil, err
+	}
+
+	localStore, err := openDataDatabase(ctx, config)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := recoverHead(ctx, localStore, config.SerialBlockWorkers)
+	_ = localStore.Close(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	result.Path = dbPath
+	result.SizeBefore = sizeBefore
+	result.SizeAfter, err = directorySize(dbPath)
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// truncateValueLogs opens the database at dbPath with truncation
+// enabled so that Badger discards any corrupted value log entries
+// (instead of refusing to open).
+func truncateValueLogs(config *configuration.Configuration, dbPath string) error {
+	settings := database.DefaultBadgerOptions(dbPath)
+	if config.Badger != nil {
+		var err error
+		settings, err = applyBadgerConfiguration(settings, config.Badger)
+		if err != nil {
+			return err
+		}
+	}
+	settings.Truncate = true
+
+	log.Printf("Truncating corrupted value logs in %s...\n", dbPath)
+	db, err := badger.Open(settings)
+	if err != nil {
+		return fmt.Errorf("%w: unable to open database (storage cannot be repaired)", err)
+	}
+
+	if err := db.Close(); err != nil {
+		return fmt.Errorf("%w: unable to close database", err)
+	}
+
+	return nil
+}
+
+// recoverHead rewinds the head block to the last
+// block that can be read from storage.
+func recoverHead(
+	ctx context.Context,
+	localStore database.Database,
+	workers int,
+) (*RepairResult, error) {
+	blockStorage := modules.NewBlockStorage(localStore, workers)
+	result := &RepairResult{}
+
+	oldest, err := blockStorage.GetOldestBlockIndex(ctx)
+	if errors.Is(err, storageErrs.ErrOldestIndexMissing) {
+		// Nothing has been synced, so there is nothing to recover.
+		return result, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: unable to get oldest block index", err)
+	}
+	result.OldestBlock = oldest
+
+	// If the head is missing, we search forward from the
+	// oldest block for the last readable block.
+	head, err := blockStorage.GetHeadBlockIdentifier(ctx)
+	if err != nil {
+		log.Printf("%s: unable to read head block\n", err.Error())
+		head = nil
+	}
+	result.PreviousHead = head
+
+	var recovered *types.BlockIdentifier
+	if head != nil {
+		for index := head.Index; index >= oldest && ctx.Err() == nil; index-- {
+			recovered = readableBlock(ctx, blockStorage, index)
+			if recovered != nil {
+				break
+			}
+		}
+	} else {
+		for index := oldest; ctx.Err() == nil; index++ {
+			block := readableBlock(ctx, blockStorage, index)
+			if block == nil {
+				break
+			}
+
+			recovered = block
+		}
+	}
+
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+
+	if recovered == nil {
+		return nil, errors.New("no readable blocks found (storage cannot be repaired)")
+	}
+
+	result.RecoveredHead = recovered
+	if head != nil {
+		result.BlocksRewound = head.Index - recovered.Index
+	}
+
+	if head != nil && types.Hash(head) == types.Hash(recovered) {
+		return result, nil
+	}
+
+	dbTx := localStore.Transaction(ctx)
+	defer dbTx.Discard(ctx)
+	if err := blockStorage.StoreHeadBlockIdentifier(ctx, dbTx, recovered); err != nil {
+		return nil, fmt.Errorf("%w: unable to store recovered head block", err)
+	}
+
+	if err := dbTx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("%w: unable to commit recovered head block", err)
+	}
+
+	result.HeadRecovered = true
+	return result, nil
+}
+
+// readableBlock returns the identifier of the canonical block
+// at index (or nil if the block cannot be read).
+func readableBlock(
+	ctx context.Context,
+	blockStorage *modules.BlockStorage,
+	index int64,
+) *types.BlockIdentifier {
+	block, err := blockStorage.GetBlock(
+		ctx,
+		&types.PartialBlockIdentifier{Index: &index},
+	)
+	if err != nil {
+		return nil
+	}
+
+	return block.BlockIdentifier
+}
@@ -0,0 +1,288 @@
+// Copyright 2022 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tester
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+
+	"github.com/coinbase/rosetta-cli/configuration"
+	"github.com/coinbase/rosetta-cli/pkg/processor"
+
+	"github.com/coinbase/rosetta-sdk-go/asserter"
+	"github.com/coinbase/rosetta-sdk-go/fetcher"
+	"github.com/coinbase/rosetta-sdk-go/parser"
+	"github.com/coinbase/rosetta-sdk-go/storage/database"
+	storageErrs "github.com/coinbase/rosetta-sdk-go/storage/errors"
+	"github.com/coinbase/rosetta-sdk-go/storage/modules"
+	"github.com/coinbase/rosetta-sdk-go/types"
+	"github.com/coinbase/rosetta-sdk-go/utils"
+)
+
+// replayLogInterval is the number of blocks
+// replayed between progress logs.
+const replayLogInterval = 10000
+
+var _ modules.BalanceStorageHandler = (*replayHandler)(nil)
+
+// ReplayResult is the result of replaying
+// a range of stored blocks.
+type ReplayResult struct {
+	StartIndex   int64  `json:"start_index"`
+	EndIndex     int64  `json:"end_index"`
+	Blocks       int64  `json:"blocks"`
+	Transactions int64  `json:"transactions"`
+	Operations   int64  `json:"operations"`
+	FailureIndex *int64 `json:"failure_index,omitempty"`
+	Error        string `json:"error,omitempty"`
+}
+
+// Failed returns a boolean indicating if
+// a stored block failed validation.
+func (r *ReplayResult) Failed() bool {
+	return len(r.Error) > 0
+}
+
+// ReplayRange re-runs block assertions and balance computation on
+// the blocks from startIndex to endIndex (inclusive) already stored
+// by `check:data` without making any requests to the node. Balances
+// are computed in a scratch store starting from zero (or from
+// the bootstrap balances when replaying from genesis), so replaying
+// from the genesis block avoids negative balances of accounts
+// funded before startIndex. A negative startIndex replays from the
+// oldest stored block and a negative endIndex replays to the head
+// block. Replay stops at the first block that fails.
+// This must not be called while `check:data` is running on the
+// same data directory.
+func ReplayRange(
+	ctx context.Context,
+	config *configuration.Configuration,
+	a *asserter.Asserter,
+	balanceExemptions []*types.BalanceExemption,
+	startIndex int64,
+	endIndex int64,
+) (*ReplayResult, error) {
+	localStore, err := openDataDatabase(ctx, config)
+	if err != nil {
+		return nil, err
+	}
+	defer localStore.Close(ctx)
+
+	blockStorage := modules.NewBlockStorage(localStore, config.SerialBlockWorkers)
+	head, err := blockStorage.GetHeadBlockIdentifier(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("%w: unable to get head block", err)
+	}
+
+	oldestIndex, err := blockStorage.GetOldestBlockIndex(ctx)
+	switch {
+	case errors.Is(err, storageErrs.ErrOldestIndexMissing):
+		oldestIndex = 0
+	case err != nil:
+		return nil, fmt.Errorf("%w: unable to get oldest block", err)
+	}
+
+	if startIndex < 0 {
+		startIndex = oldestIndex
+	}
+	if endIndex < 0 {
+		endIndex = head.Index
+	}
+
+	switch {
+	case startIndex > endIndex:
+		return nil, fmt.Errorf("invalid range %d to %d", startIndex, endIndex)
+	case head.Index < endIndex:
+		return nil, fmt.Errorf("end index %d is after head block %d", endIndex, head.Index)
+	case oldestIndex > startIndex:
+		return nil, fmt.Errorf("blocks before %d have been pruned", oldestIndex)
+	}
+
+	exemptAccounts, err := loadAccounts(config.Data.ExemptAccounts)
+	if err != nil {
+		return nil, fmt.Errorf("%w: unable to load exempt accounts", err)
+	}
+
+	negativeBalanceAccounts, err := loadAccounts(config.Data.NegativeBalanceAccounts)
+	if err != nil {
+		return nil, fmt.Errorf("%w: unable to load negative balance accounts", err)
+	}
+
+	tmpDir, err := utils.CreateTempDir()
+	if err != nil {
+		return nil, fmt.Errorf("%w: unable to create temporary directory", err)
+	}
+	defer utils.RemoveTempDir(tmpDir)
+
+	scratchStore, err := database.NewBadgerDatabase(ctx, tmpDir)
+	if err != nil {
+		return nil, fmt.Errorf("%w: unable to initialize scratch database", err)
+	}
+	defer scratchStore.Close(ctx)
+
+	// The fetcher is only used for its asserter because initial
+	// balance fetching is disabled, so no request is ever made.
+	counterStorage := modules.NewCounterStorage(scratchStore)
+	balanceStorage := modules.NewBalanceStorage(scratchStore)
+	balanceStorageHelper := processor.NewBalanceStorageHelper(
+		config.Network,
+		fetcher.New(config.OnlineURL, fetcher.WithAsserter(a)),
+		counterStorage,
+		false,
+		exemptAccounts,
+		false,
+		balanceExemptions,
+		true,
+	)
+	balanceStorageHelper.SetExemptOperationTypes(config.Data.ExemptOperationTypes)
+	balanceStorageHelper.SetIgnoreSubAccounts(
+		config.Data.SubAccounts == configuration.IgnoreSubAccounts,
+	)
+	balanceStorageHelper.SetNegativeBalanceAccounts(negativeBalanceAccounts)
+	balanceStorage.Initialize(balanceStorageHelper, &replayHandler{})
+
+	asserterConfiguration, err := a.ClientConfiguration()
+	if err != nil {
+		return nil, fmt.Errorf("%w: unable to get asserter configuration", err)
+	}
+
+	genesisBlock := asserterConfiguration.GenesisBlockIdentifier
+	if len(config.Data.BootstrapBalances) > 0 && startIndex == genesisBlock.Index {
+		if err := balanceStorage.BootstrapBalances(
+			ctx,
+			config.Data.BootstrapBalances,
+			genesisBlock,
+		); err != nil {
+			return nil, fmt.Errorf("%w: unable to bootstrap balances", err)
+		}
+	}
+
+	scratchBlocks := modules.NewBlockStorage(scratchStore, config.SerialBlockWorkers)
+	balanceStorageWorker, err := adjustedBalanceStorage(
+		config,
+		config.Network,
+		balanceStorage,
+		asserterConfiguration.AllowedOperationStatuses,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	scratchBlocks.Initialize([]modules.BlockWorker{
+		counterStorage,
+		processor.NewNegativeBalances(balanceStorageHelper, negativeBalanceAccounts),
+		balanceStorageWorker,
+	})
+
+	result := &ReplayResult{
+		StartIndex: startIndex,
+		EndIndex:   endIndex,
+	}
+	for index := startIndex; index <= endIndex; index++ {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+
+		block, err := getBlockAtIndex(ctx, blockStorage, index)
+		if err != nil {
+			return nil, err
+		}
+
+		if block == nil {
+			continue
+		}
+
+		if err := replayBlock(ctx, a, scratchBlocks, block); err != nil {
+			failureIndex := index
+			result.FailureIndex = &failureIndex
+			result.Error = err.Error()
+			break
+		}
+
+		result.Blocks++
+		result.Transactions += int64(len(block.Transactions))
+		for _, transaction := range block.Transactions {
+			result.Operations += int64(len(transaction.Operations))
+		}
+
+		if result.Blocks%replayLogInterval == 0 {
+			log.Printf("replayed %d blocks (at %d of %d)\n", result.Blocks, index, endIndex)
+		}
+	}
+
+	return result, nil
+}
+
+// replayBlock asserts a stored block and
+// applies it to the scratch storage.
+func replayBlock(
+	ctx context.Context,
+	a *asserter.Asserter,
+	scratchBlocks *modules.BlockStorage,
+	block *types.Block,
+) error {
+	if err := a.Block(block); err != nil {
+		return fmt.Errorf("%w: block %d failed assertion", err, block.BlockIdentifier.Index)
+	}
+
+	if err := scratchBlocks.AddBlock(ctx, block); err != nil {
+		return fmt.Errorf("%w: unable to apply block %d", err, block.BlockIdentifier.Index)
+	}
+
+	return nil
+}
+
+// replayHandler is a modules.BalanceStorageHandler
+// that ignores all balance changes because replays
+// do not log or reconcile balances.
+type replayHandler struct{}
+
+// BlockAdded is called whenever a block is committed to BlockStorage.
+func (h *replayHandler) BlockAdded(
+	ctx context.Context,
+	block *types.Block,
+	changes []*parser.BalanceChange,
+) error {
+	return nil
+}
+
+// BlockRemoved is called whenever a block is removed from BlockStorage.
+func (h *replayHandler) BlockRemoved(
+	ctx context.Context,
+	block *types.Block,
+	changes []*parser.BalanceChange,
+) error {
+	return nil
+}
+
+// AccountsReconciled updates the total accounts reconciled by count.
+func (h *replayHandler) AccountsReconciled(
+	ctx context.Context,
+	dbTx database.Transaction,
+	count int,
+) error {
+	return nil
+}
+
+// AccountsSeen updates the total accounts seen by count.
+func (h *replayHandler) AccountsSeen(
+	ctx context.Context,
+	dbTx database.Transaction,
+	count int,
+) error {
+	return nil
+}
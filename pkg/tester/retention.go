@@ -0,0 +1,104 @@
+// Copyright 2022 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tester
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	storageErrs "github.com/coinbase/rosetta-sdk-go/storage/errors"
+)
+
+// StartRetention periodically removes reconciliation history
+// and rotated log files that have expired (if retention is
+// configured). Counter snapshots are expired by
+// StartCounterSnapshots (which owns the snapshot file).
+func (t *DataTester) StartRetention(ctx context.Context) error {
+	config := t.config.Data.Retention
+	if config == nil {
+		return nil
+	}
+
+	tc := time.NewTicker(time.Duration(config.Frequency) * time.Second)
+	defer tc.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-tc.C:
+		}
+
+		if err := t.removeExpired(ctx); err != nil {
+			return err
+		}
+	}
+}
+
+// removeExpired removes all expired reconciliation
+// history and rotated log files.
+func (t *DataTester) removeExpired(ctx context.Context) error {
+	config := t.config.Data.Retention
+
+	var cutoff time.Time
+	if config.MaxAge > 0 {
+		cutoff = time.Now().Add(-time.Duration(config.MaxAge) * time.Second)
+
+		removed, err := t.logger.PruneRotated(cutoff)
+		if err != nil {
+			return fmt.Errorf("%w: unable to remove expired log files", err)
+		}
+
+		if removed > 0 {
+			log.Printf("Removed %d expired log files\n", removed)
+		}
+	}
+
+	if t.reconciliationHistory == nil {
+		return nil
+	}
+
+	minIndex := int64(-1)
+	if config.MaxBlocks > 0 {
+		head, err := t.blockStorage.GetHeadBlockIdentifier(ctx)
+		switch {
+		case errors.Is(err, storageErrs.ErrHeadBlockNotFound):
+			return nil
+		case err != nil:
+			return fmt.Errorf("%w: unable to get head block", err)
+		}
+
+		minIndex = head.Index - int64(config.MaxBlocks)
+	}
+
+	var minTimestamp int64
+	if !cutoff.IsZero() {
+		minTimestamp = cutoff.UnixNano()
+	}
+
+	removed, err := t.reconciliationHistory.Prune(ctx, minIndex, minTimestamp)
+	if err != nil {
+		return fmt.Errorf("%w: unable to remove expired reconciliation history", err)
+	}
+
+	if removed > 0 {
+		log.Printf("Removed %d expired reconciliation attempts\n", removed)
+	}
+
+	return nil
+}
@@ -0,0 +1,178 @@
+// Copyright 2022 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tester
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"log"
+
+	"github.com/coinbase/rosetta-cli/configuration"
+	"github.com/coinbase/rosetta-cli/pkg/processor"
+
+	"github.com/coinbase/rosetta-sdk-go/storage/database"
+	storageErrs "github.com/coinbase/rosetta-sdk-go/storage/errors"
+	"github.com/coinbase/rosetta-sdk-go/storage/modules"
+)
+
+// schemaVersionSize is the size of an encoded schema version.
+const schemaVersionSize = 8
+
+// schemaVersionKey is the key of the schema
+// version of check:data storage.
+var schemaVersionKey = []byte("schema/version")
+
+// schemaMigration upgrades check:data storage from the
+// previous schema version to the next schema version.
+type schemaMigration struct {
+	description string
+	migrate     func(context.Context, database.Database) error
+}
+
+// schemaMigrations are applied in order to storage created
+// by earlier releases. The schema version of storage is the
+// number of migrations that have been applied to it (storage
+// created before schema versions were recorded is version 0),
+// so migrations must only ever be appended.
+var schemaMigrations = []*schemaMigration{
+	{
+		description: "re-encode reconciliation history and operation totals",
+		migrate: func(ctx context.Context, db database.Database) error {
+			reencoded, err := processor.ReencodeStoredValues(ctx, db)
+			if err != nil {
+				return err
+			}
+
+			log.Printf("Re-encoded %d values\n", reencoded)
+			return nil
+		},
+	},
+}
+
+// currentSchemaVersion is the schema version
+// of storage created by this release.
+func currentSchemaVersion() uint64 {
+	return uint64(len(schemaMigrations))
+}
+
+// getSchemaVersion returns the schema version of db and a boolean
+// indicating if the version was recorded. New storage is always at
+// the current schema version (even before it is recorded).
+func getSchemaVersion(ctx context.Context, db database.Database) (uint64, bool, error) {
+	dbTx := db.ReadTransaction(ctx)
+	defer dbTx.Discard(ctx)
+
+	exists, val, err := dbTx.Get(ctx, schemaVersionKey)
+	if err != nil {
+		return 0, false, fmt.Errorf("%w: unable to get schema version", err)
+	}
+
+	if exists {
+		if len(val) != schemaVersionSize {
+			return 0, false, fmt.Errorf("schema version has invalid length %d", len(val))
+		}
+
+		return binary.BigEndian.Uint64(val), true, nil
+	}
+
+	_, err = modules.NewBlockStorage(db, 1).GetHeadBlockIdentifier(ctx)
+	switch {
+	case errors.Is(err, storageErrs.ErrHeadBlockNotFound):
+		return currentSchemaVersion(), false, nil
+	case err != nil:
+		return 0, false, fmt.Errorf("%w: unable to get head block", err)
+	default:
+		return 0, false, nil
+	}
+}
+
+func setSchemaVersion(ctx context.Context, db database.Database, version uint64) error {
+	val := make([]byte, schemaVersionSize)
+	binary.BigEndian.PutUint64(val, version)
+
+	dbTx := db.Transaction(ctx)
+	defer dbTx.Discard(ctx)
+
+	if err := dbTx.Set(ctx, schemaVersionKey, val, true); err != nil {
+		return fmt.Errorf("%w: unable to set schema version", err)
+	}
+
+	return dbTx.Commit(ctx)
+}
+
+// migrateStorage applies all schema migrations that have not
+// been applied to db (recording the schema version after each
+// migration, so an interrupted upgrade resumes where it stopped).
+// Storage created by a newer release cannot be opened.
+func migrateStorage(
+	ctx context.Context,
+	config *configuration.Configuration,
+	db database.Database,
+) error {
+	version, recorded, err := getSchemaVersion(ctx, db)
+	if err != nil {
+		return err
+	}
+
+	current := currentSchemaVersion()
+	if version > current {
+		return fmt.Errorf(
+			"storage schema version %d is newer than the supported version %d (upgrade rosetta-cli)",
+			version,
+			current,
+		)
+	}
+
+	if version == current && recorded {
+		return nil
+	}
+
+	if config.ReadOnlyStorage {
+		if version == current {
+			return nil
+		}
+
+		return fmt.Errorf(
+			"storage schema version %d must be migrated to version %d (open it without read_only_storage)",
+			version,
+			current,
+		)
+	}
+
+	// New storage only records the current version.
+	if version == current {
+		return setSchemaVersion(ctx, db, version)
+	}
+
+	for ; version < current; version++ {
+		migration := schemaMigrations[version]
+		log.Printf(
+			"Migrating storage to schema version %d: %s\n",
+			version+1,
+			migration.description,
+		)
+		if err := migration.migrate(ctx, db); err != nil {
+			return fmt.Errorf("%w: unable to migrate storage to schema version %d", err, version+1)
+		}
+
+		if err := setSchemaVersion(ctx, db, version+1); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,159 @@
+// Copyright 2022 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tester
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/coinbase/rosetta-cli/configuration"
+	"github.com/coinbase/rosetta-cli/pkg/processor"
+	"github.com/coinbase/rosetta-cli/pkg/results"
+
+	"github.com/coinbase/rosetta-sdk-go/storage/database"
+	"github.com/coinbase/rosetta-sdk-go/storage/modules"
+	"github.com/coinbase/rosetta-sdk-go/types"
+	"github.com/stretchr/testify/assert"
+)
+
+var schemaTestTotal = &results.OperationTotal{
+	Type:     "TRANSFER",
+	Currency: &types.Currency{Symbol: "ETH", Decimals: 18},
+	Value:    "100",
+	Count:    2,
+}
+
+// schemaTestTotalKey is the key of schemaTestTotal
+// (as stored by processor.OperationTotalsWorker).
+var schemaTestTotalKey = []byte("operation_totals/TRANSFER/" + types.Hash(schemaTestTotal.Currency))
+
+func storedValue(ctx context.Context, t *testing.T, db database.Database, key []byte) []byte {
+	txn := db.ReadTransaction(ctx)
+	defer txn.Discard(ctx)
+
+	exists, val, err := txn.Get(ctx, key)
+	assert.NoError(t, err)
+	assert.True(t, exists)
+
+	return val
+}
+
+// newUnversionedDatabase returns storage created before schema
+// versions were recorded (with a synced block and an operation
+// total stored as JSON).
+func newUnversionedDatabase(ctx context.Context, t *testing.T) database.Database {
+	db := newTestDatabase(t)
+	assert.NoError(t, modules.NewBlockStorage(db, 1).AddBlock(ctx, testBlock(0)))
+
+	val, err := json.Marshal(schemaTestTotal)
+	assert.NoError(t, err)
+
+	txn := db.Transaction(ctx)
+	assert.NoError(t, txn.Set(ctx, schemaTestTotalKey, val, true))
+	assert.NoError(t, txn.Commit(ctx))
+
+	return db
+}
+
+func TestMigrateStorage_Unversioned(t *testing.T) {
+	ctx := context.Background()
+	db := newUnversionedDatabase(ctx, t)
+	defer db.Close(ctx)
+
+	version, recorded, err := getSchemaVersion(ctx, db)
+	assert.NoError(t, err)
+	assert.False(t, recorded)
+	assert.Equal(t, uint64(0), version)
+
+	assert.NoError(t, migrateStorage(ctx, configuration.DefaultConfiguration(), db))
+
+	version, recorded, err = getSchemaVersion(ctx, db)
+	assert.NoError(t, err)
+	assert.True(t, recorded)
+	assert.Equal(t, currentSchemaVersion(), version)
+
+	// The operation total is no longer stored as
+	// JSON but is otherwise unchanged.
+	assert.NotEqual(t, byte('{'), storedValue(ctx, t, db, schemaTestTotalKey)[0])
+	totals, err := processor.NewOperationTotalsWorker(db, nil).GetAll(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, []*results.OperationTotal{schemaTestTotal}, totals)
+
+	head, err := modules.NewBlockStorage(db, 1).GetHeadBlockIdentifier(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, testBlock(0).BlockIdentifier, head)
+
+	// Migrated storage is not migrated again.
+	migrated := storedValue(ctx, t, db, schemaTestTotalKey)
+	assert.NoError(t, migrateStorage(ctx, configuration.DefaultConfiguration(), db))
+	assert.Equal(t, migrated, storedValue(ctx, t, db, schemaTestTotalKey))
+}
+
+func TestMigrateStorage_UnversionedReadOnly(t *testing.T) {
+	ctx := context.Background()
+	db := newUnversionedDatabase(ctx, t)
+	defer db.Close(ctx)
+
+	config := configuration.DefaultConfiguration()
+	config.ReadOnlyStorage = true
+	assert.Error(t, migrateStorage(ctx, config, db))
+
+	_, recorded, err := getSchemaVersion(ctx, db)
+	assert.NoError(t, err)
+	assert.False(t, recorded)
+}
+
+func TestMigrateStorage_New(t *testing.T) {
+	ctx := context.Background()
+	db := newTestDatabase(t)
+	defer db.Close(ctx)
+
+	assert.NoError(t, migrateStorage(ctx, configuration.DefaultConfiguration(), db))
+
+	version, recorded, err := getSchemaVersion(ctx, db)
+	assert.NoError(t, err)
+	assert.True(t, recorded)
+	assert.Equal(t, currentSchemaVersion(), version)
+}
+
+func TestMigrateStorage_Newer(t *testing.T) {
+	ctx := context.Background()
+	db := newTestDatabase(t)
+	defer db.Close(ctx)
+
+	assert.NoError(t, setSchemaVersion(ctx, db, currentSchemaVersion()+1))
+	err := migrateStorage(ctx, configuration.DefaultConfiguration(), db)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "newer than the supported version")
+
+	version, _, err := getSchemaVersion(ctx, db)
+	assert.NoError(t, err)
+	assert.Equal(t, currentSchemaVersion()+1, version)
+}
+
+func TestMigrateStorage_Unknown(t *testing.T) {
+	ctx := context.Background()
+	db := newTestDatabase(t)
+	defer db.Close(ctx)
+
+	txn := db.Transaction(ctx)
+	assert.NoError(t, txn.Set(ctx, schemaVersionKey, []byte("v2"), true))
+	assert.NoError(t, txn.Commit(ctx))
+
+	err := migrateStorage(ctx, configuration.DefaultConfiguration(), db)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "schema version has invalid length")
+}
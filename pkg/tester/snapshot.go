@@ -0,0 +1,326 @@
+// Copyright 2022 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tester
+
+import (
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/coinbase/rosetta-cli/configuration"
+	"github.com/coinbase/rosetta-cli/pkg/processor"
+
+	"github.com/coinbase/rosetta-sdk-go/fetcher"
+	"github.com/coinbase/rosetta-sdk-go/storage/database"
+	storageErrs "github.com/coinbase/rosetta-sdk-go/storage/errors"
+	"github.com/coinbase/rosetta-sdk-go/storage/modules"
+	"github.com/coinbase/rosetta-sdk-go/types"
+	"github.com/coinbase/rosetta-sdk-go/utils"
+)
+
+// snapshotVersion is the version of the snapshot format
+// written by ExportSnapshot.
+const snapshotVersion = 1
+
+// snapshotHeader is the first entry of a snapshot.
+type snapshotHeader struct {
+	Version int                      `json:"version"`
+	Network *types.NetworkIdentifier `json:"network_identifier"`
+	Block   *types.Block             `json:"block"`
+}
+
+// snapshotEntry is a balance or a coin of an account
+// at the snapshot block.
+type snapshotEntry struct {
+	Account *types.AccountIdentifier `json:"account_identifier"`
+	Amount  *types.Amount            `json:"amount,omitempty"`
+	Coin    *types.Coin              `json:"coin,omitempty"`
+}
+
+// SnapshotStats describes an exported or imported snapshot.
+type SnapshotStats struct {
+	Block    *types.BlockIdentifier `json:"block_identifier"`
+	Balances int                    `json:"balances"`
+	Coins    int                    `json:"coins"`
+}
+
+// ExportSnapshot writes the balances and coins of all tracked
+// accounts at the head block of check:data storage to w as
+// gzipped, newline-delimited JSON. Snapshots are always taken
+// at the head block (historical coins are not stored), so storage
+// should be rewound before exporting an earlier height.
+// This must not be called while `check:data` is running on the
+// same data directory.
+func ExportSnapshot(
+	ctx context.Context,
+	config *configuration.Configuration,
+	w io.Writer,
+) (*SnapshotStats, error) {
+	localStore, err := openDataDatabase(ctx, config)
+	if err != nil {
+		return nil, err
+	}
+	defer localStore.Close(ctx)
+
+	blockStorage := modules.NewBlockStorage(localStore, config.SerialBlockWorkers)
+	head, err := blockStorage.GetHeadBlockIdentifier(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("%w: unable to get head block", err)
+	}
+
+	block, err := blockStorage.GetBlock(ctx, types.ConstructPartialBlockIdentifier(head))
+	if err != nil {
+		return nil, fmt.Errorf("%w: unable to get head block", err)
+	}
+
+	gw := gzip.NewWriter(w)
+	encoder := json.NewEncoder(gw)
+	if err := encoder.Encode(&snapshotHeader{
+		Version: snapshotVersion,
+		Network: config.Network,
+		Block:   block,
+	}); err != nil {
+		return nil, fmt.Errorf("%w: unable to write snapshot header", err)
+	}
+
+	stats := &SnapshotStats{Block: head}
+	balanceStorage := modules.NewBalanceStorage(localStore)
+	accounts, err := balanceStorage.GetAllAccountCurrency(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("%w: unable to get tracked accounts", err)
+	}
+
+	for _, account := range accounts {
+		amount, err := balanceStorage.GetBalance(ctx, account.Account, account.Currency, head.Index)
+		if err != nil {
+			return nil, fmt.Errorf(
+				"%w: unable to get balance of %s",
+				err,
+				types.PrintStruct(account),
+			)
+		}
+
+		if err := encoder.Encode(&snapshotEntry{
+			Account: account.Account,
+			Amount:  amount,
+		}); err != nil {
+			return nil, fmt.Errorf("%w: unable to write balance", err)
+		}
+		stats.Balances++
+	}
+
+	coinStorage := modules.NewCoinStorage(
+		localStore,
+		processor.NewCoinStorageHelper(blockStorage),
+		nil,
+	)
+	exported := map[string]struct{}{}
+	for _, account := range accounts {
+		key := types.Hash(account.Account)
+		if _, ok := exported[key]; ok {
+			continue
+		}
+		exported[key] = struct{}{}
+
+		coins, _, err := coinStorage.GetCoins(ctx, account.Account)
+		if err != nil {
+			return nil, fmt.Errorf(
+				"%w: unable to get coins of %s",
+				err,
+				types.PrintStruct(account.Account),
+			)
+		}
+
+		for _, coin := range coins {
+			if err := encoder.Encode(&snapshotEntry{
+				Account: account.Account,
+				Coin:    coin,
+			}); err != nil {
+				return nil, fmt.Errorf("%w: unable to write coin", err)
+			}
+			stats.Coins++
+		}
+	}
+
+	if err := gw.Close(); err != nil {
+		return nil, fmt.Errorf("%w: unable to write snapshot", err)
+	}
+
+	return stats, nil
+}
+
+// ImportSnapshot initializes empty check:data storage with a
+// snapshot written by ExportSnapshot. `check:data` resumes
+// syncing at the block after the snapshot block. Only the
+// snapshot block is stored, so a reorg deeper than the snapshot
+// block cannot be handled.
+func ImportSnapshot(
+	ctx context.Context,
+	config *configuration.Configuration,
+	r io.Reader,
+) (*SnapshotStats, error) {
+	if err := assertWritableStorage(config); err != nil {
+		return nil, err
+	}
+
+	gr, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, fmt.Errorf("%w: unable to read snapshot", err)
+	}
+	defer gr.Close()
+
+	decoder := json.NewDecoder(gr)
+	header := &snapshotHeader{}
+	if err := decoder.Decode(header); err != nil {
+		return nil, fmt.Errorf("%w: unable to read snapshot header", err)
+	}
+
+	if header.Version != snapshotVersion {
+		return nil, fmt.Errorf("unsupported snapshot version %d", header.Version)
+	}
+
+	if types.Hash(header.Network) != types.Hash(config.Network) {
+		return nil, fmt.Errorf(
+			"snapshot of %s cannot be imported for %s",
+			types.PrintStruct(header.Network),
+			types.PrintStruct(config.Network),
+		)
+	}
+
+	if header.Block == nil || header.Block.BlockIdentifier == nil {
+		return nil, errors.New("snapshot block is missing")
+	}
+
+	localStore, err := openDataDatabase(ctx, config)
+	if err != nil {
+		return nil, err
+	}
+	defer localStore.Close(ctx)
+
+	blockStorage := modules.NewBlockStorage(localStore, config.SerialBlockWorkers)
+	_, err = blockStorage.GetHeadBlockIdentifier(ctx)
+	switch {
+	case err == nil:
+		return nil, errors.New("snapshots can only be imported into empty storage")
+	case !errors.Is(err, storageErrs.ErrHeadBlockNotFound):
+		return nil, fmt.Errorf("%w: unable to get head block", err)
+	}
+
+	if err := blockStorage.AddBlock(ctx, header.Block); err != nil {
+		return nil, fmt.Errorf("%w: unable to store snapshot block", err)
+	}
+
+	// Balances are set directly, so the balance storage
+	// handler is only used to update the seen accounts counter.
+	counterStorage := modules.NewCounterStorage(localStore)
+	balanceStorage := modules.NewBalanceStorage(localStore)
+	balanceStorage.Initialize(
+		processor.NewBalanceStorageHelper(
+			config.Network,
+			&fetcher.Fetcher{},
+			counterStorage,
+			false,
+			nil,
+			false,
+			nil,
+			true,
+		),
+		processor.NewBalanceStorageHandler(nil, nil, counterStorage, false, nil, nil),
+	)
+	coinStorage := modules.NewCoinStorage(
+		localStore,
+		processor.NewCoinStorageHelper(blockStorage),
+		nil,
+	)
+
+	stats := &SnapshotStats{Block: header.Block.BlockIdentifier}
+	dbTx := localStore.Transaction(ctx)
+	defer func() { dbTx.Discard(ctx) }()
+
+	coins := []*types.AccountCoin{}
+	for {
+		entry := &snapshotEntry{}
+		err := decoder.Decode(entry)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: unable to read snapshot entry", err)
+		}
+
+		switch {
+		case entry.Amount != nil:
+			if err := balanceStorage.SetBalance(
+				ctx,
+				dbTx,
+				entry.Account,
+				entry.Amount,
+				header.Block.BlockIdentifier,
+			); err != nil {
+				return nil, fmt.Errorf("%w: unable to set balance", err)
+			}
+			stats.Balances++
+
+			if stats.Balances%utils.MaxEntrySizePerTxn == 0 {
+				next, err := commitSnapshotTransaction(ctx, localStore, dbTx)
+				if err != nil {
+					return nil, err
+				}
+				dbTx = next
+			}
+		case entry.Coin != nil:
+			coins = append(coins, &types.AccountCoin{Account: entry.Account, Coin: entry.Coin})
+			if len(coins) == utils.MaxEntrySizePerTxn {
+				if err := coinStorage.AddCoins(ctx, coins); err != nil {
+					return nil, fmt.Errorf("%w: unable to add coins", err)
+				}
+				stats.Coins += len(coins)
+				coins = []*types.AccountCoin{}
+			}
+		default:
+			return nil, errors.New("snapshot entry has no balance or coin")
+		}
+	}
+
+	if err := dbTx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("%w: unable to commit balances", err)
+	}
+
+	if len(coins) > 0 {
+		if err := coinStorage.AddCoins(ctx, coins); err != nil {
+			return nil, fmt.Errorf("%w: unable to add coins", err)
+		}
+		stats.Coins += len(coins)
+	}
+
+	return stats, nil
+}
+
+// commitSnapshotTransaction commits dbTx and
+// returns a new transaction of db.
+func commitSnapshotTransaction(
+	ctx context.Context,
+	db database.Database,
+	dbTx database.Transaction,
+) (database.Transaction, error) {
+	if err := dbTx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("%w: unable to commit balances", err)
+	}
+
+	return db.Transaction(ctx), nil
+}
@@ -0,0 +1,182 @@
+// Copyright 2022 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tester
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/coinbase/rosetta-cli/configuration"
+	"github.com/coinbase/rosetta-cli/pkg/processor"
+
+	"github.com/coinbase/rosetta-sdk-go/storage/modules"
+	"github.com/coinbase/rosetta-sdk-go/types"
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	snapshotTestCurrency = &types.Currency{Symbol: "ETH", Decimals: 18}
+
+	snapshotTestBalances = []*snapshotEntry{
+		{
+			Account: &types.AccountIdentifier{Address: "addr1"},
+			Amount:  &types.Amount{Value: "100", Currency: snapshotTestCurrency},
+		},
+		{
+			Account: &types.AccountIdentifier{
+				Address:    "addr2",
+				SubAccount: &types.SubAccountIdentifier{Address: "staking"},
+			},
+			Amount: &types.Amount{Value: "5", Currency: snapshotTestCurrency},
+		},
+	}
+
+	snapshotTestCoin = &snapshotEntry{
+		Account: &types.AccountIdentifier{Address: "addr1"},
+		Coin: &types.Coin{
+			CoinIdentifier: &types.CoinIdentifier{Identifier: "coin1"},
+			Amount:         &types.Amount{Value: "100", Currency: snapshotTestCurrency},
+		},
+	}
+)
+
+func snapshotTestConfig(t *testing.T) *configuration.Configuration {
+	config := configuration.DefaultConfiguration()
+	config.DataDirectory = newTestDir(t)
+
+	return config
+}
+
+// writeTestSnapshot writes a snapshot of entries
+// at block in the format of ExportSnapshot.
+func writeTestSnapshot(
+	t *testing.T,
+	network *types.NetworkIdentifier,
+	block *types.Block,
+	entries []*snapshotEntry,
+) *bytes.Buffer {
+	var buf bytes.Buffer
+	gw := gzip.NewWriter(&buf)
+	encoder := json.NewEncoder(gw)
+	assert.NoError(t, encoder.Encode(&snapshotHeader{
+		Version: snapshotVersion,
+		Network: network,
+		Block:   block,
+	}))
+	for _, entry := range entries {
+		assert.NoError(t, encoder.Encode(entry))
+	}
+	assert.NoError(t, gw.Close())
+
+	return &buf
+}
+
+// assertSnapshotStorage asserts that the check:data storage of
+// config has the head block, balances, and coins of entries.
+func assertSnapshotStorage(
+	ctx context.Context,
+	t *testing.T,
+	config *configuration.Configuration,
+	head *types.BlockIdentifier,
+	entries []*snapshotEntry,
+) {
+	localStore, err := openDataDatabase(ctx, config)
+	assert.NoError(t, err)
+	defer localStore.Close(ctx)
+
+	blockStorage := modules.NewBlockStorage(localStore, 1)
+	storedHead, err := blockStorage.GetHeadBlockIdentifier(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, head, storedHead)
+
+	balanceStorage := modules.NewBalanceStorage(localStore)
+	coinStorage := modules.NewCoinStorage(
+		localStore,
+		processor.NewCoinStorageHelper(blockStorage),
+		nil,
+	)
+	for _, entry := range entries {
+		if entry.Amount != nil {
+			amount, err := balanceStorage.GetBalance(
+				ctx,
+				entry.Account,
+				entry.Amount.Currency,
+				head.Index,
+			)
+			assert.NoError(t, err)
+			assert.Equal(t, entry.Amount, amount)
+			continue
+		}
+
+		coins, _, err := coinStorage.GetCoins(ctx, entry.Account)
+		assert.NoError(t, err)
+		assert.Equal(t, []*types.Coin{entry.Coin}, coins)
+	}
+}
+
+func TestSnapshot_RoundTrip(t *testing.T) {
+	ctx := context.Background()
+	block := testBlock(5)
+	entries := append([]*snapshotEntry{}, snapshotTestBalances...)
+	entries = append(entries, snapshotTestCoin)
+
+	source := snapshotTestConfig(t)
+	imported, err := ImportSnapshot(
+		ctx,
+		source,
+		writeTestSnapshot(t, source.Network, block, entries),
+	)
+	assert.NoError(t, err)
+	assert.Equal(t, &SnapshotStats{
+		Block:    block.BlockIdentifier,
+		Balances: len(snapshotTestBalances),
+		Coins:    1,
+	}, imported)
+
+	var snapshot bytes.Buffer
+	exported, err := ExportSnapshot(ctx, source, &snapshot)
+	assert.NoError(t, err)
+	assert.Equal(t, imported, exported)
+
+	restored := snapshotTestConfig(t)
+	reimported, err := ImportSnapshot(ctx, restored, &snapshot)
+	assert.NoError(t, err)
+	assert.Equal(t, imported, reimported)
+
+	assertSnapshotStorage(ctx, t, restored, block.BlockIdentifier, entries)
+}
+
+func TestSnapshot_ImportNonEmptyStorage(t *testing.T) {
+	ctx := context.Background()
+	config := snapshotTestConfig(t)
+
+	_, err := ImportSnapshot(
+		ctx,
+		config,
+		writeTestSnapshot(t, config.Network, testBlock(5), snapshotTestBalances),
+	)
+	assert.NoError(t, err)
+
+	_, err = ImportSnapshot(
+		ctx,
+		config,
+		writeTestSnapshot(t, config.Network, testBlock(6), snapshotTestBalances),
+	)
+	assert.Error(t, err)
+	assertSnapshotStorage(ctx, t, config, testBlock(5).BlockIdentifier, snapshotTestBalances)
+}
@@ -0,0 +1,375 @@
+// Copyright 2022 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tester
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"path"
+	"time"
+
+	"github.com/coinbase/rosetta-cli/configuration"
+
+	"github.com/coinbase/rosetta-sdk-go/storage/database"
+	"github.com/coinbase/rosetta-sdk-go/storage/encoder"
+	"github.com/coinbase/rosetta-sdk-go/types"
+)
+
+// balanceDatabaseDirectory is the subdirectory of the
+// check:data storage path where balances are stored when
+// separate_balance_storage is enabled.
+const balanceDatabaseDirectory = "balances"
+
+// reconciledAccountNamespace is the namespace of the index
+// of the last block where each account was reconciled in
+// modules.BalanceStorage.
+const reconciledAccountNamespace = "recacc"
+
+// headBlockKey is the key of the head block
+// stored by modules.BlockStorage.
+var headBlockKey = []byte("head-block")
+
+// balanceHeadKey is the key in the balance database of
+// the head block that was committed with its balances.
+var balanceHeadKey = []byte("balancehead")
+
+// balanceNamespaces are the namespaces written by
+// modules.BalanceStorage (and the account filter journal,
+// which must be committed with the balances it covers).
+var balanceNamespaces = [][]byte{
+	[]byte("acc"),
+	[]byte("bal"),
+	[]byte("hbal"),
+	[]byte(reconciledAccountNamespace),
+	[]byte("pruneacc"),
+	[]byte("filterjournal"),
+}
+
+// isBalanceKey returns a boolean indicating if key (or a
+// scan prefix) is in a namespace of modules.BalanceStorage.
+func isBalanceKey(key []byte) bool {
+	for _, namespace := range balanceNamespaces {
+		if !bytes.HasPrefix(key, namespace) {
+			continue
+		}
+
+		// Scans of a namespace omit the separator.
+		if len(key) == len(namespace) || key[len(namespace)] == '/' {
+			return true
+		}
+	}
+
+	return false
+}
+
+// openDataStorage opens the check:data database at dataPath. If
+// separate_balance_storage is enabled, balances are stored in
+// their own database (in a subdirectory of dataPath). If
+// block_commit_batch_size is greater than 1, block commits
+// are batched. If account_filter is populated, lookups of
+// unseen accounts skip the database.
+func openDataStorage(
+	ctx context.Context,
+	config *configuration.Configuration,
+	dataPath string,
+) (database.Database, error) {
+	opts, err := storageOptions(config, dataPath)
+	if err != nil {
+		return nil, err
+	}
+
+	blockStore, err := database.NewBadgerDatabase(storageContext(ctx, config), dataPath, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	if !config.Data.SeparateBalanceStorage {
+		return wrapDataStorage(ctx, config, dataPath, blockStore)
+	}
+
+	balancePath := path.Join(dataPath, balanceDatabaseDirectory)
+	balanceOpts, err := storageOptions(config, balancePath)
+	if err != nil {
+		_ = blockStore.Close(ctx)
+		return nil, err
+	}
+
+	balanceStore, err := database.NewBadgerDatabase(
+		storageContext(ctx, config),
+		balancePath,
+		balanceOpts...,
+	)
+	if err != nil {
+		_ = blockStore.Close(ctx)
+		return nil, fmt.Errorf("%w: unable to initialize balance database", err)
+	}
+
+	if err := assertSplitHeads(ctx, blockStore, balanceStore); err != nil {
+		_ = balanceStore.Close(ctx)
+		_ = blockStore.Close(ctx)
+		return nil, err
+	}
+
+	return wrapDataStorage(
+		ctx,
+		config,
+		dataPath,
+		&splitDatabase{blocks: blockStore, balances: balanceStore},
+	)
+}
+
+// wrapDataStorage migrates db to the current schema version, adds
+// metadata deduplication and the account filter (if configured) to
+// db, and batches block commits if block_commit_batch_size is greater
+// than 1. The account filter must wrap each transaction in a batch so
+// that it observes uncommitted batches. Read-only storage only has
+// metadata deduplication (the others only apply to writes and the
+// account filter may persist itself on open).
+func wrapDataStorage(
+	ctx context.Context,
+	config *configuration.Configuration,
+	dataPath string,
+	db database.Database,
+) (database.Database, error) {
+	if err := migrateStorage(ctx, config, db); err != nil {
+		_ = db.Close(ctx)
+		return nil, fmt.Errorf("%w: unable to migrate storage", err)
+	}
+
+	// Metadata references must be resolved even if
+	// deduplication is no longer enabled.
+	db = newMetadataDedupeDatabase(db, config.Data.MetadataDedupeThreshold)
+
+	if config.ReadOnlyStorage {
+		return db, nil
+	}
+
+	if config.Data.AccountFilter != nil {
+		filePath := path.Join(dataPath, accountFilterFile)
+		if config.InMemoryStorage {
+			filePath = ""
+		}
+
+		filtered, err := newAccountFilterDatabase(ctx, db, config.Data.AccountFilter, filePath)
+		if err != nil {
+			_ = db.Close(ctx)
+			return nil, fmt.Errorf("%w: unable to initialize account filter", err)
+		}
+
+		db = filtered
+	}
+
+	if config.Data.BlockCommitBatchSize <= 1 {
+		return db, nil
+	}
+
+	return newBatchedDatabase(
+		db,
+		config.Data.BlockCommitBatchSize,
+		time.Duration(config.Data.BlockCommitFlushIntervalMs)*time.Millisecond,
+	), nil
+}
+
+var _ database.Database = (*splitDatabase)(nil)
+
+// splitDatabase implements the database.Database interface by
+// storing all keys written by modules.BalanceStorage in one
+// database and all other keys in another. This allows block
+// data to be pruned, compacted, or discarded independently
+// of balance state without any changes to the storage modules.
+//
+// Changes to both databases are committed separately (balances
+// first), so an unclean shutdown between the two commits may
+// leave balances one block ahead of the head block. To detect
+// this, the balance database records the head block committed
+// with its balances (see assertSplitHeads).
+type splitDatabase struct {
+	blocks   database.Database
+	balances database.Database
+}
+
+// Transaction acquires an exclusive write lock on both databases.
+func (d *splitDatabase) Transaction(ctx context.Context) database.Transaction {
+	return &splitTransaction{
+		blocks:   d.blocks.Transaction(ctx),
+		balances: d.balances.Transaction(ctx),
+	}
+}
+
+// ReadTransaction returns a read-only transaction
+// over both databases.
+func (d *splitDatabase) ReadTransaction(ctx context.Context) database.Transaction {
+	return &splitTransaction{
+		blocks:   d.blocks.ReadTransaction(ctx),
+		balances: d.balances.ReadTransaction(ctx),
+	}
+}
+
+// WriteTransaction acquires a granular write lock
+// for identifier on both databases.
+func (d *splitDatabase) WriteTransaction(
+	ctx context.Context,
+	identifier string,
+	priority bool,
+) database.Transaction {
+	return &splitTransaction{
+		blocks:   d.blocks.WriteTransaction(ctx, identifier, priority),
+		balances: d.balances.WriteTransaction(ctx, identifier, priority),
+	}
+}
+
+// Close closes both databases.
+func (d *splitDatabase) Close(ctx context.Context) error {
+	balancesErr := d.balances.Close(ctx)
+	if err := d.blocks.Close(ctx); err != nil {
+		return err
+	}
+
+	return balancesErr
+}
+
+// Encoder returns the *encoder.Encoder of the block database
+// (both databases are opened with the same options).
+func (d *splitDatabase) Encoder() *encoder.Encoder {
+	return d.blocks.Encoder()
+}
+
+var _ database.Transaction = (*splitTransaction)(nil)
+
+// splitTransaction routes each key to the
+// transaction of the database that stores it.
+type splitTransaction struct {
+	blocks   database.Transaction
+	balances database.Transaction
+}
+
+func (t *splitTransaction) route(key []byte) database.Transaction {
+	if isBalanceKey(key) {
+		return t.balances
+	}
+
+	return t.blocks
+}
+
+// Set stores value at key. The head block is also
+// stored in the balance database.
+func (t *splitTransaction) Set(
+	ctx context.Context,
+	key []byte,
+	value []byte,
+	reclaimValue bool,
+) error {
+	if bytes.Equal(key, headBlockKey) {
+		if err := t.balances.Set(ctx, balanceHeadKey, value, reclaimValue); err != nil {
+			return fmt.Errorf("%w: unable to store balance head block", err)
+		}
+	}
+
+	return t.route(key).Set(ctx, key, value, reclaimValue)
+}
+
+// Get returns the value stored at key.
+func (t *splitTransaction) Get(ctx context.Context, key []byte) (bool, []byte, error) {
+	return t.route(key).Get(ctx, key)
+}
+
+// Delete removes key.
+func (t *splitTransaction) Delete(ctx context.Context, key []byte) error {
+	if bytes.Equal(key, headBlockKey) {
+		if err := t.balances.Delete(ctx, balanceHeadKey); err != nil {
+			return fmt.Errorf("%w: unable to delete balance head block", err)
+		}
+	}
+
+	return t.route(key).Delete(ctx, key)
+}
+
+// Scan iterates over all keys with prefix.
+func (t *splitTransaction) Scan(
+	ctx context.Context,
+	prefix []byte,
+	seekStart []byte,
+	worker func([]byte, []byte) error,
+	logEntries bool,
+	reverse bool,
+) (int, error) {
+	return t.route(prefix).Scan(ctx, prefix, seekStart, worker, logEntries, reverse)
+}
+
+// Commit commits balances and then blocks.
+func (t *splitTransaction) Commit(ctx context.Context) error {
+	if err := t.balances.Commit(ctx); err != nil {
+		t.blocks.Discard(ctx)
+		return fmt.Errorf("%w: unable to commit balances", err)
+	}
+
+	return t.blocks.Commit(ctx)
+}
+
+// Discard discards changes to both databases.
+func (t *splitTransaction) Discard(ctx context.Context) {
+	t.balances.Discard(ctx)
+	t.blocks.Discard(ctx)
+}
+
+// assertSplitHeads returns an error if the head block committed
+// with the balances in balances is not the head block in blocks
+// (which only happens if check:data exited between the commits of
+// a splitTransaction). Balances cannot be rolled back to the head
+// block, so such storage cannot be opened. Storage created before
+// the head block was stored with balances is not checked.
+func assertSplitHeads(ctx context.Context, blocks, balances database.Database) error {
+	blocksTx := blocks.ReadTransaction(ctx)
+	defer blocksTx.Discard(ctx)
+
+	_, head, err := blocksTx.Get(ctx, headBlockKey)
+	if err != nil {
+		return fmt.Errorf("%w: unable to get head block", err)
+	}
+
+	balancesTx := balances.ReadTransaction(ctx)
+	defer balancesTx.Discard(ctx)
+
+	recorded, balanceHead, err := balancesTx.Get(ctx, balanceHeadKey)
+	if err != nil {
+		return fmt.Errorf("%w: unable to get balance head block", err)
+	}
+
+	if !recorded || bytes.Equal(head, balanceHead) {
+		return nil
+	}
+
+	return fmt.Errorf(
+		"balances were committed at block %s but the head block is %s (check:data exited while committing a block; restore a snapshot or checkpoint, or remove the data directory)",
+		headBlockString(blocks.Encoder(), balanceHead),
+		headBlockString(blocks.Encoder(), head),
+	)
+}
+
+// headBlockString returns a description of the head
+// block stored at headBlockKey (which may be missing).
+func headBlockString(enc *encoder.Encoder, val []byte) string {
+	if len(val) == 0 {
+		return "missing"
+	}
+
+	var blockIdentifier types.BlockIdentifier
+	if err := enc.Decode("", val, &blockIdentifier, true); err != nil {
+		return fmt.Sprintf("unknown (%s)", err.Error())
+	}
+
+	return types.PrintStruct(blockIdentifier)
+}
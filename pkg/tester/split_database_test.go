@@ -0,0 +1,118 @@
+// Copyright 2022 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tester
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"testing"
+
+	"github.com/coinbase/rosetta-cli/configuration"
+
+	"github.com/coinbase/rosetta-sdk-go/storage/modules"
+	"github.com/coinbase/rosetta-sdk-go/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func splitTestConfig() *configuration.Configuration {
+	config := configuration.DefaultConfiguration()
+	config.Data.SeparateBalanceStorage = true
+
+	return config
+}
+
+func testBlock(index int64) *types.Block {
+	parentIndex := index - 1
+	if parentIndex < 0 {
+		parentIndex = 0
+	}
+
+	return &types.Block{
+		BlockIdentifier: &types.BlockIdentifier{
+			Index: index,
+			Hash:  fmt.Sprintf("block %d", index),
+		},
+		ParentBlockIdentifier: &types.BlockIdentifier{
+			Index: parentIndex,
+			Hash:  fmt.Sprintf("block %d", parentIndex),
+		},
+	}
+}
+
+// openSplitTestDatabase opens the databases of split
+// storage at dir without checking their head blocks.
+func openSplitTestDatabase(t *testing.T, dir string) *splitDatabase {
+	return &splitDatabase{
+		blocks:   openTestDatabase(t, dir),
+		balances: openTestDatabase(t, path.Join(dir, balanceDatabaseDirectory)),
+	}
+}
+
+func TestSplitDatabase_Reopen(t *testing.T) {
+	ctx := context.Background()
+	dir := newTestDir(t)
+
+	db, err := openDataStorage(ctx, splitTestConfig(), dir)
+	assert.NoError(t, err)
+	blockStorage := modules.NewBlockStorage(db, 1)
+	assert.NoError(t, blockStorage.AddBlock(ctx, testBlock(0)))
+	assert.NoError(t, blockStorage.AddBlock(ctx, testBlock(1)))
+	assert.NoError(t, db.Close(ctx))
+
+	db, err = openDataStorage(ctx, splitTestConfig(), dir)
+	assert.NoError(t, err)
+	head, err := modules.NewBlockStorage(db, 1).GetHeadBlockIdentifier(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, testBlock(1).BlockIdentifier, head)
+	assert.NoError(t, db.Close(ctx))
+}
+
+func TestSplitDatabase_CrashBetweenCommits(t *testing.T) {
+	ctx := context.Background()
+	dir := newTestDir(t)
+
+	db, err := openDataStorage(ctx, splitTestConfig(), dir)
+	assert.NoError(t, err)
+	assert.NoError(t, modules.NewBlockStorage(db, 1).AddBlock(ctx, testBlock(0)))
+	assert.NoError(t, db.Close(ctx))
+
+	// Exit after the balances of block 1 are
+	// committed but before the block is.
+	crashed := openSplitTestDatabase(t, dir)
+	crashed.blocks = &failingDatabase{Database: crashed.blocks}
+	assert.Error(t, modules.NewBlockStorage(crashed, 1).AddBlock(ctx, testBlock(1)))
+	assert.NoError(t, crashed.Close(ctx))
+
+	_, err = openDataStorage(ctx, splitTestConfig(), dir)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), types.PrintStruct(testBlock(1).BlockIdentifier))
+	assert.Contains(t, err.Error(), types.PrintStruct(testBlock(0).BlockIdentifier))
+}
+
+func TestSplitDatabase_HeadNotRecorded(t *testing.T) {
+	ctx := context.Background()
+	dir := newTestDir(t)
+
+	// Storage created before the head block was stored
+	// with balances only has the head block.
+	db := openSplitTestDatabase(t, dir)
+	assert.NoError(t, modules.NewBlockStorage(db.blocks, 1).AddBlock(ctx, testBlock(0)))
+	assert.NoError(t, db.Close(ctx))
+
+	reopened, err := openDataStorage(ctx, splitTestConfig(), dir)
+	assert.NoError(t, err)
+	assert.NoError(t, reopened.Close(ctx))
+}
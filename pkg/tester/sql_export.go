@@ -0,0 +1,292 @@
+// Copyright 2022 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tester
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/coinbase/rosetta-cli/configuration"
+
+	storageErrs "github.com/coinbase/rosetta-sdk-go/storage/errors"
+	"github.com/coinbase/rosetta-sdk-go/storage/modules"
+	"github.com/coinbase/rosetta-sdk-go/types"
+)
+
+// sqlSchema is created at the start of a SQL export.
+const sqlSchema = `CREATE TABLE blocks (
+  block_index INTEGER PRIMARY KEY,
+  block_hash TEXT NOT NULL,
+  parent_block_index INTEGER NOT NULL,
+  parent_block_hash TEXT NOT NULL,
+  timestamp INTEGER NOT NULL,
+  metadata TEXT
+);
+CREATE TABLE transactions (
+  block_index INTEGER NOT NULL REFERENCES blocks(block_index),
+  transaction_hash TEXT NOT NULL,
+  metadata TEXT,
+  PRIMARY KEY (block_index, transaction_hash)
+);
+CREATE TABLE operations (
+  block_index INTEGER NOT NULL REFERENCES blocks(block_index),
+  transaction_hash TEXT NOT NULL,
+  operation_index INTEGER NOT NULL,
+  type TEXT NOT NULL,
+  status TEXT,
+  address TEXT,
+  sub_account TEXT,
+  currency_symbol TEXT,
+  currency_decimals INTEGER,
+  amount TEXT,
+  metadata TEXT,
+  PRIMARY KEY (block_index, transaction_hash, operation_index)
+);
+CREATE TABLE balances (
+  address TEXT NOT NULL,
+  sub_account TEXT,
+  currency_symbol TEXT NOT NULL,
+  currency_decimals INTEGER NOT NULL,
+  value TEXT NOT NULL,
+  block_index INTEGER NOT NULL
+);
+`
+
+// sqlIndexes are created after all rows are
+// inserted (which is faster than updating them
+// on every insert).
+const sqlIndexes = `CREATE INDEX blocks_hash ON blocks (block_hash);
+CREATE INDEX transactions_hash ON transactions (transaction_hash);
+CREATE INDEX operations_transaction ON operations (transaction_hash);
+CREATE INDEX operations_address ON operations (address, sub_account);
+CREATE INDEX operations_type ON operations (type);
+CREATE INDEX balances_address ON balances (address, sub_account);
+`
+
+// SQLExportStats summarizes a SQL export.
+type SQLExportStats struct {
+	Blocks       int64 `json:"blocks"`
+	Transactions int64 `json:"transactions"`
+	Operations   int64 `json:"operations"`
+	Balances     int64 `json:"balances"`
+}
+
+// ExportSQL writes a SQL script (in the SQLite dialect) to w that
+// creates and populates tables with all blocks, transactions, and operations
+// stored by previous runs of `check:data` (and the balance of each
+// account at the head block). This must not be called while
+// `check:data` is running on the same data directory.
+func ExportSQL(
+	ctx context.Context,
+	config *configuration.Configuration,
+	w io.Writer,
+) (*SQLExportStats, error) {
+	localStore, err := openDataDatabase(ctx, config)
+	if err != nil {
+		return nil, err
+	}
+	defer localStore.Close(ctx)
+
+	blockStorage := modules.NewBlockStorage(localStore, config.SerialBlockWorkers)
+	balanceStorage := modules.NewBalanceStorage(localStore)
+
+	head, err := blockStorage.GetHeadBlockIdentifier(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("%w: unable to get head block", err)
+	}
+
+	oldest, err := blockStorage.GetOldestBlockIndex(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("%w: unable to get oldest block index", err)
+	}
+
+	out := bufio.NewWriter(w)
+	stats := &SQLExportStats{}
+	fmt.Fprint(out, "BEGIN TRANSACTION;\n", sqlSchema)
+
+	for index := oldest; index <= head.Index; index++ {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+
+		// Omitted blocks are not stored, so there
+		// is nothing to export at their index.
+		block, err := getBlockAtIndex(ctx, blockStorage, index)
+		if err != nil {
+			return nil, err
+		}
+		if block == nil {
+			continue
+		}
+
+		writeSQLBlock(out, block, stats)
+	}
+
+	accounts, err := balanceStorage.GetAllAccountCurrency(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("%w: unable to get accounts", err)
+	}
+
+	for _, account := range accounts {
+		amount, err := balanceStorage.GetBalance(
+			ctx,
+			account.Account,
+			account.Currency,
+			head.Index,
+		)
+		if err != nil {
+			return nil, fmt.Errorf(
+				"%w: unable to get balance of %s",
+				err,
+				types.AccountString(account.Account),
+			)
+		}
+
+		address, subAccount := sqlAccount(account.Account)
+		fmt.Fprintf(
+			out,
+			"INSERT INTO balances VALUES (%s, %s, %s, %d, %s, %d);\n",
+			address,
+			subAccount,
+			sqlString(account.Currency.Symbol),
+			account.Currency.Decimals,
+			sqlString(amount.Value),
+			head.Index,
+		)
+		stats.Balances++
+	}
+
+	fmt.Fprint(out, sqlIndexes, "COMMIT;\n")
+	if err := out.Flush(); err != nil {
+		return nil, fmt.Errorf("%w: unable to write sql export", err)
+	}
+
+	return stats, nil
+}
+
+func writeSQLBlock(out io.Writer, block *types.Block, stats *SQLExportStats) {
+	fmt.Fprintf(
+		out,
+		"INSERT INTO blocks VALUES (%d, %s, %d, %s, %d, %s);\n",
+		block.BlockIdentifier.Index,
+		sqlString(block.BlockIdentifier.Hash),
+		block.ParentBlockIdentifier.Index,
+		sqlString(block.ParentBlockIdentifier.Hash),
+		block.Timestamp,
+		sqlJSON(block.Metadata),
+	)
+	stats.Blocks++
+
+	for _, tx := range block.Transactions {
+		fmt.Fprintf(
+			out,
+			"INSERT INTO transactions VALUES (%d, %s, %s);\n",
+			block.BlockIdentifier.Index,
+			sqlString(tx.TransactionIdentifier.Hash),
+			sqlJSON(tx.Metadata),
+		)
+		stats.Transactions++
+
+		for _, op := range tx.Operations {
+			status := "NULL"
+			if op.Status != nil {
+				status = sqlString(*op.Status)
+			}
+
+			address, subAccount := sqlAccount(op.Account)
+			symbol, decimals, amount := "NULL", "NULL", "NULL"
+			if op.Amount != nil {
+				symbol = sqlString(op.Amount.Currency.Symbol)
+				decimals = strconv.FormatInt(int64(op.Amount.Currency.Decimals), 10)
+				amount = sqlString(op.Amount.Value)
+			}
+
+			fmt.Fprintf(
+				out,
+				"INSERT INTO operations VALUES (%d, %s, %d, %s, %s, %s, %s, %s, %s, %s, %s);\n",
+				block.BlockIdentifier.Index,
+				sqlString(tx.TransactionIdentifier.Hash),
+				op.OperationIdentifier.Index,
+				sqlString(op.Type),
+				status,
+				address,
+				subAccount,
+				symbol,
+				decimals,
+				amount,
+				sqlJSON(op.Metadata),
+			)
+			stats.Operations++
+		}
+	}
+}
+
+// sqlString returns a quoted SQL string literal.
+func sqlString(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
+
+// sqlJSON returns metadata as a quoted JSON string
+// (or NULL if there is no metadata).
+func sqlJSON(metadata map[string]interface{}) string {
+	if len(metadata) == 0 {
+		return "NULL"
+	}
+
+	b, err := json.Marshal(metadata)
+	if err != nil {
+		return "NULL"
+	}
+
+	return sqlString(string(b))
+}
+
+// sqlAccount returns the address and sub-account
+// of an account as SQL values.
+func sqlAccount(account *types.AccountIdentifier) (string, string) {
+	if account == nil {
+		return "NULL", "NULL"
+	}
+
+	if account.SubAccount == nil {
+		return sqlString(account.Address), "NULL"
+	}
+
+	return sqlString(account.Address), sqlString(account.SubAccount.Address)
+}
+
+// getBlockAtIndex returns the block stored at index
+// (or nil if there is no such block).
+func getBlockAtIndex(
+	ctx context.Context,
+	blockStorage *modules.BlockStorage,
+	index int64,
+) (*types.Block, error) {
+	block, err := blockStorage.GetBlock(ctx, &types.PartialBlockIdentifier{Index: &index})
+	if errors.Is(err, storageErrs.ErrBlockNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: unable to get block %d", err, index)
+	}
+
+	return block, nil
+}
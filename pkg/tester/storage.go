@@ -0,0 +1,226 @@
+// Copyright 2022 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tester
+
+import (
+	"context"
+	"crypto/aes"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/coinbase/rosetta-cli/configuration"
+
+	"github.com/coinbase/rosetta-sdk-go/storage/database"
+	"github.com/dgraph-io/badger/v2"
+	"github.com/dgraph-io/badger/v2/options"
+)
+
+var (
+	badgerCompression = map[configuration.BadgerCompression]options.CompressionType{
+		configuration.BadgerCompressionNone:   options.None,
+		configuration.BadgerCompressionSnappy: options.Snappy,
+		configuration.BadgerCompressionZSTD:   options.ZSTD,
+	}
+
+	badgerLoadingModes = map[configuration.BadgerLoadingMode]options.FileLoadingMode{
+		configuration.BadgerLoadingFileIO:    options.FileIO,
+		configuration.BadgerLoadingMemoryMap: options.MemoryMap,
+		configuration.BadgerLoadingRAM:       options.LoadToRAM,
+	}
+)
+
+// storageOptions returns the database.BadgerOption used to
+// open the database at dataPath, applying any Badger
+// settings overridden in the configuration.
+func storageOptions(
+	config *configuration.Configuration,
+	dataPath string,
+) ([]database.BadgerOption, error) {
+	opts := []database.BadgerOption{}
+	if config.CompressionDisabled {
+		opts = append(opts, database.WithoutCompression())
+	}
+
+	if !config.MemoryLimitDisabled && config.Badger == nil &&
+		!config.InMemoryStorage && !config.ReadOnlyStorage {
+		return opts, nil
+	}
+
+	settings := database.DefaultBadgerOptions(dataPath)
+	if config.MemoryLimitDisabled {
+		settings = database.PerformanceBadgerOptions(dataPath)
+	}
+
+	if config.Badger != nil {
+		var err error
+		settings, err = applyBadgerConfiguration(settings, config.Badger)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if config.InMemoryStorage {
+		// Badger refuses to open in memory if a directory is set.
+		settings.InMemory = true
+		settings.Dir = ""
+		settings.ValueDir = ""
+	}
+
+	if config.ReadOnlyStorage {
+		// Badger holds an exclusive lock on the directory while it is
+		// open for writing, so the lock is bypassed to read storage
+		// while a check is in progress. Badger refuses to open
+		// read-only if there are writes that have not been flushed
+		// to tables yet.
+		settings.ReadOnly = true
+		settings.BypassLockGuard = true
+	}
+
+	return append(opts, database.WithCustomSettings(settings)), nil
+}
+
+// assertWritableStorage returns an error if
+// storage is configured to be opened read-only.
+func assertWritableStorage(config *configuration.Configuration) error {
+	if config.ReadOnlyStorage {
+		return errors.New("storage cannot be modified when read_only_storage is enabled")
+	}
+
+	return nil
+}
+
+// storageContext returns the context used to open a database.
+// Value log garbage collection runs until this context is done,
+// so it is already done if garbage collection is disabled (or
+// storage is read-only, where garbage collection is rejected).
+func storageContext(
+	ctx context.Context,
+	config *configuration.Configuration,
+) context.Context {
+	if !config.ReadOnlyStorage &&
+		(config.Badger == nil || !config.Badger.ValueLogGCDisabled) {
+		return ctx
+	}
+
+	gcCtx, cancel := context.WithCancel(ctx)
+	cancel()
+
+	return gcCtx
+}
+
+// applyBadgerConfiguration overrides settings with
+// all populated fields in the *BadgerConfiguration.
+func applyBadgerConfiguration(
+	settings badger.Options,
+	config *configuration.BadgerConfiguration,
+) (badger.Options, error) {
+	if config.MaxTableSize != nil {
+		settings.MaxTableSize = *config.MaxTableSize
+	}
+
+	if config.ValueLogFileSize != nil {
+		settings.ValueLogFileSize = *config.ValueLogFileSize
+	}
+
+	if compression, ok := badgerCompression[config.Compression]; ok {
+		settings.Compression = compression
+	}
+
+	if mode, ok := badgerLoadingModes[config.TableLoadingMode]; ok {
+		settings.TableLoadingMode = mode
+	}
+
+	if mode, ok := badgerLoadingModes[config.ValueLogLoadingMode]; ok {
+		settings.ValueLogLoadingMode = mode
+	}
+
+	if config.IndexCacheSize != nil {
+		settings.IndexCacheSize = *config.IndexCacheSize
+	}
+
+	if config.BlockCacheSize != nil {
+		settings.BlockCacheSize = *config.BlockCacheSize
+	}
+
+	if config.KeepL0InMemory != nil {
+		settings.KeepL0InMemory = *config.KeepL0InMemory
+	}
+
+	if config.LoadBloomsOnOpen != nil {
+		settings.LoadBloomsOnOpen = *config.LoadBloomsOnOpen
+	}
+
+	if config.Encryption != nil {
+		key, err := badgerEncryptionKey(config.Encryption)
+		if err != nil {
+			return settings, err
+		}
+
+		settings.EncryptionKey = key
+		if config.Encryption.KeyRotationDuration > 0 {
+			settings.EncryptionKeyRotationDuration = time.Duration(
+				config.Encryption.KeyRotationDuration,
+			) * time.Second
+		}
+
+		// Badger requires an index cache when
+		// encryption is enabled.
+		if settings.IndexCacheSize == 0 {
+			settings.IndexCacheSize = database.DefaultIndexCacheSize
+		}
+	}
+
+	return settings, nil
+}
+
+// badgerEncryptionKey loads the hex-encoded encryption key
+// from the configured environment variable or file.
+func badgerEncryptionKey(config *configuration.BadgerEncryptionConfiguration) ([]byte, error) {
+	var encoded string
+	if len(config.KeyEnv) > 0 {
+		value, ok := os.LookupEnv(config.KeyEnv)
+		if !ok {
+			return nil, fmt.Errorf("encryption key environment variable %s is not set", config.KeyEnv)
+		}
+
+		encoded = value
+	} else {
+		b, err := ioutil.ReadFile(path.Clean(config.KeyFile))
+		if err != nil {
+			return nil, fmt.Errorf("%w: unable to read encryption key file", err)
+		}
+
+		encoded = string(b)
+	}
+
+	key, err := hex.DecodeString(strings.TrimSpace(encoded))
+	if err != nil {
+		return nil, fmt.Errorf("%w: encryption key must be hex-encoded", err)
+	}
+
+	// Badger encrypts with AES, so the key is
+	// valid if it is a valid AES key.
+	if _, err := aes.NewCipher(key); err != nil {
+		return nil, fmt.Errorf("%w: encryption key must be 16, 24, or 32 bytes", err)
+	}
+
+	return key, nil
+}
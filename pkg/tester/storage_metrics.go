@@ -0,0 +1,96 @@
+// Copyright 2022 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tester
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/coinbase/rosetta-cli/pkg/results"
+)
+
+const (
+	// tableExtension is the extension of Badger table files.
+	tableExtension = ".sst"
+
+	// valueLogExtension is the extension of Badger value log files.
+	valueLogExtension = ".vlog"
+)
+
+// storageMetrics computes *results.CheckDataStorage from the
+// files of check:data storage. Badger only deletes value logs
+// after garbage collection rewrites them, so the value logs
+// that disappear between calls are counted as reclaimed.
+type storageMetrics struct {
+	dataPath  string
+	valueLogs map[string]struct{}
+	reclaimed int
+}
+
+func newStorageMetrics(dataPath string) *storageMetrics {
+	return &storageMetrics{
+		dataPath:  dataPath,
+		valueLogs: map[string]struct{}{},
+	}
+}
+
+// compute returns the current *results.CheckDataStorage. compute
+// must not be called concurrently.
+func (m *storageMetrics) compute() (*results.CheckDataStorage, error) {
+	storage := &results.CheckDataStorage{}
+	valueLogs := map[string]struct{}{}
+	err := filepath.Walk(m.dataPath, func(filePath string, info os.FileInfo, err error) error {
+		// Files may be removed by compaction or
+		// garbage collection during the walk.
+		if os.IsNotExist(err) {
+			return nil
+		}
+
+		if err != nil {
+			return err
+		}
+
+		if info.IsDir() {
+			return nil
+		}
+
+		storage.Size += info.Size()
+		switch {
+		case strings.HasSuffix(filePath, tableExtension):
+			storage.Tables++
+			storage.TablesSize += info.Size()
+		case strings.HasSuffix(filePath, valueLogExtension):
+			storage.ValueLogs++
+			storage.ValueLogSize += info.Size()
+			valueLogs[filePath] = struct{}{}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for filePath := range m.valueLogs {
+		if _, ok := valueLogs[filePath]; !ok {
+			m.reclaimed++
+		}
+	}
+	m.valueLogs = valueLogs
+	storage.ValueLogsReclaimed = m.reclaimed
+
+	return storage, nil
+}
@@ -0,0 +1,167 @@
+// Copyright 2022 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tracing
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+
+	"github.com/coinbase/rosetta-sdk-go/storage/database"
+	"github.com/coinbase/rosetta-sdk-go/storage/modules"
+	"github.com/coinbase/rosetta-sdk-go/types"
+	"github.com/neilotoole/errgroup"
+)
+
+// transport records a *Span for each request
+// made to a Rosetta implementation.
+type transport struct {
+	tracer *Tracer
+	base   http.RoundTripper
+}
+
+// requestBlockIndex returns the index of the block_identifier
+// in a request body (like /block or /account/balance), if any.
+func requestBlockIndex(req *http.Request) (int64, bool) {
+	if req.GetBody == nil {
+		return 0, false
+	}
+
+	body, err := req.GetBody()
+	if err != nil {
+		return 0, false
+	}
+	defer body.Close()
+
+	b, err := ioutil.ReadAll(body)
+	if err != nil {
+		return 0, false
+	}
+
+	var request struct {
+		BlockIdentifier *types.PartialBlockIdentifier `json:"block_identifier"`
+	}
+	if err := json.Unmarshal(b, &request); err != nil {
+		return 0, false
+	}
+
+	if request.BlockIdentifier == nil || request.BlockIdentifier.Index == nil {
+		return 0, false
+	}
+
+	return *request.BlockIdentifier.Index, true
+}
+
+// RoundTrip implements the http.RoundTripper interface.
+func (t *transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	name := fmt.Sprintf("fetch %s", req.URL.Path)
+	attributes := []Attribute{
+		String("http.method", req.Method),
+		String("http.url", req.URL.String()),
+	}
+
+	var span *Span
+	if index, ok := requestBlockIndex(req); ok {
+		_, span = t.tracer.StartBlockSpan(req.Context(), name, index, attributes...)
+	} else {
+		_, span = t.tracer.StartSpan(req.Context(), name, attributes...)
+	}
+	span.kind = spanKindClient
+
+	resp, err := t.base.RoundTrip(req)
+	if err != nil {
+		span.End(err)
+		return nil, err
+	}
+
+	span.SetAttributes(Int64("http.status_code", int64(resp.StatusCode)))
+	if resp.StatusCode != http.StatusOK {
+		span.End(fmt.Errorf("received status %d", resp.StatusCode))
+	} else {
+		span.End(nil)
+	}
+
+	return resp, nil
+}
+
+// Transport returns an http.RoundTripper that sends requests
+// with base and records a *Span for each request. If the *Tracer
+// is nil, base is returned.
+func (t *Tracer) Transport(base http.RoundTripper) http.RoundTripper {
+	if t == nil {
+		return base
+	}
+
+	return &transport{
+		tracer: t,
+		base:   base,
+	}
+}
+
+var _ modules.BlockWorker = (*BlockWorker)(nil)
+
+// BlockWorker implements the modules.BlockWorker interface. It
+// records a *Span covering the storage of each block. It must be
+// the first worker provided to BlockStorage so that the *Span
+// covers all other workers and the commit of the block.
+type BlockWorker struct {
+	tracer *Tracer
+}
+
+// NewBlockWorker returns a new *BlockWorker.
+func NewBlockWorker(tracer *Tracer) *BlockWorker {
+	return &BlockWorker{tracer: tracer}
+}
+
+func (w *BlockWorker) blockSpan(
+	ctx context.Context,
+	name string,
+	block *types.Block,
+) database.CommitWorker {
+	_, span := w.tracer.StartBlockSpan(
+		ctx,
+		name,
+		block.BlockIdentifier.Index,
+		String("block.hash", block.BlockIdentifier.Hash),
+		Int64("block.transactions", int64(len(block.Transactions))),
+	)
+
+	return func(ctx context.Context) error {
+		span.End(nil)
+		return nil
+	}
+}
+
+// AddingBlock is called by BlockStorage when adding a block.
+func (w *BlockWorker) AddingBlock(
+	ctx context.Context,
+	g *errgroup.Group,
+	block *types.Block,
+	dbTx database.Transaction,
+) (database.CommitWorker, error) {
+	return w.blockSpan(ctx, "store block", block), nil
+}
+
+// RemovingBlock is called by BlockStorage when removing a block.
+func (w *BlockWorker) RemovingBlock(
+	ctx context.Context,
+	g *errgroup.Group,
+	block *types.Block,
+	dbTx database.Transaction,
+) (database.CommitWorker, error) {
+	return w.blockSpan(ctx, "remove block", block), nil
+}
@@ -0,0 +1,363 @@
+// Copyright 2022 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tracing
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/coinbase/rosetta-cli/configuration"
+)
+
+const (
+	// tracesPath is appended to the configured
+	// endpoint when exporting spans.
+	tracesPath = "/v1/traces"
+
+	// exportTimeout is the maximum amount of time
+	// to wait for the collector to accept spans.
+	exportTimeout = 10 * time.Second
+
+	// maxQueuedSpans is the maximum number of spans to
+	// hold in memory if the collector is unavailable.
+	maxQueuedSpans = 100000
+
+	// traceIDSize and spanIDSize are the
+	// sizes (in bytes) of OpenTelemetry identifiers.
+	traceIDSize = 16
+	spanIDSize  = 8
+
+	spanKindInternal = 1
+	spanKindClient   = 3
+
+	statusCodeError = 2
+)
+
+type spanContextKey struct{}
+
+// Attribute is a key/value pair describing a *Span.
+type Attribute struct {
+	Key   string         `json:"key"`
+	Value attributeValue `json:"value"`
+}
+
+type attributeValue struct {
+	StringValue *string `json:"stringValue,omitempty"`
+	IntValue    *string `json:"intValue,omitempty"`
+}
+
+// String returns a string Attribute.
+func String(key string, value string) Attribute {
+	return Attribute{Key: key, Value: attributeValue{StringValue: &value}}
+}
+
+// Int64 returns an integer Attribute.
+func Int64(key string, value int64) Attribute {
+	v := strconv.FormatInt(value, 10)
+	return Attribute{Key: key, Value: attributeValue{IntValue: &v}}
+}
+
+// Span is a single timed operation. All methods
+// on a nil *Span are no-ops.
+type Span struct {
+	tracer *Tracer
+
+	traceID    string
+	spanID     string
+	parentID   string
+	name       string
+	kind       int
+	start      time.Time
+	attributes []Attribute
+}
+
+// SetAttributes adds attributes to the *Span.
+func (s *Span) SetAttributes(attributes ...Attribute) {
+	if s == nil {
+		return
+	}
+
+	s.attributes = append(s.attributes, attributes...)
+}
+
+// End completes the *Span and queues it for export. If err
+// is not nil, the *Span is marked as failed.
+func (s *Span) End(err error) {
+	if s == nil {
+		return
+	}
+
+	exported := &otlpSpan{
+		TraceID:      s.traceID,
+		SpanID:       s.spanID,
+		ParentSpanID: s.parentID,
+		Name:         s.name,
+		Kind:         s.kind,
+		StartTime:    strconv.FormatInt(s.start.UnixNano(), 10),
+		EndTime:      strconv.FormatInt(time.Now().UnixNano(), 10),
+		Attributes:   s.attributes,
+	}
+	if err != nil {
+		exported.Status = &otlpStatus{Code: statusCodeError, Message: err.Error()}
+	}
+
+	s.tracer.enqueue(exported)
+}
+
+// Tracer records spans and periodically exports them to an
+// OpenTelemetry collector using OTLP/HTTP (JSON encoding). All
+// methods on a nil *Tracer are no-ops so that callers do not
+// need to check if tracing is enabled.
+type Tracer struct {
+	config *configuration.TracingConfiguration
+	client *http.Client
+
+	queueLock sync.Mutex
+	queue     []*otlpSpan
+}
+
+// NewTracer returns a new *Tracer. If config is nil,
+// tracing is disabled and nil is returned.
+func NewTracer(config *configuration.TracingConfiguration) *Tracer {
+	if config == nil {
+		return nil
+	}
+
+	return &Tracer{
+		config: config,
+		client: &http.Client{Timeout: exportTimeout},
+	}
+}
+
+func randomID(size int) string {
+	b := make([]byte, size)
+	if _, err := rand.Read(b); err != nil {
+		log.Printf("%s: unable to generate span identifier\n", err.Error())
+	}
+
+	return hex.EncodeToString(b)
+}
+
+// blockTraceID deterministically derives a trace identifier
+// from a block index so that fetching, storing, and reconciling
+// the same block are grouped in a single trace.
+func blockTraceID(index int64) string {
+	digest := sha256.Sum256([]byte(fmt.Sprintf("block/%d", index)))
+	return hex.EncodeToString(digest[:traceIDSize])
+}
+
+func (t *Tracer) start(
+	ctx context.Context,
+	name string,
+	traceID string,
+	attributes []Attribute,
+) (context.Context, *Span) {
+	span := &Span{
+		tracer:     t,
+		traceID:    traceID,
+		spanID:     randomID(spanIDSize),
+		name:       name,
+		kind:       spanKindInternal,
+		start:      time.Now(),
+		attributes: attributes,
+	}
+
+	if parent, ok := ctx.Value(spanContextKey{}).(*Span); ok {
+		span.traceID = parent.traceID
+		span.parentID = parent.spanID
+	}
+
+	return context.WithValue(ctx, spanContextKey{}, span), span
+}
+
+// StartSpan starts a *Span that is a child of any
+// *Span in the provided context.
+func (t *Tracer) StartSpan(
+	ctx context.Context,
+	name string,
+	attributes ...Attribute,
+) (context.Context, *Span) {
+	if t == nil {
+		return ctx, nil
+	}
+
+	return t.start(ctx, name, randomID(traceIDSize), attributes)
+}
+
+// StartBlockSpan starts a *Span for work on a particular
+// block. If there is no *Span in the provided context, the
+// *Span is added to the trace of the block.
+func (t *Tracer) StartBlockSpan(
+	ctx context.Context,
+	name string,
+	index int64,
+	attributes ...Attribute,
+) (context.Context, *Span) {
+	if t == nil {
+		return ctx, nil
+	}
+
+	attributes = append(attributes, Int64("block.index", index))
+	return t.start(ctx, name, blockTraceID(index), attributes)
+}
+
+func (t *Tracer) enqueue(span *otlpSpan) {
+	t.queueLock.Lock()
+	defer t.queueLock.Unlock()
+
+	if len(t.queue) >= maxQueuedSpans {
+		return
+	}
+
+	t.queue = append(t.queue, span)
+}
+
+// Export sends all queued spans to the collector.
+func (t *Tracer) Export(ctx context.Context) error {
+	if t == nil {
+		return nil
+	}
+
+	t.queueLock.Lock()
+	spans := t.queue
+	t.queue = nil
+	t.queueLock.Unlock()
+
+	if len(spans) == 0 {
+		return nil
+	}
+
+	payload, err := json.Marshal(&otlpRequest{
+		ResourceSpans: []*otlpResourceSpans{
+			{
+				Resource: &otlpResource{
+					Attributes: []Attribute{String("service.name", t.config.ServiceName)},
+				},
+				ScopeSpans: []*otlpScopeSpans{
+					{
+						Scope: &otlpScope{Name: "github.com/coinbase/rosetta-cli"},
+						Spans: spans,
+					},
+				},
+			},
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("%w: unable to encode spans", err)
+	}
+
+	endpoint := fmt.Sprintf("%s%s", strings.TrimSuffix(t.config.Endpoint, "/"), tracesPath)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(payload))
+	if err != nil {
+		return fmt.Errorf("%w: unable to create export request", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range t.config.Headers {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := t.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("%w: unable to export %d spans", err, len(spans))
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("collector returned status %d exporting %d spans", resp.StatusCode, len(spans))
+	}
+
+	return nil
+}
+
+// StartExporter periodically exports queued spans until the
+// context is canceled, at which point any remaining spans
+// are exported.
+func (t *Tracer) StartExporter(ctx context.Context) error {
+	if t == nil {
+		return nil
+	}
+
+	tc := time.NewTicker(time.Duration(t.config.ExportInterval) * time.Second)
+	defer tc.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			exportCtx, cancel := context.WithTimeout(context.Background(), exportTimeout)
+			defer cancel()
+
+			if err := t.Export(exportCtx); err != nil {
+				log.Printf("%s: unable to export final spans\n", err.Error())
+			}
+
+			return ctx.Err()
+		case <-tc.C:
+			if err := t.Export(ctx); err != nil {
+				log.Printf("%s: unable to export spans\n", err.Error())
+			}
+		}
+	}
+}
+
+type otlpRequest struct {
+	ResourceSpans []*otlpResourceSpans `json:"resourceSpans"`
+}
+
+type otlpResourceSpans struct {
+	Resource   *otlpResource     `json:"resource"`
+	ScopeSpans []*otlpScopeSpans `json:"scopeSpans"`
+}
+
+type otlpResource struct {
+	Attributes []Attribute `json:"attributes"`
+}
+
+type otlpScopeSpans struct {
+	Scope *otlpScope  `json:"scope"`
+	Spans []*otlpSpan `json:"spans"`
+}
+
+type otlpScope struct {
+	Name string `json:"name"`
+}
+
+type otlpSpan struct {
+	TraceID      string      `json:"traceId"`
+	SpanID       string      `json:"spanId"`
+	ParentSpanID string      `json:"parentSpanId,omitempty"`
+	Name         string      `json:"name"`
+	Kind         int         `json:"kind"`
+	StartTime    string      `json:"startTimeUnixNano"`
+	EndTime      string      `json:"endTimeUnixNano"`
+	Attributes   []Attribute `json:"attributes,omitempty"`
+	Status       *otlpStatus `json:"status,omitempty"`
+}
+
+type otlpStatus struct {
+	Code    int    `json:"code"`
+	Message string `json:"message,omitempty"`
+}
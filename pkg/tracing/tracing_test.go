@@ -0,0 +1,78 @@
+// Copyright 2022 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tracing
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/coinbase/rosetta-cli/configuration"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExport(t *testing.T) {
+	var received otlpRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, tracesPath, r.URL.Path)
+		assert.Equal(t, "secret", r.Header.Get("Authorization"))
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&received))
+	}))
+	defer server.Close()
+
+	tracer := NewTracer(&configuration.TracingConfiguration{
+		Endpoint:    server.URL,
+		ServiceName: "test",
+		Headers:     map[string]string{"Authorization": "secret"},
+	})
+
+	ctx := context.Background()
+	ctx, parent := tracer.StartBlockSpan(ctx, "parent", 10)
+	_, child := tracer.StartSpan(ctx, "child")
+	child.End(errors.New("failed"))
+	parent.End(nil)
+
+	assert.NoError(t, tracer.Export(ctx))
+	assert.Len(t, received.ResourceSpans, 1)
+	spans := received.ResourceSpans[0].ScopeSpans[0].Spans
+	assert.Len(t, spans, 2)
+
+	assert.Equal(t, "child", spans[0].Name)
+	assert.Equal(t, blockTraceID(10), spans[0].TraceID)
+	assert.Equal(t, spans[1].SpanID, spans[0].ParentSpanID)
+	assert.Equal(t, statusCodeError, spans[0].Status.Code)
+
+	assert.Equal(t, "parent", spans[1].Name)
+	assert.Equal(t, blockTraceID(10), spans[1].TraceID)
+	assert.Empty(t, spans[1].ParentSpanID)
+	assert.Nil(t, spans[1].Status)
+
+	// Nothing is exported when the queue is empty.
+	received = otlpRequest{}
+	assert.NoError(t, tracer.Export(ctx))
+	assert.Nil(t, received.ResourceSpans)
+}
+
+func TestNilTracer(t *testing.T) {
+	var tracer *Tracer
+	ctx, span := tracer.StartBlockSpan(context.Background(), "noop", 1)
+	assert.Nil(t, span)
+	span.End(nil)
+	assert.NoError(t, tracer.Export(ctx))
+}
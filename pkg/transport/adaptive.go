@@ -0,0 +1,145 @@
+// Copyright 2022 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package transport
+
+import (
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/coinbase/rosetta-cli/configuration"
+)
+
+const (
+	// adaptiveWindow is the number of requests observed
+	// before the limit of an *AdaptiveLimiter is adjusted.
+	adaptiveWindow = 20
+
+	// blockPath and blockTransactionPath are the
+	// requests limited by an *AdaptiveLimiter.
+	blockPath            = "/block"
+	blockTransactionPath = "/block/transaction"
+)
+
+// AdaptiveLimiter implements the http.RoundTripper interface. It
+// limits the number of concurrent /block (and /block/transaction)
+// requests. After every window of requests, the limit is halved if
+// any request failed or average latency exceeded the target and is
+// increased by one if average latency was below half of the target.
+type AdaptiveLimiter struct {
+	base          http.RoundTripper
+	targetLatency time.Duration
+	minLimit      int64
+	maxLimit      int64
+
+	lock     sync.Mutex
+	cond     *sync.Cond
+	limit    int64
+	inFlight int64
+
+	requests int64
+	failures int64
+	latency  time.Duration
+}
+
+// NewAdaptiveLimiter returns a new *AdaptiveLimiter that
+// starts at (and never exceeds) maxLimit concurrent requests.
+func NewAdaptiveLimiter(
+	config *configuration.AdaptiveConcurrencyConfiguration,
+	maxLimit int64,
+	base http.RoundTripper,
+) *AdaptiveLimiter {
+	l := &AdaptiveLimiter{
+		base:          base,
+		targetLatency: time.Duration(config.TargetLatency) * time.Millisecond,
+		minLimit:      config.MinConcurrency,
+		maxLimit:      maxLimit,
+		limit:         maxLimit,
+	}
+	l.cond = sync.NewCond(&l.lock)
+
+	return l
+}
+
+// Limit returns the current limit of concurrent requests.
+func (l *AdaptiveLimiter) Limit() int64 {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	return l.limit
+}
+
+// RoundTrip implements the http.RoundTripper interface.
+func (l *AdaptiveLimiter) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.URL.Path != blockPath && req.URL.Path != blockTransactionPath {
+		return l.base.RoundTrip(req)
+	}
+
+	l.acquire()
+	start := time.Now()
+	resp, err := l.base.RoundTrip(req)
+	l.release(time.Since(start), err != nil || resp.StatusCode != http.StatusOK)
+
+	return resp, err
+}
+
+// acquire blocks until fewer than limit requests are in flight.
+func (l *AdaptiveLimiter) acquire() {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	for l.inFlight >= l.limit {
+		l.cond.Wait()
+	}
+	l.inFlight++
+}
+
+// release records the result of a request and
+// adjusts the limit at the end of each window.
+func (l *AdaptiveLimiter) release(latency time.Duration, failed bool) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	l.inFlight--
+	l.requests++
+	l.latency += latency
+	if failed {
+		l.failures++
+	}
+
+	if l.requests >= adaptiveWindow {
+		l.adjust()
+	}
+
+	l.cond.Broadcast()
+}
+
+// adjust must be called while holding lock.
+func (l *AdaptiveLimiter) adjust() {
+	average := l.latency / time.Duration(l.requests)
+	switch {
+	case l.failures > 0 || average > l.targetLatency:
+		l.limit /= 2
+		if l.limit < l.minLimit {
+			l.limit = l.minLimit
+		}
+	case average < l.targetLatency/2 && l.limit < l.maxLimit:
+		l.limit++
+	}
+
+	l.requests = 0
+	l.failures = 0
+	l.latency = 0
+}
@@ -0,0 +1,70 @@
+// Copyright 2022 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package transport
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/coinbase/rosetta-cli/configuration"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type statusRoundTripper struct {
+	status int
+}
+
+func (s *statusRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	return &http.Response{StatusCode: s.status, Request: req}, nil
+}
+
+func TestAdaptiveLimiter(t *testing.T) {
+	base := &statusRoundTripper{status: http.StatusInternalServerError}
+	l := NewAdaptiveLimiter(
+		&configuration.AdaptiveConcurrencyConfiguration{
+			TargetLatency:  1000,
+			MinConcurrency: 4,
+		},
+		16,
+		base,
+	)
+	assert.Equal(t, int64(16), l.Limit())
+
+	roundTrip := func(path string, count int) {
+		for i := 0; i < count; i++ {
+			_, err := l.RoundTrip(httptest.NewRequest(http.MethodPost, path, nil))
+			assert.NoError(t, err)
+		}
+	}
+
+	// Other requests are not limited
+	roundTrip("/network/status", adaptiveWindow)
+	assert.Equal(t, int64(16), l.Limit())
+
+	// Failures halve the limit (down to the minimum)
+	roundTrip(blockPath, adaptiveWindow)
+	assert.Equal(t, int64(8), l.Limit())
+	roundTrip(blockTransactionPath, 2*adaptiveWindow)
+	assert.Equal(t, int64(4), l.Limit())
+
+	// Fast requests increase the limit (up to the maximum)
+	base.status = http.StatusOK
+	roundTrip(blockPath, 3*adaptiveWindow)
+	assert.Equal(t, int64(7), l.Limit())
+	roundTrip(blockPath, 20*adaptiveWindow)
+	assert.Equal(t, int64(16), l.Limit())
+}
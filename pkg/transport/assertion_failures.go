@@ -0,0 +1,128 @@
+// Copyright 2022 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package transport
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/coinbase/rosetta-sdk-go/asserter"
+	"github.com/coinbase/rosetta-sdk-go/types"
+)
+
+// AssertionFailures is an http.RoundTripper that asserts each
+// /block response before the fetcher does. If a block fails
+// assertion, the failure is recorded and the transactions (and
+// other transactions) of the block are removed from the response
+// so syncing can continue. Blocks that still fail assertion
+// without their transactions are passed through unmodified.
+type AssertionFailures struct {
+	base http.RoundTripper
+
+	lock     sync.Mutex
+	asserter *asserter.Asserter
+	record   func(block *types.BlockIdentifier, err error)
+}
+
+// NewAssertionFailures returns a new *AssertionFailures that
+// sends all requests with base. Responses are passed through
+// unmodified until Start is called.
+func NewAssertionFailures(base http.RoundTripper) *AssertionFailures {
+	return &AssertionFailures{
+		base: base,
+	}
+}
+
+// Start asserts all subsequent /block responses with asserter
+// and calls record with each block that fails assertion.
+func (a *AssertionFailures) Start(
+	asserter *asserter.Asserter,
+	record func(block *types.BlockIdentifier, err error),
+) {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+
+	a.asserter = asserter
+	a.record = record
+}
+
+// RoundTrip sends req with base and removes the transactions
+// from the response if the block fails assertion.
+func (a *AssertionFailures) RoundTrip(req *http.Request) (*http.Response, error) {
+	a.lock.Lock()
+	blockAsserter, record := a.asserter, a.record
+	a.lock.Unlock()
+
+	resp, err := a.base.RoundTrip(req)
+	if err != nil ||
+		blockAsserter == nil ||
+		resp.StatusCode != http.StatusOK ||
+		!strings.HasSuffix(req.URL.Path, blockPath) {
+		return resp, err
+	}
+
+	body, err := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, fmt.Errorf("%w: unable to read block response", err)
+	}
+
+	setBody(resp, a.assert(blockAsserter, record, body))
+	return resp, nil
+}
+
+// assert returns body without the transactions of the
+// block if the block fails assertion with them but not
+// without them (otherwise body is returned).
+func (a *AssertionFailures) assert(
+	blockAsserter *asserter.Asserter,
+	record func(block *types.BlockIdentifier, err error),
+	body []byte,
+) []byte {
+	var response types.BlockResponse
+	if err := json.Unmarshal(body, &response); err != nil || response.Block == nil {
+		return body
+	}
+
+	assertionErr := blockAsserter.Block(response.Block)
+	if assertionErr == nil {
+		return body
+	}
+
+	stripped, err := withoutTransactions(body)
+	if err != nil {
+		return body
+	}
+
+	var strippedResponse types.BlockResponse
+	if err := json.Unmarshal(stripped, &strippedResponse); err != nil ||
+		blockAsserter.Block(strippedResponse.Block) != nil {
+		return body
+	}
+
+	log.Printf(
+		"%s: continuing without the transactions of block %s\n",
+		assertionErr.Error(),
+		types.PrintStruct(response.Block.BlockIdentifier),
+	)
+	record(response.Block.BlockIdentifier, assertionErr)
+
+	return stripped
+}
@@ -0,0 +1,100 @@
+// Copyright 2022 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package transport
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/coinbase/rosetta-sdk-go/asserter"
+	"github.com/coinbase/rosetta-sdk-go/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAssertionFailures(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var request types.BlockRequest
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&request))
+
+		// The transaction of block 2 is malformed (it has no hash).
+		index := *request.BlockIdentifier.Index
+		txHash := fmt.Sprintf("tx %d", index)
+		if index == 2 {
+			txHash = ""
+		}
+
+		fmt.Fprintf(
+			w,
+			`{"block":{"block_identifier":{"index":%d,"hash":"block %d"},"parent_block_identifier":{"index":%d,"hash":"block %d"},"timestamp":1600000000000,"transactions":[{"transaction_identifier":{"hash":"%s"},"operations":[]}]}}`,
+			index,
+			index,
+			index-1,
+			index-1,
+			txHash,
+		)
+	}))
+	defer server.Close()
+
+	a, err := asserter.NewClientWithOptions(
+		&types.NetworkIdentifier{Blockchain: "bitcoin", Network: "mainnet"},
+		&types.BlockIdentifier{Index: 0, Hash: "block 0"},
+		[]string{"Transfer"},
+		[]*types.OperationStatus{{Status: "Success", Successful: true}},
+		nil,
+		nil,
+		&asserter.Validations{Enabled: false},
+	)
+	assert.NoError(t, err)
+
+	assertionFailures := NewAssertionFailures(http.DefaultTransport)
+	client := NewHTTPClient(0, assertionFailures)
+	fetch := func(index int64) *types.Block {
+		resp, err := client.Post(
+			server.URL+"/block",
+			"application/json",
+			strings.NewReader(fmt.Sprintf(`{"block_identifier":{"index":%d}}`, index)),
+		)
+		assert.NoError(t, err)
+		defer resp.Body.Close()
+
+		var response types.BlockResponse
+		assert.NoError(t, json.NewDecoder(resp.Body).Decode(&response))
+
+		return response.Block
+	}
+
+	// Responses are not asserted until started.
+	assert.Len(t, fetch(2).Transactions, 1)
+
+	recorded := map[int64]error{}
+	assertionFailures.Start(a, func(block *types.BlockIdentifier, err error) {
+		recorded[block.Index] = err
+	})
+
+	// Blocks that fail assertion are returned without their transactions.
+	block := fetch(2)
+	assert.Empty(t, block.Transactions)
+	assert.NoError(t, a.Block(block))
+	assert.Len(t, recorded, 1)
+	assert.True(t, errors.Is(recorded[2], asserter.ErrTxIdentifierHashMissing))
+
+	assert.Len(t, fetch(3).Transactions, 1)
+	assert.Len(t, recorded, 1)
+}
@@ -0,0 +1,178 @@
+// Copyright 2022 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package transport
+
+import (
+	"bytes"
+	"container/list"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/coinbase/rosetta-cli/configuration"
+)
+
+// BlockCache is an http.RoundTripper that answers /block
+// requests with blocks synced by a running check:data (when
+// available) before sending them to the node. Blocks returned
+// by check:data are held in a size-bounded LRU cache.
+type BlockCache struct {
+	url   string
+	size  int
+	base  http.RoundTripper
+	cache http.RoundTripper
+
+	lock    sync.Mutex
+	entries map[string]*list.Element
+	order   *list.List
+}
+
+type blockCacheEntry struct {
+	key  string
+	body []byte
+}
+
+// NewBlockCache returns a new *BlockCache that sends
+// requests it cannot answer with base.
+func NewBlockCache(
+	config *configuration.BlockCacheConfiguration,
+	base http.RoundTripper,
+) *BlockCache {
+	return &BlockCache{
+		url:     strings.TrimSuffix(config.URL, "/"),
+		size:    config.Size,
+		base:    base,
+		cache:   http.DefaultTransport,
+		entries: map[string]*list.Element{},
+		order:   list.New(),
+	}
+}
+
+// RoundTrip answers /block requests from the cache
+// (or check:data) and sends all other requests with base.
+func (c *BlockCache) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Method != http.MethodPost ||
+		!strings.HasSuffix(req.URL.Path, blockPath) ||
+		req.GetBody == nil {
+		return c.base.RoundTrip(req)
+	}
+
+	body, err := readBody(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if cached, ok := c.get(string(body)); ok {
+		return blockResponse(req, cached), nil
+	}
+
+	if fetched, ok := c.fetch(req, body); ok {
+		c.set(string(body), fetched)
+		return blockResponse(req, fetched), nil
+	}
+
+	return c.base.RoundTrip(req)
+}
+
+// fetch requests a block from check:data. Any failure
+// is treated as a miss so the block is fetched from the node.
+func (c *BlockCache) fetch(req *http.Request, body []byte) ([]byte, bool) {
+	cacheReq, err := http.NewRequestWithContext(
+		req.Context(),
+		http.MethodPost,
+		c.url+blockPath,
+		bytes.NewReader(body),
+	)
+	if err != nil {
+		return nil, false
+	}
+	cacheReq.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.cache.RoundTrip(cacheReq)
+	if err != nil {
+		return nil, false
+	}
+	defer resp.Body.Close()
+
+	fetched, err := ioutil.ReadAll(resp.Body)
+	if err != nil || resp.StatusCode != http.StatusOK {
+		return nil, false
+	}
+
+	return fetched, true
+}
+
+func (c *BlockCache) get(key string) ([]byte, bool) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	element, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+
+	c.order.MoveToFront(element)
+	return element.Value.(*blockCacheEntry).body, true
+}
+
+func (c *BlockCache) set(key string, body []byte) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if element, ok := c.entries[key]; ok {
+		c.order.MoveToFront(element)
+		return
+	}
+
+	c.entries[key] = c.order.PushFront(&blockCacheEntry{key: key, body: body})
+	if c.order.Len() > c.size {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*blockCacheEntry).key)
+	}
+}
+
+// readBody returns the body of req without consuming it.
+func readBody(req *http.Request) ([]byte, error) {
+	body, err := req.GetBody()
+	if err != nil {
+		return nil, fmt.Errorf("%w: unable to read request body", err)
+	}
+	defer body.Close()
+
+	b, err := ioutil.ReadAll(body)
+	if err != nil {
+		return nil, fmt.Errorf("%w: unable to read request body", err)
+	}
+
+	return b, nil
+}
+
+// blockResponse returns a 200 response to req with body.
+func blockResponse(req *http.Request, body []byte) *http.Response {
+	return &http.Response{
+		Status:        "200 OK",
+		StatusCode:    http.StatusOK,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        http.Header{"Content-Type": []string{"application/json; charset=UTF-8"}},
+		Body:          ioutil.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}
+}
@@ -0,0 +1,176 @@
+// Copyright 2022 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package transport
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/coinbase/rosetta-cli/configuration"
+	cliErrs "github.com/coinbase/rosetta-cli/pkg/errors"
+)
+
+// CircuitBreaker is an http.RoundTripper that pauses all
+// requests when the online server appears to be down (after
+// several consecutive requests could not reach it) instead of
+// letting each request retry independently. After a cooldown,
+// a single request probes if the server is back while all
+// other requests remain paused.
+type CircuitBreaker struct {
+	threshold int
+	cooldown  time.Duration
+	budget    int
+	base      http.RoundTripper
+
+	lock      sync.Mutex
+	failures  int
+	probes    int
+	openUntil time.Time
+	probe     chan struct{}
+	exhausted bool
+}
+
+// NewCircuitBreaker returns a new *CircuitBreaker.
+func NewCircuitBreaker(
+	config *configuration.CircuitBreakerConfiguration,
+	base http.RoundTripper,
+) *CircuitBreaker {
+	return &CircuitBreaker{
+		threshold: config.FailureThreshold,
+		cooldown:  time.Duration(config.Cooldown) * time.Second,
+		budget:    config.RetryBudget,
+		base:      base,
+	}
+}
+
+// RoundTrip waits until the circuit is closed (or
+// req is the probe) and sends req with base.
+func (c *CircuitBreaker) RoundTrip(req *http.Request) (*http.Response, error) {
+	for {
+		wait, probing, err := c.acquire()
+		if err != nil {
+			return nil, err
+		}
+
+		if wait == nil {
+			resp, err := c.base.RoundTrip(req)
+			c.record(req.Context(), probing, resp, err)
+			return resp, err
+		}
+
+		select {
+		case <-wait:
+		case <-req.Context().Done():
+			return nil, req.Context().Err()
+		}
+	}
+}
+
+// acquire returns a channel to wait on if the circuit is
+// open (nil if the request can be sent) and a boolean
+// indicating if the request is the probe.
+func (c *CircuitBreaker) acquire() (<-chan struct{}, bool, error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	switch {
+	case c.exhausted:
+		return nil, false, cliErrs.ErrRetryBudgetExhausted
+	case c.openUntil.IsZero():
+		return nil, false, nil
+	case c.probe != nil:
+		return c.probe, false, nil
+	}
+
+	if remaining := time.Until(c.openUntil); remaining > 0 {
+		wait := make(chan struct{})
+		time.AfterFunc(remaining, func() { close(wait) })
+		return wait, false, nil
+	}
+
+	c.probe = make(chan struct{})
+	return nil, true, nil
+}
+
+// record updates the state of the circuit
+// with the result of a request.
+func (c *CircuitBreaker) record(
+	ctx context.Context,
+	probing bool,
+	resp *http.Response,
+	err error,
+) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if probing {
+		defer func() {
+			close(c.probe)
+			c.probe = nil
+		}()
+	}
+
+	// Requests canceled by the caller say
+	// nothing about the server.
+	if err != nil && errors.Is(ctx.Err(), context.Canceled) {
+		return
+	}
+
+	if err == nil && !unavailable(resp.StatusCode) {
+		if !c.openUntil.IsZero() {
+			log.Printf("online server is reachable again, resuming requests\n")
+		}
+
+		c.failures = 0
+		c.probes = 0
+		c.openUntil = time.Time{}
+		return
+	}
+
+	c.failures++
+	switch {
+	case probing:
+		c.probes++
+		if c.budget > 0 && c.probes >= c.budget {
+			c.exhausted = true
+			log.Printf("online server is still unreachable after %d probes, stopping\n", c.probes)
+			return
+		}
+	case !c.openUntil.IsZero() || c.failures < c.threshold:
+		return
+	}
+
+	c.openUntil = time.Now().Add(c.cooldown)
+	log.Printf(
+		"online server is unreachable (%d consecutive failed requests), pausing requests for %s: %s\n",
+		c.failures,
+		c.cooldown,
+		failureReason(resp, err),
+	)
+}
+
+// failureReason describes why a request failed.
+func failureReason(resp *http.Response, err error) string {
+	if err != nil {
+		return err.Error()
+	}
+
+	return fmt.Sprintf("received %s", resp.Status)
+}
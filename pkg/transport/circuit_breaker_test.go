@@ -0,0 +1,54 @@
+// Copyright 2022 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package transport
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/coinbase/rosetta-cli/configuration"
+	cliErrs "github.com/coinbase/rosetta-cli/pkg/errors"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCircuitBreaker(t *testing.T) {
+	sent := 0
+	down := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		sent++
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer down.Close()
+
+	c := NewCircuitBreaker(&configuration.CircuitBreakerConfiguration{
+		FailureThreshold: 2,
+		RetryBudget:      1,
+	}, http.DefaultTransport)
+
+	client := NewHTTPClient(0, c)
+	for i := 0; i < 3; i++ {
+		resp, err := client.Post(down.URL+"/block", "application/json", strings.NewReader("{}"))
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+		resp.Body.Close()
+	}
+
+	_, err := client.Post(down.URL+"/block", "application/json", strings.NewReader("{}"))
+	assert.True(t, errors.Is(err, cliErrs.ErrRetryBudgetExhausted))
+	assert.Equal(t, 3, sent)
+}
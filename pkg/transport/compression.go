@@ -0,0 +1,75 @@
+// Copyright 2022 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package transport
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+)
+
+// RequestCompression is an http.RoundTripper that compresses
+// request bodies larger than a threshold with gzip.
+type RequestCompression struct {
+	threshold int
+	base      http.RoundTripper
+}
+
+// NewRequestCompression returns a new *RequestCompression.
+func NewRequestCompression(threshold int, base http.RoundTripper) *RequestCompression {
+	return &RequestCompression{
+		threshold: threshold,
+		base:      base,
+	}
+}
+
+// RoundTrip compresses the body of req (if it is
+// larger than the threshold) and sends it with base.
+func (c *RequestCompression) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.GetBody == nil || req.ContentLength < int64(c.threshold) ||
+		len(req.Header.Get("Content-Encoding")) > 0 {
+		return c.base.RoundTrip(req)
+	}
+
+	body, err := readBody(req)
+	if err != nil {
+		return nil, err
+	}
+
+	var compressed bytes.Buffer
+	writer := gzip.NewWriter(&compressed)
+	if _, err := writer.Write(body); err != nil {
+		return nil, fmt.Errorf("%w: unable to compress request body", err)
+	}
+	if err := writer.Close(); err != nil {
+		return nil, fmt.Errorf("%w: unable to compress request body", err)
+	}
+
+	// The request is cloned because a RoundTripper
+	// must not modify the request it is given.
+	b := compressed.Bytes()
+	compressedReq := req.Clone(req.Context())
+	compressedReq.Header.Set("Content-Encoding", "gzip")
+	compressedReq.ContentLength = int64(len(b))
+	compressedReq.Body = ioutil.NopCloser(bytes.NewReader(b))
+	compressedReq.GetBody = func() (io.ReadCloser, error) {
+		return ioutil.NopCloser(bytes.NewReader(b)), nil
+	}
+
+	return c.base.RoundTrip(compressedReq)
+}
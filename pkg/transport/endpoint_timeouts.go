@@ -0,0 +1,137 @@
+// Copyright 2022 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package transport
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// EndpointTimeouts is an http.RoundTripper that applies a
+// timeout to each request based on its endpoint. Requests
+// to endpoints without a timeout use the default timeout.
+type EndpointTimeouts struct {
+	defaultTimeout time.Duration
+	timeouts       map[string]time.Duration
+	base           http.RoundTripper
+}
+
+// NewEndpointTimeouts returns a new *EndpointTimeouts. The
+// keys of timeouts are endpoint paths (like "/account/balance")
+// and match any request path ending with the endpoint.
+func NewEndpointTimeouts(
+	defaultTimeout time.Duration,
+	timeouts map[string]time.Duration,
+	base http.RoundTripper,
+) *EndpointTimeouts {
+	return &EndpointTimeouts{
+		defaultTimeout: defaultTimeout,
+		timeouts:       timeouts,
+		base:           base,
+	}
+}
+
+// timeout returns the timeout of the longest
+// endpoint that requestPath ends with.
+func (e *EndpointTimeouts) timeout(requestPath string) time.Duration {
+	timeout := e.defaultTimeout
+	matched := ""
+	for endpoint, endpointTimeout := range e.timeouts {
+		if strings.HasSuffix(requestPath, endpoint) && len(endpoint) > len(matched) {
+			timeout = endpointTimeout
+			matched = endpoint
+		}
+	}
+
+	return timeout
+}
+
+// RoundTrip sends req with base, canceling it if the
+// response body is not read before the timeout.
+func (e *EndpointTimeouts) RoundTrip(req *http.Request) (*http.Response, error) {
+	timeout := e.timeout(req.URL.Path)
+	if timeout <= 0 {
+		return e.base.RoundTrip(req)
+	}
+
+	ctx, cancel := context.WithTimeout(req.Context(), timeout)
+	resp, err := e.base.RoundTrip(req.WithContext(ctx))
+	if err != nil {
+		cancel()
+		return nil, timeoutError(ctx, req.URL.Path, timeout, err)
+	}
+
+	resp.Body = &timeoutBody{
+		ReadCloser: resp.Body,
+		ctx:        ctx,
+		cancel:     cancel,
+		path:       req.URL.Path,
+		timeout:    timeout,
+	}
+
+	return resp, nil
+}
+
+// timeoutBody cancels the context of
+// a request when its body is closed.
+type timeoutBody struct {
+	io.ReadCloser
+
+	ctx     context.Context
+	cancel  context.CancelFunc
+	path    string
+	timeout time.Duration
+}
+
+func (b *timeoutBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return n, timeoutError(b.ctx, b.path, b.timeout, err)
+	}
+
+	return n, err
+}
+
+func (b *timeoutBody) Close() error {
+	defer b.cancel()
+
+	return b.ReadCloser.Close()
+}
+
+// timeoutError describes err as a client timeout if the
+// timeout of ctx expired so that the fetcher retries
+// the request like a request exceeding http_timeout.
+func timeoutError(
+	ctx context.Context,
+	requestPath string,
+	timeout time.Duration,
+	err error,
+) error {
+	if !errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return err
+	}
+
+	return fmt.Errorf(
+		"%w: %s (Client.Timeout exceeded after %s)",
+		err,
+		requestPath,
+		timeout,
+	)
+}
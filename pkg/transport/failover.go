@@ -0,0 +1,159 @@
+// Copyright 2022 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package transport
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync/atomic"
+
+	"github.com/coinbase/rosetta-cli/configuration"
+)
+
+// Failover is an http.RoundTripper that sends requests to
+// one of several Rosetta servers, retrying a request on the
+// next server when a server cannot be reached.
+type Failover struct {
+	endpoints []*url.URL
+	strategy  configuration.OnlineURLStrategy
+	base      http.RoundTripper
+
+	// next is the index of the endpoint that is tried first
+	// (the last available endpoint when using failover and the
+	// next endpoint in the rotation when using round robin).
+	next uint64
+}
+
+// NewFailover returns a new *Failover that rewrites requests
+// addressed to primary to any of primary and urls.
+func NewFailover(
+	primary string,
+	urls []string,
+	strategy configuration.OnlineURLStrategy,
+	base http.RoundTripper,
+) (*Failover, error) {
+	endpoints := make([]*url.URL, 0, len(urls)+1)
+	for _, rawURL := range append([]string{primary}, urls...) {
+		endpoint, err := url.Parse(rawURL)
+		if err != nil {
+			return nil, fmt.Errorf("%w: unable to parse %s", err, rawURL)
+		}
+
+		endpoints = append(endpoints, endpoint)
+	}
+
+	return &Failover{
+		endpoints: endpoints,
+		strategy:  strategy,
+		base:      base,
+	}, nil
+}
+
+// RoundTrip sends req to the first available endpoint.
+func (f *Failover) RoundTrip(req *http.Request) (*http.Response, error) {
+	start := f.start()
+	for i := 0; i < len(f.endpoints); i++ {
+		index := (start + i) % len(f.endpoints)
+		attempt, err := f.rewrite(req, f.endpoints[index], i > 0)
+		if err != nil {
+			return nil, err
+		}
+
+		resp, err := f.base.RoundTrip(attempt)
+		last := i == len(f.endpoints)-1 || !replayable(req) || req.Context().Err() != nil
+		if err == nil && !unavailable(resp.StatusCode) {
+			if f.strategy != configuration.OnlineURLRoundRobin {
+				atomic.StoreUint64(&f.next, uint64(index))
+			}
+
+			return resp, nil
+		}
+
+		if last {
+			return resp, err
+		}
+
+		if resp != nil {
+			_, _ = io.Copy(ioutil.Discard, resp.Body)
+			resp.Body.Close()
+		}
+	}
+
+	// Unreachable because the primary endpoint is always present.
+	return nil, fmt.Errorf("no endpoints available for %s", req.URL.String())
+}
+
+// start returns the index of the first endpoint to try.
+func (f *Failover) start() int {
+	if f.strategy == configuration.OnlineURLRoundRobin {
+		return int((atomic.AddUint64(&f.next, 1) - 1) % uint64(len(f.endpoints)))
+	}
+
+	return int(atomic.LoadUint64(&f.next))
+}
+
+// rewrite returns a copy of req addressed to endpoint. The
+// request path is kept relative to the path of the primary
+// endpoint so that servers mounted under different paths
+// can be used interchangeably.
+func (f *Failover) rewrite(
+	req *http.Request,
+	endpoint *url.URL,
+	replay bool,
+) (*http.Request, error) {
+	attempt := req.Clone(req.Context())
+	attempt.Host = ""
+	attempt.URL.Scheme = endpoint.Scheme
+	attempt.URL.Host = endpoint.Host
+	attempt.URL.Path = endpoint.Path + strings.TrimPrefix(
+		req.URL.Path,
+		f.endpoints[0].Path,
+	)
+	attempt.URL.RawPath = ""
+
+	if replay && req.GetBody != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			return nil, fmt.Errorf("%w: unable to replay request body", err)
+		}
+
+		attempt.Body = body
+	}
+
+	return attempt, nil
+}
+
+// replayable returns a boolean indicating if req can be
+// sent more than once.
+func replayable(req *http.Request) bool {
+	return req.Body == nil || req.Body == http.NoBody || req.GetBody != nil
+}
+
+// unavailable returns a boolean indicating if statusCode
+// indicates the server (rather than the request) is the
+// problem. A 500 is not considered unavailable because
+// Rosetta servers use it to return *types.Error.
+func unavailable(statusCode int) bool {
+	switch statusCode {
+	case http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	default:
+		return false
+	}
+}
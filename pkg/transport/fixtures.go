@@ -0,0 +1,203 @@
+// Copyright 2022 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package transport
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path"
+	"sync"
+
+	"github.com/coinbase/rosetta-cli/configuration"
+	cliErrs "github.com/coinbase/rosetta-cli/pkg/errors"
+)
+
+// fixtureFilePermissions are the permissions of recorded fixtures.
+const fixtureFilePermissions = 0600
+
+// Fixture is a response recorded for a request.
+type Fixture struct {
+	Path       string          `json:"path"`
+	Request    json.RawMessage `json:"request"`
+	StatusCode int             `json:"status_code"`
+	Response   string          `json:"response"`
+}
+
+// Fixtures is an http.RoundTripper that records all responses
+// into a directory or answers all requests with the responses
+// recorded earlier. Requests are identified by their path and
+// body (not by host), so fixtures can be replayed against any
+// online_url. When the same request is sent more than once, the
+// responses are recorded in order and replayed in the same order
+// (repeating the last response once all have been replayed).
+type Fixtures struct {
+	directory string
+	mode      configuration.FixturesMode
+	base      http.RoundTripper
+
+	lock  sync.Mutex
+	sends map[string]int
+}
+
+// NewFixtures returns a new *Fixtures that records the
+// responses of base (base is not used when replaying).
+func NewFixtures(
+	config *configuration.FixturesConfiguration,
+	base http.RoundTripper,
+) (*Fixtures, error) {
+	if config.Mode == configuration.FixturesRecord {
+		if err := os.MkdirAll(config.Directory, os.FileMode(0700)); err != nil {
+			return nil, fmt.Errorf("%w: unable to create fixture directory", err)
+		}
+	}
+
+	return &Fixtures{
+		directory: config.Directory,
+		mode:      config.Mode,
+		base:      base,
+		sends:     map[string]int{},
+	}, nil
+}
+
+// RoundTrip records the response to req or
+// answers req with a recorded response.
+func (f *Fixtures) RoundTrip(req *http.Request) (*http.Response, error) {
+	var body []byte
+	if req.GetBody != nil {
+		var err error
+		body, err = readBody(req)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	key := fixtureKey(req.URL.Path, body)
+	if f.mode == configuration.FixturesReplay {
+		return f.replay(req, key)
+	}
+
+	resp, err := f.base.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	response, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("%w: unable to read response body", err)
+	}
+
+	fixture := &Fixture{
+		Path:       req.URL.Path,
+		StatusCode: resp.StatusCode,
+		Response:   string(response),
+	}
+	if json.Valid(body) {
+		fixture.Request = body
+	}
+
+	if err := f.record(key, fixture); err != nil {
+		return nil, err
+	}
+
+	resp.Body = ioutil.NopCloser(bytes.NewReader(response))
+	return resp, nil
+}
+
+// next returns the number of times
+// key was sent before (and counts it).
+func (f *Fixtures) next(key string) int {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	sent := f.sends[key]
+	f.sends[key]++
+	return sent
+}
+
+// rewind resets the number of times key was sent
+// so the next replay returns the last response.
+func (f *Fixtures) rewind(key string, sent int) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	f.sends[key] = sent
+}
+
+func (f *Fixtures) record(key string, fixture *Fixture) error {
+	b, err := json.MarshalIndent(fixture, "", " ")
+	if err != nil {
+		return fmt.Errorf("%w: unable to marshal fixture", err)
+	}
+
+	// Fixtures are written to a temporary file and renamed
+	// so that an interrupted recording never leaves a
+	// partial fixture behind.
+	file := f.fixturePath(key, f.next(key))
+	if err := ioutil.WriteFile(file+".tmp", b, os.FileMode(fixtureFilePermissions)); err != nil {
+		return fmt.Errorf("%w: unable to write fixture", err)
+	}
+
+	if err := os.Rename(file+".tmp", file); err != nil {
+		return fmt.Errorf("%w: unable to write fixture", err)
+	}
+
+	return nil
+}
+
+func (f *Fixtures) replay(req *http.Request, key string) (*http.Response, error) {
+	sent := f.next(key)
+
+	b, err := ioutil.ReadFile(f.fixturePath(key, sent))
+	if os.IsNotExist(err) && sent > 0 {
+		// Repeat the last response once all
+		// recorded responses have been replayed.
+		f.rewind(key, sent)
+		b, err = ioutil.ReadFile(f.fixturePath(key, sent-1))
+	}
+	if os.IsNotExist(err) {
+		return nil, fmt.Errorf("%w: %s", cliErrs.ErrFixtureNotFound, req.URL.Path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: unable to read fixture", err)
+	}
+
+	var fixture Fixture
+	if err := json.Unmarshal(b, &fixture); err != nil {
+		return nil, fmt.Errorf("%w: unable to unmarshal fixture", err)
+	}
+
+	resp := blockResponse(req, []byte(fixture.Response))
+	resp.StatusCode = fixture.StatusCode
+	resp.Status = fmt.Sprintf("%d %s", fixture.StatusCode, http.StatusText(fixture.StatusCode))
+	return resp, nil
+}
+
+func (f *Fixtures) fixturePath(key string, sent int) string {
+	return path.Join(f.directory, fmt.Sprintf("%s-%d.json", key, sent))
+}
+
+// fixtureKey returns a deterministic
+// key for requestPath and body.
+func fixtureKey(requestPath string, body []byte) string {
+	hash := sha256.Sum256(append([]byte(requestPath+"\n"), body...))
+	return hex.EncodeToString(hash[:])
+}
@@ -0,0 +1,85 @@
+// Copyright 2022 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package transport
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/coinbase/rosetta-cli/configuration"
+	cliErrs "github.com/coinbase/rosetta-cli/pkg/errors"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFixtures(t *testing.T) {
+	directory, err := ioutil.TempDir("", "fixtures")
+	assert.NoError(t, err)
+	defer os.RemoveAll(directory)
+
+	sent := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		sent++
+		fmt.Fprintf(w, `{"index":%d}`, sent)
+	}))
+	defer server.Close()
+
+	post := func(f *Fixtures, path string) (string, error) {
+		resp, err := NewHTTPClient(0, f).Post(
+			server.URL+path,
+			"application/json",
+			strings.NewReader("{}"),
+		)
+		if err != nil {
+			return "", err
+		}
+		defer resp.Body.Close()
+
+		b, err := ioutil.ReadAll(resp.Body)
+		return string(b), err
+	}
+
+	recorder, err := NewFixtures(&configuration.FixturesConfiguration{
+		Directory: directory,
+		Mode:      configuration.FixturesRecord,
+	}, http.DefaultTransport)
+	assert.NoError(t, err)
+	for i := 1; i <= 2; i++ {
+		body, err := post(recorder, "/network/status")
+		assert.NoError(t, err)
+		assert.Equal(t, fmt.Sprintf(`{"index":%d}`, i), body)
+	}
+
+	server.Close()
+	replayer, err := NewFixtures(&configuration.FixturesConfiguration{
+		Directory: directory,
+		Mode:      configuration.FixturesReplay,
+	}, nil)
+	assert.NoError(t, err)
+	for _, expected := range []string{`{"index":1}`, `{"index":2}`, `{"index":2}`} {
+		body, err := post(replayer, "/network/status")
+		assert.NoError(t, err)
+		assert.Equal(t, expected, body)
+	}
+
+	_, err = post(replayer, "/block")
+	assert.True(t, errors.Is(err, cliErrs.ErrFixtureNotFound))
+}
@@ -0,0 +1,164 @@
+// Copyright 2022 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package transport
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/coinbase/rosetta-sdk-go/types"
+)
+
+// otherTransactionsContextKey is the key of the context
+// value that marks requests counted by *OtherTransactions.
+type otherTransactionsContextKey struct{}
+
+// CountOtherTransactions returns a context whose /block/transaction
+// requests are counted by *OtherTransactions. It should only be used
+// to sync blocks (other transactions fetched with any other context,
+// like when searching for missing operations, are not counted).
+func CountOtherTransactions(ctx context.Context) context.Context {
+	return context.WithValue(ctx, otherTransactionsContextKey{}, true)
+}
+
+// OtherTransactions is an http.RoundTripper that limits the number
+// of concurrent /block/transaction requests sent by the fetcher to
+// fetch the other_transactions of each block and counts the requests
+// sent with a context from CountOtherTransactions. Requests and
+// responses are passed through unmodified (the fetcher retries and
+// validates each transaction and adds it to the block).
+type OtherTransactions struct {
+	base  http.RoundTripper
+	slots chan struct{}
+
+	lock    sync.Mutex
+	fetched map[string]*fetchedBlock
+}
+
+// fetchedBlock is the other transactions
+// fetched for a block that is not yet synced.
+type fetchedBlock struct {
+	index        int64
+	transactions map[string]struct{}
+}
+
+// NewOtherTransactions returns a new *OtherTransactions that sends
+// all requests with base (and at most concurrency /block/transaction
+// requests at once).
+func NewOtherTransactions(concurrency int, base http.RoundTripper) *OtherTransactions {
+	return &OtherTransactions{
+		base:    base,
+		slots:   make(chan struct{}, concurrency),
+		fetched: map[string]*fetchedBlock{},
+	}
+}
+
+// Fetched returns the number of other transactions fetched for
+// block. Blocks are synced in order, so the transactions fetched
+// for block and for any other block at or below its index (which
+// were orphaned or fetched again) are forgotten.
+func (o *OtherTransactions) Fetched(block *types.BlockIdentifier) int64 {
+	o.lock.Lock()
+	defer o.lock.Unlock()
+
+	var count int64
+	if fetched, ok := o.fetched[block.Hash]; ok && fetched.index == block.Index {
+		count = int64(len(fetched.transactions))
+	}
+
+	for hash, fetched := range o.fetched {
+		if fetched.index <= block.Index {
+			delete(o.fetched, hash)
+		}
+	}
+
+	return count
+}
+
+// RoundTrip sends req with base and records the
+// transaction of any successful /block/transaction
+// request that is counted.
+func (o *OtherTransactions) RoundTrip(req *http.Request) (*http.Response, error) {
+	if !strings.HasSuffix(req.URL.Path, blockTransactionPath) {
+		return o.base.RoundTrip(req)
+	}
+
+	select {
+	case o.slots <- struct{}{}:
+	case <-req.Context().Done():
+		return nil, req.Context().Err()
+	}
+	resp, err := o.base.RoundTrip(req)
+	<-o.slots
+
+	if err != nil ||
+		resp.StatusCode != http.StatusOK ||
+		req.Context().Value(otherTransactionsContextKey{}) == nil {
+		return resp, err
+	}
+
+	var txRequest types.BlockTransactionRequest
+	if err := readJSON(req, &txRequest); err != nil ||
+		txRequest.BlockIdentifier == nil ||
+		txRequest.TransactionIdentifier == nil {
+		return resp, nil
+	}
+
+	o.record(txRequest.BlockIdentifier, txRequest.TransactionIdentifier.Hash)
+	return resp, nil
+}
+
+// record adds the transaction with txHash to the
+// other transactions fetched for block (retried
+// requests are only counted once).
+func (o *OtherTransactions) record(block *types.BlockIdentifier, txHash string) {
+	o.lock.Lock()
+	defer o.lock.Unlock()
+
+	fetched, ok := o.fetched[block.Hash]
+	if !ok || fetched.index != block.Index {
+		fetched = &fetchedBlock{
+			index:        block.Index,
+			transactions: map[string]struct{}{},
+		}
+		o.fetched[block.Hash] = fetched
+	}
+
+	fetched.transactions[txHash] = struct{}{}
+}
+
+// readJSON decodes the body of req into v
+// without consuming it.
+func readJSON(req *http.Request, v interface{}) error {
+	if req.GetBody == nil {
+		return errors.New("request body cannot be read again")
+	}
+
+	body, err := readBody(req)
+	if err != nil {
+		return err
+	}
+
+	if err := json.Unmarshal(body, v); err != nil {
+		return fmt.Errorf("%w: unable to parse request", err)
+	}
+
+	return nil
+}
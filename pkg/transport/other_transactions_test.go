@@ -0,0 +1,102 @@
+// Copyright 2022 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package transport
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/coinbase/rosetta-sdk-go/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOtherTransactions(t *testing.T) {
+	var inFlight, maxInFlight int64
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		current := atomic.AddInt64(&inFlight, 1)
+		defer atomic.AddInt64(&inFlight, -1)
+		for {
+			seen := atomic.LoadInt64(&maxInFlight)
+			if current <= seen || atomic.CompareAndSwapInt64(&maxInFlight, seen, current) {
+				break
+			}
+		}
+
+		time.Sleep(10 * time.Millisecond)
+		fmt.Fprint(w, `{}`)
+	}))
+	defer server.Close()
+
+	otherTransactions := NewOtherTransactions(2, http.DefaultTransport)
+	client := NewHTTPClient(0, otherTransactions)
+
+	fetch := func(ctx context.Context, index int64, txHash string) {
+		req, err := http.NewRequestWithContext(
+			ctx,
+			http.MethodPost,
+			server.URL+"/block/transaction",
+			strings.NewReader(fmt.Sprintf(
+				`{"block_identifier":{"index":%d,"hash":"block %d"},"transaction_identifier":{"hash":"%s"}}`,
+				index,
+				index,
+				txHash,
+			)),
+		)
+		assert.NoError(t, err)
+
+		resp, err := client.Do(req)
+		assert.NoError(t, err)
+		resp.Body.Close()
+	}
+
+	syncing := CountOtherTransactions(context.Background())
+	var wg sync.WaitGroup
+	for i := 0; i < 6; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			fetch(syncing, 2, fmt.Sprintf("tx %d", i%3))
+		}(i)
+	}
+	wg.Wait()
+	assert.Equal(t, int64(2), atomic.LoadInt64(&maxInFlight))
+
+	// Transactions fetched outside of syncing are not counted.
+	fetch(context.Background(), 2, "tx 3")
+	fetch(syncing, 1, "tx 4")
+	fetch(syncing, 3, "tx 5")
+
+	block := func(index int64) *types.BlockIdentifier {
+		return &types.BlockIdentifier{Index: index, Hash: fmt.Sprintf("block %d", index)}
+	}
+
+	// Retried transactions are only counted once, and blocks at
+	// or below the synced block (including orphaned blocks) are
+	// forgotten.
+	assert.Equal(t, int64(3), otherTransactions.Fetched(block(2)))
+	assert.Equal(t, int64(0), otherTransactions.Fetched(block(1)))
+	assert.Equal(t, int64(0), otherTransactions.Fetched(&types.BlockIdentifier{
+		Index: 3,
+		Hash:  "other block 3",
+	}))
+	assert.Equal(t, int64(0), otherTransactions.Fetched(block(3)))
+}
@@ -0,0 +1,73 @@
+// Copyright 2022 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package transport
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/coinbase/rosetta-cli/configuration"
+)
+
+// NewProxy returns a function that selects the proxy of each
+// request (for use as the Proxy of an *http.Transport). A nil
+// proxy URL sends the request directly.
+func NewProxy(
+	config *configuration.ProxyConfiguration,
+) (func(*http.Request) (*url.URL, error), error) {
+	var proxyURL *url.URL
+	if len(config.URL) > 0 {
+		var err error
+		proxyURL, err = url.Parse(config.URL)
+		if err != nil {
+			return nil, fmt.Errorf("%w: unable to parse proxy %s", err, config.URL)
+		}
+	}
+
+	overrides := map[string]*url.URL{}
+	for host, override := range config.Overrides {
+		if len(override) == 0 {
+			overrides[host] = nil
+			continue
+		}
+
+		overrideURL, err := url.Parse(override)
+		if err != nil {
+			return nil, fmt.Errorf("%w: unable to parse proxy %s", err, override)
+		}
+
+		overrides[host] = overrideURL
+	}
+
+	return func(req *http.Request) (*url.URL, error) {
+		if override, ok := overrides[req.URL.Host]; ok {
+			return override, nil
+		}
+
+		if proxyURL == nil {
+			return http.ProxyFromEnvironment(req)
+		}
+
+		if config.Environment {
+			environmentURL, err := http.ProxyFromEnvironment(req)
+			if err != nil || environmentURL != nil {
+				return environmentURL, err
+			}
+		}
+
+		return proxyURL, nil
+	}, nil
+}
@@ -0,0 +1,198 @@
+// Copyright 2022 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package transport
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/coinbase/rosetta-cli/configuration"
+	cliErrs "github.com/coinbase/rosetta-cli/pkg/errors"
+
+	"github.com/coinbase/rosetta-sdk-go/types"
+)
+
+// initialBackoff is the wait before the first retry.
+const initialBackoff = time.Second
+
+// RetryPolicy is an http.RoundTripper that retries requests
+// based on the class of error they receive: rate limits (429),
+// server errors (408 and 5xx other than Rosetta errors), and
+// Rosetta errors with retriable=false. All other responses
+// (including Rosetta errors with retriable=true) are returned
+// so that the fetcher retries them as usual.
+type RetryPolicy struct {
+	config     *configuration.RetryPolicyConfiguration
+	maxBackoff time.Duration
+	base       http.RoundTripper
+}
+
+// NewRetryPolicy returns a new *RetryPolicy.
+func NewRetryPolicy(
+	config *configuration.RetryPolicyConfiguration,
+	base http.RoundTripper,
+) *RetryPolicy {
+	return &RetryPolicy{
+		config:     config,
+		maxBackoff: time.Duration(config.MaxBackoff) * time.Second,
+		base:       base,
+	}
+}
+
+// RoundTrip sends req with base, retrying
+// it as allowed by its class of error.
+func (r *RetryPolicy) RoundTrip(req *http.Request) (*http.Response, error) {
+	backoff := initialBackoff
+	retries := map[string]int{}
+	for {
+		attempt, err := replayRequest(req, len(retries) > 0)
+		if err != nil {
+			return nil, err
+		}
+
+		resp, err := r.base.RoundTrip(attempt)
+		if err != nil {
+			return nil, err
+		}
+
+		class, limit, err := r.classify(resp)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(class) == 0 {
+			return resp, nil
+		}
+
+		if retries[class] >= limit || !replayable(req) {
+			// Server errors are returned as errors that are not
+			// transient so that the fetcher does not retry them.
+			if class == serverErrorClass {
+				resp.Body.Close()
+				return nil, fmt.Errorf(
+					"%w: %s received %s after %d retries",
+					cliErrs.ErrServerErrorRetriesExhausted,
+					req.URL.Path,
+					resp.Status,
+					retries[class],
+				)
+			}
+
+			return resp, nil
+		}
+
+		wait := backoff
+		if retryAfter, ok := retryAfter(resp); ok {
+			wait = retryAfter
+		}
+		if wait > r.maxBackoff {
+			wait = r.maxBackoff
+		}
+		resp.Body.Close()
+
+		retries[class]++
+		log.Printf(
+			"%s received %s: retrying after %s (%s retries: %d)\n",
+			req.URL.Path,
+			resp.Status,
+			wait,
+			class,
+			retries[class],
+		)
+
+		select {
+		case <-time.After(wait):
+		case <-req.Context().Done():
+			return nil, req.Context().Err()
+		}
+
+		backoff *= 2
+	}
+}
+
+const (
+	rateLimitClass         = "rate limit"
+	serverErrorClass       = "server error"
+	nonRetriableErrorClass = "non-retriable error"
+)
+
+// classify returns the class of error of resp (empty if
+// resp should not be retried by the policy) and the number
+// of retries allowed for the class.
+func (r *RetryPolicy) classify(resp *http.Response) (string, int, error) {
+	switch {
+	case resp.StatusCode == http.StatusTooManyRequests:
+		return rateLimitClass, r.config.RateLimitRetries, nil
+	case resp.StatusCode == http.StatusRequestTimeout ||
+		(resp.StatusCode > http.StatusInternalServerError && resp.StatusCode < 600):
+		return serverErrorClass, r.config.ServerErrorRetries, nil
+	case resp.StatusCode != http.StatusInternalServerError:
+		return "", 0, nil
+	}
+
+	// The body of a 500 is read to determine if it is a Rosetta
+	// error (and restored so that the client can decode it).
+	body, err := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return "", 0, fmt.Errorf("%w: unable to read response body", err)
+	}
+	resp.Body = ioutil.NopCloser(bytes.NewReader(body))
+
+	var rosettaErr types.Error
+	if err := json.Unmarshal(body, &rosettaErr); err != nil || len(rosettaErr.Message) == 0 {
+		return serverErrorClass, r.config.ServerErrorRetries, nil
+	}
+
+	if rosettaErr.Retriable {
+		return "", 0, nil
+	}
+
+	return nonRetriableErrorClass, r.config.NonRetriableErrorRetries, nil
+}
+
+// retryAfter returns the wait requested by
+// the Retry-After header of resp (if any).
+func retryAfter(resp *http.Response) (time.Duration, bool) {
+	seconds, err := strconv.ParseInt(resp.Header.Get("Retry-After"), 10, 64)
+	if err != nil || seconds < 0 {
+		return 0, false
+	}
+
+	return time.Duration(seconds) * time.Second, true
+}
+
+// replayRequest returns req for the first attempt
+// and a copy of req with a new body for retries.
+func replayRequest(req *http.Request, retry bool) (*http.Request, error) {
+	if !retry || req.GetBody == nil {
+		return req, nil
+	}
+
+	body, err := req.GetBody()
+	if err != nil {
+		return nil, fmt.Errorf("%w: unable to replay request body", err)
+	}
+
+	attempt := req.Clone(req.Context())
+	attempt.Body = body
+	return attempt, nil
+}
@@ -0,0 +1,56 @@
+// Copyright 2022 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package transport
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/coinbase/rosetta-cli/configuration"
+	cliErrs "github.com/coinbase/rosetta-cli/pkg/errors"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRetryPolicy(t *testing.T) {
+	statuses := []int{
+		http.StatusTooManyRequests,
+		http.StatusOK,
+		http.StatusServiceUnavailable,
+		http.StatusServiceUnavailable,
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(statuses[0])
+		statuses = statuses[1:]
+	}))
+	defer server.Close()
+
+	client := NewHTTPClient(0, NewRetryPolicy(&configuration.RetryPolicyConfiguration{
+		RateLimitRetries:   1,
+		ServerErrorRetries: 1,
+	}, http.DefaultTransport))
+
+	resp, err := client.Post(server.URL+"/block", "application/json", strings.NewReader("{}"))
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	resp.Body.Close()
+
+	_, err = client.Post(server.URL+"/block", "application/json", strings.NewReader("{}"))
+	assert.True(t, errors.Is(err, cliErrs.ErrServerErrorRetriesExhausted))
+	assert.Empty(t, statuses)
+}
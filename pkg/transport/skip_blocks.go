@@ -0,0 +1,171 @@
+// Copyright 2022 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package transport
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/coinbase/rosetta-sdk-go/types"
+)
+
+// SkipBlocks is an http.RoundTripper that removes the
+// transactions (and other transactions) from the /block
+// responses of skipped blocks, so their contents are never
+// asserted or applied to balances. Only responses to requests
+// that may identify a skipped block are decoded.
+type SkipBlocks struct {
+	skipBlocks []*types.PartialBlockIdentifier
+	base       http.RoundTripper
+}
+
+// NewSkipBlocks returns a new *SkipBlocks that skips any
+// block matching the index or hash of any of skipBlocks.
+func NewSkipBlocks(
+	skipBlocks []*types.PartialBlockIdentifier,
+	base http.RoundTripper,
+) *SkipBlocks {
+	return &SkipBlocks{
+		skipBlocks: skipBlocks,
+		base:       base,
+	}
+}
+
+// matches returns a boolean indicating if block could be
+// any of skipBlocks (fields missing from block match any
+// value).
+func (s *SkipBlocks) matches(block *types.PartialBlockIdentifier) bool {
+	for _, skipBlock := range s.skipBlocks {
+		if skipBlock.Index != nil && block.Index != nil && *skipBlock.Index != *block.Index {
+			continue
+		}
+
+		if skipBlock.Hash != nil && block.Hash != nil && *skipBlock.Hash != *block.Hash {
+			continue
+		}
+
+		return true
+	}
+
+	return false
+}
+
+// RoundTrip sends req with base and removes the transactions
+// from the response if it is a skipped block.
+func (s *SkipBlocks) RoundTrip(req *http.Request) (*http.Response, error) {
+	if !strings.HasSuffix(req.URL.Path, blockPath) {
+		return s.base.RoundTrip(req)
+	}
+
+	var blockRequest types.BlockRequest
+	if err := readJSON(req, &blockRequest); err != nil ||
+		blockRequest.BlockIdentifier == nil ||
+		!s.matches(blockRequest.BlockIdentifier) {
+		return s.base.RoundTrip(req)
+	}
+
+	resp, err := s.base.RoundTrip(req)
+	if err != nil || resp.StatusCode != http.StatusOK {
+		return resp, err
+	}
+
+	body, err := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, fmt.Errorf("%w: unable to read block response", err)
+	}
+
+	body, err = s.strip(body)
+	if err != nil {
+		return nil, err
+	}
+
+	setBody(resp, body)
+	return resp, nil
+}
+
+// strip returns body without the transactions of the block
+// if the block is skipped (a block requested by index may
+// not have the hash of a skipped block). Only the block
+// identifier must be well-formed to be skipped.
+func (s *SkipBlocks) strip(body []byte) ([]byte, error) {
+	var response struct {
+		Block *struct {
+			BlockIdentifier *types.BlockIdentifier `json:"block_identifier"`
+		} `json:"block"`
+	}
+	if err := json.Unmarshal(body, &response); err != nil ||
+		response.Block == nil ||
+		response.Block.BlockIdentifier == nil ||
+		!s.matches(types.ConstructPartialBlockIdentifier(response.Block.BlockIdentifier)) {
+		return body, nil
+	}
+
+	stripped, err := withoutTransactions(body)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Printf(
+		"skipping transactions of block %s\n",
+		types.PrintStruct(response.Block.BlockIdentifier),
+	)
+
+	return stripped, nil
+}
+
+// withoutTransactions returns the /block response body
+// without the transactions (or other transactions) of
+// the block.
+func withoutTransactions(body []byte) ([]byte, error) {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(body, &fields); err != nil {
+		return nil, fmt.Errorf("%w: unable to parse block response", err)
+	}
+
+	var block map[string]json.RawMessage
+	if err := json.Unmarshal(fields["block"], &block); err != nil {
+		return nil, fmt.Errorf("%w: unable to parse block", err)
+	}
+
+	block["transactions"] = json.RawMessage("[]")
+	delete(fields, "other_transactions")
+
+	strippedBlock, err := json.Marshal(block)
+	if err != nil {
+		return nil, fmt.Errorf("%w: unable to encode block", err)
+	}
+	fields["block"] = strippedBlock
+
+	stripped, err := json.Marshal(fields)
+	if err != nil {
+		return nil, fmt.Errorf("%w: unable to encode block response", err)
+	}
+
+	return stripped, nil
+}
+
+// setBody replaces the body of resp with body.
+func setBody(resp *http.Response, body []byte) {
+	resp.Body = ioutil.NopCloser(bytes.NewReader(body))
+	resp.ContentLength = int64(len(body))
+	resp.Header.Set("Content-Length", strconv.Itoa(len(body)))
+}
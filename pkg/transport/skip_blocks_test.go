@@ -0,0 +1,83 @@
+// Copyright 2022 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package transport
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/coinbase/rosetta-sdk-go/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSkipBlocks(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var request types.BlockRequest
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&request))
+
+		// The transaction is malformed (it has no identifier).
+		fmt.Fprintf(
+			w,
+			`{"block":{"block_identifier":{"index":%d,"hash":"block %d"},"transactions":[{"operations":[]}]},"other_transactions":[{"hash":"tx"}]}`,
+			*request.BlockIdentifier.Index,
+			*request.BlockIdentifier.Index,
+		)
+	}))
+	defer server.Close()
+
+	client := NewHTTPClient(0, NewSkipBlocks(
+		[]*types.PartialBlockIdentifier{
+			{Index: types.Int64(2)},
+			{Index: types.Int64(3), Hash: types.String("other block 3")},
+		},
+		http.DefaultTransport,
+	))
+
+	fetch := func(index int64) map[string]json.RawMessage {
+		resp, err := client.Post(
+			server.URL+"/block",
+			"application/json",
+			strings.NewReader(fmt.Sprintf(`{"block_identifier":{"index":%d}}`, index)),
+		)
+		assert.NoError(t, err)
+		defer resp.Body.Close()
+
+		var response map[string]json.RawMessage
+		assert.NoError(t, json.NewDecoder(resp.Body).Decode(&response))
+
+		return response
+	}
+
+	// Skipped blocks are returned without their transactions.
+	skipped := fetch(2)
+	var block types.Block
+	assert.NoError(t, json.Unmarshal(skipped["block"], &block))
+	assert.Equal(t, &types.BlockIdentifier{Index: 2, Hash: "block 2"}, block.BlockIdentifier)
+	assert.Empty(t, block.Transactions)
+	assert.NotContains(t, skipped, "other_transactions")
+
+	// Other blocks (and blocks skipped with another hash) are unmodified.
+	for _, index := range []int64{1, 3} {
+		response := fetch(index)
+		block = types.Block{}
+		assert.NoError(t, json.Unmarshal(response["block"], &block))
+		assert.Len(t, block.Transactions, 1)
+		assert.Contains(t, response, "other_transactions")
+	}
+}
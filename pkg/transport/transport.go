@@ -0,0 +1,89 @@
+// Copyright 2022 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package transport
+
+import (
+	"crypto/tls"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/coinbase/rosetta-cli/configuration"
+
+	"github.com/coinbase/rosetta-sdk-go/fetcher"
+)
+
+// dialTimeout is the maximum amount of time
+// to wait for a connection to be established.
+const dialTimeout = 30 * time.Second
+
+// NewHTTPTransport returns an *http.Transport configured like
+// the default transport of a *fetcher.Fetcher, tuned by
+// config (if populated).
+func NewHTTPTransport(
+	maxConnections int,
+	config *configuration.HTTPTransportConfiguration,
+) *http.Transport {
+	// See this conversation around why `.Clone()` is used here:
+	// https://github.com/golang/go/issues/26013
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.IdleConnTimeout = fetcher.DefaultIdleConnTimeout
+	transport.MaxIdleConns = maxConnections
+	transport.MaxIdleConnsPerHost = fetcher.DefaultMaxConnections
+	if config == nil {
+		return transport
+	}
+
+	if config.KeepAlive > 0 {
+		dialer := &net.Dialer{
+			Timeout:   dialTimeout,
+			KeepAlive: time.Duration(config.KeepAlive) * time.Second,
+		}
+		transport.DialContext = dialer.DialContext
+	}
+
+	if config.IdleConnectionTimeout > 0 {
+		transport.IdleConnTimeout = time.Duration(config.IdleConnectionTimeout) * time.Second
+	}
+
+	if config.MaxIdleConnections > 0 {
+		transport.MaxIdleConns = config.MaxIdleConnections
+	}
+
+	if config.MaxIdleConnectionsPerHost > 0 {
+		transport.MaxIdleConnsPerHost = config.MaxIdleConnectionsPerHost
+	}
+
+	transport.MaxConnsPerHost = config.MaxConnectionsPerHost
+	transport.DisableKeepAlives = config.ConnectionReuseDisabled
+
+	// A non-nil, empty TLSNextProto prevents
+	// HTTP/2 from being negotiated over TLS.
+	if config.HTTP2Disabled {
+		transport.ForceAttemptHTTP2 = false
+		transport.TLSNextProto = map[string]func(string, *tls.Conn) http.RoundTripper{}
+	}
+
+	return transport
+}
+
+// NewHTTPClient returns an *http.Client that sends
+// requests with roundTripper.
+func NewHTTPClient(timeout time.Duration, roundTripper http.RoundTripper) *http.Client {
+	return &http.Client{
+		Timeout:   timeout,
+		Transport: roundTripper,
+	}
+}